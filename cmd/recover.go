@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"key_recovery/modules/backup"
+	"key_recovery/modules/configuration"
+	crypto_protocols "key_recovery/modules/crypto"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"key_recovery/modules/shamir"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	recoverInputDir  string
+	recoverOutput    string
+	recoverScheme    string
+	recoverThreshold int
+	recoverTimeout   time.Duration
+	recoverRobust    bool
+)
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "Recover a secret from collected packets",
+	Long: `Recover a secret from a directory of packet files collected from the
+members of the anonymity set
+If the directory contains an encrypted payload, the recovered data key is
+used for decrypting it and the original file is given back`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("threshold") || !cmd.Flags().Changed("workers") {
+			cfg, err := configuration.NewSimulationConfig(configFilePath)
+			if err != nil {
+				return fmt.Errorf("error in accessing the config file: %w", err)
+			}
+			if !cmd.Flags().Changed("threshold") {
+				recoverThreshold = cfg.DefaultAbsoluteThreshold
+			}
+			setNoOfWorkers(cmd, cfg)
+		} else {
+			setNoOfWorkers(cmd, nil)
+		}
+		packets, err := backup.ReadPacketFiles(recoverInputDir)
+		if err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Println("Packets obtained:", len(packets))
+		}
+		// The scheme is stored in the header of every packet
+		if !cmd.Flags().Changed("scheme") {
+			recoverScheme, err = backup.PacketScheme(packets[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx := context.Background()
+		if recoverTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, recoverTimeout)
+			defer cancel()
+		}
+		var f shamir.Field
+		var secret []byte
+		if recoverRobust {
+			if recoverScheme != backup.SchemeThresholded {
+				return fmt.Errorf("robust recovery is only supported for the %s scheme",
+					backup.SchemeThresholded)
+			}
+			var inconsistent []secretbe.InconsistentShare
+			secret, inconsistent, err = backup.RecoverSecretRobust(ctx, f,
+				recoverThreshold, packets, noOfWorkers)
+			// The packets are read in the lexical order of their file names
+			for _, shareVal := range inconsistent {
+				fmt.Fprintf(os.Stderr, "Inconsistent share in packet %d (part %d, x = %d)\n",
+					shareVal.Packet, shareVal.SecretIndex, shareVal.X)
+			}
+		} else {
+			secret, err = backup.RecoverSecret(ctx, f, recoverScheme,
+				recoverThreshold, packets, noOfWorkers)
+		}
+		if err != nil {
+			return err
+		}
+		// The secret is the data key of the payload if the packets come with
+		// an encrypted payload
+		blob, err := backup.ReadPayloadFile(recoverInputDir)
+		if err == nil {
+			secret, err = crypto_protocols.OpenPayload(secret, blob)
+			if err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if recoverOutput == "" || recoverOutput == "-" {
+			_, err = os.Stdout.Write(secret)
+			return err
+		}
+		return os.WriteFile(recoverOutput, secret, 0600)
+	},
+}
+
+func init() {
+	flags := recoverCmd.Flags()
+	flags.StringVarP(&recoverInputDir, "input", "i", "packets", "Directory containing the collected packet files (binary, armored or qr)")
+	flags.StringVarP(&recoverOutput, "output", "o", "", "File for storing the recovered secret (stdout if empty or -)")
+	flags.StringVarP(&recoverScheme, "scheme", "s", backup.SchemeAdditive, "Scheme used for sharing - additive, thresholded or hinted (read from the packets if not provided)")
+	flags.IntVar(&recoverThreshold, "threshold", 0, "Absolute threshold of the leaves layer")
+	flags.BoolVar(&recoverRobust, "robust", false, "Check the shares of the recovered subsecrets and report the inconsistent ones (thresholded scheme only)")
+	flags.DurationVar(&recoverTimeout, "timeout", 0, "Time budget for the recovery, e.g. 10m (no limit if 0)")
+	rootCmd.AddCommand(recoverCmd)
+}
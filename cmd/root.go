@@ -5,39 +5,76 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/evaluation"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
+	secretbe "key_recovery/modules/secret_binary_extension"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// Default values of the parameters are stored in this file
+const configFilePath = "modules/configuration/config.yaml"
+
 var (
 	evalType         int
 	varyingParameter int
 	verbose          bool
+	noOfWorkers      int
+	specFilePath     string
+	showSpec         bool
+	seed             int64
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "key_recovery",
 	Short: "Key Recovery",
 	Long:  `Running and evaluating the defined key recovery mechanism`,
+	// The seed is a persistent flag, so it is checked for every subcommand
+	// The splitting of real secrets never takes a seed, so the packets of
+	// split always come from crypto/rand
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("seed") {
+			return nil
+		}
+		if cmd == splitCmd {
+			return fmt.Errorf("--seed cannot be used with split, whose packets always come from crypto/rand")
+		}
+		randomness.SetSeed(seed)
+		if verbose {
+			fmt.Println("Seed:", seed)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if verbose {
 			fmt.Println("Verbose mode enabled")
 		}
-		configFilePath := "modules/configuration/config.yaml"
 		cfg, err := configuration.NewSimulationConfig(configFilePath)
 		if err != nil {
 			fmt.Println("Error in accessing the config file", err)
 			fmt.Println(err)
 		}
+		setNoOfWorkers(cmd, cfg)
+		setSeed(cmd, cfg)
+
+		if showSpec {
+			printPresetSpec(cfg)
+			return
+		}
 
 		// Get the current timestamp
 		timestamp := time.Now().Unix()
 
 		mainDir := "/" + strconv.Itoa(int(timestamp)) + "/"
 
+		if specFilePath != "" {
+			runSpec(cfg, "results-experiments"+mainDir)
+			return
+		}
+
 		switch {
 		case evalType%3 == 0:
 			mainDir = "results-computation-parallelized" + mainDir
@@ -67,8 +104,77 @@ func Execute() {
 	}
 }
 
+// Sets the number of routines for the recovery from the flag or else from
+// the config file
+// The config keeps the value as the evaluation passes it to every recovery
+func setNoOfWorkers(cmd *cobra.Command, cfg *configuration.SimulationConfig) {
+	if !cmd.Flags().Changed("workers") && cfg != nil {
+		noOfWorkers = cfg.NoOfWorkers
+	}
+	if cfg != nil {
+		cfg.NoOfWorkers = noOfWorkers
+	}
+	if verbose {
+		fmt.Println("Recovery routines:", secretbe.NoOfWorkers(noOfWorkers))
+	}
+}
+
+// Sets the seed of the simulations and the access orders from the flag or
+// else from the config file
+// The config keeps the seed as the evaluation builds its generators from it
+func setSeed(cmd *cobra.Command, cfg *configuration.SimulationConfig) {
+	if cfg == nil {
+		return
+	}
+	if cmd.Flags().Changed("seed") {
+		cfg.Seed = &seed
+	} else if cfg.Seed != nil {
+		randomness.SetSeed(*cfg.Seed)
+	}
+}
+
+// Runs the experiment described by the spec file
+func runSpec(cfg *configuration.SimulationConfig, mainDir string) {
+	spec, err := configuration.NewExperimentSpec(specFilePath)
+	if err != nil {
+		fmt.Println("Error in reading the experiment spec:", err)
+		return
+	}
+	err, _ = files.CreateDirectory(mainDir)
+	if err != nil {
+		fmt.Println("Error creating directory:", err)
+		return
+	}
+	err = evaluation.RunExperiment(cfg, mainDir, spec)
+	if err != nil {
+		fmt.Println("Error in running the experiment:", err)
+		return
+	}
+	fmt.Println("Results stored in", mainDir+spec.Name)
+}
+
+// Prints the spec that the -t and -p codes stand for so that it can be
+// edited and run with --spec
+func printPresetSpec(cfg *configuration.SimulationConfig) {
+	spec, ok := evaluation.PresetSpec(cfg, evalType, varyingParameter)
+	if !ok {
+		fmt.Println("No spec for type", evalType, "and parameter", varyingParameter)
+		return
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(string(data))
+}
+
 func init() {
 	rootCmd.Flags().IntVarP(&evalType, "type", "t", 0, "Evaluation type - either the run evaluates the computation cost or the probability")
 	rootCmd.Flags().IntVarP(&varyingParameter, "parameter", "p", 0, "Parameter to be varied during evaluation")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose mode")
+	rootCmd.Flags().StringVar(&specFilePath, "spec", "", "YAML file describing the experiment to run instead of -t and -p")
+	rootCmd.Flags().BoolVar(&showSpec, "show-spec", false, "Print the spec of -t and -p instead of running it")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose mode")
+	rootCmd.PersistentFlags().Int64Var(&seed, "seed", 0, "Seed that makes the run reproducible (crypto/rand if not provided, not accepted by split)")
+	rootCmd.PersistentFlags().IntVar(&noOfWorkers, "workers", 0, "Number of routines used for the recovery (number of CPUs if 0)")
 }
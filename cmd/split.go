@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"key_recovery/modules/backup"
+	"key_recovery/modules/configuration"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitInput        string
+	splitOutputDir    string
+	splitFormat       string
+	splitParameters   backup.Parameters
+	splitVerifierCost uint8
+	splitPayload      bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split a secret into packets",
+	Long: `Split a secret read from a file (or stdin) into one packet per member
+of the anonymity set with MLSS (additive), TMLSS (thresholded) or
+HMLSS (hinted)
+The packets are shuffled, and the packets of the trustees (in the order of
+the weights) are listed so that they can be handed to the right people
+With --payload, the input is encrypted under a random data key which is
+split instead, and the encrypted file is stored next to the packets`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := configuration.NewSimulationConfig(configFilePath)
+		if err != nil {
+			return fmt.Errorf("error in accessing the config file: %w", err)
+		}
+		applySplitDefaults(cmd, cfg)
+
+		var secret []byte
+		if splitInput == "" || splitInput == "-" {
+			secret, err = io.ReadAll(os.Stdin)
+		} else {
+			secret, err = os.ReadFile(splitInput)
+		}
+		if err != nil {
+			return err
+		}
+
+		if splitVerifierCost != 0 {
+			// The thresholded and hinted packets have no verifier of the
+			// secret key whose parameters could be recorded
+			if splitParameters.Scheme != backup.SchemeAdditive {
+				return fmt.Errorf("--verifier-cost cannot be used with the %s scheme: %w",
+					splitParameters.Scheme, errors.ErrVerifierSchemeMismatch)
+			}
+			splitParameters.Verifier = crypto_protocols.VerifierParams{
+				LogN: splitVerifierCost, R: 8, P: 1}
+		}
+
+		var f shamir.Field
+		var packets [][]byte
+		var owners []int
+		var blob []byte
+		if splitPayload {
+			packets, owners, blob, err = backup.SplitPayload(f,
+				splitParameters, secret)
+		} else {
+			packets, owners, err = backup.SplitSecret(f, splitParameters,
+				secret)
+		}
+		if err != nil {
+			return err
+		}
+		filenames, err := backup.WritePacketFiles(splitOutputDir, packets,
+			splitFormat)
+		if err != nil {
+			return err
+		}
+		if splitPayload {
+			filename, err := backup.WritePayloadFile(splitOutputDir, blob)
+			if err != nil {
+				return err
+			}
+			filenames = append(filenames, filename)
+		}
+		if verbose {
+			for _, filename := range filenames {
+				fmt.Println(filename)
+			}
+		}
+		fmt.Printf("Wrote %d packets to %s\n", len(packets), splitOutputDir)
+		for i, owner := range owners {
+			if owner != -1 {
+				fmt.Printf("Trustee %d: %s\n", owner+1,
+					filepath.Base(filenames[i]))
+			}
+		}
+		return nil
+	},
+}
+
+// The parameters which are not provided as flags are taken from the
+// config file
+func applySplitDefaults(cmd *cobra.Command,
+	cfg *configuration.SimulationConfig) {
+	flags := cmd.Flags()
+	if !flags.Changed("trustees") {
+		splitParameters.Trustees = cfg.DefaultTrustees
+	}
+	if !flags.Changed("anonymity") {
+		splitParameters.AnonymitySetSize = cfg.DefaultAnonymitySetSize
+	}
+	if !flags.Changed("threshold") {
+		splitParameters.AbsoluteThreshold = cfg.DefaultAbsoluteThreshold
+	}
+	if !flags.Changed("subsecrets") {
+		splitParameters.NoOfSubsecrets = cfg.DefaultNoOfSubsecrets
+	}
+	if !flags.Changed("percentage") {
+		splitParameters.PercentageLeavesLayerThreshold = cfg.DefaultPercentageThreshold
+	}
+	if !flags.Changed("upper-percentage") {
+		splitParameters.PercentageUpperLayerThreshold = cfg.DefaultSubsecretsThreshold
+	}
+	if !flags.Changed("hints") {
+		splitParameters.NoOfHints = cfg.DefaultTrusteesHint
+	}
+}
+
+func init() {
+	flags := splitCmd.Flags()
+	flags.StringVarP(&splitInput, "input", "i", "", "File containing the secret (stdin if empty or -)")
+	flags.StringVarP(&splitOutputDir, "output", "o", "packets", "Directory for storing the packet files")
+	flags.StringVarP(&splitFormat, "format", "f", backup.FormatBinary, "Format of the packet files - binary, armor (base32), armor64 (base64) or qr")
+	flags.StringVarP(&splitParameters.Scheme, "scheme", "s", backup.SchemeAdditive, "Scheme used for sharing - additive, thresholded or hinted")
+	flags.IntVarP(&splitParameters.Trustees, "trustees", "n", 0, "Number of trustees")
+	flags.IntVarP(&splitParameters.AnonymitySetSize, "anonymity", "a", 0, "Size of the anonymity set")
+	flags.IntVar(&splitParameters.AbsoluteThreshold, "threshold", 0, "Absolute threshold of the leaves layer")
+	flags.IntVar(&splitParameters.NoOfSubsecrets, "subsecrets", 0, "Number of subsecrets")
+	flags.IntVar(&splitParameters.PercentageLeavesLayerThreshold, "percentage", 0, "Percentage threshold of the leaves layer")
+	flags.IntVar(&splitParameters.PercentageUpperLayerThreshold, "upper-percentage", 0, "Percentage threshold of the subsecrets layer (thresholded)")
+	flags.IntVar(&splitParameters.NoOfHints, "hints", 0, "Number of hinted trustees (hinted)")
+	flags.IntSliceVarP(&splitParameters.Weights, "weights", "w", nil, "Comma-separated weights of the trustees (uniform if not provided)")
+	flags.Uint8Var(&splitVerifierCost, "verifier-cost", 0, "Log2 of the scrypt cost of the verifier of the secret (additive only, 0 for the fast verifier)")
+	flags.BoolVar(&splitPayload, "payload", false, "Encrypt the input as a payload of any size and split only the data key")
+	rootCmd.AddCommand(splitCmd)
+}
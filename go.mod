@@ -5,6 +5,7 @@ go 1.21.4
 require (
 	github.com/spf13/cobra v1.8.0
 	go.dedis.ch/kyber/v3 v3.1.0
+	golang.org/x/crypto v0.0.0-20190123085648-057139ce5d2b
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -12,6 +13,5 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	go.dedis.ch/fixbuf v1.0.3 // indirect
-	golang.org/x/crypto v0.0.0-20190123085648-057139ce5d2b // indirect
 	golang.org/x/sys v0.0.0-20190124100055-b90733256f2e // indirect
 )
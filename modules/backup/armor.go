@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"key_recovery/modules/errors"
+	"strconv"
+	"strings"
+)
+
+// Text forms of the packets for storing them on paper, in a password
+// manager or in QR codes
+// Both forms carry the CRC-32 of the binary packet so that typos are
+// detected before the packet is used for recovery
+const (
+	ArmorBase32 = "base32"
+	ArmorBase64 = "base64"
+
+	armorBeginLine  = "-----BEGIN KEY RECOVERY PACKET-----"
+	armorEndLine    = "-----END KEY RECOVERY PACKET-----"
+	armorLineLength = 64
+
+	// Chunks only use the alphanumeric mode characters of QR codes
+	// KRP:<chunk number>/<total chunks>:<checksum>:<base32 data>
+	qrChunkPrefix = "KRP:"
+	// Default number of base32 characters in a chunk
+	// This fits in a QR code of version 20 with the medium error correction
+	QRChunkSize = 800
+)
+
+var armorBase32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func packetChecksum(packet []byte) string {
+	return fmt.Sprintf("%08X", crc32.ChecksumIEEE(packet))
+}
+
+// ArmorPacket returns the armored text form of a binary packet
+//
+//	-----BEGIN KEY RECOVERY PACKET-----
+//	Encoding: base32
+//	Checksum: 1A2B3C4D
+//
+//	<encoded packet wrapped at 64 characters>
+//	-----END KEY RECOVERY PACKET-----
+func ArmorPacket(packet []byte, encoding string) (string, error) {
+	var encoded string
+	switch encoding {
+	case ArmorBase32:
+		encoded = armorBase32Encoding.EncodeToString(packet)
+	case ArmorBase64:
+		encoded = base64.StdEncoding.EncodeToString(packet)
+	default:
+		return "", errors.ErrInvalidArmor
+	}
+	var sb strings.Builder
+	sb.WriteString(armorBeginLine + "\n")
+	sb.WriteString("Encoding: " + encoding + "\n")
+	sb.WriteString("Checksum: " + packetChecksum(packet) + "\n\n")
+	for len(encoded) > armorLineLength {
+		sb.WriteString(encoded[:armorLineLength] + "\n")
+		encoded = encoded[armorLineLength:]
+	}
+	if len(encoded) > 0 {
+		sb.WriteString(encoded + "\n")
+	}
+	sb.WriteString(armorEndLine + "\n")
+	return sb.String(), nil
+}
+
+// DearmorPacket returns the binary packet from its armored text form
+// The whitespace inside the body is ignored and base32 is case-insensitive
+func DearmorPacket(text string) ([]byte, error) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(nil, 1<<20)
+	headers := make(map[string]string)
+	var body strings.Builder
+	begun, ended, inBody := false, false, false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case !begun:
+			begun = line == armorBeginLine
+		case line == armorEndLine:
+			ended = true
+		case ended:
+		case !inBody && line == "":
+			inBody = true
+		case !inBody:
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				return nil, errors.ErrInvalidArmor
+			}
+			headers[strings.ToLower(strings.TrimSpace(key))] =
+				strings.TrimSpace(value)
+		default:
+			body.WriteString(strings.Join(strings.Fields(line), ""))
+		}
+		if ended {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, errors.ErrInvalidArmor
+	}
+	var packet []byte
+	var err error
+	switch headers["encoding"] {
+	case ArmorBase32:
+		packet, err = armorBase32Encoding.DecodeString(
+			strings.ToUpper(body.String()))
+	case ArmorBase64:
+		packet, err = base64.StdEncoding.DecodeString(body.String())
+	default:
+		return nil, errors.ErrInvalidArmor
+	}
+	// Typos that break the encoding itself are also reported as checksum
+	// mismatches
+	if err != nil ||
+		!strings.EqualFold(headers["checksum"], packetChecksum(packet)) {
+		return nil, errors.ErrChecksumMismatch
+	}
+	return packet, nil
+}
+
+// SplitQRChunks splits a binary packet into text chunks with at most
+// chunkSize characters of data each, so that every chunk fits in a QR code
+func SplitQRChunks(packet []byte, chunkSize int) ([]string, error) {
+	if chunkSize <= 0 {
+		return nil, errors.ErrInvalidInput
+	}
+	encoded := armorBase32Encoding.EncodeToString(packet)
+	noOfChunks := (len(encoded) + chunkSize - 1) / chunkSize
+	if noOfChunks == 0 {
+		noOfChunks = 1
+	}
+	checksum := packetChecksum(packet)
+	var chunks []string
+	for i := 0; i < noOfChunks; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		chunks = append(chunks, fmt.Sprintf("%s%d/%d:%s:%s", qrChunkPrefix,
+			i+1, noOfChunks, checksum, encoded[i*chunkSize:end]))
+	}
+	return chunks, nil
+}
+
+// JoinQRChunks returns the binary packet from its chunks
+// The chunks can be provided in any order
+func JoinQRChunks(chunks []string) ([]byte, error) {
+	if len(chunks) == 0 {
+		return nil, errors.ErrInvalidChunk
+	}
+	parts := make([]string, len(chunks))
+	checksum := ""
+	for _, chunk := range chunks {
+		chunk = strings.ToUpper(strings.TrimSpace(chunk))
+		fields := strings.SplitN(strings.TrimPrefix(chunk, qrChunkPrefix),
+			":", 3)
+		if !strings.HasPrefix(chunk, qrChunkPrefix) || len(fields) != 3 {
+			return nil, errors.ErrInvalidChunk
+		}
+		number, total, found := strings.Cut(fields[0], "/")
+		chunkNum, err1 := strconv.Atoi(number)
+		noOfChunks, err2 := strconv.Atoi(total)
+		if !found || err1 != nil || err2 != nil || noOfChunks != len(chunks) ||
+			chunkNum < 1 || chunkNum > noOfChunks || parts[chunkNum-1] != "" {
+			return nil, errors.ErrInvalidChunk
+		}
+		if checksum == "" {
+			checksum = fields[1]
+		} else if checksum != fields[1] {
+			return nil, errors.ErrInvalidChunk
+		}
+		parts[chunkNum-1] = fields[2]
+	}
+	packet, err := armorBase32Encoding.DecodeString(strings.Join(parts, ""))
+	if err != nil || checksum != packetChecksum(packet) {
+		return nil, errors.ErrChecksumMismatch
+	}
+	return packet, nil
+}
@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"bytes"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+	"strings"
+	"testing"
+)
+
+func generateTestPacket(t *testing.T) []byte {
+	var f shamir.Field
+	params := Parameters{SchemeThresholded, 5, 8, 3, 3, 50, 100, 2, nil,
+		crypto_protocols.VerifierParams{}}
+	packets, _, err := SplitSecret(f, params, []byte("test secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+func TestArmorPacket(t *testing.T) {
+	packet := generateTestPacket(t)
+	for _, encoding := range []string{ArmorBase32, ArmorBase64} {
+		text, err := ArmorPacket(packet, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(text, "\n") {
+			if len(line) > armorLineLength {
+				t.Error("Line is not wrapped", len(line))
+			}
+		}
+		decoded, err := DearmorPacket(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, packet) {
+			t.Error("Packet changed after armoring", encoding)
+		}
+		// A typo in the body must be detected by the checksum
+		lines := strings.Split(text, "\n")
+		typoLine := []byte(lines[5])
+		if typoLine[10] == 'A' {
+			typoLine[10] = 'B'
+		} else {
+			typoLine[10] = 'A'
+		}
+		lines[5] = string(typoLine)
+		_, err = DearmorPacket(strings.Join(lines, "\n"))
+		if err != errors.ErrChecksumMismatch {
+			t.Error("Typo not detected", encoding, err)
+		}
+	}
+	// Base32 can be typed in lower case
+	text, _ := ArmorPacket(packet, ArmorBase32)
+	lines := strings.Split(text, "\n")
+	for i := 4; i < len(lines)-2; i++ {
+		lines[i] = strings.ToLower(lines[i])
+	}
+	decoded, err := DearmorPacket(strings.Join(lines, "\n"))
+	if err != nil || !bytes.Equal(decoded, packet) {
+		t.Error("Lower case base32 not accepted", err)
+	}
+	if _, err := DearmorPacket(text[:len(text)-20]); err != errors.ErrInvalidArmor {
+		t.Error("Armor without the end line accepted", err)
+	}
+}
+
+func TestQRChunks(t *testing.T) {
+	packet := generateTestPacket(t)
+	chunks, err := SplitQRChunks(packet, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatal("Packet not split into chunks", len(chunks))
+	}
+	// QR alphanumeric mode characters
+	const qrCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	for _, chunk := range chunks {
+		for _, c := range chunk {
+			if !strings.ContainsRune(qrCharset, c) {
+				t.Fatal("Chunk is not QR alphanumeric", string(c))
+			}
+		}
+	}
+	reversed := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		reversed[len(chunks)-1-i] = chunk
+	}
+	decoded, err := JoinQRChunks(reversed)
+	if err != nil || !bytes.Equal(decoded, packet) {
+		t.Error("Packet changed after chunking", err)
+	}
+	if _, err := JoinQRChunks(chunks[1:]); err != errors.ErrInvalidChunk {
+		t.Error("Missing chunk not detected", err)
+	}
+	corrupted := append([]string(nil), chunks...)
+	last := []byte(corrupted[0])
+	if last[len(last)-1] == 'A' {
+		last[len(last)-1] = 'B'
+	} else {
+		last[len(last)-1] = 'A'
+	}
+	corrupted[0] = string(last)
+	if _, err := JoinQRChunks(corrupted); err != errors.ErrChecksumMismatch {
+		t.Error("Typo not detected", err)
+	}
+}
+
+func TestPacketFileFormats(t *testing.T) {
+	packet := generateTestPacket(t)
+	for _, format := range []string{FormatBinary, FormatArmor,
+		FormatArmorBase64, FormatQR} {
+		dir := t.TempDir()
+		if _, err := WritePacketFiles(dir, [][]byte{packet}, format); err != nil {
+			t.Fatal(err)
+		}
+		packets, err := ReadPacketFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packets) != 1 || !bytes.Equal(packets[0], packet) {
+			t.Error("Packet changed after storing", format)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/shamir"
+	"os"
+	"testing"
+)
+
+// Parameters of the tests for the scheme and the threshold of the upper
+// layer, which the test cases change
+func testParameters(scheme string, upperThreshold int) Parameters {
+	return Parameters{
+		Scheme:                         scheme,
+		Trustees:                       5,
+		AnonymitySetSize:               8,
+		AbsoluteThreshold:              3,
+		NoOfSubsecrets:                 3,
+		PercentageLeavesLayerThreshold: 50,
+		PercentageUpperLayerThreshold:  upperThreshold,
+		NoOfHints:                      2,
+	}
+}
+
+func TestSplitRecoverSecret(t *testing.T) {
+	var f shamir.Field
+	secret := []byte("correct horse battery staple")
+	weightedAdditive := testParameters(SchemeAdditive, 100)
+	weightedAdditive.Weights = []int{4, 1, 1, 1, 1}
+	weightedThresholded := testParameters(SchemeThresholded, 60)
+	weightedThresholded.Weights = []int{1, 2, 3, 2, 1}
+	weightedHinted := testParameters(SchemeHinted, 100)
+	weightedHinted.Weights = []int{2, 2, 1, 1, 1}
+	verified := testParameters(SchemeAdditive, 100)
+	verified.Verifier = crypto_protocols.VerifierParams{LogN: 10, R: 8, P: 1}
+	testCases := []Parameters{
+		testParameters(SchemeAdditive, 100),
+		testParameters(SchemeThresholded, 60),
+		testParameters(SchemeHinted, 100),
+		weightedAdditive,
+		weightedThresholded,
+		weightedHinted,
+		verified,
+	}
+	for _, tc := range testCases {
+		packets, _, err := SplitSecret(f, tc, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packets) != tc.AnonymitySetSize {
+			t.Error("Wrong number of packets generated", len(packets))
+		}
+		dir := t.TempDir()
+		_, err = WritePacketFiles(dir, packets, FormatBinary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		readPackets, err := ReadPacketFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverSecret(context.Background(), f, tc.Scheme,
+			tc.AbsoluteThreshold, readPackets, 0)
+		if err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(recovered, secret) {
+			t.Error("Secret not recovered", tc.Scheme)
+		}
+	}
+}
+
+func TestSplitSecretShuffle(t *testing.T) {
+	var f shamir.Field
+	secret := []byte("correct horse battery staple")
+	// The split is seeded, so the order of the packets is always the same
+	randomness.SetSeed(5)
+	defer randomness.Reset()
+	params := testParameters(SchemeAdditive, 100)
+	params.Weights = []int{4, 1, 1, 1, 1}
+	packets, owners, err := SplitSecret(f, params, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != len(packets) {
+		t.Fatal("Wrong number of owners", len(owners))
+	}
+	var trusteePackets [][]byte
+	seen := make(map[int]bool)
+	leading := true
+	for i, owner := range owners {
+		if owner == -1 {
+			if i < params.Trustees {
+				leading = false
+			}
+			continue
+		}
+		if owner >= params.Trustees || seen[owner] {
+			t.Error("Wrong owner", i, owner)
+		}
+		seen[owner] = true
+		trusteePackets = append(trusteePackets, packets[i])
+	}
+	if len(seen) != params.Trustees {
+		t.Error("Not every trustee owns a packet", owners)
+	}
+	if leading {
+		t.Error("The packets of the trustees are the first ones", owners)
+	}
+	// The owners point at the packets of the trustees
+	recovered, err := RecoverSecret(context.Background(), f, params.Scheme,
+		params.AbsoluteThreshold, trusteePackets, 0)
+	if err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(recovered, secret) {
+		t.Error("Secret not recovered from the packets of the trustees")
+	}
+}
+
+func TestSplitSecretInvalidParameters(t *testing.T) {
+	var f shamir.Field
+	// Every case breaks one of the valid additive parameters
+	testCases := []func(p *Parameters){
+		func(p *Parameters) { p.Scheme = "unknown" },
+		func(p *Parameters) { p.AnonymitySetSize = 4 },
+		func(p *Parameters) { p.AbsoluteThreshold = 6 },
+		func(p *Parameters) { p.PercentageLeavesLayerThreshold = 120 },
+		func(p *Parameters) { p.Scheme = SchemeHinted; p.NoOfHints = 6 },
+		func(p *Parameters) { p.Weights = []int{1, 1} },
+		func(p *Parameters) { p.Weights = []int{1, 1, 0, 1, 1} },
+		func(p *Parameters) {
+			p.Scheme = SchemeThresholded
+			p.PercentageUpperLayerThreshold = 60
+			p.Verifier = crypto_protocols.VerifierParams{LogN: 10, R: 8, P: 1}
+		},
+		func(p *Parameters) {
+			p.Verifier = crypto_protocols.VerifierParams{LogN: 0, R: 8, P: 1}
+		},
+	}
+	for i, change := range testCases {
+		params := testParameters(SchemeAdditive, 100)
+		change(&params)
+		if _, _, err := SplitSecret(f, params, []byte("test")); err == nil {
+			t.Error("Invalid parameters accepted", i, params)
+		}
+	}
+	// The hardened verifier is rejected for the schemes without a verifier
+	params := testParameters(SchemeHinted, 100)
+	params.Verifier = crypto_protocols.VerifierParams{LogN: 10, R: 8, P: 1}
+	if _, _, err := SplitSecret(f, params, []byte("test")); err != errors.ErrVerifierSchemeMismatch {
+		t.Error("Hardened verifier accepted for the hinted scheme", err)
+	}
+}
+
+func TestSplitRecoverPayload(t *testing.T) {
+	var f shamir.Field
+	payload := bytes.Repeat([]byte("wallet data "), 1000)
+	for _, scheme := range []string{SchemeAdditive, SchemeThresholded,
+		SchemeHinted} {
+		params := testParameters(scheme, 60)
+		packets, _, blob, err := SplitPayload(f, params, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		if _, err := WritePacketFiles(dir, packets, FormatBinary); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := WritePayloadFile(dir, blob); err != nil {
+			t.Fatal(err)
+		}
+		readPackets, err := ReadPacketFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(readPackets) != len(packets) {
+			t.Error("Payload file read as a packet", scheme)
+		}
+		readBlob, err := ReadPayloadFile(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverPayload(context.Background(), f, scheme,
+			params.AbsoluteThreshold, readPackets, readBlob, 0)
+		if err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(recovered, payload) {
+			t.Error("Payload not recovered", scheme)
+		}
+	}
+	if _, err := ReadPayloadFile(t.TempDir()); !os.IsNotExist(err) {
+		t.Error("Missing payload file not reported", err)
+	}
+}
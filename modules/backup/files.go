@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/files"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Formats of the packet files
+const (
+	FormatBinary        = "binary"
+	FormatArmor         = "armor"
+	FormatArmorBase64   = "armor64"
+	FormatQR            = "qr"
+	PacketFileExtension = ".pkt"
+	ArmorFileExtension  = ".asc"
+	QRFileExtension     = ".qr"
+)
+
+// PacketScheme returns the scheme of an encoded packet from its header
+func PacketScheme(data []byte) (string, error) {
+	tag, err := secretbe.PacketSchemeTag(data)
+	if err != nil {
+		return "", err
+	}
+	switch tag {
+	case secretbe.SchemeTagAdditive:
+		return SchemeAdditive, nil
+	case secretbe.SchemeTagThresholded:
+		return SchemeThresholded, nil
+	default:
+		return SchemeHinted, nil
+	}
+}
+
+// EncodePacketFile converts a binary packet into the contents of a packet
+// file of the given format along with the extension of the file
+// QR files store one chunk per line
+func EncodePacketFile(packet []byte, format string) ([]byte, string, error) {
+	switch format {
+	case FormatBinary:
+		return packet, PacketFileExtension, nil
+	case FormatArmor, FormatArmorBase64:
+		encoding := ArmorBase32
+		if format == FormatArmorBase64 {
+			encoding = ArmorBase64
+		}
+		text, err := ArmorPacket(packet, encoding)
+		return []byte(text), ArmorFileExtension, err
+	case FormatQR:
+		chunks, err := SplitQRChunks(packet, QRChunkSize)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(strings.Join(chunks, "\n") + "\n"), QRFileExtension, nil
+	default:
+		return nil, "", errors.ErrUnknownFormat
+	}
+}
+
+// DecodePacketFile returns the binary packet from the contents of a packet
+// file based on its extension
+// The checksums of the text forms are verified here
+func DecodePacketFile(data []byte, extension string) ([]byte, error) {
+	switch extension {
+	case PacketFileExtension:
+		return data, nil
+	case ArmorFileExtension:
+		return DearmorPacket(string(data))
+	case QRFileExtension:
+		return JoinQRChunks(strings.Fields(string(data)))
+	default:
+		return nil, errors.ErrUnknownFormat
+	}
+}
+
+// WritePacketFiles stores every packet in a separate file inside the
+// directory so that the packets can be handed to the people
+func WritePacketFiles(dir string, packets [][]byte,
+	format string) ([]string, error) {
+	err, _ := files.CreateDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	for i, packet := range packets {
+		data, extension, err := EncodePacketFile(packet, format)
+		if err != nil {
+			return nil, err
+		}
+		filename := filepath.Join(dir,
+			"packet-"+strconv.Itoa(i+1)+extension)
+		// The packets must only be readable by the owner until they are
+		// handed over
+		if err := os.WriteFile(filename, data, 0600); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// ReadPacketFiles reads all the packet files inside the directory
+// Files of all the formats can be mixed in the same directory
+// The files are returned in the lexical order of their names
+func ReadPacketFiles(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var packets [][]byte
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || (extension != PacketFileExtension &&
+			extension != ArmorFileExtension && extension != QRFileExtension) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		packet, err := DecodePacketFile(data, extension)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		packets = append(packets, packet)
+	}
+	if len(packets) == 0 {
+		return nil, errors.ErrNoPacketsFound
+	}
+	return packets, nil
+}
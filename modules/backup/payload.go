@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"context"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/shamir"
+	"os"
+	"path/filepath"
+)
+
+// Name of the file storing the encrypted payload next to the packets
+// ReadPacketFiles skips it since it has a different extension
+const PayloadFileName = "payload.enc"
+
+// SplitPayload encrypts a payload of any size under a random data key and
+// splits only the data key into the packets of the anonymity set
+// The encrypted payload is returned along with the packets and their owners
+func SplitPayload(f shamir.Field, params Parameters,
+	payload []byte) ([][]byte, []int, []byte, error) {
+	dataKey, err := crypto_protocols.GenerateDataKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	blob, err := crypto_protocols.SealPayload(dataKey, payload)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	packets, owners, err := SplitSecret(f, params, dataKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return packets, owners, blob, nil
+}
+
+// RecoverPayload recovers the data key from the packets and decrypts the
+// payload with it
+func RecoverPayload(ctx context.Context, f shamir.Field, scheme string,
+	absoluteThreshold int, encodedPackets [][]byte, blob []byte,
+	noOfWorkers int) ([]byte, error) {
+	dataKey, err := RecoverSecret(ctx, f, scheme, absoluteThreshold,
+		encodedPackets, noOfWorkers)
+	if err != nil {
+		return nil, err
+	}
+	return crypto_protocols.OpenPayload(dataKey, blob)
+}
+
+// WritePayloadFile stores the encrypted payload inside the directory of the
+// packets
+func WritePayloadFile(dir string, blob []byte) (string, error) {
+	filename := filepath.Join(dir, PayloadFileName)
+	if err := os.WriteFile(filename, blob, 0600); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// ReadPayloadFile reads the encrypted payload from the directory
+// The error satisfies os.IsNotExist if the packets protect a secret directly
+func ReadPayloadFile(dir string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, PayloadFileName))
+}
@@ -0,0 +1,81 @@
+package backup
+
+import (
+	"context"
+	"key_recovery/modules/errors"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"key_recovery/modules/shamir"
+)
+
+// NewRecoverer returns the recoverer of the chosen scheme
+// The recovery runs noOfWorkers routines, or runtime.NumCPU() if it is 0
+func NewRecoverer(f shamir.Field, scheme string,
+	absoluteThreshold, noOfWorkers int) (secretbe.Recoverer, error) {
+	switch scheme {
+	case SchemeAdditive:
+		recoverer := secretbe.NewAdditiveRecoverer(f, absoluteThreshold)
+		recoverer.SetNoOfWorkers(noOfWorkers)
+		return recoverer, nil
+	case SchemeThresholded:
+		recoverer := secretbe.NewThresholdedRecoverer(f, absoluteThreshold)
+		recoverer.SetNoOfWorkers(noOfWorkers)
+		return recoverer, nil
+	case SchemeHinted:
+		recoverer := secretbe.NewHintedTRecoverer(f, absoluteThreshold)
+		recoverer.SetNoOfWorkers(noOfWorkers)
+		return recoverer, nil
+	default:
+		return nil, errors.ErrUnknownScheme
+	}
+}
+
+// RecoverSecret feeds the packets collected from the contacted people to the
+// recoverer of the chosen scheme
+// The packets are used in the order in which they are provided
+// The recovery stops with ErrRecoveryCancelled once the context is done
+func RecoverSecret(ctx context.Context, f shamir.Field, scheme string,
+	absoluteThreshold int, encodedPackets [][]byte,
+	noOfWorkers int) ([]byte, error) {
+	recoverer, err := NewRecoverer(f, scheme, absoluteThreshold, noOfWorkers)
+	if err != nil {
+		return nil, err
+	}
+	for _, encoded := range encodedPackets {
+		if err := recoverer.AddPacket(encoded); err != nil {
+			return nil, err
+		}
+	}
+	secret, done, err := recoverer.TryRecover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		return nil, errors.ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+// RecoverSecretRobust recovers the secret of the thresholded scheme while
+// checking the shares of every recovered subsecret
+// The shares which do not lie on the polynomial of their subsecret are
+// returned along with the secret, with the packet indices following the
+// order of encodedPackets
+func RecoverSecretRobust(ctx context.Context, f shamir.Field,
+	absoluteThreshold int, encodedPackets [][]byte, noOfWorkers int) ([]byte,
+	[]secretbe.InconsistentShare, error) {
+	recoverer := secretbe.NewRobustThresholdedRecoverer(f, absoluteThreshold)
+	recoverer.SetNoOfWorkers(noOfWorkers)
+	for _, encoded := range encodedPackets {
+		if err := recoverer.AddPacket(encoded); err != nil {
+			return nil, nil, err
+		}
+	}
+	secret, done, err := recoverer.TryRecover(ctx)
+	if err != nil {
+		return nil, recoverer.InconsistentShares(), err
+	}
+	if !done {
+		return nil, recoverer.InconsistentShares(), errors.ErrSecretNotFound
+	}
+	return secret, recoverer.InconsistentShares(), nil
+}
@@ -0,0 +1,220 @@
+package backup
+
+import (
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+)
+
+// Names of the schemes that can be used for protecting a secret
+// additive corresponds to MLSS, thresholded to TMLSS and hinted to HMLSS
+const (
+	SchemeAdditive    = "additive"
+	SchemeThresholded = "thresholded"
+	SchemeHinted      = "hinted"
+)
+
+// Parameters for splitting a secret into the packets of the anonymity set
+type Parameters struct {
+	Scheme                         string
+	Trustees                       int
+	AnonymitySetSize               int
+	AbsoluteThreshold              int
+	NoOfSubsecrets                 int
+	PercentageLeavesLayerThreshold int
+	PercentageUpperLayerThreshold  int
+	NoOfHints                      int
+	// Weights of the trustees (uniform distribution of the leaves if nil)
+	Weights []int
+	// Parameters of the verifier of the secret (additive only)
+	Verifier crypto_protocols.VerifierParams
+}
+
+func (p Parameters) check() error {
+	if p.Trustees < 2 || p.AbsoluteThreshold < 2 || p.NoOfSubsecrets < 2 {
+		return errors.ErrInvalidInput
+	}
+	if p.AbsoluteThreshold > p.Trustees {
+		return errors.ErrInvalidThreshold
+	}
+	if p.PercentageLeavesLayerThreshold <= 0 ||
+		p.PercentageLeavesLayerThreshold > 100 {
+		return errors.ErrInvalidThreshold
+	}
+	if p.Scheme == SchemeThresholded && (p.PercentageUpperLayerThreshold <= 0 ||
+		p.PercentageUpperLayerThreshold > 100) {
+		return errors.ErrInvalidThreshold
+	}
+	// Every trustee must receive at least one leaf
+	leavesPerSubsecret := utils.FloorDivide(p.AbsoluteThreshold*100,
+		p.PercentageLeavesLayerThreshold)
+	if leavesPerSubsecret*p.NoOfSubsecrets < p.Trustees {
+		return errors.ErrInvalidInput
+	}
+	// The anonymity set contains the trustees
+	if p.AnonymitySetSize < p.Trustees {
+		return errors.ErrInvalidInput
+	}
+	if p.Scheme == SchemeHinted && (p.NoOfHints < 1 || p.NoOfHints > p.Trustees) {
+		return errors.ErrInvalidInput
+	}
+	if p.Weights != nil && len(p.Weights) != p.Trustees {
+		return errors.ErrInvalidSliceLength
+	}
+	if err := p.Verifier.Check(); err != nil {
+		return err
+	}
+	// Only the additive packets record the parameters of the verifier
+	if p.Verifier.Hardened() && p.Scheme != SchemeAdditive {
+		return errors.ErrVerifierSchemeMismatch
+	}
+	return nil
+}
+
+// SplitSecret runs the complete pipeline of the chosen scheme on the secret
+// and returns one encoded packet per member of the anonymity set
+// The packets are shuffled so that the position of a packet does not reveal
+// if the packet belongs to a trustee or not
+// The owners give the trustee (in the order of the weights) of every packet,
+// and -1 for the packets of the other members
+func SplitSecret(f shamir.Field, params Parameters,
+	secret []byte) ([][]byte, []int, error) {
+	if len(secret) == 0 {
+		return nil, nil, errors.ErrInvalidInput
+	}
+	if err := params.check(); err != nil {
+		return nil, nil, err
+	}
+	f.InitializeTables()
+	var packets [][]byte
+	var err error
+	switch params.Scheme {
+	case SchemeAdditive:
+		packets, err = splitAdditive(f, params, secret)
+	case SchemeThresholded:
+		packets, err = splitThresholded(f, params, secret)
+	case SchemeHinted:
+		packets, err = splitHinted(f, params, secret)
+	default:
+		return nil, nil, errors.ErrUnknownScheme
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// The first packets belong to the trustees
+	order := utils.GenerateIndicesSet(len(packets))
+	utils.Shuffle(order, randomness.NewRand())
+	shuffledPackets := make([][]byte, len(packets))
+	owners := make([]int, len(packets))
+	for i, index := range order {
+		shuffledPackets[i] = packets[index]
+		owners[i] = -1
+		if index < params.Trustees {
+			owners[i] = index
+		}
+	}
+	return shuffledPackets, owners, nil
+}
+
+func splitAdditive(f shamir.Field, params Parameters,
+	secret []byte) ([][]byte, error) {
+	secretKey := shamir.KeyBytesToKeyUint16s(secret)
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		secretbe.GenerateAdditiveTwoLayeredOptIndisShares(f, params.Trustees,
+			secretKey, params.AbsoluteThreshold, params.NoOfSubsecrets,
+			params.PercentageLeavesLayerThreshold)
+	if err != nil {
+		return nil, err
+	}
+	sharePackets, maxSharesPerPerson, err := secretbe.GetAdditiveSharePacketsWeighted(f,
+		secretKey, params.Trustees, params.AbsoluteThreshold,
+		leavesData, subsecrets, parentSubsecrets, &xUsedCoords,
+		params.Weights)
+	if err != nil {
+		return nil, err
+	}
+	anonymityPackets, err := secretbe.GetAdditiveAnonymityPackets(
+		sharePackets, params.AnonymitySetSize, maxSharesPerPerson,
+		len(secretKey), &xUsedCoords)
+	if err != nil {
+		return nil, err
+	}
+	if params.Verifier.Hardened() {
+		err = secretbe.HardenAdditivePackets(anonymityPackets, secretKey,
+			params.Verifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var output [][]byte
+	for _, packet := range anonymityPackets {
+		output = append(output, secretbe.MarshalAdditivePacket(packet))
+	}
+	return output, nil
+}
+
+func splitThresholded(f shamir.Field, params Parameters,
+	secret []byte) ([][]byte, error) {
+	// The subsecrets are used as AES keys for the marker information
+	// Therefore, the secret is broken into chunks of 16 bytes
+	secretKey := shamir.KeyBytesToAESKeyUint16s(secret)
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		secretbe.GenerateThresholdedTwoLayeredOptIndisShares(f, params.Trustees,
+			secretKey, params.AbsoluteThreshold, params.NoOfSubsecrets,
+			params.PercentageLeavesLayerThreshold,
+			params.PercentageUpperLayerThreshold)
+	if err != nil {
+		return nil, err
+	}
+	sharePackets, maxSharesPerPerson, encryptionLength, err :=
+		secretbe.GetThresholdedSharePacketsWeighted(f, secretKey,
+			params.Trustees, params.AbsoluteThreshold, leavesData, subsecrets,
+			parentSubsecrets, &xUsedCoords, params.Weights)
+	if err != nil {
+		return nil, err
+	}
+	anonymityPackets, err := secretbe.GetThresholdedAnonymityPackets(
+		sharePackets, params.AnonymitySetSize, maxSharesPerPerson,
+		len(secretKey[0]), len(secretKey), &xUsedCoords, encryptionLength)
+	if err != nil {
+		return nil, err
+	}
+	var output [][]byte
+	for _, packet := range anonymityPackets {
+		output = append(output, secretbe.MarshalThresholdedPacket(packet))
+	}
+	return output, nil
+}
+
+func splitHinted(f shamir.Field, params Parameters,
+	secret []byte) ([][]byte, error) {
+	secretKey := shamir.KeyBytesToAESKeyUint16s(secret)
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		secretbe.GenerateHintedTTwoLayeredOptIndisShares(f, params.Trustees,
+			secretKey, params.AbsoluteThreshold, params.NoOfSubsecrets,
+			params.PercentageLeavesLayerThreshold)
+	if err != nil {
+		return nil, err
+	}
+	sharePackets, maxSharesPerPerson, encryptionLength, err :=
+		secretbe.GetHintedTSharePacketsWeighted(f, secretKey,
+			params.Trustees, params.AbsoluteThreshold, leavesData, subsecrets,
+			parentSubsecrets, &xUsedCoords, params.NoOfHints, params.Weights)
+	if err != nil {
+		return nil, err
+	}
+	anonymityPackets, err := secretbe.GetHintedTAnonymityPackets(
+		sharePackets, params.AnonymitySetSize, maxSharesPerPerson,
+		len(secretKey[0]), len(secretKey), &xUsedCoords, encryptionLength)
+	if err != nil {
+		return nil, err
+	}
+	var output [][]byte
+	for _, packet := range anonymityPackets {
+		output = append(output, secretbe.MarshalHintedTPacket(packet))
+	}
+	return output, nil
+}
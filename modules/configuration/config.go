@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +21,19 @@ type SimulationConfig struct {
 	DefaultSharesHint                 int `yaml:"default_shares_hint"`
 	DefaultSimulationDistributionNums int `yaml:"simulation_distribution_nums"`
 	DefaultSimulationRunNums          int `yaml:"simulation_run_nums"`
+	// Routines used for the recovery (runtime.NumCPU() if 0)
+	NoOfWorkers int `yaml:"no_of_workers"`
+	// Seed of the simulations and of the access orders (random if not set)
+	Seed *int64 `yaml:"seed"`
+	// Intervals of the simulated probabilities (wilson or clopper_pearson)
+	// and their confidence level (0.95 if 0)
+	ConfidenceInterval string  `yaml:"confidence_interval"`
+	ConfidenceLevel    float64 `yaml:"confidence_level"`
+	// The probability experiments keep simulating until every interval is
+	// narrower than the target width or the time budget (e.g. 10m) runs out
+	// A single batch of simulations is run if both are 0
+	TargetIntervalWidth float64       `yaml:"target_interval_width"`
+	TimeBudget          time.Duration `yaml:"time_budget"`
 }
 
 func NewSimulationConfig(filename string) (*SimulationConfig, error) {
@@ -0,0 +1,421 @@
+package configuration
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schemes of the experiments
+const (
+	SchemeBaseline    = "baseline"
+	SchemeAdditive    = "additive"
+	SchemeThresholded = "thresholded"
+	SchemeHinted      = "hinted"
+)
+
+// Backends of the experiments - kyber for the legacy engine over
+// edwards25519 and gf16 for the engine over GF(2^16)
+const (
+	BackendKyber  = "kyber"
+	BackendBinExt = "gf16"
+)
+
+// Metrics measured by the experiments
+const (
+	MetricTime        = "time"
+	MetricCPUTime     = "cpu_time"
+	MetricProbability = "probability"
+	MetricPacketSize  = "packet_size"
+	// Computed from the exact model instead of the simulations
+	MetricExactProbability = "exact_probability"
+	// Wall-clock time of the recovery with the response times of the latency
+	MetricRecoveryTime = "recovery_time"
+	// Probability that a coalition of insiders recovers the secret before
+	// being reported
+	MetricCoalition = "coalition"
+)
+
+// Parameters that can be swept
+// The parameters which are not swept take the defaults from config.yaml
+const (
+	ParamTrustees            = "trustees"
+	ParamAnonymitySetSize    = "anonymity_set_size"
+	ParamAbsoluteThreshold   = "absolute_threshold"
+	ParamSubsecrets          = "subsecrets"
+	ParamLeavesThreshold     = "leaves_threshold"
+	ParamSubsecretsThreshold = "subsecrets_threshold"
+	ParamHints               = "hints"
+	ParamSharesPerPerson     = "shares_per_person"
+	// People waiting for an answer at once in the recovery time (1 if not
+	// swept)
+	ParamContactWidth = "contact_width"
+	// Trustees in the coalition (0 if not swept)
+	ParamInsiders = "insiders"
+)
+
+// ExperimentSpec describes one experiment, for instance
+//
+//	name: thresholded-anonymity
+//	scheme: thresholded
+//	backend: gf16
+//	metric: cpu_time
+//	sweep:
+//	  anonymity_set_size: [{from: 20, to: 50}, {from: 55, to: 150, step: 5}]
+//	  subsecrets_threshold: [60, 80]
+//
+// The test cases are all the combinations of the swept values, where the
+// parameter listed first changes the slowest
+type ExperimentSpec struct {
+	Name    string `yaml:"name"`
+	Scheme  string `yaml:"scheme"`
+	Backend string `yaml:"backend"`
+	Metric  string `yaml:"metric"`
+	// Runs per test case (iterations * iterations from config.yaml if 0)
+	// For the cross check of the exact probability, the number of
+	// simulations (10000 if 0)
+	Iterations int `yaml:"iterations,omitempty"`
+	// Also runs the simulations for the exact probability and reports the
+	// points that do not agree
+	CrossCheck bool `yaml:"cross_check,omitempty"`
+	// Weights of the trustees, who receive the leaves in proportion to them
+	// (probability only, uniform if empty)
+	Weights []int `yaml:"weights,omitempty,flow"`
+	// The people may not answer when they are contacted (probability only)
+	Availability *AvailabilitySpec `yaml:"availability,omitempty"`
+	// Response times of the people for the recovery time
+	Latency *LatencySpec `yaml:"latency,omitempty"`
+	// Behaviour of the people contacted by the coalition
+	Adversary *AdversarySpec `yaml:"adversary,omitempty"`
+	// Order in which the user (probability) or the coalition contacts the
+	// people
+	Strategy *StrategySpec `yaml:"strategy,omitempty"`
+	// Adds the commitments of the verifiable mode to the packets and drops
+	// the unverified shares before the recovery (kyber trees only)
+	Verifiable bool  `yaml:"verifiable,omitempty"`
+	Sweep      Sweep `yaml:"sweep"`
+}
+
+// AvailabilitySpec gives the probabilities that a trustee and that another
+// person of the anonymity set answers with their packet, for instance
+//
+//	availability: {trustees: 0.9, non_trustees: 1, permanent: true}
+//
+// With permanent losses, the people who do not answer have lost their
+// packets for all the runs on the same packets
+type AvailabilitySpec struct {
+	Trustees    float64 `yaml:"trustees"`
+	NonTrustees float64 `yaml:"non_trustees"`
+	Permanent   bool    `yaml:"permanent,omitempty"`
+}
+
+// Sweep is the ordered list of the swept parameters
+type Sweep []SweepParameter
+
+type SweepParameter struct {
+	Name   string
+	Ranges []SweepRange
+}
+
+// SweepRange goes from From to To (both included) in steps of Step
+// A single value is a range with From equal to To
+type SweepRange struct {
+	From int `yaml:"from"`
+	To   int `yaml:"to"`
+	Step int `yaml:"step,omitempty"`
+}
+
+func NewExperimentSpec(filename string) (*ExperimentSpec, error) {
+	var spec ExperimentSpec
+
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(data, &spec)
+
+	if err != nil {
+		return nil, err
+	}
+	err = spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks that the scheme, backend, metric and the swept parameters
+// are known
+func (spec *ExperimentSpec) Validate() error {
+	if spec.Name == "" {
+		return fmt.Errorf("%w: missing name", errors.ErrInvalidExperimentSpec)
+	}
+	switch spec.Scheme {
+	case SchemeBaseline, SchemeAdditive, SchemeThresholded, SchemeHinted:
+	default:
+		return fmt.Errorf("%w: unknown scheme %q", errors.ErrInvalidExperimentSpec,
+			spec.Scheme)
+	}
+	switch spec.Backend {
+	case BackendKyber, BackendBinExt:
+	default:
+		return fmt.Errorf("%w: unknown backend %q", errors.ErrInvalidExperimentSpec,
+			spec.Backend)
+	}
+	switch spec.Metric {
+	case MetricTime, MetricCPUTime, MetricProbability, MetricPacketSize,
+		MetricExactProbability, MetricRecoveryTime, MetricCoalition:
+	default:
+		return fmt.Errorf("%w: unknown metric %q", errors.ErrInvalidExperimentSpec,
+			spec.Metric)
+	}
+	if spec.CrossCheck && spec.Metric != MetricExactProbability {
+		return fmt.Errorf("%w: cross_check is only for %s",
+			errors.ErrInvalidExperimentSpec, MetricExactProbability)
+	}
+	if len(spec.Weights) != 0 {
+		if spec.Metric != MetricProbability || spec.Scheme == SchemeBaseline ||
+			spec.Availability != nil || spec.Strategy != nil {
+			return fmt.Errorf("%w: weights are only for the %s of the trees",
+				errors.ErrInvalidExperimentSpec, MetricProbability)
+		}
+		// There is one weight per trustee, so the number of trustees is
+		// fixed
+		for _, param := range spec.Sweep {
+			if param.Name == ParamTrustees && len(param.Values()) != 1 {
+				return fmt.Errorf("%w: weights with more than one number of %s",
+					errors.ErrInvalidExperimentSpec, ParamTrustees)
+			}
+		}
+		for _, weight := range spec.Weights {
+			if weight <= 0 {
+				return fmt.Errorf("%w: weight %d is not positive",
+					errors.ErrInvalidExperimentSpec, weight)
+			}
+		}
+	}
+	if spec.Availability != nil {
+		if spec.Metric != MetricProbability || spec.Scheme == SchemeBaseline {
+			return fmt.Errorf("%w: availability is only for the %s of the trees",
+				errors.ErrInvalidExperimentSpec, MetricProbability)
+		}
+		if spec.Availability.Trustees < 0 || spec.Availability.Trustees > 1 ||
+			spec.Availability.NonTrustees < 0 ||
+			spec.Availability.NonTrustees > 1 {
+			return fmt.Errorf("%w: availability is not a probability",
+				errors.ErrInvalidExperimentSpec)
+		}
+	}
+	if (spec.Metric == MetricRecoveryTime) != (spec.Latency != nil) {
+		return fmt.Errorf("%w: latency is needed for %s and only for it",
+			errors.ErrInvalidExperimentSpec, MetricRecoveryTime)
+	}
+	if (spec.Metric == MetricCoalition) != (spec.Adversary != nil) {
+		return fmt.Errorf("%w: adversary is needed for %s and only for it",
+			errors.ErrInvalidExperimentSpec, MetricCoalition)
+	}
+	if spec.Adversary != nil && (spec.Adversary.DeltaTrustees > 100 ||
+		spec.Adversary.DeltaNonTrustees > 100 || spec.Adversary.Obtain > 100 ||
+		spec.Adversary.Whistleblow > 100) {
+		return fmt.Errorf("%w: adversary percentage above 100",
+			errors.ErrInvalidExperimentSpec)
+	}
+	if spec.Strategy != nil {
+		if (spec.Metric != MetricProbability && spec.Metric != MetricCoalition) ||
+			spec.Scheme == SchemeBaseline || spec.Availability != nil {
+			return fmt.Errorf("%w: strategy is only for the %s (without availability) and the %s of the trees",
+				errors.ErrInvalidExperimentSpec, MetricProbability,
+				MetricCoalition)
+		}
+	}
+	if spec.Verifiable {
+		// The commitments are over the group of the kyber backend
+		if spec.Backend != BackendKyber || spec.Scheme == SchemeBaseline ||
+			(spec.Metric != MetricTime && spec.Metric != MetricCPUTime &&
+				spec.Metric != MetricPacketSize) {
+			return fmt.Errorf("%w: verifiable is only for the %s trees with the %s, %s or %s",
+				errors.ErrInvalidExperimentSpec, BackendKyber, MetricTime,
+				MetricCPUTime, MetricPacketSize)
+		}
+	}
+	if spec.Iterations < 0 {
+		return fmt.Errorf("%w: negative iterations", errors.ErrInvalidExperimentSpec)
+	}
+	seen := make(map[string]bool)
+	for _, param := range spec.Sweep {
+		switch param.Name {
+		case ParamTrustees, ParamAnonymitySetSize, ParamAbsoluteThreshold,
+			ParamSubsecrets, ParamLeavesThreshold, ParamSubsecretsThreshold,
+			ParamHints, ParamSharesPerPerson, ParamContactWidth,
+			ParamInsiders:
+		default:
+			return fmt.Errorf("%w: unknown parameter %q",
+				errors.ErrInvalidExperimentSpec, param.Name)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("%w: parameter %q swept twice",
+				errors.ErrInvalidExperimentSpec, param.Name)
+		}
+		seen[param.Name] = true
+		if len(param.Values()) == 0 {
+			return fmt.Errorf("%w: no values for %q",
+				errors.ErrInvalidExperimentSpec, param.Name)
+		}
+	}
+	return nil
+}
+
+// Sweeps tells whether the parameter is swept by the spec
+func (spec *ExperimentSpec) Sweeps(name string) bool {
+	for _, param := range spec.Sweep {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Values gives the swept values in the given order
+func (param SweepParameter) Values() []int {
+	var values []int
+	for _, r := range param.Ranges {
+		step := r.Step
+		if step == 0 {
+			step = 1
+			if r.To < r.From {
+				step = -1
+			}
+		}
+		for v := r.From; (step > 0 && v <= r.To) || (step < 0 && v >= r.To); v += step {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Keeps the order of the parameters in the mapping
+func (sweep *Sweep) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: sweep is not a mapping (line %d)",
+			errors.ErrInvalidExperimentSpec, node.Line)
+	}
+	*sweep = nil
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		param := SweepParameter{Name: node.Content[i].Value}
+		ranges, err := decodeSweepRanges(node.Content[i+1])
+		if err != nil {
+			return err
+		}
+		param.Ranges = ranges
+		*sweep = append(*sweep, param)
+	}
+	return nil
+}
+
+// LatencySpec gives the distribution of the response times (constant,
+// uniform, exponential or lognormal) with its mean and spread (the half
+// width of the uniform one and the deviation of the logarithm of the
+// log-normal one), for instance
+//
+//	latency: {distribution: exponential, mean: 24}
+type LatencySpec struct {
+	Distribution string  `yaml:"distribution"`
+	Mean         float64 `yaml:"mean"`
+	Spread       float64 `yaml:"spread,omitempty"`
+}
+
+// AdversarySpec gives the percentages of the trustees and of the other
+// people that the coalition misidentifies, and the percentages of the
+// contacted people that give their packet and that report the coalition,
+// for instance
+//
+//	adversary: {delta_trustees: 20, delta_non_trustees: 20, obtain: 90, whistleblow: 2}
+type AdversarySpec struct {
+	DeltaTrustees    uint16 `yaml:"delta_trustees"`
+	DeltaNonTrustees uint16 `yaml:"delta_non_trustees"`
+	Obtain           byte   `yaml:"obtain"`
+	Whistleblow      byte   `yaml:"whistleblow"`
+}
+
+// StrategySpec gives the strategy (random, noisy_prior, hint_following or
+// bayesian) and the percentages of the trustees and of the other people
+// that it misidentifies, for instance
+//
+//	strategy: {name: bayesian, delta_trustees: 20, delta_non_trustees: 20}
+//
+// Without a strategy, the user contacts the people in a random order and
+// follows the hints, and the coalition also puts first the people who look
+// like trustees according to the deltas of the adversary
+type StrategySpec struct {
+	Name             string `yaml:"name"`
+	DeltaTrustees    uint16 `yaml:"delta_trustees,omitempty"`
+	DeltaNonTrustees uint16 `yaml:"delta_non_trustees,omitempty"`
+}
+
+// The values are given as a number, a range or a list of both
+func decodeSweepRanges(node *yaml.Node) ([]SweepRange, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var v int
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%w: %w", errors.ErrInvalidExperimentSpec, err)
+		}
+		return []SweepRange{{From: v, To: v}}, nil
+	case yaml.MappingNode:
+		var r SweepRange
+		if err := node.Decode(&r); err != nil {
+			return nil, fmt.Errorf("%w: %w", errors.ErrInvalidExperimentSpec, err)
+		}
+		if (r.Step > 0 && r.To < r.From) || (r.Step < 0 && r.To > r.From) {
+			return nil, fmt.Errorf("%w: range from %d to %d never ends with step %d (line %d)",
+				errors.ErrInvalidExperimentSpec, r.From, r.To, r.Step, node.Line)
+		}
+		return []SweepRange{r}, nil
+	case yaml.SequenceNode:
+		var ranges []SweepRange
+		for _, item := range node.Content {
+			if item.Kind == yaml.SequenceNode {
+				return nil, fmt.Errorf("%w: nested list (line %d)",
+					errors.ErrInvalidExperimentSpec, item.Line)
+			}
+			itemRanges, err := decodeSweepRanges(item)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, itemRanges...)
+		}
+		return ranges, nil
+	}
+	return nil, fmt.Errorf("%w: invalid values (line %d)",
+		errors.ErrInvalidExperimentSpec, node.Line)
+}
+
+// Writes the sweep back in the same form
+func (sweep Sweep) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, param := range sweep {
+		values := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, r := range param.Ranges {
+			if r.From == r.To {
+				values.Content = append(values.Content, &yaml.Node{
+					Kind: yaml.ScalarNode, Value: fmt.Sprint(r.From)})
+				continue
+			}
+			// A step of one is the default for increasing ranges
+			if r.Step == 1 {
+				r.Step = 0
+			}
+			var rangeNode yaml.Node
+			if err := rangeNode.Encode(r); err != nil {
+				return nil, err
+			}
+			values.Content = append(values.Content, &rangeNode)
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: param.Name}, values)
+	}
+	return node, nil
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
 	"io"
+	"key_recovery/modules/randomness"
 )
 
 // **************************************************************************
@@ -44,7 +44,7 @@ func GetAESEncryption(key []byte, data []byte) []byte {
 	// Ciphertext will contain the IV and the encryption of the data
 	ciphertext := make([]byte, aes.BlockSize+len(paddedData))
 	iv := ciphertext[:aes.BlockSize] // Initialization vector
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+	if _, err := io.ReadFull(randomness.Reader, iv); err != nil {
 		panic(err)
 	}
 	mode := cipher.NewCBCEncrypter(block, iv)
@@ -26,21 +26,23 @@ func GetAESGCMEncryption(key,
 	return ciphertext
 }
 
+// The error is returned (and not panicked) when the authentication fails,
+// since a wrong key is expected while checking the markers
 func GetAESGCMDecryption(key, nonce, ciphertext,
 	authData []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 
 	aesgcm, err := cipher.NewGCM(block)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 
 	plaintext, err := aesgcm.Open(nil, nonce, ciphertext, authData)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 
 	return plaintext, nil
@@ -1,7 +1,10 @@
 package crypto
 
 import (
+	"bytes"
 	"fmt"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
 	"log"
 	randm "math/rand"
 	"testing"
@@ -178,6 +181,230 @@ func TestMarkerData(t *testing.T) {
 	}
 }
 
+func TestMarkerV2BinExt(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		nonce, err := GenerateSalt32()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := GenerateRandomBytes(16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shareVal := shamir.PriShare{X: uint16(i), Y: shamir.BytesToUint16s(key)}
+		encryption, encryptionLength, err := GetRelevantEncryptionBinExt(nonce,
+			shareVal, LabelSubsecretCheck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encryptionLength != 30 || MarkerVersion(encryption) != MarkerV2 {
+			t.Fatal("Unexpected marker length", encryptionLength)
+		}
+		blob, err := GenerateRandomBytes(encryptionLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encryptions := [][]byte{blob, encryption}
+		x, matchedEncryption, matched, err := ThresholdedDecryptionCheckBinExt(
+			key, nonce, encryptions, LabelSubsecretCheck, false)
+		if err != nil || !matched || !bytes.Equal(matchedEncryption, encryption) ||
+			x != shareVal.X {
+			t.Error("Marker not matched with the correct key", err)
+		}
+		// A wrong key or another nonce never matches
+		wrongKey := append([]byte(nil), key...)
+		wrongKey[0] ^= 1
+		_, _, ok, err := ThresholdedDecryptionCheckBinExt(wrongKey, nonce,
+			encryptions, LabelSubsecretCheck, false)
+		if err != nil || ok {
+			t.Error("Marker matched with a wrong key")
+		}
+		// Nor does the same key for another role
+		_, _, ok, err = ThresholdedDecryptionCheckBinExt(key, nonce,
+			encryptions, LabelFinalSecretCheck, false)
+		if err != nil || ok {
+			t.Error("Marker matched with another label")
+		}
+		// The markers of the older packets use the value as the key
+		_, _, ok, err = ThresholdedDecryptionCheckBinExt(key, nonce,
+			encryptions, LabelSubsecretCheck, true)
+		if err != nil || ok {
+			t.Error("Marker matched as an older marker")
+		}
+		legacyEncryption, err := GetMarkerEncryption(key, nonce,
+			shamir.ConvertIndexUint16ToBytes(shareVal.X))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, _, matched, err = ThresholdedDecryptionCheckBinExt(key, nonce,
+			[][]byte{blob, legacyEncryption}, LabelSubsecretCheck, true)
+		if err != nil || !matched || x != shareVal.X {
+			t.Error("Older marker not matched", err)
+		}
+		nonce[0] ^= 1
+		_, _, ok, err = ThresholdedDecryptionCheckBinExt(key, nonce,
+			encryptions, LabelSubsecretCheck, false)
+		if err != nil || ok {
+			t.Error("Marker matched with another nonce")
+		}
+	}
+}
+
+func TestMarkerV1Compatibility(t *testing.T) {
+	nonce, err := GenerateSalt32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := GenerateRandomBytes(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nonce || 00000000 || index
+	markerData := append(append(nonce[:], make([]byte, 8)...), 0x01, 0x02)
+	encryption := GetAESEncryption(key, markerData)
+	if MarkerVersion(encryption) != MarkerV1 {
+		t.Fatal("v1 marker not detected", len(encryption))
+	}
+	// A v2 random blob next to it
+	blob, err := GenerateRandomBytes(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, _, matched, err := ThresholdedDecryptionCheckBinExt(key, nonce,
+		[][]byte{blob, encryption}, LabelSubsecretCheck, false)
+	if err != nil || !matched || x != 0x0102 {
+		t.Error("v1 marker not decoded", x, err)
+	}
+
+	// Kyber markers with the indicator bytes
+	g := edwards25519.NewBlakeSHA256Ed25519()
+	secretKey := g.Scalar().Pick(g.RandomStream())
+	markerInfo, _, err := GetMarkerInfo(secretKey,
+		[][]*share.PriShare{{{I: 3, V: secretKey}}}, nonce,
+		[]*share.PriShare{{I: 3, V: secretKey}}, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, final, _, matched, err := CorrectDecryptionCheck(
+		ConvertKeyToBytes(secretKey), nonce, markerInfo)
+	if err != nil || !matched || !final || index != 3 {
+		t.Error("v2 kyber marker not decoded", index, final, err)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt, err := GenerateSalt32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := []string{LabelLeafCheck, LabelSubsecretCheck, LabelFinalSecretCheck,
+		LabelHint}
+	for _, size := range []int{1, 16, 20, 51} {
+		secret, err := GenerateRandomBytes(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hashes [][32]byte
+		for _, label := range labels {
+			hash := GetLabelledSaltedHash(salt, secret, label)
+			if hash != GetLabelledSaltedHash(salt, secret, label) {
+				t.Error("Derivation not deterministic", label)
+			}
+			if GetHashMembership(hashes, hash) {
+				t.Error("Same hash for two labels", label)
+			}
+			hashes = append(hashes, hash)
+			if len(DeriveMarkerKey(secret, salt, label)) != 32 {
+				t.Error("Marker key is not an AES-256 key", size)
+			}
+			if GetRoleSaltedHash(salt, secret, label, true) !=
+				GetSaltedHash(salt, secret) {
+				t.Error("Older salted hash not kept", label)
+			}
+		}
+	}
+}
+
+func TestAdditiveSaltedHashMatch(t *testing.T) {
+	salt, err := GenerateSalt32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subsecrets := [][]uint16{{1, 2, 3}, {4, 5, 6}}
+	keyBytes := shamir.Uint16sToBytes([]uint16{5, 7, 5})
+	params := VerifierParams{LogN: 10, R: 8, P: 1}
+	hash, err := GetVerifierHash(salt, keyBytes, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched, key, err := GetAdditiveSaltedHashMatchBinExt([][32]byte{hash},
+		salt, subsecrets, params, false)
+	if err != nil || !matched || !CompareUint16s(key, []uint16{5, 7, 5}) {
+		t.Error("Secret key not matched with the hardened verifier", err)
+	}
+	// A hardened packet is only matched by scrypt, not by the fast hashes
+	for _, fastHash := range [][32]byte{
+		GetLabelledSaltedHash(salt, keyBytes, LabelFinalSecretCheck),
+		GetSaltedHash(salt, keyBytes)} {
+		for _, legacy := range []bool{false, true} {
+			matched, _, err = GetAdditiveSaltedHashMatchBinExt(
+				[][32]byte{fastHash}, salt, subsecrets, params, legacy)
+			if err != nil || matched {
+				t.Error("Hardened packet matched by the fast hash", legacy,
+					err)
+			}
+		}
+	}
+	// Without hardening, the labelled hash is the verifier
+	matched, _, err = GetAdditiveSaltedHashMatchBinExt([][32]byte{
+		GetLabelledSaltedHash(salt, keyBytes, LabelFinalSecretCheck)}, salt,
+		subsecrets, VerifierParams{}, false)
+	if err != nil || !matched {
+		t.Error("Secret key not matched with the labelled hash", err)
+	}
+	// The errors are returned instead of stopping the recovery
+	_, _, err = GetAdditiveSaltedHashMatchBinExt([][32]byte{hash}, salt,
+		subsecrets, VerifierParams{LogN: 31, R: 8, P: 1}, false)
+	if err != errors.ErrInvalidVerifierParams {
+		t.Error("Invalid verifier accepted", err)
+	}
+	_, _, err = GetAdditiveSaltedHashMatchBinExt([][32]byte{hash}, salt,
+		[][]uint16{{1, 2, 3}, {4}}, params, false)
+	if err == nil {
+		t.Error("Subsecrets of different lengths added")
+	}
+}
+
+func TestEnvelopePayload(t *testing.T) {
+	dataKey, err := GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range [][]byte{{}, []byte("wallet"),
+		bytes.Repeat([]byte{0xab}, 100000)} {
+		blob, err := SealPayload(dataKey, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opened, err := OpenPayload(dataKey, blob)
+		if err != nil || !bytes.Equal(opened, payload) {
+			t.Error("Payload not decrypted", len(payload), err)
+		}
+		wrongKey, _ := GenerateDataKey()
+		if _, err := OpenPayload(wrongKey, blob); err != errors.ErrPayloadAuthentication {
+			t.Error("Wrong data key accepted", err)
+		}
+		modified := append([]byte(nil), blob...)
+		modified[len(modified)-1] ^= 1
+		if _, err := OpenPayload(dataKey, modified); err != errors.ErrPayloadAuthentication {
+			t.Error("Modified payload accepted", err)
+		}
+	}
+	if _, err := OpenPayload(dataKey, []byte("KRPL")); err != errors.ErrInvalidPayload {
+		t.Error("Truncated payload accepted", err)
+	}
+}
+
 func TestConvertStringToBytes(t *testing.T) {
 	testString := ""
 	for i := 0; i < 240; i++ {
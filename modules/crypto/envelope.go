@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"bytes"
+	"key_recovery/modules/errors"
+)
+
+// **************************************************************************
+// **************************************************************************
+
+// *************************Envelope encryption******************************
+// **************************************************************************
+
+// A payload of any size is encrypted with AES-GCM under a random data key and
+// only the data key is shared with the schemes
+// The encrypted payload is
+// magic(4) || version(1) || gcmNonce(12) || ciphertext || tag(16)
+// with the magic and the version as the additional data
+const (
+	DataKeySize    = 32
+	PayloadVersion = 1
+)
+
+var payloadMagic = []byte("KRPL")
+
+const payloadHeaderSize = 5
+
+// GenerateDataKey gives a random AES-256 key for encrypting a payload
+func GenerateDataKey() ([]byte, error) {
+	return GenerateRandomBytes(DataKeySize)
+}
+
+// SealPayload encrypts the payload under the data key
+func SealPayload(dataKey, payload []byte) ([]byte, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, errors.ErrInvalidInput
+	}
+	nonce, err := GenerateRandomBytes(markerGCMNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	header := append(append([]byte(nil), payloadMagic...), PayloadVersion)
+	blob := append(append([]byte(nil), header...), nonce...)
+	return append(blob, GetAESGCMEncryption(dataKey, nonce, payload,
+		header)...), nil
+}
+
+// OpenPayload decrypts the payload sealed under the data key
+// A wrong data key or a modified payload gives ErrPayloadAuthentication
+func OpenPayload(dataKey, blob []byte) ([]byte, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, errors.ErrInvalidInput
+	}
+	if len(blob) < payloadHeaderSize+markerGCMNonceSize+markerTagSize ||
+		!bytes.Equal(blob[:len(payloadMagic)], payloadMagic) {
+		return nil, errors.ErrInvalidPayload
+	}
+	if blob[len(payloadMagic)] != PayloadVersion {
+		return nil, errors.ErrUnsupportedPacketVersion
+	}
+	header := blob[:payloadHeaderSize]
+	nonce := blob[payloadHeaderSize : payloadHeaderSize+markerGCMNonceSize]
+	payload, err := GetAESGCMDecryption(dataKey, nonce,
+		blob[payloadHeaderSize+markerGCMNonceSize:], header)
+	if err != nil {
+		return nil, errors.ErrPayloadAuthentication
+	}
+	return payload, nil
+}
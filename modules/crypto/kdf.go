@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"io"
+	"log"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// **************************************************************************
+// **************************************************************************
+
+// ****************************Key Derivation********************************
+// **************************************************************************
+
+// The values checked during the recovery have different roles
+// Every role has its own HKDF label, so a hash or a marker key derived for
+// one role can never match a value checked for another role
+const (
+	// A subsecret recovered from the leaves
+	LabelLeafCheck = "key_recovery leaf check"
+	// A subsecret recovered from the subsecrets below it (the upper nodes of
+	// the hierarchical trees)
+	LabelSubsecretCheck = "key_recovery subsecret check"
+	// The secret key (or a part of it) recovered from the subsecrets
+	LabelFinalSecretCheck = "key_recovery final secret check"
+	// A subsecret whose marker carries a hint
+	LabelHint = "key_recovery hint"
+)
+
+// DeriveKey derives length bytes from the secret with HKDF-SHA256
+// The salt (or nonce) of the packet is the HKDF salt and the label of the
+// role is the info, so the secret can be of any length
+func DeriveKey(secret []byte, salt [32]byte, label string,
+	length int) []byte {
+	reader := hkdf.New(sha256.New, secret, salt[:], []byte(label))
+	output := make([]byte, length)
+	if _, err := io.ReadFull(reader, output); err != nil {
+		log.Fatalln(err)
+	}
+	return output
+}
+
+// DeriveMarkerKey gives the AES-256 key of a marker
+func DeriveMarkerKey(secret []byte, nonce [32]byte, label string) []byte {
+	return DeriveKey(secret, nonce, label, 32)
+}
+
+// GetLabelledSaltedHash replaces SHA256(secret || salt) for the salted hashes
+// stored in the packets
+func GetLabelledSaltedHash(salt [32]byte, secret []byte,
+	label string) [32]byte {
+	var output [32]byte
+	copy(output[:], DeriveKey(secret, salt, label, 32))
+	return output
+}
+
+// Packets encoded before the format version 2 used SHA256(secret || salt)
+// as the salted hashes and the value itself as the key of the v2 markers,
+// whatever the role
+// GetRoleSaltedHash gives the salted hash of the role, or the one of these
+// packets when legacy is set
+func GetRoleSaltedHash(salt [32]byte, secret []byte, label string,
+	legacy bool) [32]byte {
+	if legacy {
+		return GetSaltedHash(salt, secret)
+	}
+	return GetLabelledSaltedHash(salt, secret, label)
+}
+
+// GetRoleMarkerKey gives the key of the v2 markers of the role, or the value
+// itself when legacy is set
+func GetRoleMarkerKey(secret []byte, nonce [32]byte, label string,
+	legacy bool) []byte {
+	if legacy {
+		return secret
+	}
+	return DeriveMarkerKey(secret, nonce, label)
+}
@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"key_recovery/modules/errors"
+)
+
+// **************************************************************************
+// **************************************************************************
+
+// ***************************Marker formats*********************************
+// **************************************************************************
+
+// v1: AES-CBC of nonce || 00000000 || index (|| indicator bytes) with PKCS7
+// padding and a random IV
+// A wrong key is only caught by the nonce and the zero bytes of the
+// plaintext, and the marker takes 64 bytes for a 2-byte index
+// v2: AES-GCM of index (|| indicator bytes) with the nonce (or salt) of the
+// packet as the additional data
+// A wrong key fails the authentication, and the marker is
+// gcmNonce(12) || encrypted index || tag(16)
+// v1 markers are whole AES blocks (the IV and the padded data) while v2
+// markers never are, so the checks tell them apart by their length and the
+// packets generated before v2 can still be recovered
+const (
+	MarkerV1 = 1
+	MarkerV2 = 2
+)
+
+const (
+	markerGCMNonceSize = 12
+	markerTagSize      = 16
+)
+
+// MarkerVersion gives the format of a marker from its length
+func MarkerVersion(marker []byte) int {
+	if len(marker)%aes.BlockSize == 0 {
+		return MarkerV1
+	}
+	return MarkerV2
+}
+
+// GetMarkerEncryption gives the v2 marker of the plaintext under the key
+// Every marker gets its own random GCM nonce, as the same key can encrypt
+// more than one marker
+func GetMarkerEncryption(key []byte, nonce [32]byte,
+	plaintext []byte) ([]byte, error) {
+	// The length would be read as a v1 marker
+	markerLength := markerGCMNonceSize + len(plaintext) + markerTagSize
+	if markerLength%aes.BlockSize == 0 {
+		return nil, errors.ErrInvalidInput
+	}
+	gcmNonce, err := GenerateRandomBytes(markerGCMNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	encryption := GetAESGCMEncryption(key, gcmNonce, plaintext, nonce[:])
+	return append(gcmNonce, encryption...), nil
+}
+
+// Tells if a value of the size can be used directly as an AES key
+func isAESKeySize(size int) bool {
+	switch size {
+	case 16, 24, 32:
+		return true
+	}
+	return false
+}
+
+// Opens v2 markers under a single key
+type markerOpener struct {
+	aead cipher.AEAD
+}
+
+func newMarkerOpener(key []byte) (*markerOpener, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &markerOpener{aead: aead}, nil
+}
+
+// Gives the plaintext of the marker, or false if the key or the nonce of the
+// packet is wrong (or the marker is a random blob)
+func (m *markerOpener) open(nonce [32]byte, marker []byte) ([]byte, bool) {
+	if len(marker) < markerGCMNonceSize+markerTagSize {
+		return nil, false
+	}
+	plaintext, err := m.aead.Open(nil, marker[:markerGCMNonceSize],
+		marker[markerGCMNonceSize:], nonce[:])
+	if err != nil {
+		return nil, false
+	}
+	return plaintext, true
+}
+
+// Compatibility decoder for the v1 markers
+// Gives the plaintext if it starts with nonce || 00000000 and has at least
+// minLength bytes, or nil otherwise
+// matched is false if the padding is invalid (the search then stops as
+// before)
+func decryptionCheckV1(bytesVal []byte, nonce [32]byte,
+	relevantEncryption []byte, minLength int) ([]byte, bool, error) {
+	// The IV and at least one block of data
+	if len(relevantEncryption) < 2*aes.BlockSize {
+		return nil, true, nil
+	}
+	// The v1 markers were encrypted with the value itself, so only values
+	// of the AES key sizes can match them
+	if !isAESKeySize(len(bytesVal)) {
+		return nil, true, nil
+	}
+	copiedEncryption := make([]byte, len(relevantEncryption))
+	copy(copiedEncryption, relevantEncryption)
+	plaintext, validity, err := GetAESDecryption(bytesVal, copiedEncryption)
+	if err != nil {
+		return nil, false, err
+	}
+	if !validity {
+		return nil, false, nil
+	}
+	if len(plaintext) < minLength {
+		return nil, true, nil
+	}
+	// The structure of the marker info is
+	// nonce || 00000000 || index (|| indicator bytes)
+	// Thus, first check if the first 32 bytes contain the salt
+	for i := 0; i < 32; i++ {
+		if plaintext[i] != nonce[i] {
+			return nil, true, nil
+		}
+	}
+	// Then, check if the next 8 bytes are 0 or not
+	for i := 32; i < 40; i++ {
+		if plaintext[i] != 0 {
+			return nil, true, nil
+		}
+	}
+	return plaintext, true, nil
+}
@@ -1,7 +1,7 @@
 package crypto
 
 import (
-	"crypto/rand"
+	"key_recovery/modules/randomness"
 	"log"
 )
 
@@ -10,7 +10,7 @@ import (
 // GenerateSalt generates a random salt of the given size
 func GenerateSalt(size int) ([]byte, error) {
 	salt := make([]byte, size)
-	_, err := rand.Read(salt)
+	_, err := randomness.Read(salt)
 	return salt, err
 }
 
@@ -28,7 +28,7 @@ func GenerateSalt32() ([32]byte, error) {
 // GenerateRandomBytes generates n random bytes.
 func GenerateRandomBytes(n int) ([]byte, error) {
 	bytes := make([]byte, n)
-	_, err := rand.Read(bytes)
+	_, err := randomness.Read(bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,8 @@ package crypto
 import (
 	"crypto/subtle"
 	"encoding/binary"
+	"fmt"
+	"key_recovery/modules/errors"
 	"key_recovery/modules/shamir"
 	"log"
 )
@@ -13,12 +15,14 @@ func CompareUint16s(data1, data2 []uint16) bool {
 	return subtle.ConstantTimeCompare(data1Bytes, data2Bytes) == 1
 }
 
+// legacy is set for the packets encoded before the role labels
 func GetAdditiveIndisShareMatchBinExt(recovered []uint16,
 	runRelevantHashes [][32]byte,
-	runRelevantSalt [32]byte) (bool, [32]byte, error) {
+	runRelevantSalt [32]byte, legacy bool) (bool, [32]byte, error) {
 	var correctHash [32]byte
 	bytesVal := shamir.Uint16sToBytes(recovered)
-	saltedHash := GetSaltedHash(runRelevantSalt, bytesVal)
+	saltedHash := GetRoleSaltedHash(runRelevantSalt, bytesVal,
+		LabelLeafCheck, legacy)
 	isContainedHash := false
 	for _, runRelevantHash := range runRelevantHashes {
 		if CheckHashesEqual(saltedHash, runRelevantHash) {
@@ -71,32 +75,55 @@ func GetSharesSetDifferenceBinExt(shares1,
 	return outputShares, nil
 }
 
+// The secret key is checked with the verifier given by the packet
+// The fast verifier of the packets encoded before the role labels is the
+// plain salted hash
+// An error is returned if the subsecrets cannot be added or the verifier
+// cannot be computed with the parameters of the packet
 func GetAdditiveSaltedHashMatchBinExt(
 	runRelevantHashes [][32]byte,
 	runRelevantSalt [32]byte,
-	obtainedSubsecrets [][]uint16) (bool, []uint16) {
+	obtainedSubsecrets [][]uint16,
+	verifier VerifierParams, legacy bool) (bool, []uint16, error) {
 	recoveredKey := make([]uint16, len(obtainedSubsecrets[0]))
 	for _, obtainedSubsecret := range obtainedSubsecrets {
 		tempKey, err := shamir.SliceAdd(recoveredKey, obtainedSubsecret)
 		if err != nil {
-			log.Fatalln(err)
+			return false, nil, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet,
+				err)
 		}
 		recoveredKey = tempKey
 	}
 	obtainedKeyBytes := shamir.Uint16sToBytes(recoveredKey)
-	saltedHash := GetSaltedHash(runRelevantSalt, obtainedKeyBytes)
+	// Only the hash of the packet is computed, so a hardened packet costs a
+	// single run of scrypt per candidate
+	var saltedHash [32]byte
+	switch {
+	case verifier.Hardened():
+		var err error
+		saltedHash, err = GetVerifierHash(runRelevantSalt, obtainedKeyBytes,
+			verifier)
+		if err != nil {
+			return false, nil, err
+		}
+	case legacy:
+		saltedHash = GetSaltedHash(runRelevantSalt, obtainedKeyBytes)
+	default:
+		saltedHash = GetLabelledSaltedHash(runRelevantSalt, obtainedKeyBytes,
+			LabelFinalSecretCheck)
+	}
 	for _, runRelevantHash := range runRelevantHashes {
 		if CheckHashesEqual(saltedHash, runRelevantHash) {
-			return true, recoveredKey
+			return true, recoveredKey, nil
 		}
 	}
-	return false, recoveredKey
+	return false, recoveredKey, nil
 }
 
 func GetSaltedKeyMembershipBinExt(hashes [][32]byte, salt [32]byte,
-	k []uint16) bool {
+	k []uint16, label string) bool {
 	bytesKey := shamir.Uint16sToBytes(k)
-	saltedHash := GetSaltedHash(salt, bytesKey)
+	saltedHash := GetLabelledSaltedHash(salt, bytesKey, label)
 	return GetHashMembership(hashes, saltedHash)
 }
 
@@ -114,29 +141,20 @@ func CheckRecSecretKeyBinExt(
 // *************Relevant functions for thresholded packets*******************
 // **************************************************************************
 
+// The key of the marker is derived from the value of the share for the role
+// given by the label, so the value can be of any length
 func GetRelevantEncryptionBinExt(nonce [32]byte,
-	shareVal shamir.PriShare) ([]byte, int, error) {
-	// Padding to be added in the marker info to show that the
-	// the obtained secret is correct
-	zeroPadding := [8]byte{}
-	// The padding for the secret key is different from the others
+	shareVal shamir.PriShare, label string) ([]byte, int, error) {
+	// Format of the marker (v2)
+	// The nonce is authenticated along with the index of the share
 	bytesIndex := shamir.ConvertIndexUint16ToBytes(shareVal.X)
-	// Format of the markerData
-	// nonce || 00000000 || index || indicator bytes
-	var markerData []byte
-	// First of all, append the salt
-	for _, nonceData := range nonce {
-		markerData = append(markerData, nonceData)
-	}
-	// Next, append the zero padding
-	for _, zero := range zeroPadding {
-		markerData = append(markerData, zero)
-	}
-	// Then, append the index of the share
-	markerData = append(markerData, bytesIndex...)
-	encryptionKey := shamir.Uint16sToBytes(shareVal.Y)
+	encryptionKey := DeriveMarkerKey(shamir.Uint16sToBytes(shareVal.Y), nonce,
+		label)
 	// Encrypt the marker information generated
-	encryption := GetAESEncryption(encryptionKey, markerData)
+	encryption, err := GetMarkerEncryption(encryptionKey, nonce, bytesIndex)
+	if err != nil {
+		return nil, -1, err
+	}
 	// Encryption length represents the length for a certain share
 	encryptionLength := len(encryption)
 	return encryption, encryptionLength, nil
@@ -144,63 +162,61 @@ func GetRelevantEncryptionBinExt(nonce [32]byte,
 
 func GetThresholdedIndisShareMatchBinExt(recovered []uint16,
 	runRelevantNonce [32]byte,
-	runRelevantEncryptions [][]byte) (bool, uint16, []byte, error) {
+	runRelevantEncryptions [][]byte, label string,
+	legacy bool) (bool, uint16, []byte, error) {
 	var correctEncryption []byte
 	bytesVal := shamir.Uint16sToBytes(recovered)
 	correctX, correctEncryption, markerMatched, err := ThresholdedDecryptionCheckBinExt(bytesVal,
-		runRelevantNonce, runRelevantEncryptions)
+		runRelevantNonce, runRelevantEncryptions, label, legacy)
 	if err != nil {
 		return false, 0, nil, err
 	}
 	return markerMatched, correctX, correctEncryption, nil
 }
 
+// The v1 markers are encrypted with the value itself, while the key of the
+// v2 markers is derived for the role given by the label (or is the value
+// itself for the packets encoded before the role labels)
 func ThresholdedDecryptionCheckBinExt(
 	bytesVal []byte,
 	nonce [32]byte,
-	runRelevantEncryptions [][]byte) (uint16, []byte, bool, error) {
-	// fmt.Println("zz", len(runRelevantMarkerInfo))
+	runRelevantEncryptions [][]byte, label string,
+	legacy bool) (uint16, []byte, bool, error) {
+	// The opener is only created when a v2 marker is found
+	var opener *markerOpener
 	for _, relevantEncryption := range runRelevantEncryptions {
-		copiedEncryption := make([]byte, len(relevantEncryption))
-		copy(copiedEncryption, relevantEncryption)
-		encryptionMatched := true
-		plaintext, validity, err := GetAESDecryption(bytesVal, copiedEncryption)
-		if err != nil {
-			return 0, nil, false, err
-		}
-		if !validity {
-			return 0, nil, false, err
-		}
-		// The structure of the marker info is
-		// nonce || 00000000 || index
-		// Thus, first check if the first 32 bytes contain the salt
-		for i := 0; i < 32; i++ {
-			if plaintext[i] != nonce[i] {
-				encryptionMatched = false
-				break
+		if MarkerVersion(relevantEncryption) == MarkerV1 {
+			plaintext, matched, err := decryptionCheckV1(bytesVal, nonce,
+				relevantEncryption, 42)
+			if err != nil || !matched {
+				return 0, nil, false, err
 			}
+			if plaintext == nil {
+				continue
+			}
+			// The next 2 bytes indicate the x index of the share
+			xIndex := binary.BigEndian.Uint16(plaintext[40:42])
+			return xIndex, relevantEncryption, true, nil
 		}
-		// If there is no match, then continue to the next marker info
-		if !encryptionMatched {
-			continue
-		}
-		// fmt.Println("Passed 1")
-		// Then, check if the next 8 bytes are 0 or not
-		for i := 32; i < 40; i++ {
-			if plaintext[i] != 0 {
-				encryptionMatched = false
-				break
+		if opener == nil {
+			// Only the values of the AES key sizes can open the older
+			// markers
+			if legacy && !isAESKeySize(len(bytesVal)) {
+				continue
+			}
+			var err error
+			opener, err = newMarkerOpener(GetRoleMarkerKey(bytesVal, nonce,
+				label, legacy))
+			if err != nil {
+				return 0, nil, false, err
 			}
 		}
-		// If there is no match, then continue to the next marker info
-		if !encryptionMatched {
+		plaintext, matched := opener.open(nonce, relevantEncryption)
+		if !matched || len(plaintext) != 2 {
 			continue
 		}
-		// The next 8 bytes indicate the x index of the share
-		byteXValue := plaintext[40:42]
-		xIndex := binary.BigEndian.Uint16(byteXValue)
-		return xIndex, relevantEncryption, encryptionMatched, nil
-
+		xIndex := binary.BigEndian.Uint16(plaintext)
+		return xIndex, relevantEncryption, true, nil
 	}
 	return 0, nil, false, nil
 }
@@ -209,7 +225,7 @@ func GetThresholdedNoncedSubsecretMatchBinExt(
 	f shamir.Field,
 	runRelevantEncryptions [][]byte,
 	runRelevantNonce [32]byte,
-	obtainedSubsecrets []shamir.PriShare) (bool, []uint16) {
+	obtainedSubsecrets []shamir.PriShare, legacy bool) (bool, []uint16) {
 	recoveredKey, err := f.CombineUniqueX(obtainedSubsecrets)
 	if err != nil {
 		log.Fatal(err)
@@ -217,7 +233,8 @@ func GetThresholdedNoncedSubsecretMatchBinExt(
 	}
 	bytesVal := shamir.Uint16sToBytes(recoveredKey)
 	correctX, _, markerMatched, err := ThresholdedDecryptionCheckBinExt(bytesVal,
-		runRelevantNonce, runRelevantEncryptions)
+		runRelevantNonce, runRelevantEncryptions, LabelFinalSecretCheck,
+		legacy)
 	if err != nil {
 		log.Fatal(err)
 		return false, nil
@@ -234,28 +251,17 @@ func GetThresholdedNoncedSubsecretMatchBinExt(
 // ***************Relevant functions for hinted packets**********************
 // **************************************************************************
 func GetHintedRelevantEncryptionBinExt(nonce [32]byte,
-	subsecret []uint16, hint uint16) ([]byte, int, error) {
-	// Padding to be added in the marker info to show that the
-	// the obtained secret is correct
-	zeroPadding := [8]byte{}
-	// The padding for the secret key is different from the others
+	subsecret []uint16, hint uint16, label string) ([]byte, int, error) {
+	// Format of the marker (v2)
+	// The nonce is authenticated along with the hint
 	bytesHint := shamir.ConvertIndexUint16ToBytes(hint)
-	// Format of the markerData
-	// nonce || 00000000 || hint
-	var markerData []byte
-	// First of all, append the nonce
-	for _, nonceData := range nonce {
-		markerData = append(markerData, nonceData)
-	}
-	// Next, append the zero padding
-	for _, zero := range zeroPadding {
-		markerData = append(markerData, zero)
-	}
-	// Then, append the index of the share
-	markerData = append(markerData, bytesHint...)
-	encryptionKey := shamir.Uint16sToBytes(subsecret)
+	encryptionKey := DeriveMarkerKey(shamir.Uint16sToBytes(subsecret), nonce,
+		label)
 	// Encrypt the marker information generated
-	encryption := GetAESEncryption(encryptionKey, markerData)
+	encryption, err := GetMarkerEncryption(encryptionKey, nonce, bytesHint)
+	if err != nil {
+		return nil, -1, err
+	}
 	// Encryption length represents the length for a certain share
 	encryptionLength := len(encryption)
 	return encryption, encryptionLength, nil
@@ -265,7 +271,7 @@ func GetHintedTNoncedSubsecretMatchBinExt(
 	runRelevantEncryptions [][]byte,
 	runRelevantNonce [32]byte,
 	obtainedSubsecrets [][]uint16,
-	recoveryHint uint16) (bool, []uint16) {
+	recoveryHint uint16, legacy bool) (bool, []uint16) {
 	recoveredKey := make([]uint16, len(obtainedSubsecrets[0]))
 	for _, obtainedSubsecret := range obtainedSubsecrets {
 		tempKey, err := shamir.SliceAdd(recoveredKey, obtainedSubsecret)
@@ -276,7 +282,8 @@ func GetHintedTNoncedSubsecretMatchBinExt(
 	}
 	bytesVal := shamir.Uint16sToBytes(recoveredKey)
 	correctX, _, markerMatched, err := ThresholdedDecryptionCheckBinExt(bytesVal,
-		runRelevantNonce, runRelevantEncryptions)
+		runRelevantNonce, runRelevantEncryptions, LabelFinalSecretCheck,
+		legacy)
 	if err != nil {
 		log.Fatal(err)
 		return false, nil
@@ -286,3 +293,44 @@ func GetHintedTNoncedSubsecretMatchBinExt(
 	}
 	return false, nil
 }
+
+// **************************************************************************
+// **************************************************************************
+
+// ************Relevant functions for hierarchical packets*******************
+// **************************************************************************
+
+// The secrets of the nodes can be of any size, which the derivation of the
+// marker keys takes care of
+// The root (the secret key) is at the x-coordinate 0 and gets the label of
+// the final secret, the nodes right above the leaves the one of the leaf
+// check and the other nodes the one of the subsecrets
+func GetHierarchicalEncryptionBinExt(nonce [32]byte,
+	shareVal shamir.PriShare, aboveLeaves bool) ([]byte, int, error) {
+	label := LabelSubsecretCheck
+	if shareVal.X == 0 {
+		label = LabelFinalSecretCheck
+	} else if aboveLeaves {
+		label = LabelLeafCheck
+	}
+	return GetRelevantEncryptionBinExt(nonce, shareVal, label)
+}
+
+// Returns the x-coordinate of the node if the recovered secret matches
+// one of the markers
+// The role of the recovered secret is not known, so all the labels of the
+// nodes are tried
+func GetHierarchicalShareMatchBinExt(recovered []uint16,
+	runRelevantNonce [32]byte,
+	runRelevantEncryptions [][]byte) (bool, uint16, error) {
+	bytesVal := shamir.Uint16sToBytes(recovered)
+	for _, label := range []string{LabelLeafCheck, LabelSubsecretCheck,
+		LabelFinalSecretCheck} {
+		correctX, _, markerMatched, err := ThresholdedDecryptionCheckBinExt(
+			bytesVal, runRelevantNonce, runRelevantEncryptions, label, false)
+		if err != nil || markerMatched {
+			return markerMatched, correctX, err
+		}
+	}
+	return false, 0, nil
+}
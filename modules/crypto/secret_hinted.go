@@ -15,27 +15,15 @@ import (
 
 func GetHintedRelevantEncryption(nonce [32]byte,
 	subsecret kyber.Scalar, hint int) ([]byte, int, error) {
-	// Padding to be added in the marker info to show that the
-	// the obtained secret is correct
-	zeroPadding := [8]byte{}
-	// The padding for the secret key is different from the others
+	// Format of the marker (v2)
+	// The nonce is authenticated along with the hint
 	bytesHint := ConvertIndexToBytes(hint)
-	// Format of the markerData
-	// nonce || 00000000 || hint
-	var markerData []byte
-	// First of all, append the nonce
-	for _, nonceData := range nonce {
-		markerData = append(markerData, nonceData)
-	}
-	// Next, append the zero padding
-	for _, zero := range zeroPadding {
-		markerData = append(markerData, zero)
-	}
-	// Then, append the index of the share
-	markerData = append(markerData, bytesHint...)
 	encryptionKey := ConvertKeyToBytes(subsecret)
 	// Encrypt the marker information generated
-	encryption := GetAESEncryption(encryptionKey, markerData)
+	encryption, err := GetMarkerEncryption(encryptionKey, nonce, bytesHint)
+	if err != nil {
+		return nil, -1, err
+	}
 	// Encryption length represents the length for a certain share
 	encryptionLength := len(encryption)
 	return encryption, encryptionLength, nil
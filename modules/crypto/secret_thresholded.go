@@ -2,7 +2,6 @@ package crypto
 
 import (
 	"crypto/cipher"
-	"fmt"
 	"key_recovery/modules/errors"
 	"log"
 
@@ -18,27 +17,15 @@ import (
 
 func GetRelevantEncryption(nonce [32]byte,
 	shareVal *share.PriShare) ([]byte, int, error) {
-	// Padding to be added in the marker info to show that the
-	// the obtained secret is correct
-	zeroPadding := [8]byte{}
-	// The padding for the secret key is different from the others
+	// Format of the marker (v2)
+	// The nonce is authenticated along with the index of the share
 	bytesIndex := ConvertIndexToBytes(shareVal.I)
-	// Format of the markerData
-	// nonce || 00000000 || index || indicator bytes
-	var markerData []byte
-	// First of all, append the salt
-	for _, nonceData := range nonce {
-		markerData = append(markerData, nonceData)
-	}
-	// Next, append the zero padding
-	for _, zero := range zeroPadding {
-		markerData = append(markerData, zero)
-	}
-	// Then, append the index of the share
-	markerData = append(markerData, bytesIndex...)
 	encryptionKey := ConvertKeyToBytes(shareVal.V)
 	// Encrypt the marker information generated
-	encryption := GetAESEncryption(encryptionKey, markerData)
+	encryption, err := GetMarkerEncryption(encryptionKey, nonce, bytesIndex)
+	if err != nil {
+		return nil, -1, err
+	}
 	// Encryption length represents the length for a certain share
 	encryptionLength := len(encryption)
 	return encryption, encryptionLength, nil
@@ -48,48 +35,34 @@ func ThresholdedDecryptionCheck(
 	bytesVal []byte,
 	nonce [32]byte,
 	runRelevantEncryptions [][]byte) (int, []byte, bool, error) {
-	// fmt.Println("zz", len(runRelevantMarkerInfo))
+	// The opener is only created when a v2 marker is found
+	var opener *markerOpener
 	for _, relevantEncryption := range runRelevantEncryptions {
-		copiedEncryption := make([]byte, len(relevantEncryption))
-		copy(copiedEncryption, relevantEncryption)
-		encryptionMatched := true
-		plaintext, validity, err := GetAESDecryption(bytesVal, copiedEncryption)
-		if err != nil {
-			return -1, nil, false, err
-		}
-		if !validity {
-			return -1, nil, false, err
-		}
-		// The structure of the marker info is
-		// nonce || 00000000 || index
-		// Thus, first check if the first 32 bytes contain the salt
-		for i := 0; i < 32; i++ {
-			if plaintext[i] != nonce[i] {
-				encryptionMatched = false
-				break
+		if MarkerVersion(relevantEncryption) == MarkerV1 {
+			plaintext, matched, err := decryptionCheckV1(bytesVal, nonce,
+				relevantEncryption, 48)
+			if err != nil || !matched {
+				return -1, nil, false, err
 			}
+			if plaintext == nil {
+				continue
+			}
+			// The next 8 bytes indicate the x index of the share
+			xIndex := ConvertBytesToIndex(plaintext[40:48])
+			return xIndex, relevantEncryption, true, nil
 		}
-		// If there is no match, then continue to the next marker info
-		if !encryptionMatched {
-			continue
-		}
-		// fmt.Println("Passed 1")
-		// Then, check if the next 8 bytes are 0 or not
-		for i := 32; i < 40; i++ {
-			if plaintext[i] != 0 {
-				encryptionMatched = false
-				break
+		if opener == nil {
+			var err error
+			opener, err = newMarkerOpener(bytesVal)
+			if err != nil {
+				return -1, nil, false, err
 			}
 		}
-		// If there is no match, then continue to the next marker info
-		if !encryptionMatched {
+		plaintext, matched := opener.open(nonce, relevantEncryption)
+		if !matched || len(plaintext) != 8 {
 			continue
 		}
-		// The next 8 bytes indicate the x index of the share
-		byteXValue := plaintext[40:48]
-		xIndex := ConvertBytesToIndex(byteXValue)
-		return xIndex, relevantEncryption, encryptionMatched, nil
-
+		return ConvertBytesToIndex(plaintext), relevantEncryption, true, nil
 	}
 	return -1, nil, false, nil
 }
@@ -180,8 +153,7 @@ func GetMarkerInfo(
 	shareVals []*share.PriShare,
 	noOfSharesReceived int,
 	noOfLevels int) ([][]byte, int, error) {
-	// Padding to be added in the marker info to show that the
-	// the obtained secret is correct
+	// The indicator bytes are zero only for the secret key
 	zeroPadding := [8]byte{}
 	var output [][]byte
 	// The encryption length is relevant in generating random information for
@@ -191,21 +163,10 @@ func GetMarkerInfo(
 	// Generate the marker info for each share
 	for i := 0; i < noOfSharesReceived; i++ {
 		for j, relevantSecret := range relevantSecrets[i] {
-			// The padding for the secret key is different from the others
-			bytesIndex := ConvertIndexToBytes(relevantSecret.I)
-			// Format of the markerData
-			// salt || 00000000 || index || indicator bytes
-			var markerData []byte
-			// First of all, append the salt
-			for _, saltData := range salt {
-				markerData = append(markerData, saltData)
-			}
-			// Next, append the zero padding
-			for _, zero := range zeroPadding {
-				markerData = append(markerData, zero)
-			}
-			// Then, append the index of the share
-			markerData = append(markerData, bytesIndex...)
+			// Format of the marker (v2)
+			// index || indicator bytes
+			// The salt is authenticated along with them
+			markerData := ConvertIndexToBytes(relevantSecret.I)
 			// When you have the secret key, then pad more zeros
 			if j == len(relevantSecrets[i])-1 {
 				// Check if the secret key actually matches
@@ -213,9 +174,7 @@ func GetMarkerInfo(
 				if !valuesEqual {
 					return nil, -1, errors.ErrBytesNotEqual
 				}
-				for _, zero := range zeroPadding {
-					markerData = append(markerData, zero)
-				}
+				markerData = append(markerData, zeroPadding[:]...)
 			} else {
 				// Otherwise pad some random bits
 				randomBytes, err := GenerateRandomBytes(8)
@@ -226,7 +185,11 @@ func GetMarkerInfo(
 			}
 			encryptionKey := ConvertKeyToBytes(relevantSecret.V)
 			// Encrypt the marker information generated
-			encryption := GetAESEncryption(encryptionKey, markerData)
+			encryption, err := GetMarkerEncryption(encryptionKey, salt,
+				markerData)
+			if err != nil {
+				return nil, -1, err
+			}
 			// Encryption length represents the length for a certain share
 			encryptionLength = len(encryption)
 			output = append(output, encryption)
@@ -286,56 +249,42 @@ func CorrectDecryptionCheck(
 	bytesVal []byte,
 	salt [32]byte,
 	runRelevantMarkerInfo [][]byte) (int, bool, []byte, bool, error) {
-	// fmt.Println("zz", len(runRelevantMarkerInfo))
+	// The opener is only created when a v2 marker is found
+	var opener *markerOpener
 	for _, markerInfo := range runRelevantMarkerInfo {
-		// fmt.Println("hehe", markerInfo)
-		// fmt.Println("aa")
-		copiedMarkerInfo := make([]byte, len(markerInfo))
-		copy(copiedMarkerInfo, markerInfo)
-		markerMatched := true
-		plaintext, validity, err := GetAESDecryption(bytesVal, copiedMarkerInfo)
-		fmt.Println(plaintext)
-		if err != nil {
-			// fmt.Println("yy")
-			return -1, false, nil, false, err
-		}
-		if !validity {
-			// fmt.Println("xx")
-			return -1, false, nil, false, err
-		}
-		// The structure of the marker info is
-		// salt || 00000000 || index || indicator bytes
-		// Thus, first check if the first 32 bytes contain the salt
-		for i := 0; i < 32; i++ {
-			if plaintext[i] != salt[i] {
-				markerMatched = false
-				break
+		// The index and the indicator bytes of the marker
+		var indexBytes, padCheckBytes []byte
+		if MarkerVersion(markerInfo) == MarkerV1 {
+			plaintext, validity, err := decryptionCheckV1(bytesVal, salt,
+				markerInfo, 48)
+			if err != nil || !validity {
+				return -1, false, nil, false, err
 			}
-		}
-		// If there is no match, then continue to the next marker info
-		if !markerMatched {
-			continue
-		}
-		// fmt.Println("Passed 1")
-		// Then, check if the next 8 bytes are 0 or not
-		for i := 32; i < 40; i++ {
-			if plaintext[i] != 0 {
-				markerMatched = false
-				break
+			if plaintext == nil {
+				continue
 			}
+			// salt || 00000000 || index || indicator bytes
+			indexBytes, padCheckBytes = plaintext[40:48], plaintext[48:]
+		} else {
+			if opener == nil {
+				var err error
+				opener, err = newMarkerOpener(bytesVal)
+				if err != nil {
+					return -1, false, nil, false, err
+				}
+			}
+			plaintext, matched := opener.open(salt, markerInfo)
+			if !matched || len(plaintext) < 8 {
+				continue
+			}
+			// index || indicator bytes
+			indexBytes, padCheckBytes = plaintext[:8], plaintext[8:]
 		}
-		// If there is no match, then continue to the next marker info
-		if !markerMatched {
-			continue
-		}
-		// The next 8 bytes indicate the x index of the share
-		byteXValue := plaintext[40:48]
-		xIndex := ConvertBytesToIndex(byteXValue)
+		xIndex := ConvertBytesToIndex(indexBytes)
 		// Final check the indicator bytes
 		// These bytes indicate if the recovered secret is the main secret
 		// If all the bytes of the indicator are 0's,
 		// then the secret has been recovered
-		padCheckBytes := plaintext[48:]
 		finalLevelObtained := true
 		for _, padCheckByte := range padCheckBytes {
 			if padCheckByte != 0 {
@@ -343,8 +292,7 @@ func CorrectDecryptionCheck(
 				break
 			}
 		}
-		return xIndex, finalLevelObtained, markerInfo, markerMatched, nil
-
+		return xIndex, finalLevelObtained, markerInfo, true, nil
 	}
 	return -1, false, nil, false, errors.ErrMarkerNoMatch
 }
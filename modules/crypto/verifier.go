@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"key_recovery/modules/errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// **************************************************************************
+// **************************************************************************
+
+// **********************Verifier of the secret key**************************
+// **************************************************************************
+
+// The salted hash of the secret key lets anyone holding a single packet test
+// guesses of a low-entropy secret offline
+// The hardened verifier replaces it with scrypt, which is slow and
+// memory-hard, while the checks of the subsecrets stay fast
+// The verifier is only computed when a candidate secret key is checked,
+// which happens once per newly recovered subsecret
+type VerifierParams struct {
+	LogN uint8 // log2 of the CPU/memory cost of scrypt, 0 for the fast hash
+	R    uint8 // block size of scrypt
+	P    uint8 // parallelization of scrypt
+}
+
+// Recommended parameters of scrypt for interactive use (32 MiB)
+var DefaultVerifierParams = VerifierParams{LogN: 15, R: 8, P: 1}
+
+// Memory used by scrypt is 128 * N * R bytes
+// Larger parameters are rejected so that a packet cannot exhaust the memory
+// of the recovery
+const maxVerifierMemory = 1 << 30
+
+// Hardened tells if the verifier is computed with scrypt
+func (p VerifierParams) Hardened() bool {
+	return p.LogN != 0
+}
+
+// Check returns an error if scrypt cannot be run with the parameters
+func (p VerifierParams) Check() error {
+	if !p.Hardened() {
+		if p.R != 0 || p.P != 0 {
+			return errors.ErrInvalidVerifierParams
+		}
+		return nil
+	}
+	if p.LogN > 30 || p.R == 0 || p.P == 0 {
+		return errors.ErrInvalidVerifierParams
+	}
+	if 128*(uint64(1)<<p.LogN)*uint64(p.R) > maxVerifierMemory {
+		return errors.ErrInvalidVerifierParams
+	}
+	return nil
+}
+
+// GetVerifierHash gives the verifier of the secret key for the salt
+// Without hardening, it is the labelled salted hash of the final secret
+func GetVerifierHash(salt [32]byte, secret []byte,
+	params VerifierParams) ([32]byte, error) {
+	var output [32]byte
+	if err := params.Check(); err != nil {
+		return output, err
+	}
+	if !params.Hardened() {
+		return GetLabelledSaltedHash(salt, secret, LabelFinalSecretCheck), nil
+	}
+	// The label keeps the verifier apart from the other salted hashes
+	scryptSalt := append(salt[:], LabelFinalSecretCheck...)
+	key, err := scrypt.Key(secret, scryptSalt, 1<<params.LogN,
+		int(params.R), int(params.P), len(output))
+	if err != nil {
+		return output, err
+	}
+	copy(output[:], key)
+	return output, nil
+}
@@ -4,13 +4,39 @@ import "errors"
 
 // Custom errors used across the repository
 var (
-	ErrBytesNotEqual       = errors.New("byte slices not equal")
-	ErrPacketsNotGenerated = errors.New("error in generating packets")
-	ErrInvalidThreshold    = errors.New("threshold is more than the number of shares")
-	ErrVeryLargeThreshold  = errors.New("threshold is more than the system requirement")
-	ErrInvalidInput        = errors.New("invalid input")
-	ErrNoOfSharesNotEqual  = errors.New("no. of shares are not equal in the two packets")
-	ErrMarkerNoMatch       = errors.New("no marker info matches from the obtained secret")
-	ErrSecretNotFound      = errors.New("secret could not be found with any of the combinations")
-	ErrInvalidSliceLength  = errors.New("length of the slices do not match")
+	ErrBytesNotEqual            = errors.New("byte slices not equal")
+	ErrPacketsNotGenerated      = errors.New("error in generating packets")
+	ErrInvalidThreshold         = errors.New("threshold is more than the number of shares")
+	ErrVeryLargeThreshold       = errors.New("threshold is more than the system requirement")
+	ErrInvalidInput             = errors.New("invalid input")
+	ErrNoOfSharesNotEqual       = errors.New("no. of shares are not equal in the two packets")
+	ErrMarkerNoMatch            = errors.New("no marker info matches from the obtained secret")
+	ErrSecretNotFound           = errors.New("secret could not be found with any of the combinations")
+	ErrInvalidSliceLength       = errors.New("length of the slices do not match")
+	ErrUnknownScheme            = errors.New("unknown secret sharing scheme")
+	ErrNoPacketsFound           = errors.New("no packets found in the directory")
+	ErrInvalidPacket            = errors.New("invalid packet encoding")
+	ErrTruncatedPacket          = errors.New("packet encoding is truncated")
+	ErrTrailingPacketData       = errors.New("packet encoding has trailing data")
+	ErrPacketTooLarge           = errors.New("packet encoding is too large")
+	ErrPacketSchemeMismatch     = errors.New("packet belongs to a different scheme")
+	ErrUnsupportedPacketVersion = errors.New("unsupported packet format version")
+	ErrInvalidArmor             = errors.New("invalid armored packet")
+	ErrInvalidChunk             = errors.New("invalid or missing packet chunk")
+	ErrUnknownFormat            = errors.New("unknown packet file format")
+	ErrChecksumMismatch         = errors.New("checksum of the packet does not match")
+	ErrRecoveryCancelled        = errors.New("secret recovery was cancelled")
+	ErrInvalidShareSet          = errors.New("shares could not be combined")
+	ErrTooManyCombinations      = errors.New("number of combinations does not fit in 64 bits")
+	ErrInvalidExperimentSpec    = errors.New("invalid experiment specification")
+	ErrUnsupportedExperiment    = errors.New("metric is not supported for the scheme and backend")
+	ErrInconsistentLeaves       = errors.New("leaves of a subsecret are not on one polynomial")
+	ErrTooManyErrors            = errors.New("too many inconsistent shares to decode")
+	ErrInvalidVerifierParams    = errors.New("invalid parameters of the secret key verifier")
+	ErrVerifierSchemeMismatch   = errors.New("hardened verifier is only supported by the additive scheme")
+	ErrInvalidPayload           = errors.New("invalid encrypted payload")
+	ErrPayloadAuthentication    = errors.New("payload could not be decrypted with the recovered key")
+	ErrInvalidVerificationKey   = errors.New("invalid verification key of the packet")
+	ErrInvalidOpening           = errors.New("opening of the commitment could not be unsealed with the key")
+	ErrInconsistentCommitments  = errors.New("shares of a subsecret are not under the same commitments")
 )
@@ -2,10 +2,19 @@ package evaluation
 
 import (
 	"key_recovery/modules/configuration"
+	"log"
 )
 
+// The codes which have a preset in presets.go run through RunExperiment
 func Evaluate(cfg *configuration.SimulationConfig, mainDir string, evalType int,
 	varyingParameter int) {
+	if spec, ok := PresetSpec(cfg, evalType, varyingParameter); ok {
+		err := RunExperiment(cfg, mainDir, spec)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
 	switch {
 	case evalType%3 == 0:
 		switch varyingParameter {
@@ -156,44 +165,12 @@ func Evaluate(cfg *configuration.SimulationConfig, mainDir string, evalType int,
 		}
 	case evalType%3 == 1:
 		switch varyingParameter {
-		case 1:
-			EvaluateGetAdditiveProbabilityFixedThTotalCDFVAnon(cfg, mainDir)
-		case 2:
-			EvaluateGetAdditiveProbabilityFixedThTotalCDFVTh(cfg, mainDir)
-		case 3:
-			EvaluateGetAdditiveProbabilityFixedThTotalCDFVTr(cfg, mainDir)
-		case 4:
-			EvaluateGetAdditiveProbabilityFixedThTotalCDFVAT(cfg, mainDir)
 		case 5:
 			EvaluateGetAdditiveProbabilityFixedThTotalCDFVSS(cfg, mainDir)
-		case 6:
-			EvaluateGetBaselineProbabilityCDFVTh(cfg, mainDir)
-		case 7:
-			EvaluateGetBaselineProbabilityCDFVTr(cfg, mainDir)
-		case 8:
-			EvaluateGetBaselineProbabilityCDFVAnon(cfg, mainDir)
-		case 9:
-			EvaluateGetThresholdedProbabilityFixedThTotalCDFVAnon(cfg, mainDir)
-		case 10:
-			EvaluateGetThresholdedProbabilityFixedThTotalCDFVTh(cfg, mainDir)
-		case 11:
-			EvaluateGetThresholdedProbabilityFixedThTotalCDFVTr(cfg, mainDir)
-		case 12:
-			EvaluateGetThresholdedProbabilityFixedThTotalCDFVAT(cfg, mainDir)
 		case 13:
 			EvaluateGetThresholdedProbabilityFixedThTotalCDFVSS(cfg, mainDir)
-		case 14:
-			EvaluateGetHintedTProbabilityFixedThTotalCDFVAnon(cfg, mainDir)
-		case 15:
-			EvaluateGetHintedTProbabilityFixedThTotalCDFVTh(cfg, mainDir)
-		case 16:
-			EvaluateGetHintedTProbabilityFixedThTotalCDFVTr(cfg, mainDir)
-		case 17:
-			EvaluateGetHintedTProbabilityFixedThTotalCDFVAT(cfg, mainDir)
 		case 18:
 			EvaluateGetHintedTProbabilityFixedThTotalCDFVSS(cfg, mainDir)
-		case 19:
-			EvaluateGetAdditiveProbabilityFixedThTotalCDFVSPP(cfg, mainDir)
 		case 20:
 			EvaluateGetAdditiveProbabilityFixedThTotalCDFVAnonExponential(cfg, mainDir)
 		case 21:
@@ -235,21 +212,6 @@ func Evaluate(cfg *configuration.SimulationConfig, mainDir string, evalType int,
 		}
 	case evalType%3 == 2:
 		switch varyingParameter {
-		case 1:
-			// EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(cfg, mainDir)
-			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 1)
-		case 2:
-			// EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(cfg, mainDir)
-			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 2)
-		case 3:
-			// EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(cfg, mainDir)
-			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 3)
-		case 4:
-			// EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(cfg, mainDir)
-			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 4)
-		case 5:
-			// EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(cfg, mainDir)
-			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 5)
 		case 6:
 			EvaluateBasicHashedSecretRecoveryBinExtCPU(cfg, mainDir, 1)
 			EvaluateBasicHashedSecretRecoveryBinExtCPU(cfg, mainDir, 7)
@@ -274,21 +236,6 @@ func Evaluate(cfg *configuration.SimulationConfig, mainDir string, evalType int,
 		case 13:
 			// EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(cfg, mainDir)
 			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT6BinExtCPU(cfg, mainDir)
-		case 14:
-			// EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(cfg, mainDir)
-			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 1)
-		case 15:
-			// EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(cfg, mainDir)
-			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 2)
-		case 16:
-			// EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(cfg, mainDir)
-			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 3)
-		case 17:
-			// EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(cfg, mainDir)
-			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 4)
-		case 18:
-			// EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(cfg, mainDir)
-			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 5)
 		case 19:
 			EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(cfg, mainDir)
 		// case 20:
@@ -328,21 +275,6 @@ func Evaluate(cfg *configuration.SimulationConfig, mainDir string, evalType int,
 		case 34:
 			EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(cfg, mainDir)
 			// EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(cfg, mainDir)
-		case 35:
-			// EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(cfg, mainDir)
-			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 1)
-		case 36:
-			// EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(cfg, mainDir)
-			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 2)
-		case 37:
-			// EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(cfg, mainDir)
-			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 3)
-		case 38:
-			// EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(cfg, mainDir)
-			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 4)
-		case 39:
-			// EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(cfg, mainDir)
-			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(cfg, mainDir, 5)
 		case 40:
 			// EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(cfg, mainDir)
 			EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(cfg, mainDir)
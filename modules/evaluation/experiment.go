@@ -0,0 +1,731 @@
+package evaluation
+
+import (
+	"fmt"
+	"key_recovery/modules/configuration"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/files"
+	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"key_recovery/modules/utils"
+	randm "math/rand"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Test case of an experiment spec
+// The parameters of the thresholded and the hinted schemes are kept along
+// with the ones of RunDataType, so one type serves all the schemes
+type RunDataTypeSpec struct {
+	RunDataType
+	percentageSubsecretsThreshold int
+	noOfHints                     int
+	sharesPerPerson               int
+	contactWidth                  int
+	insiders                      int
+}
+
+// GenerateTestCasesSpec gives all the combinations of the swept values
+// The parameter listed first in the sweep changes the slowest
+// The parameters which are not swept take the defaults from the config and
+// the number of subsecrets is the ideal one unless it is swept
+func GenerateTestCasesSpec(spec *configuration.ExperimentSpec,
+	cfg *configuration.SimulationConfig) []RunDataTypeSpec {
+	base := map[string]int{
+		configuration.ParamTrustees:            cfg.DefaultTrustees,
+		configuration.ParamAnonymitySetSize:    cfg.DefaultAnonymitySetSize,
+		configuration.ParamAbsoluteThreshold:   cfg.DefaultAbsoluteThreshold,
+		configuration.ParamLeavesThreshold:     cfg.DefaultPercentageThreshold,
+		configuration.ParamSubsecretsThreshold: cfg.DefaultSubsecretsThreshold,
+		configuration.ParamHints:               cfg.DefaultTrusteesHint,
+		configuration.ParamSharesPerPerson:     cfg.DefaultSharesPerPerson,
+		configuration.ParamContactWidth:        1,
+		configuration.ParamInsiders:            0,
+	}
+	var testCases []RunDataTypeSpec
+	var addTestCases func(i int, params map[string]int)
+	addTestCases = func(i int, params map[string]int) {
+		if i == len(spec.Sweep) {
+			testCases = append(testCases, newRunDataTypeSpec(params))
+			return
+		}
+		for _, value := range spec.Sweep[i].Values() {
+			params[spec.Sweep[i].Name] = value
+			addTestCases(i+1, params)
+		}
+		delete(params, spec.Sweep[i].Name)
+	}
+	addTestCases(0, base)
+	return testCases
+}
+
+func newRunDataTypeSpec(params map[string]int) RunDataTypeSpec {
+	noOfSubsecrets, ok := params[configuration.ParamSubsecrets]
+	if !ok {
+		noOfSubsecrets = GetIdealNoOfSubsecrets(
+			params[configuration.ParamSharesPerPerson],
+			params[configuration.ParamLeavesThreshold],
+			params[configuration.ParamTrustees],
+			params[configuration.ParamAbsoluteThreshold])
+	}
+	return RunDataTypeSpec{
+		RunDataType: RunDataType{
+			n:                              params[configuration.ParamTrustees],
+			a:                              params[configuration.ParamAnonymitySetSize],
+			absoluteThreshold:              params[configuration.ParamAbsoluteThreshold],
+			noOfSubsecrets:                 noOfSubsecrets,
+			percentageLeavesLayerThreshold: params[configuration.ParamLeavesThreshold],
+		},
+		percentageSubsecretsThreshold: params[configuration.ParamSubsecretsThreshold],
+		noOfHints:                     params[configuration.ParamHints],
+		sharesPerPerson:               params[configuration.ParamSharesPerPerson],
+		contactWidth:                  params[configuration.ParamContactWidth],
+		insiders:                      params[configuration.ParamInsiders],
+	}
+}
+
+// RunExperiment runs the experiment of the spec and stores the results
+// along with the spec in mainDir/<name of the spec>/
+func RunExperiment(cfg *configuration.SimulationConfig, mainDir string,
+	spec *configuration.ExperimentSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if (spec.Metric == configuration.MetricRecoveryTime ||
+		spec.Metric == configuration.MetricCoalition) &&
+		spec.Scheme == configuration.SchemeBaseline {
+		return fmt.Errorf("%w: %s %s %s", errors.ErrUnsupportedExperiment,
+			spec.Metric, spec.Scheme, spec.Backend)
+	}
+	if spec.Metric == configuration.MetricExactProbability &&
+		spec.Scheme != configuration.SchemeAdditive &&
+		spec.Scheme != configuration.SchemeThresholded {
+		return fmt.Errorf("%w: %s %s %s", errors.ErrUnsupportedExperiment,
+			spec.Metric, spec.Scheme, spec.Backend)
+	}
+	if spec.Metric == configuration.MetricPacketSize &&
+		spec.Backend == configuration.BackendKyber &&
+		spec.Scheme != configuration.SchemeAdditive {
+		return fmt.Errorf("%w: %s %s %s", errors.ErrUnsupportedExperiment,
+			spec.Metric, spec.Scheme, spec.Backend)
+	}
+	testCases := GenerateTestCasesSpec(spec, cfg)
+	csvDir := mainDir + spec.Name + "/"
+	err, _ := files.CreateDirectory(csvDir)
+	if err != nil {
+		return err
+	}
+	// Keep the spec next to the results for running the experiment again
+	specData, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(csvDir+"spec.yaml", specData, 0644)
+	if err != nil {
+		return err
+	}
+	if spec.Metric == configuration.MetricProbability {
+		return runProbabilityExperiment(cfg, csvDir, spec, testCases)
+	}
+	if spec.Metric == configuration.MetricExactProbability {
+		return runExactProbabilityExperiment(cfg, csvDir, spec, testCases)
+	}
+	if spec.Metric == configuration.MetricRecoveryTime {
+		return runRecoveryTimeExperiment(cfg, csvDir, spec, testCases)
+	}
+	if spec.Metric == configuration.MetricCoalition {
+		return runCoalitionExperiment(cfg, csvDir, spec, testCases)
+	}
+
+	iterations := spec.Iterations
+	if iterations == 0 {
+		iterations = cfg.Iterations * cfg.Iterations
+	}
+	csvFileName := csvDir + "results.csv"
+	err, _ = files.CreateFile(csvFileName)
+	if err != nil {
+		return err
+	}
+	err = files.WriteToCSVFile(csvFileName,
+		[][]interface{}{experimentTopData(spec)})
+	if err != nil {
+		return err
+	}
+	env := newExperimentEnv(spec.Metric == configuration.MetricCPUTime,
+		cfg.NoOfWorkers, randomness.NewSeededRand(cfg.Seed))
+	for _, tc := range testCases {
+		var data [][]interface{}
+		for simulationNumber := 0; simulationNumber < iterations; simulationNumber++ {
+			fmt.Println(spec.Name, tc)
+			rows, err := env.runExperimentCase(spec, tc)
+			if err != nil {
+				return fmt.Errorf("%v: %w", tc, err)
+			}
+			data = append(data, rows...)
+		}
+		// Write after every test case so that long sweeps keep their results
+		err = files.WriteToCSVFile(csvFileName, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Header of the results of the time and packet size metrics
+func experimentTopData(spec *configuration.ExperimentSpec) []interface{} {
+	topData := []interface{}{
+		"Trustees",
+		"Anonymity Set Size",
+		"Leaves Threshold",
+	}
+	switch spec.Metric {
+	case configuration.MetricPacketSize:
+		topData = append(topData, "Packet size")
+	case configuration.MetricCoalition:
+		topData = append(topData,
+			"Insiders",
+			"Runs",
+			"Recovered before reported",
+			"Probability",
+			"Lower",
+			"Upper")
+	default:
+		topData = append(topData,
+			"Time taken for secret sharing",
+			"Time taken for secret recovery")
+	}
+	topData = append(topData,
+		"Absolute Threshold",
+		"Subsecrets",
+	)
+	switch spec.Scheme {
+	case configuration.SchemeThresholded:
+		topData = append(topData, "Subsecrets Threshold")
+	case configuration.SchemeHinted:
+		topData = append(topData, "Hints")
+	}
+	if isTimeMetric(spec) && spec.Backend == configuration.BackendBinExt {
+		topData = append(topData, "Workers")
+	}
+	return topData
+}
+
+// The number of workers is only stored along with the times
+func isTimeMetric(spec *configuration.ExperimentSpec) bool {
+	return spec.Metric == configuration.MetricTime ||
+		spec.Metric == configuration.MetricCPUTime
+}
+
+// Row of the results in the order of experimentTopData
+func experimentRow(spec *configuration.ExperimentSpec, tc RunDataTypeSpec,
+	noOfWorkers int, measured ...interface{}) []interface{} {
+	row := []interface{}{
+		tc.n,
+		tc.a,
+		tc.percentageLeavesLayerThreshold,
+	}
+	row = append(row, measured...)
+	row = append(row,
+		tc.absoluteThreshold,
+		tc.noOfSubsecrets,
+	)
+	switch spec.Scheme {
+	case configuration.SchemeThresholded:
+		row = append(row, tc.percentageSubsecretsThreshold)
+	case configuration.SchemeHinted:
+		row = append(row, tc.noOfHints)
+	}
+	if isTimeMetric(spec) && spec.Backend == configuration.BackendBinExt {
+		row = append(row, secretbe.NoOfWorkers(noOfWorkers))
+	}
+	return row
+}
+
+// Gives the rows of one run of the test case
+// The time metrics give one row and the packet size gives one row per packet
+func (env *experimentEnv) runExperimentCase(spec *configuration.ExperimentSpec,
+	tc RunDataTypeSpec) ([][]interface{}, error) {
+	env.clock.reset()
+	shared, err := env.share(spec, tc)
+	if err != nil {
+		return nil, err
+	}
+	elapsedTime1 := env.clock.record()
+	if spec.Metric == configuration.MetricPacketSize {
+		var rows [][]interface{}
+		for _, packetSize := range shared.packetSizes() {
+			rows = append(rows, experimentRow(spec, tc, env.noOfWorkers, packetSize))
+		}
+		return rows, nil
+	}
+
+	accessOrder := utils.GenerateIndicesSet(tc.a)
+	utils.Shuffle(accessOrder, env.rng)
+
+	env.clock.reset()
+	err = env.recover(shared, accessOrder, tc.absoluteThreshold)
+	if err != nil {
+		return nil, err
+	}
+	elapsedTime2 := env.clock.record()
+	fmt.Println("Generation:", elapsedTime1)
+	fmt.Println("Reconstruction:", elapsedTime2)
+	return [][]interface{}{
+		experimentRow(spec, tc, env.noOfWorkers, elapsedTime1, elapsedTime2),
+	}, nil
+}
+
+// The probability does not depend on the backend
+// Every test case has its own file as in the other probability evaluations
+func runProbabilityExperiment(cfg *configuration.SimulationConfig,
+	csvDir string, spec *configuration.ExperimentSpec,
+	testCases []RunDataTypeSpec) error {
+	rng := randomness.NewSeededRand(cfg.Seed)
+	simulationsDist := cfg.DefaultSimulationDistributionNums
+	simulationsRun := cfg.DefaultSimulationRunNums
+	interval, err := probability.NewInterval(cfg.ConfidenceInterval,
+		cfg.ConfidenceLevel)
+	if err != nil {
+		return err
+	}
+	// The simulations of a test case are repeated until the intervals are
+	// narrow enough if the config asks for it
+	control := probability.AdaptiveControl{
+		Interval:    interval,
+		TargetWidth: cfg.TargetIntervalWidth,
+		TimeBudget:  cfg.TimeBudget,
+	}
+	var strategy probability.AccessStrategy
+	if spec.Strategy != nil {
+		strategy, err = experimentStrategy(spec, nil)
+		if err != nil {
+			return err
+		}
+	}
+	l := 2
+	for _, tc := range testCases {
+		fmt.Println(spec.Name, tc)
+		// The weights are checked here as the number of trustees may come
+		// from config.yaml
+		if len(spec.Weights) != 0 && len(spec.Weights) != tc.n {
+			return fmt.Errorf("%w: %d weights for %d trustees",
+				errors.ErrInvalidExperimentSpec, len(spec.Weights), tc.n)
+		}
+		batch := func() (map[int]int, map[int]int, error) {
+			switch spec.Scheme {
+			case configuration.SchemeBaseline:
+				return probability.GetBaselineProbabilityCDF(
+					simulationsDist*simulationsRun,
+					tc.percentageLeavesLayerThreshold, tc.n, tc.a, rng)
+			case configuration.SchemeAdditive:
+				return probability.GetAdditiveProbabilityFixedThTotalCDFParallelizedWeighted(
+					simulationsDist, simulationsRun, l,
+					tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+					tc.absoluteThreshold, tc.noOfSubsecrets, spec.Weights, rng)
+			case configuration.SchemeThresholded:
+				return probability.GetThresholdedProbabilityFixedThTotalCDFParallelizedWeighted(
+					simulationsDist, simulationsRun, l,
+					tc.percentageLeavesLayerThreshold,
+					tc.percentageSubsecretsThreshold, tc.n, tc.a,
+					tc.absoluteThreshold, tc.noOfSubsecrets, spec.Weights, rng)
+			case configuration.SchemeHinted:
+				return probability.GetHintedTProbabilityFixedThTotalCDFParallelizedWeighted(
+					simulationsDist, simulationsRun, l,
+					tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+					tc.absoluteThreshold, tc.noOfSubsecrets, tc.noOfHints,
+					spec.Weights, rng)
+			}
+			return nil, nil, errors.ErrUnsupportedExperiment
+		}
+		if spec.Availability != nil {
+			batch, err = availabilityBatch(spec, tc, simulationsDist,
+				simulationsRun, l, rng)
+			if err != nil {
+				return fmt.Errorf("%v: %w", tc, err)
+			}
+		}
+		if strategy != nil {
+			batch = strategyBatch(spec, tc, strategy, simulationsDist,
+				simulationsRun, l, rng)
+		}
+		results, resultsAnon, err := probability.RunAdaptive(batch, control)
+		if err != nil {
+			return fmt.Errorf("%v: %w", tc, err)
+		}
+		if spec.Availability != nil {
+			err = writeImpossibleProbability(csvDir, spec, tc, l, results,
+				interval)
+			if err != nil {
+				return err
+			}
+		}
+		data, sum1, sum2 := FormDataForCSVWithIntervals(results, resultsAnon,
+			interval)
+		fmt.Println(tc.percentageLeavesLayerThreshold, sum1, sum2)
+		csvFileName := probabilityFileName(csvDir+"result-probability-", spec,
+			tc, l)
+		err, _ = files.CreateFile(csvFileName)
+		if err != nil {
+			return err
+		}
+		err = files.WriteToCSVFile(csvFileName, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The strategy of the spec, or the default one if the spec has none
+func experimentStrategy(spec *configuration.ExperimentSpec,
+	defaultStrategy probability.AccessStrategy) (probability.AccessStrategy,
+	error) {
+	if spec.Strategy == nil {
+		return defaultStrategy, nil
+	}
+	return probability.NewStrategy(spec.Strategy.Name,
+		spec.Strategy.DeltaTrustees, spec.Strategy.DeltaNonTrustees)
+}
+
+// The user contacts the people in the order of the strategy
+func strategyBatch(spec *configuration.ExperimentSpec, tc RunDataTypeSpec,
+	strategy probability.AccessStrategy, simulationsDist, simulationsRun,
+	l int, rng *randm.Rand) func() (map[int]int, map[int]int, error) {
+	return func() (map[int]int, map[int]int, error) {
+		switch spec.Scheme {
+		case configuration.SchemeAdditive:
+			return probability.GetAdditiveProbabilityStrategyCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, strategy, rng)
+		case configuration.SchemeThresholded:
+			return probability.GetThresholdedProbabilityStrategyCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold,
+				tc.percentageSubsecretsThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, strategy, rng)
+		case configuration.SchemeHinted:
+			return probability.GetHintedTProbabilityStrategyCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.noOfHints,
+				strategy, rng)
+		}
+		return nil, nil, errors.ErrUnsupportedExperiment
+	}
+}
+
+// The runs in which the secret is never recovered are counted after the
+// last size of the anonymity set, so the CDFs are over all the runs
+func availabilityBatch(spec *configuration.ExperimentSpec,
+	tc RunDataTypeSpec, simulationsDist, simulationsRun, l int,
+	rng *randm.Rand) (func() (map[int]int, map[int]int, error), error) {
+	availability, err := probability.NewAvailability(tc.n, tc.a,
+		spec.Availability.Trustees, spec.Availability.NonTrustees,
+		spec.Availability.Permanent)
+	if err != nil {
+		return nil, err
+	}
+	return func() (map[int]int, map[int]int, error) {
+		var results, resultsAnon map[int]int
+		var failures int
+		var err error
+		switch spec.Scheme {
+		case configuration.SchemeAdditive:
+			results, resultsAnon, failures, err = probability.GetAdditiveProbabilityAvailabilityCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, availability, rng)
+		case configuration.SchemeThresholded:
+			results, resultsAnon, failures, err = probability.GetThresholdedProbabilityAvailabilityCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold,
+				tc.percentageSubsecretsThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, availability, rng)
+		case configuration.SchemeHinted:
+			results, resultsAnon, failures, err = probability.GetHintedTProbabilityAvailabilityCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.noOfHints,
+				availability, rng)
+		default:
+			return nil, nil, errors.ErrUnsupportedExperiment
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		results[tc.a+1] += failures
+		resultsAnon[tc.a+1] += failures
+		return results, resultsAnon, nil
+	}, nil
+}
+
+// Stores the probability that the secret cannot be recovered from the people
+// who answer, with its interval
+func writeImpossibleProbability(csvDir string,
+	spec *configuration.ExperimentSpec, tc RunDataTypeSpec, l int,
+	results map[int]int, interval probability.Interval) error {
+	runs := 0
+	for _, cases := range results {
+		runs += cases
+	}
+	failures := results[tc.a+1]
+	lower, upper := interval(failures, runs)
+	fmt.Println("Recovery impossible in", failures, "of", runs, "runs")
+	data := [][]interface{}{
+		{"Runs", "Failures", "Probability of failure", "Lower", "Upper"},
+		{runs, failures, float64(failures) / float64(runs), lower, upper},
+	}
+	csvFileName := probabilityFileName(csvDir+"result-impossible-", spec, tc,
+		l)
+	err, _ := files.CreateFile(csvFileName)
+	if err != nil {
+		return err
+	}
+	return files.WriteToCSVFile(csvFileName, data)
+}
+
+// Same names as the other probability evaluations with the parameters of
+// the scheme
+func probabilityFileName(prefix string, spec *configuration.ExperimentSpec,
+	tc RunDataTypeSpec, l int) string {
+	csvFileName := prefix + strconv.Itoa(l) + "-" +
+		strconv.Itoa(tc.percentageLeavesLayerThreshold) + "-" +
+		strconv.Itoa(tc.n) + "-" + strconv.Itoa(tc.a) + "-" +
+		strconv.Itoa(tc.noOfSubsecrets) + "-" +
+		strconv.Itoa(tc.absoluteThreshold) + "-"
+	switch spec.Scheme {
+	case configuration.SchemeThresholded:
+		csvFileName += strconv.Itoa(tc.percentageSubsecretsThreshold) + "-"
+	case configuration.SchemeHinted:
+		csvFileName += strconv.Itoa(tc.noOfHints) + "-"
+	}
+	// The shares per person only change the number of subsecrets, which
+	// may stay the same for different values
+	if spec.Sweeps(configuration.ParamSharesPerPerson) {
+		csvFileName += strconv.Itoa(tc.sharesPerPerson) + "-"
+	}
+	if spec.Metric == configuration.MetricRecoveryTime {
+		csvFileName += strconv.Itoa(tc.contactWidth) + "-"
+	}
+	csvFileName += ".csv"
+	return csvFileName
+}
+
+// Default number of simulations of the cross check
+const crossCheckRuns = 10000
+
+// The exact CDF is only computed for the additive and the thresholded
+// schemes, and the simulations are run as well if the spec asks for a
+// cross check
+func runExactProbabilityExperiment(cfg *configuration.SimulationConfig,
+	csvDir string, spec *configuration.ExperimentSpec,
+	testCases []RunDataTypeSpec) error {
+	rng := randomness.NewSeededRand(cfg.Seed)
+	l := 2
+	// Every run of the cross check has its own packets, so that the runs are
+	// independent
+	runs := spec.Iterations
+	if runs == 0 {
+		runs = crossCheckRuns
+	}
+	for _, tc := range testCases {
+		fmt.Println(spec.Name, tc)
+		var cdf *probability.ExactCDF
+		var resultsAnon map[int]int
+		var err error
+		switch spec.Scheme {
+		case configuration.SchemeAdditive:
+			cdf, err = probability.GetAdditiveExactCDF(l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets)
+			if err == nil && spec.CrossCheck {
+				_, resultsAnon, err = probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
+					runs, 1, l, tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+					tc.absoluteThreshold, tc.noOfSubsecrets, rng)
+			}
+		case configuration.SchemeThresholded:
+			cdf, err = probability.GetThresholdedExactCDF(l,
+				tc.percentageLeavesLayerThreshold,
+				tc.percentageSubsecretsThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets)
+			if err == nil && spec.CrossCheck {
+				_, resultsAnon, err = probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
+					runs, 1, l, tc.percentageLeavesLayerThreshold,
+					tc.percentageSubsecretsThreshold, tc.n, tc.a,
+					tc.absoluteThreshold, tc.noOfSubsecrets, rng)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", tc, err)
+		}
+		if spec.CrossCheck {
+			// z = 3.29 for 99.9%
+			for _, d := range cdf.CrossCheck(resultsAnon, 3.29) {
+				fmt.Println("Deviation", tc, d)
+			}
+		}
+		data := FormExactDataForCSV(cdf, resultsAnon)
+		csvFileName := probabilityFileName(csvDir+"result-exact-probability-",
+			spec, tc, l)
+		err, _ = files.CreateFile(csvFileName)
+		if err != nil {
+			return err
+		}
+		err = files.WriteToCSVFile(csvFileName, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The time is in the unit of the mean of the latency
+func runRecoveryTimeExperiment(cfg *configuration.SimulationConfig,
+	csvDir string, spec *configuration.ExperimentSpec,
+	testCases []RunDataTypeSpec) error {
+	rng := randomness.NewSeededRand(cfg.Seed)
+	simulationsDist := cfg.DefaultSimulationDistributionNums
+	simulationsRun := cfg.DefaultSimulationRunNums
+	latency, err := probability.NewLatency(spec.Latency.Distribution,
+		spec.Latency.Mean, spec.Latency.Spread)
+	if err != nil {
+		return err
+	}
+	interval, err := probability.NewInterval(cfg.ConfidenceInterval,
+		cfg.ConfidenceLevel)
+	if err != nil {
+		return err
+	}
+	l := 2
+	for _, tc := range testCases {
+		fmt.Println(spec.Name, tc)
+		var times []float64
+		switch spec.Scheme {
+		case configuration.SchemeAdditive:
+			times, err = probability.GetAdditiveTimedRecoveryParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.contactWidth,
+				latency, rng)
+		case configuration.SchemeThresholded:
+			times, err = probability.GetThresholdedTimedRecoveryParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold,
+				tc.percentageSubsecretsThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.contactWidth,
+				latency, rng)
+		case configuration.SchemeHinted:
+			times, err = probability.GetHintedTTimedRecoveryParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.noOfHints,
+				tc.contactWidth, latency, rng)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", tc, err)
+		}
+		if len(times) != 0 {
+			fmt.Println("Median time", times[len(times)/2])
+		}
+		data := FormRecoveryTimeDataForCSV(times, simulationsDist*simulationsRun,
+			interval)
+		csvFileName := probabilityFileName(csvDir+"result-time-", spec, tc, l)
+		err, _ = files.CreateFile(csvFileName)
+		if err != nil {
+			return err
+		}
+		err = files.WriteToCSVFile(csvFileName, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// All the test cases go to results.csv, so that the probability can be
+// plotted against the number of insiders
+func runCoalitionExperiment(cfg *configuration.SimulationConfig,
+	csvDir string, spec *configuration.ExperimentSpec,
+	testCases []RunDataTypeSpec) error {
+	rng := randomness.NewSeededRand(cfg.Seed)
+	simulationsDist := cfg.DefaultSimulationDistributionNums
+	simulationsRun := cfg.DefaultSimulationRunNums
+	interval, err := probability.NewInterval(cfg.ConfidenceInterval,
+		cfg.ConfidenceLevel)
+	if err != nil {
+		return err
+	}
+	adversary := spec.Adversary
+	// By default, the coalition puts first the people who look like trustees
+	// and follows the hints
+	strategy, err := experimentStrategy(spec,
+		probability.HintFollowingStrategy{Base: probability.NoisyPriorStrategy{
+			DeltaTr:    adversary.DeltaTrustees,
+			DeltaNonTr: adversary.DeltaNonTrustees,
+		}})
+	if err != nil {
+		return err
+	}
+	csvFileName := csvDir + "results.csv"
+	err, _ = files.CreateFile(csvFileName)
+	if err != nil {
+		return err
+	}
+	err = files.WriteToCSVFile(csvFileName,
+		[][]interface{}{experimentTopData(spec)})
+	if err != nil {
+		return err
+	}
+	l := 2
+	for _, tc := range testCases {
+		fmt.Println(spec.Name, tc)
+		var results map[int]int
+		var failures int
+		switch spec.Scheme {
+		case configuration.SchemeAdditive:
+			results, failures, err = probability.GetAdditiveCoalitionCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.insiders,
+				strategy, adversary.Obtain, adversary.Whistleblow, rng)
+		case configuration.SchemeThresholded:
+			results, failures, err = probability.GetThresholdedCoalitionCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold,
+				tc.percentageSubsecretsThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.insiders,
+				strategy, adversary.Obtain, adversary.Whistleblow, rng)
+		case configuration.SchemeHinted:
+			results, failures, err = probability.GetHintedTCoalitionCDFParallelized(
+				simulationsDist, simulationsRun, l,
+				tc.percentageLeavesLayerThreshold, tc.n, tc.a,
+				tc.absoluteThreshold, tc.noOfSubsecrets, tc.noOfHints,
+				tc.insiders, strategy, adversary.Obtain,
+				adversary.Whistleblow, rng)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", tc, err)
+		}
+		recovered := 0
+		for _, cases := range results {
+			recovered += cases
+		}
+		runs := recovered + failures
+		lower, upper := interval(recovered, runs)
+		fmt.Println("Recovered before reported in", recovered, "of", runs,
+			"runs")
+		row := experimentRow(spec, tc, cfg.NoOfWorkers, tc.insiders, runs, recovered,
+			float64(recovered)/float64(runs), lower, upper)
+		// Write after every test case so that long sweeps keep their results
+		err = files.WriteToCSVFile(csvFileName, [][]interface{}{row})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
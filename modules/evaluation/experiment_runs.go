@@ -0,0 +1,422 @@
+package evaluation
+
+import (
+	"context"
+	"crypto/cipher"
+	"key_recovery/modules/configuration"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/monitor"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/secret"
+	secretbe "key_recovery/modules/secret_binary_extension"
+	"key_recovery/modules/shamir"
+	randm "math/rand"
+	"time"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// Measures either the time in nanoseconds or the CPU time in milliseconds
+// as in the other evaluations
+type experimentClock struct {
+	cpu       bool
+	monitor   *monitor.Monitor
+	startTime time.Time
+}
+
+func (c *experimentClock) reset() {
+	if c.cpu {
+		c.monitor.Reset()
+	} else {
+		c.startTime = time.Now()
+	}
+}
+
+func (c *experimentClock) record() interface{} {
+	if c.cpu {
+		return c.monitor.Record()
+	}
+	return int(time.Since(c.startTime).Nanoseconds())
+}
+
+// State shared by the runs of an experiment
+type experimentEnv struct {
+	f              shamir.Field
+	g              *edwards25519.SuiteEd25519
+	randSeedShares cipher.Stream
+	clock          *experimentClock
+	// Routines of the GF(2^16) recovery, runtime.NumCPU() if 0
+	noOfWorkers int
+	// Generator of the access orders
+	rng *randm.Rand
+}
+
+func newExperimentEnv(cpu bool, noOfWorkers int,
+	rng *randm.Rand) *experimentEnv {
+	env := &experimentEnv{clock: &experimentClock{cpu: cpu},
+		noOfWorkers: noOfWorkers, rng: rng}
+	if cpu {
+		env.clock.monitor = monitor.NewMonitor()
+	}
+	env.f.InitializeTables()
+	env.g = edwards25519.NewBlakeSHA256Ed25519()
+	env.randSeedShares = randomness.Stream()
+	return env
+}
+
+// Secret shared by one run along with the anonymity set
+// Only the fields of the scheme and the backend of the run are set
+type sharedSecret struct {
+	scheme     string
+	backend    string
+	verifiable bool
+
+	// GF(2^16)
+	secretKey          []uint16
+	secretKeyAES       [][]uint16
+	secretKeyBytes     []byte
+	secretKeyHash      [32]byte
+	baselineShares     []shamir.PriShare
+	additivePackets    []secretbe.AdditivePacket
+	thresholdedPackets []secretbe.ThresholdedPacket
+	hintedPackets      []secretbe.HintedTPacket
+
+	// kyber
+	kyberSecretKey      kyber.Scalar
+	kyberBaselineShares []*share.PriShare
+	kyberAdditive       []secret.AdditivePacket
+	kyberThresholded    []secret.ThresholdedPacket
+	kyberHinted         []secret.HintedTPacket
+	// Verification keys of the kyber packets in the verifiable mode, which
+	// the holders hand over with their packets
+	kyberKeys [][]byte
+}
+
+// Maximum size of the anonymity set of the baseline as in the
+// other evaluations
+const baselineMaxSize = 200
+
+// Generates a secret and the anonymity set for the test case
+func (env *experimentEnv) share(spec *configuration.ExperimentSpec,
+	tc RunDataTypeSpec) (*sharedSecret, error) {
+	shared := &sharedSecret{scheme: spec.Scheme, backend: spec.Backend,
+		verifiable: spec.Verifiable}
+	if spec.Backend == configuration.BackendKyber {
+		return shared, env.shareKyber(shared, tc)
+	}
+	f := env.f
+	var err error
+	switch spec.Scheme {
+	case configuration.SchemeThresholded, configuration.SchemeHinted:
+		shared.secretKeyBytes, err = crypto_protocols.GenerateRandomBytes(28)
+		if err != nil {
+			return nil, err
+		}
+		shared.secretKeyAES = shamir.KeyBytesToAESKeyUint16s(shared.secretKeyBytes)
+	default:
+		shared.secretKeyBytes, err = crypto_protocols.GenerateRandomBytes(31)
+		if err != nil {
+			return nil, err
+		}
+		shared.secretKey = shamir.KeyBytesToKeyUint16s(shared.secretKeyBytes)
+	}
+
+	switch spec.Scheme {
+	case configuration.SchemeBaseline:
+		shared.secretKeyHash = crypto_protocols.GetSHA256(
+			shamir.Uint16sToBytes(shared.secretKey))
+		var xUsedCoords []uint16
+		shareVals, err := secretbe.GenerateSharesPercentage(f,
+			tc.percentageLeavesLayerThreshold, tc.n, shared.secretKey, &xUsedCoords)
+		if err != nil {
+			return nil, err
+		}
+		shared.baselineShares, _ = secretbe.GetDisAnonymitySet(f, tc.n, tc.a,
+			baselineMaxSize, shareVals, &xUsedCoords, len(shared.secretKey))
+	case configuration.SchemeAdditive:
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			secretbe.GenerateAdditiveTwoLayeredOptIndisShares(f, tc.n,
+				shared.secretKey, tc.absoluteThreshold,
+				tc.noOfSubsecrets, tc.percentageLeavesLayerThreshold)
+		if err != nil {
+			return nil, err
+		}
+		sharePackets, maxSharesPerPerson, err := secretbe.GetAdditiveSharePackets(f,
+			shared.secretKey, tc.n, tc.absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+		if err != nil {
+			return nil, err
+		}
+		shared.additivePackets, err = secretbe.GetAdditiveAnonymityPackets(
+			sharePackets, tc.a, maxSharesPerPerson, len(shared.secretKey),
+			&xUsedCoords)
+		if err != nil {
+			return nil, err
+		}
+	case configuration.SchemeThresholded:
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			secretbe.GenerateThresholdedTwoLayeredOptIndisShares(f, tc.n,
+				shared.secretKeyAES, tc.absoluteThreshold, tc.noOfSubsecrets,
+				tc.percentageLeavesLayerThreshold, tc.percentageSubsecretsThreshold)
+		if err != nil {
+			return nil, err
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, err := secretbe.GetThresholdedSharePackets(f,
+			shared.secretKeyAES, tc.n, tc.absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+		if err != nil {
+			return nil, err
+		}
+		shared.thresholdedPackets, err = secretbe.GetThresholdedAnonymityPackets(
+			sharePackets, tc.a, maxSharesPerPerson,
+			len(shared.secretKeyAES[0]), len(shared.secretKeyAES),
+			&xUsedCoords, encryptionLength)
+		if err != nil {
+			return nil, err
+		}
+	case configuration.SchemeHinted:
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			secretbe.GenerateHintedTTwoLayeredOptIndisShares(f, tc.n,
+				shared.secretKeyAES, tc.absoluteThreshold,
+				tc.noOfSubsecrets, tc.percentageLeavesLayerThreshold)
+		if err != nil {
+			return nil, err
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, err := secretbe.GetHintedTSharePackets(f,
+			shared.secretKeyAES, tc.n, tc.absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords,
+			tc.noOfHints)
+		if err != nil {
+			return nil, err
+		}
+		shared.hintedPackets, err = secretbe.GetHintedTAnonymityPackets(
+			sharePackets, tc.a, maxSharesPerPerson,
+			len(shared.secretKeyAES[0]), len(shared.secretKeyAES),
+			&xUsedCoords, encryptionLength)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shared, nil
+}
+
+func (env *experimentEnv) shareKyber(shared *sharedSecret,
+	tc RunDataTypeSpec) error {
+	g := env.g
+	randSeedShares := env.randSeedShares
+	shared.kyberSecretKey = g.Scalar().Pick(randSeedShares)
+	secretKey := shared.kyberSecretKey
+	switch shared.scheme {
+	case configuration.SchemeBaseline:
+		shared.secretKeyHash = crypto_protocols.GetSHA256(
+			crypto_protocols.ConvertKeyToBytes(secretKey))
+		shareVals := secret.GenerateSharesPercentage(g,
+			tc.percentageLeavesLayerThreshold, tc.n, secretKey, randSeedShares)
+		shared.kyberBaselineShares, _ = secret.GetDisAnonymitySet(g,
+			tc.n, tc.a, baselineMaxSize, randSeedShares, shareVals)
+	case configuration.SchemeAdditive, configuration.SchemeHinted:
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			secret.GenerateAdditiveTwoLayeredOptIndisShares(g, tc.n,
+				secretKey, randSeedShares, tc.absoluteThreshold,
+				tc.noOfSubsecrets, tc.percentageLeavesLayerThreshold)
+		if err != nil {
+			return err
+		}
+		if shared.scheme == configuration.SchemeAdditive {
+			sharePackets, maxSharesPerPerson, err := secret.GetAdditiveSharePackets(g,
+				randSeedShares, secretKey, tc.n, tc.absoluteThreshold,
+				leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+			if err != nil {
+				return err
+			}
+			shared.kyberAdditive, err = secret.GetAdditiveAnonymityPackets(g,
+				randSeedShares, sharePackets, tc.a, maxSharesPerPerson,
+				&xUsedCoords)
+			if err != nil || !shared.verifiable {
+				return err
+			}
+			polynomials, err := secret.LeafPolynomials(g, tc.absoluteThreshold,
+				secret.AdditiveLeafGroups(leavesData, parentSubsecrets))
+			if err != nil {
+				return err
+			}
+			shared.kyberKeys, err = secret.CommitAdditivePackets(g,
+				randSeedShares, tc.absoluteThreshold, polynomials,
+				shared.kyberAdditive)
+			return err
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, err := secret.GetHintedTSharePackets(g,
+			randSeedShares, secretKey, tc.n, tc.absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords,
+			tc.noOfHints)
+		if err != nil {
+			return err
+		}
+		shared.kyberHinted, err = secret.GetHintedTAnonymityPackets(g,
+			randSeedShares, sharePackets, tc.a, maxSharesPerPerson,
+			&xUsedCoords, encryptionLength)
+		if err != nil || !shared.verifiable {
+			return err
+		}
+		polynomials, err := secret.LeafPolynomials(g, tc.absoluteThreshold,
+			secret.AdditiveLeafGroups(leavesData, parentSubsecrets))
+		if err != nil {
+			return err
+		}
+		shared.kyberKeys, err = secret.CommitHintedTPackets(g, randSeedShares,
+			tc.absoluteThreshold, polynomials, shared.kyberHinted)
+		return err
+	case configuration.SchemeThresholded:
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			secret.GenerateTwoLayeredOptIndisShares(g, tc.n, secretKey,
+				randSeedShares, tc.absoluteThreshold, tc.noOfSubsecrets,
+				tc.percentageLeavesLayerThreshold, tc.percentageSubsecretsThreshold)
+		if err != nil {
+			return err
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, err := secret.GetThresholdedSharePackets(g,
+			randSeedShares, secretKey, tc.n, tc.absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+		if err != nil {
+			return err
+		}
+		shared.kyberThresholded, err = secret.GetThresholdedAnonymityPackets(g,
+			randSeedShares, sharePackets, tc.a, maxSharesPerPerson,
+			&xUsedCoords, encryptionLength)
+		if err != nil || !shared.verifiable {
+			return err
+		}
+		polynomials, err := secret.LeafPolynomials(g, tc.absoluteThreshold,
+			secret.ThresholdedLeafGroups(leavesData, parentSubsecrets))
+		if err != nil {
+			return err
+		}
+		shared.kyberKeys, err = secret.CommitThresholdedPackets(g,
+			randSeedShares, tc.absoluteThreshold, polynomials,
+			shared.kyberThresholded)
+		return err
+	}
+	return nil
+}
+
+// Recovers the secret by contacting the anonymity set in the access order
+// and checks that it is the shared one
+func (env *experimentEnv) recover(shared *sharedSecret, accessOrder []int,
+	absoluteThreshold int) error {
+	if shared.backend == configuration.BackendKyber {
+		return env.recoverKyber(shared, accessOrder, absoluteThreshold)
+	}
+	f := env.f
+	ctx := context.Background()
+	matched := false
+	switch shared.scheme {
+	case configuration.SchemeBaseline:
+		recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(ctx, f,
+			shared.baselineShares, accessOrder, shared.secretKeyHash, env.noOfWorkers)
+		if err != nil {
+			return err
+		}
+		matched = crypto_protocols.CheckRecSecretKeyBinExt(shared.secretKeyHash,
+			recovered)
+	case configuration.SchemeAdditive:
+		recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(ctx,
+			f, shared.additivePackets, accessOrder, absoluteThreshold, env.noOfWorkers)
+		if err != nil {
+			return err
+		}
+		matched = crypto_protocols.CompareUint16s(shared.secretKey, recoveredKey)
+	case configuration.SchemeThresholded:
+		recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(ctx,
+			f, shared.thresholdedPackets, accessOrder, absoluteThreshold, env.noOfWorkers)
+		if err != nil {
+			return err
+		}
+		matched = crypto_protocols.CheckByteArrayEqual(shared.secretKeyBytes,
+			shamir.AESKeyUint16sToKeyBytes(recoveredKey))
+	case configuration.SchemeHinted:
+		recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(ctx,
+			f, shared.hintedPackets, accessOrder, absoluteThreshold, env.noOfWorkers)
+		if err != nil {
+			return err
+		}
+		matched = crypto_protocols.CheckByteArrayEqual(shared.secretKeyBytes,
+			shamir.AESKeyUint16sToKeyBytes(recoveredKey))
+	}
+	if !matched {
+		return errors.ErrSecretNotFound
+	}
+	return nil
+}
+
+func (env *experimentEnv) recoverKyber(shared *sharedSecret, accessOrder []int,
+	absoluteThreshold int) error {
+	g := env.g
+	randSeedShares := env.randSeedShares
+	var recoveredKey kyber.Scalar
+	// In the verifiable mode the user drops the unverified shares with the
+	// keys handed over along with the packets (kyberKeys is nil otherwise)
+	switch shared.scheme {
+	case configuration.SchemeBaseline:
+		var err error
+		recoveredKey, err = secret.BasicHashedSecretRecoveryParallelizedUint16(g,
+			shared.kyberBaselineShares, accessOrder, shared.secretKeyHash)
+		if err != nil {
+			return err
+		}
+	case configuration.SchemeAdditive:
+		packets := secret.FilterVerifiedAdditivePackets(g,
+			shared.kyberAdditive, shared.kyberKeys)
+		recoveredKey = secret.AdditiveOptUsedIndisSecretRecoveryParallelized(g,
+			randSeedShares, packets, accessOrder, absoluteThreshold)
+	case configuration.SchemeThresholded:
+		packets := secret.FilterVerifiedThresholdedPackets(g,
+			shared.kyberThresholded, shared.kyberKeys)
+		recoveredKey = secret.ThOptUsedIndisSecretRecoveryParallelized(g,
+			randSeedShares, packets, accessOrder, absoluteThreshold)
+	case configuration.SchemeHinted:
+		packets := secret.FilterVerifiedHintedTPackets(g,
+			shared.kyberHinted, shared.kyberKeys)
+		recoveredKey = secret.HintedTOptUsedIndisSecretRecoveryParallelized(g,
+			randSeedShares, packets, accessOrder, absoluteThreshold)
+	}
+	if recoveredKey == nil ||
+		!crypto_protocols.CheckValuesEqual(shared.kyberSecretKey, recoveredKey) {
+		return errors.ErrSecretNotFound
+	}
+	return nil
+}
+
+// Gives the size of every packet of the anonymity set
+// The packets over GF(2^16) are measured in their binary encoding
+// and a share of the baseline takes its x and y coordinates
+func (shared *sharedSecret) packetSizes() []int {
+	var sizes []int
+	if shared.backend == configuration.BackendKyber {
+		for _, packet := range shared.kyberAdditive {
+			sizes = append(sizes, EvaluateOnePacketSize(packet))
+		}
+		return sizes
+	}
+	switch shared.scheme {
+	case configuration.SchemeBaseline:
+		for _, shareVal := range shared.baselineShares {
+			sizes = append(sizes, 2+len(shamir.Uint16sToBytes(shareVal.Y)))
+		}
+	case configuration.SchemeAdditive:
+		for _, packet := range shared.additivePackets {
+			sizes = append(sizes, len(secretbe.MarshalAdditivePacket(packet)))
+		}
+	case configuration.SchemeThresholded:
+		for _, packet := range shared.thresholdedPackets {
+			sizes = append(sizes, len(secretbe.MarshalThresholdedPacket(packet)))
+		}
+	case configuration.SchemeHinted:
+		for _, packet := range shared.hintedPackets {
+			sizes = append(sizes, len(secretbe.MarshalHintedTPacket(packet)))
+		}
+	}
+	return sizes
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -33,7 +34,7 @@ func EvaluateOverallPacketSize(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -595,6 +596,11 @@ func EvaluateOnePacketSize(anonymityPacket secret.AdditivePacket) int {
 
 	size += len(anonymityPacket.Salt)
 
+	// Commitments are only there in the verifiable mode
+	for _, commitment := range anonymityPacket.Commitments {
+		size += len(commitment.Sealed)
+	}
+
 	return size
 }
 
@@ -626,7 +632,7 @@ func EvaluateOverallPacketSize2(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -0,0 +1,174 @@
+package evaluation
+
+import (
+	"fmt"
+	"key_recovery/modules/configuration"
+)
+
+// **************************************************************************
+// Presets for the numeric codes of -t and -p
+// The codes listed here are run by RunExperiment and the others still call
+// the evaluation functions in evaluate.go
+// **************************************************************************
+
+type sweepRanges = []configuration.SweepRange
+
+func sweepValues(values ...int) sweepRanges {
+	var ranges sweepRanges
+	for _, v := range values {
+		ranges = append(ranges, configuration.SweepRange{From: v, To: v})
+	}
+	return ranges
+}
+
+func sweepRange(from, to, step int) configuration.SweepRange {
+	return configuration.SweepRange{From: from, To: to, Step: step}
+}
+
+// Sizes of the anonymity set of the CPU time evaluations, which are split
+// into five runs by the range indicator
+func cpuAnonymityRanges(scheme string, rangeIndicator int) sweepRanges {
+	switch rangeIndicator {
+	case 1:
+		switch scheme {
+		case configuration.SchemeAdditive:
+			return sweepRanges{sweepRange(20, 50, 1), sweepRange(50, 150, 5),
+				sweepRange(310, 330, 10)}
+		case configuration.SchemeHinted:
+			return sweepRanges{sweepRange(20, 50, 1), sweepRange(55, 100, 5),
+				sweepRange(310, 330, 10)}
+		default:
+			return sweepRanges{sweepRange(20, 50, 1), sweepRange(55, 150, 5),
+				sweepRange(310, 330, 10)}
+		}
+	case 2:
+		return sweepRanges{sweepRange(155, 200, 5), sweepRange(340, 360, 10)}
+	case 3:
+		return sweepRanges{sweepRange(205, 250, 5), sweepRange(370, 390, 10)}
+	case 4:
+		return sweepRanges{sweepRange(255, 300, 5), sweepRange(400, 420, 10)}
+	default:
+		return sweepRanges{sweepRange(430, 500, 10)}
+	}
+}
+
+// PresetSpec gives the spec that the code of -t and -p stands for
+// ok is false for the codes which have no spec
+func PresetSpec(cfg *configuration.SimulationConfig, evalType int,
+	varyingParameter int) (spec *configuration.ExperimentSpec, ok bool) {
+	switch evalType % 3 {
+	case 1:
+		return probabilityPresetSpec(cfg, varyingParameter)
+	case 2:
+		return cpuPresetSpec(varyingParameter)
+	}
+	return nil, false
+}
+
+func probabilityPresetSpec(cfg *configuration.SimulationConfig,
+	varyingParameter int) (*configuration.ExperimentSpec, bool) {
+	anonymity := configuration.SweepParameter{
+		Name:   configuration.ParamAnonymitySetSize,
+		Ranges: sweepRanges{sweepRange(30, 150, 10)}}
+	threshold := configuration.SweepParameter{
+		Name:   configuration.ParamLeavesThreshold,
+		Ranges: sweepRanges{sweepRange(30, 90, 10)}}
+	trustees := configuration.SweepParameter{
+		Name:   configuration.ParamTrustees,
+		Ranges: sweepRanges{sweepRange(10, cfg.DefaultAnonymitySetSize, 5)}}
+	absoluteThreshold := configuration.SweepParameter{
+		Name:   configuration.ParamAbsoluteThreshold,
+		Ranges: sweepRanges{sweepRange(3, 8, 1)}}
+	subsecretsThreshold := configuration.SweepParameter{
+		Name:   configuration.ParamSubsecretsThreshold,
+		Ranges: sweepRanges{sweepRange(40, 80, 10)}}
+	hints := configuration.SweepParameter{
+		Name:   configuration.ParamHints,
+		Ranges: sweepValues(5, 10)}
+	sharesPerPerson := configuration.SweepParameter{
+		Name: configuration.ParamSharesPerPerson,
+		Ranges: append(sweepValues(2, 3, 4, 5),
+			sweepRange(10, 50, 5))}
+
+	var scheme, name string
+	var sweep configuration.Sweep
+	switch varyingParameter {
+	case 1, 2, 3, 4, 19:
+		scheme = configuration.SchemeAdditive
+		name = "p-add-"
+	case 6, 7, 8:
+		scheme = configuration.SchemeBaseline
+		name = "p-baseline-"
+	case 9, 10, 11, 12:
+		scheme = configuration.SchemeThresholded
+		name = "p-thr-"
+		sweep = configuration.Sweep{subsecretsThreshold}
+	case 14, 15, 16, 17:
+		scheme = configuration.SchemeHinted
+		name = "p-hintedT-"
+		sweep = configuration.Sweep{hints}
+	default:
+		return nil, false
+	}
+	switch varyingParameter {
+	case 1, 8, 9, 14:
+		sweep = append(sweep, anonymity)
+		name += "an"
+	case 2, 6, 10, 15:
+		sweep = append(sweep, threshold)
+		name += "th"
+	case 3, 7, 11, 16:
+		sweep = append(sweep, trustees)
+		name += "tr"
+	case 4, 12, 17:
+		sweep = append(sweep, absoluteThreshold)
+		name += "at"
+	case 19:
+		sweep = append(sweep, sharesPerPerson)
+		name += "spp"
+	}
+	return &configuration.ExperimentSpec{
+		Name:    name,
+		Scheme:  scheme,
+		Backend: configuration.BackendBinExt,
+		Metric:  configuration.MetricProbability,
+		Sweep:   sweep,
+	}, true
+}
+
+func cpuPresetSpec(varyingParameter int) (*configuration.ExperimentSpec, bool) {
+	var scheme string
+	var rangeIndicator int
+	var sweep configuration.Sweep
+	switch {
+	case varyingParameter >= 1 && varyingParameter <= 5:
+		scheme = configuration.SchemeAdditive
+		rangeIndicator = varyingParameter
+	case varyingParameter >= 14 && varyingParameter <= 18:
+		scheme = configuration.SchemeHinted
+		rangeIndicator = varyingParameter - 13
+	case varyingParameter >= 35 && varyingParameter <= 39:
+		scheme = configuration.SchemeThresholded
+		rangeIndicator = varyingParameter - 34
+	default:
+		return nil, false
+	}
+	sweep = configuration.Sweep{{
+		Name:   configuration.ParamAnonymitySetSize,
+		Ranges: cpuAnonymityRanges(scheme, rangeIndicator)}}
+	switch scheme {
+	case configuration.SchemeHinted:
+		sweep = append(sweep, configuration.SweepParameter{
+			Name: configuration.ParamHints, Ranges: sweepValues(5, 10)})
+	case configuration.SchemeThresholded:
+		sweep = append(sweep, configuration.SweepParameter{
+			Name: configuration.ParamSubsecretsThreshold, Ranges: sweepValues(60, 80)})
+	}
+	return &configuration.ExperimentSpec{
+		Name:    fmt.Sprintf("%s-cpu-a-%d", scheme, rangeIndicator),
+		Scheme:  scheme,
+		Backend: configuration.BackendBinExt,
+		Metric:  configuration.MetricCPUTime,
+		Sweep:   sweep,
+	}, true
+}
@@ -5,9 +5,8 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
 	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 // ************************************************************************
@@ -26,8 +25,7 @@ func EvaluateGetBaselineProbabilityCDFVAnon(cfg *configuration.SimulationConfig,
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 	simulations := simulationsDist * simulationsRun
@@ -35,7 +33,8 @@ func EvaluateGetBaselineProbabilityCDFVAnon(cfg *configuration.SimulationConfig,
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err :=
-			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a)
+			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a,
+				rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -70,8 +69,7 @@ func EvaluateGetBaselineProbabilityCDFVTh(cfg *configuration.SimulationConfig,
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 	simulations := simulationsDist * simulationsRun
@@ -79,7 +77,8 @@ func EvaluateGetBaselineProbabilityCDFVTh(cfg *configuration.SimulationConfig,
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err :=
-			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a)
+			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a,
+				rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -114,8 +113,7 @@ func EvaluateGetBaselineProbabilityCDFVTr(cfg *configuration.SimulationConfig,
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 	simulations := simulationsDist * simulationsRun
@@ -123,7 +121,8 @@ func EvaluateGetBaselineProbabilityCDFVTr(cfg *configuration.SimulationConfig,
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err :=
-			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a)
+			probability.GetBaselineProbabilityCDF(simulations, tc.th, tc.tr, tc.a,
+				rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
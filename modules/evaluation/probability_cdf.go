@@ -5,10 +5,9 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
 	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 // ************************************************************************
@@ -26,15 +25,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVAnon(cfg *configuration.Simul
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -68,15 +67,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVAnonExponential(cfg *configur
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -110,15 +109,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVTh(cfg *configuration.Simulat
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -152,15 +151,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVTr(cfg *configuration.Simulat
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -194,15 +193,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVAT(cfg *configuration.Simulat
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -236,15 +235,15 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVSS(cfg *configuration.Simulat
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -278,8 +277,7 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVSPP(cfg *configuration.Simula
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -289,7 +287,8 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVSPP(cfg *configuration.Simula
 		sharesPerPerson := totalShares / tc.tr
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -327,8 +326,7 @@ func EvaluateGetAdditiveExpectedProbabilityFixedThTotalCDFVAnon(cfg *configurati
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -336,7 +334,8 @@ func EvaluateGetAdditiveExpectedProbabilityFixedThTotalCDFVAnon(cfg *configurati
 		fmt.Println(tc)
 		for extra := 0; extra < 11; extra++ {
 			results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+				rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -371,8 +370,7 @@ func EvaluateGetAdditiveExpectedProbabilityFixedThTotalCDFVAnonExponential(cfg *
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -380,7 +378,8 @@ func EvaluateGetAdditiveExpectedProbabilityFixedThTotalCDFVAnonExponential(cfg *
 		fmt.Println(tc)
 		for extra := 0; extra < 11; extra++ {
 			results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDFParallelized(
-				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+				rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -426,8 +425,7 @@ func EvaluateGetUserCompProbabilityCDFParallelized(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -441,7 +439,8 @@ func EvaluateGetUserCompProbabilityCDFParallelized(
 				continue
 			}
 			results, results_anon, err := probability.GetCompProbabilityCDFParallelized(
-				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d2)
+				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d2,
+				rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -486,8 +485,7 @@ func EvaluateGetAdvCompProbabilityCDFParallelized(
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -501,7 +499,8 @@ func EvaluateGetAdvCompProbabilityCDFParallelized(
 				continue
 			}
 			results, results_anon, err := probability.GetCompProbabilityCDFParallelized(
-				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d2)
+				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d2,
+				rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -547,8 +546,7 @@ func EvaluateGetCompProbabilityCDFParallelized(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -560,7 +558,8 @@ func EvaluateGetCompProbabilityCDFParallelized(
 	// Evaluation for user
 	for _, d1 := range delta_1 {
 		results, results_anon, err := probability.GetCompProbabilityCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d1)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d1,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -605,8 +604,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelized(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -624,7 +622,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelized(
 				fmt.Println(d1, wb, obt)
 				results, results_anon, err := probability.GetCompWBAdvObtProbabilityCDFParallelized(
 					simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d1,
-					obt, wb)
+					obt, wb, rng)
 				if err != nil {
 					log.Fatal(err)
 				} else {
@@ -671,8 +669,7 @@ func EvaluateGetWBAdvObtProbabilityCDFParallelized(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -685,7 +682,8 @@ func EvaluateGetWBAdvObtProbabilityCDFParallelized(
 		for _, obt := range obts {
 			fmt.Println(wb, obt)
 			results, results_anon, err := probability.GetWBAdvObtProbabilityCDFParallelized(
-				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, obt, wb)
+				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, obt, wb,
+				rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -732,8 +730,7 @@ func EvaluateGetCompWBAdvObtBaselineProbabilityCDF(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -752,7 +749,7 @@ func EvaluateGetCompWBAdvObtBaselineProbabilityCDF(
 				fmt.Println(d1, wb, obt)
 				results, results_anon, err := probability.GetCompWBAdvObtBaselineProbabilityCDF(
 					simulations, tc.th, tc.tr, tc.a, d1, d1,
-					obt, wb)
+					obt, wb, rng)
 				if err != nil {
 					log.Fatal(err)
 				} else {
@@ -799,8 +796,7 @@ func EvaluateGetWBAdvObtBaselineProbabilityCDF(
 		return
 	}
 
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -813,7 +809,7 @@ func EvaluateGetWBAdvObtBaselineProbabilityCDF(
 		for _, obt := range obts {
 			fmt.Println(wb, obt)
 			results, results_anon, err := probability.GetWBAdvObtBaselineProbabilityCDF(
-				simulations, tc.th, tc.tr, tc.a, obt, wb)
+				simulations, tc.th, tc.tr, tc.a, obt, wb, rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -854,8 +850,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelizedAbs(cfg *configuration.Sim
 			return
 		}
 
-		source := randm.NewSource(time.Now().UnixNano())
-		rng := randm.New(source)
+		rng := randomness.NewSeededRand(cfg.Seed)
 		simulationsDist := cfg.DefaultSimulationDistributionNums
 		simulationsRun := cfg.DefaultSimulationRunNums
 		testCasesWBObt := []struct {
@@ -871,7 +866,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelizedAbs(cfg *configuration.Sim
 			fmt.Println(tc.at, tcwo.d1, tcwo.d1, tcwo.wb, tcwo.obt)
 			results, results_anon, err := probability.GetCompWBAdvObtProbabilityCDFParallelized(
 				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, tcwo.d1, tcwo.d1,
-				tcwo.obt, tcwo.wb)
+				tcwo.obt, tcwo.wb, rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -913,8 +908,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelizedSS(cfg *configuration.Simu
 			return
 		}
 
-		source := randm.NewSource(time.Now().UnixNano())
-		rng := randm.New(source)
+		rng := randomness.NewSeededRand(cfg.Seed)
 		simulationsDist := cfg.DefaultSimulationDistributionNums
 		simulationsRun := cfg.DefaultSimulationRunNums
 		testCasesWBObt := []struct {
@@ -930,7 +924,7 @@ func EvaluateGetCompWBAdvObtProbabilityCDFParallelizedSS(cfg *configuration.Simu
 			fmt.Println(tc.hlpn, tcwo.d1, tcwo.d1, tcwo.wb, tcwo.obt)
 			results, results_anon, err := probability.GetCompWBAdvObtProbabilityCDFParallelized(
 				simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, tcwo.d1, tcwo.d1,
-				tcwo.obt, tcwo.wb)
+				tcwo.obt, tcwo.wb, rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
@@ -5,9 +5,8 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
 	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 // ************************************************************************
@@ -25,8 +24,7 @@ func EvaluateGetAdditiveProbabilityFixedThNumwiseCDFVAnon(cfg *configuration.Sim
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	// simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -36,7 +34,7 @@ func EvaluateGetAdditiveProbabilityFixedThNumwiseCDFVAnon(cfg *configuration.Sim
 			continue
 		}
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThNumwiseCDF(
-			simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -70,8 +68,7 @@ func EvaluateGetAdditiveCompWBAdvObtProbabilityFixedThNumwise(cfg *configuration
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	// simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -85,7 +82,7 @@ func EvaluateGetAdditiveCompWBAdvObtProbabilityFixedThNumwise(cfg *configuration
 				fmt.Println(d1, d1, wb, obt)
 				results, results_anon, err := probability.GetAdditiveCompWBAdvObtProbabilityFixedThNumwiseCDF(
 					simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn, d1, d1,
-					obt, wb)
+					obt, wb, rng)
 				if err != nil {
 					log.Fatal(err)
 				} else {
@@ -122,8 +119,7 @@ func EvaluateGetAdditiveWBAdvObtProbabilityFixedThNumwise(cfg *configuration.Sim
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	// simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
@@ -135,7 +131,7 @@ func EvaluateGetAdditiveWBAdvObtProbabilityFixedThNumwise(cfg *configuration.Sim
 			fmt.Println(wb, obt)
 			results, results_anon, err := probability.GetAdditiveWBAdvObtProbabilityFixedThNumwiseCDF(
 				simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
-				obt, wb)
+				obt, wb, rng)
 			if err != nil {
 				log.Fatal(err)
 			} else {
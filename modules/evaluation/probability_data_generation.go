@@ -3,7 +3,9 @@ package evaluation
 import (
 	"fmt"
 	"key_recovery/modules/configuration"
+	"key_recovery/modules/probability"
 	"key_recovery/modules/utils"
+	"log"
 	"strconv"
 )
 
@@ -37,13 +39,39 @@ type ProbEvalHintedT struct {
 }
 
 func FormDataForCSV(input1, input2 map[int]int) ([][]interface{}, int, int) {
+	interval, err := probability.NewInterval(probability.IntervalWilson, 0)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return FormDataForCSVWithIntervals(input1, input2, interval)
+}
+
+// Same as FormDataForCSV with the simulated CDFs and their intervals after
+// the counts
+func FormDataForCSVWithIntervals(input1, input2 map[int]int,
+	interval probability.Interval) ([][]interface{}, int, int) {
 	var output [][]interface{}
 	topData := []interface{}{
 		"Size required to recover",
 		"No. of cases",
 		"No. of cases in anonymity",
+		"CDF",
+		"Lower",
+		"Upper",
+		"CDF in anonymity",
+		"Lower in anonymity",
+		"Upper in anonymity",
 	}
 	output = append(output, topData)
+	maxSize := 0
+	for size := range input1 {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	cdf, lower, upper := probability.CDFIntervals(input1, maxSize, interval)
+	cdfAnon, lowerAnon, upperAnon := probability.CDFIntervals(input2, maxSize,
+		interval)
 	sum1 := 0
 	sum2 := 0
 	for size, cases := range input1 {
@@ -52,6 +80,10 @@ func FormDataForCSV(input1, input2 map[int]int) ([][]interface{}, int, int) {
 			cases,
 			input2[size],
 		}
+		if size > 0 {
+			row = append(row, cdf[size], lower[size], upper[size],
+				cdfAnon[size], lowerAnon[size], upperAnon[size])
+		}
 		sum1 += cases
 		sum2 += input2[size]
 		output = append(output, row)
@@ -120,6 +152,82 @@ func FormExpectedDataForCSV(expectedData,
 	return output
 }
 
+// The exact CDF for every size of the anonymity set, along with the
+// simulated CDF and its 95% Wilson interval if there are simulated results
+func FormExactDataForCSV(cdf *probability.ExactCDF,
+	resultsAnon map[int]int) [][]interface{} {
+	var output [][]interface{}
+	topData := []interface{}{
+		"Size required to recover",
+		"Probability (trustees)",
+		"Probability (anonymity)",
+	}
+	if resultsAnon != nil {
+		topData = append(topData, "Simulated (anonymity)", "Lower", "Upper")
+	}
+	output = append(output, topData)
+	total := 0
+	for _, cases := range resultsAnon {
+		total += cases
+	}
+	cumulative := 0
+	trustees := len(cdf.Trustees) - 1
+	for size := 1; size < len(cdf.Contacts); size++ {
+		// All the trustees give the secret
+		trusteesProb := 1.0
+		if size <= trustees {
+			trusteesProb = cdf.Trustees[size]
+		}
+		row := []interface{}{
+			size,
+			trusteesProb,
+			cdf.Contacts[size],
+		}
+		if resultsAnon != nil {
+			cumulative += resultsAnon[size]
+			lower, upper := probability.WilsonInterval(cumulative, total, 1.96)
+			row = append(row, float64(cumulative)/float64(total), lower, upper)
+		}
+		output = append(output, row)
+	}
+	return output
+}
+
+// The CDF of the sorted times of recovery out of all the runs at a hundred
+// evenly spaced times up to the longest one
+func FormRecoveryTimeDataForCSV(times []float64, runs int,
+	interval probability.Interval) [][]interface{} {
+	var output [][]interface{}
+	topData := []interface{}{
+		"Time",
+		"CDF",
+		"Lower",
+		"Upper",
+	}
+	output = append(output, topData)
+	if len(times) == 0 {
+		return output
+	}
+	steps := 100
+	maxTime := times[len(times)-1]
+	recovered := 0
+	for i := 1; i <= steps; i++ {
+		t := maxTime * float64(i) / float64(steps)
+		for recovered < len(times) && times[recovered] <= t {
+			recovered++
+		}
+		lower, upper := interval(recovered, runs)
+		row := []interface{}{
+			t,
+			float64(recovered) / float64(runs),
+			lower,
+			upper,
+		}
+		output = append(output, row)
+	}
+	return output
+}
+
 // ************************************************************************
 // Baseline
 // ************************************************************************
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 func EvaluateTrusteesExpectedProbability(cfg *configuration.SimulationConfig, mainDir string) {
@@ -20,8 +19,7 @@ func EvaluateTrusteesExpectedProbability(cfg *configuration.SimulationConfig, ma
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 
 	gamma := cfg.DefaultSharesPerPerson
 	alpha := cfg.DefaultAbsoluteThreshold
@@ -106,8 +104,7 @@ func EvaluateExpectedProbability(cfg *configuration.SimulationConfig, mainDir st
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 
 	recoveryProbabilities := make(map[int][]float64)
 
@@ -5,9 +5,8 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
 	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 // CDF, Total, Additive - same trustess and anon. - with varying threshold
@@ -19,8 +18,7 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVThSameAnon(cfg *configuration
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 	// Generate the data over which the simulations will be run
@@ -30,7 +28,8 @@ func EvaluateGetAdditiveProbabilityFixedThTotalCDFVThSameAnon(cfg *configuration
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetAdditiveProbabilityFixedThTotalCDF(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -62,8 +61,7 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVThSameAnon(cfg *configurat
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 	// Generate the data over which the simulations will be run
@@ -73,7 +71,8 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVThSameAnon(cfg *configurat
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDF(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -5,9 +5,8 @@ import (
 	"key_recovery/modules/configuration"
 	"key_recovery/modules/files"
 	"key_recovery/modules/probability"
+	"key_recovery/modules/randomness"
 	"log"
-	randm "math/rand"
-	"time"
 )
 
 // ************************************************************************
@@ -25,15 +24,15 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVAnon(cfg *configuration.Si
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -67,15 +66,15 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVTh(cfg *configuration.Simu
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -109,15 +108,15 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVTr(cfg *configuration.Simu
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -151,15 +150,15 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVAT(cfg *configuration.Simu
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
@@ -193,15 +192,15 @@ func EvaluateGetThresholdedProbabilityFixedThTotalCDFVSS(cfg *configuration.Simu
 		fmt.Println("Error creating directory:", err)
 		return
 	}
-	source := randm.NewSource(time.Now().UnixNano())
-	rng := randm.New(source)
+	rng := randomness.NewSeededRand(cfg.Seed)
 	simulationsDist := cfg.DefaultSimulationDistributionNums
 	simulationsRun := cfg.DefaultSimulationRunNums
 
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		results, results_anon, err := probability.GetThresholdedProbabilityFixedThTotalCDFParallelized(
-			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn)
+			simulationsDist, simulationsRun, tc.l, tc.th, tc.uth, tc.tr, tc.a, tc.at, tc.hlpn,
+			rng)
 		if err != nil {
 			log.Fatal(err)
 		} else {
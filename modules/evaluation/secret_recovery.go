@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -30,7 +31,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -87,7 +89,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
@@ -147,7 +149,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -204,7 +207,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// recoveredKey := secret.AdditiveOptUsedIndisSecretRecovery(g,
@@ -263,7 +266,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -320,7 +324,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// utils.Shuffle(accessOrder)
 			// fmt.Println(accessOrder)
 
@@ -380,7 +384,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -439,7 +444,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// utils.Shuffle(accessOrder)
 			// fmt.Println(accessOrder)
 
@@ -498,7 +503,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -555,7 +561,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// recoveredKey := secret.AdditiveOptUsedIndisSecretRecovery(g,
@@ -613,7 +619,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPerson(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -678,7 +685,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPerson(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// recoveredKey := secret.AdditiveOptUsedIndisSecretRecovery(g,
@@ -737,7 +744,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnon(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -794,7 +802,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnon(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
@@ -853,7 +861,8 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponential(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -910,7 +919,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponential(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -25,7 +26,8 @@ func BenchmarkBasicHashedSecretRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -57,7 +59,7 @@ func BenchmarkBasicHashedSecretRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(g,
@@ -106,7 +108,8 @@ func BenchmarkBasicHashedSecretRecoveryAlternate(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -138,7 +141,7 @@ func BenchmarkBasicHashedSecretRecoveryAlternate(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(g,
@@ -189,7 +192,8 @@ func EvaluateBasicHashedSecretRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -220,7 +224,7 @@ func EvaluateBasicHashedSecretRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(g,
@@ -269,7 +273,8 @@ func EvaluateBasicHashedSecretRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -301,7 +306,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(g,
@@ -350,7 +355,8 @@ func EvaluateBasicHashedSecretRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -383,7 +389,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(g,
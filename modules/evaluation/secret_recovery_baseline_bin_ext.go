@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -16,6 +18,7 @@ import (
 func BenchmarkBasicHashedSecretRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateBenchmarkTestCases(cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -43,6 +46,7 @@ func BenchmarkBasicHashedSecretRecoveryBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 
 	data = append(data, topData)
@@ -63,11 +67,11 @@ func BenchmarkBasicHashedSecretRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
 				tc.n,
@@ -77,6 +81,7 @@ func BenchmarkBasicHashedSecretRecoveryBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -108,6 +113,7 @@ func BenchmarkBasicHashedSecretRecoveryBinExt(
 func BenchmarkBasicHashedSecretRecoveryAlternateBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateBenchmarkTestCases(cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -135,6 +141,7 @@ func BenchmarkBasicHashedSecretRecoveryAlternateBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 
 	data = append(data, topData)
@@ -155,11 +162,11 @@ func BenchmarkBasicHashedSecretRecoveryAlternateBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelizedAlternate(f,
-				anonPackets, accessOrder, secretKeyHash, (tc.percentageLeavesLayerThreshold*tc.n)/100)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelizedAlternate(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, (tc.percentageLeavesLayerThreshold*tc.n)/100, cfg.NoOfWorkers)
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
 				tc.n,
@@ -169,6 +176,7 @@ func BenchmarkBasicHashedSecretRecoveryAlternateBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -201,6 +209,7 @@ func BenchmarkBasicHashedSecretRecoveryAlternateBinExt(
 func EvaluateBasicHashedSecretRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(1, 2, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -227,6 +236,7 @@ func EvaluateBasicHashedSecretRecoveryBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -247,13 +257,13 @@ func EvaluateBasicHashedSecretRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -267,6 +277,7 @@ func EvaluateBasicHashedSecretRecoveryBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -297,6 +308,7 @@ func EvaluateBasicHashedSecretRecoveryBinExt(
 func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 2, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -323,6 +335,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -344,13 +357,13 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -364,6 +377,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -394,6 +408,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExt(
 func EvaluateBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(4, 2, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -420,6 +435,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -440,13 +456,13 @@ func EvaluateBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -460,6 +476,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -31,7 +32,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -88,9 +90,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -152,7 +154,8 @@ func EvaluateBCBasicHashedSecretRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -183,9 +186,9 @@ func EvaluateBCBasicHashedSecretRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -240,7 +243,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -297,9 +301,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -359,7 +363,8 @@ func EvaluateBCBasicHashedSecretRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -391,9 +396,9 @@ func EvaluateBCBasicHashedSecretRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -449,7 +454,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -506,9 +512,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -570,7 +576,8 @@ func EvaluateBCBasicHashedSecretRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -601,9 +608,9 @@ func EvaluateBCBasicHashedSecretRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -658,7 +665,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -716,9 +724,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -781,7 +789,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -838,9 +847,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -902,7 +911,8 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -967,9 +977,9 @@ func EvaluateBCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -16,6 +18,7 @@ import (
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -40,6 +43,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -85,14 +89,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -103,6 +110,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -132,6 +140,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtParam(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 	param int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(param, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -156,6 +165,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtParam(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -201,14 +211,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtParam(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -219,6 +232,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtParam(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -249,6 +263,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtParam(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -273,6 +288,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -318,13 +334,16 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -341,6 +360,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -365,6 +385,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(4, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -389,6 +410,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -434,15 +456,18 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// utils.Shuffle(accessOrder)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -459,6 +484,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -482,6 +508,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(3, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -506,6 +533,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -553,15 +581,18 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// utils.Shuffle(accessOrder)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -579,6 +610,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -600,6 +632,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(6, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -624,6 +657,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -669,13 +703,16 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -692,6 +729,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -714,6 +752,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(5, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -739,6 +778,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Shares Per Trustee",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -791,13 +831,16 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -815,6 +858,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				sharesPerTrustee,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -837,6 +881,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSharesPerPersonBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnonBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(7, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -861,6 +906,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnonBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -906,14 +952,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnonBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -924,6 +973,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryLargeAnonBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -953,6 +1003,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponentialBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 ) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(8, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -977,6 +1028,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponentialBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -1022,14 +1074,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponentialBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -1040,6 +1095,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponentialBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -1068,6 +1124,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryExponentialBinExt(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(69, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -1090,6 +1147,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -1141,14 +1199,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExt(
 				elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 				accessOrder := utils.GenerateIndicesSet(tc.a)
-				utils.Shuffle(accessOrder)
+				utils.Shuffle(accessOrder, rng)
 				// fmt.Println(accessOrder)
 
 				startTime2 := time.Now()
 
-				recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+				recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 					anonymityPackets, accessOrder,
-					tc.absoluteThreshold)
+					tc.absoluteThreshold, cfg.NoOfWorkers)
+				if err != nil {
+					log.Fatalln(err)
+				}
 
 				elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 				row := []interface{}{
@@ -1159,6 +1220,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExt(
 					elapsedTime2,
 					tc.absoluteThreshold,
 					tc.noOfSubsecrets,
+					secretbe.NoOfWorkers(cfg.NoOfWorkers),
 				}
 
 				if !crypto_protocols.CompareUint16s(secretKey,
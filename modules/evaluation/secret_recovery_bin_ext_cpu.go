@@ -1,11 +1,13 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
 	"key_recovery/modules/monitor"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -17,6 +19,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 	rangeIndicator int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesCPU(1, 1, rangeIndicator, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -41,6 +44,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -87,14 +91,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -105,6 +112,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -134,6 +142,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 	rangeIndicator int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesCPU(1, 2, rangeIndicator, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -161,6 +170,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -181,13 +191,13 @@ func EvaluateBasicHashedSecretRecoveryBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -201,6 +211,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -231,6 +242,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -255,6 +267,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -301,13 +314,16 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 
@@ -324,6 +340,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -345,6 +362,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExtCPU(
 func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 2, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -372,6 +390,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -393,13 +412,13 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonPackets, accessOrder, secretKeyHash)
+			recovered, err := secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -413,6 +432,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -443,6 +463,7 @@ func EvaluateBasicHashedSecretRecoveryVaryingThresholdBinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(4, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -467,6 +488,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -513,15 +535,18 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// utils.Shuffle(accessOrder)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -538,6 +563,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -559,6 +585,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -583,6 +610,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	possibleSubsecrets := GetAllPossibleSubsecrets(2, cfg.DefaultPercentageThreshold,
 		cfg.DefaultTrustees, cfg.DefaultAbsoluteThreshold)
@@ -632,13 +660,16 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(
 				elapsedTime1 := totalTimer.Record()
 
 				accessOrder := utils.GenerateIndicesSet(tc.a)
-				utils.Shuffle(accessOrder)
+				utils.Shuffle(accessOrder, rng)
 
 				totalTimer.Reset()
 
-				recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+				recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 					anonymityPackets, accessOrder,
-					tc.absoluteThreshold)
+					tc.absoluteThreshold, cfg.NoOfWorkers)
+				if err != nil {
+					log.Fatalln(err)
+				}
 
 				elapsedTime2 := totalTimer.Record()
 
@@ -655,6 +686,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(
 					elapsedTime2,
 					tc.absoluteThreshold,
 					subsecretNum,
+					secretbe.NoOfWorkers(cfg.NoOfWorkers),
 				}
 
 				data = append(data, row)
@@ -678,6 +710,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(69, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -700,6 +733,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -752,14 +786,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExtCPU(
 				elapsedTime1 := totalTimer.Record()
 
 				accessOrder := utils.GenerateIndicesSet(tc.a)
-				utils.Shuffle(accessOrder)
+				utils.Shuffle(accessOrder, rng)
 				// fmt.Println(accessOrder)
 
 				totalTimer.Reset()
 
-				recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+				recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 					anonymityPackets, accessOrder,
-					tc.absoluteThreshold)
+					tc.absoluteThreshold, cfg.NoOfWorkers)
+				if err != nil {
+					log.Fatalln(err)
+				}
 
 				elapsedTime2 := totalTimer.Record()
 				row := []interface{}{
@@ -770,6 +807,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExtCPU(
 					elapsedTime2,
 					tc.absoluteThreshold,
 					tc.noOfSubsecrets,
+					secretbe.NoOfWorkers(cfg.NoOfWorkers),
 				}
 
 				if !crypto_protocols.CompareUint16s(secretKey,
@@ -799,6 +837,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoverySecretSizeBinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT4BinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 4
 	testCases, dirSubstr := GenerateTestCases(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
@@ -824,6 +863,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT4BinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -870,14 +910,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT4BinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -888,6 +931,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT4BinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -916,6 +960,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT4BinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT5BinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 5
 	testCases, dirSubstr := GenerateTestCases(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
@@ -941,6 +986,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT5BinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -987,14 +1033,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT5BinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -1005,6 +1054,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT5BinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -1033,6 +1083,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT5BinExtCPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT6BinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 6
 	testCases, dirSubstr := GenerateTestCases(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
@@ -1058,6 +1109,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT6BinExtCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -1104,14 +1156,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT6BinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -1122,6 +1177,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT6BinExtCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -1151,6 +1207,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 	rangeIndicator int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedTCPU(1, 1, rangeIndicator, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -1176,6 +1233,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -1225,13 +1283,16 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 
@@ -1249,6 +1310,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExtCPU(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -1272,6 +1334,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string,
 	rangeIndicator int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholdedCPU(1, 1, rangeIndicator, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -1297,6 +1360,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -1346,13 +1410,16 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 
@@ -1371,6 +1438,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExtCPU(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -1,11 +1,13 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
 	"key_recovery/modules/monitor"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -17,6 +19,7 @@ import (
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
 	tc.a = 1000
@@ -43,6 +46,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -97,14 +101,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -115,6 +122,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -139,6 +147,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonLimits(
 	mainDir string,
 	lower int,
 	upper int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
 	tc.a = upper
@@ -165,6 +174,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonLimits(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -217,14 +227,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonLimits(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -235,6 +248,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonLimits(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -257,6 +271,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonLimits(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
 	tc.a = 1000
@@ -283,6 +298,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -338,14 +354,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -356,6 +375,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -380,6 +400,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPULimits(
 	mainDir string,
 	lower int,
 	upper int) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
 	tc.a = upper
@@ -406,6 +427,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPULimits(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -459,14 +481,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPULimits(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -477,6 +502,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPULimits(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -499,6 +525,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPersonCPULimits(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 4
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -526,6 +553,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -580,14 +608,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -598,6 +629,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -620,6 +652,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4CPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 4
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -647,6 +680,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4CPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -702,14 +736,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4CPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -720,6 +757,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4CPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -742,6 +780,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson4CPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 5
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -769,6 +808,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -823,14 +863,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -841,6 +884,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -863,6 +907,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5CPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 5
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -890,6 +935,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5CPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -945,14 +991,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5CPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -963,6 +1012,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5CPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -985,6 +1035,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson5CPU(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 6
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -1012,6 +1063,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -1066,14 +1118,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			startTime2 := time.Now()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			row := []interface{}{
@@ -1084,6 +1139,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -1106,6 +1162,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6(
 func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6CPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	cfg.DefaultAbsoluteThreshold = 6
 	testCases, dirSubstr := GenerateTestCases(100, 1, false, cfg)
 	tc := testCases[0]
@@ -1133,6 +1190,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6CPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -1188,14 +1246,17 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6CPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 			// fmt.Println(accessOrder)
 
 			totalTimer.Reset()
 
-			secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f,
+			err = secretbe.AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold, obtainedNumber)
+				tc.absoluteThreshold, obtainedNumber, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := totalTimer.Record()
 			row := []interface{}{
@@ -1206,6 +1267,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6CPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -1228,6 +1290,7 @@ func EvaluateTwoLayeredAdditiveOptUsedIndisRecoveryBinExtPerPerson6CPU(
 func EvaluateBasicHashedSecretRecoveryBinExtPerPerson(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 2, false, cfg)
 	tc := testCases[0]
 	tc.a = 500
@@ -1256,6 +1319,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPerson(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -1285,13 +1349,13 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPerson(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			secretbe.BasicHashedSecretRecoveryParallelizedPerPersonUint16(f,
-				anonPackets, accessOrder, secretKeyHash, obtainedNumber)
+			err = secretbe.BasicHashedSecretRecoveryParallelizedPerPersonUint16(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, obtainedNumber, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -1305,6 +1369,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPerson(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
@@ -1329,6 +1394,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPerson(
 func EvaluateBasicHashedSecretRecoveryBinExtPerPersonCPU(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(100, 2, false, cfg)
 	tc := testCases[0]
 	tc.a = 500
@@ -1357,6 +1423,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPersonCPU(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -1387,13 +1454,13 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPersonCPU(
 			elapsedTime1 := totalTimer.Record()
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			totalTimer.Reset()
 			// _, err := secret.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			secretbe.BasicHashedSecretRecoveryParallelizedPerPersonUint16(f,
-				anonPackets, accessOrder, secretKeyHash, obtainedNumber)
+			err = secretbe.BasicHashedSecretRecoveryParallelizedPerPersonUint16(context.Background(), f,
+				anonPackets, accessOrder, secretKeyHash, obtainedNumber, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -1407,6 +1474,7 @@ func EvaluateBasicHashedSecretRecoveryBinExtPerPersonCPU(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			if err != nil {
 				log.Fatalln(err)
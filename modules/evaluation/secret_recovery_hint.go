@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -29,7 +30,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -89,7 +91,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -144,7 +146,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -203,7 +206,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -258,7 +261,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -317,7 +321,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -372,7 +376,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -431,7 +436,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -486,7 +491,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -545,7 +551,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -600,7 +606,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHints(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -659,7 +666,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHints(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -714,7 +721,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnon(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -774,7 +782,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnon(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -829,7 +837,8 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponential(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -889,7 +898,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponential(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -20,6 +22,7 @@ import (
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -45,6 +48,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -93,13 +97,16 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -117,6 +124,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -139,6 +147,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(2, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -164,6 +173,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -211,13 +221,16 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -235,6 +248,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -257,6 +271,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingThresholdBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -282,6 +297,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -329,14 +345,17 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -354,6 +373,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -376,6 +396,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingATBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(4, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -401,6 +422,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -448,13 +470,16 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -472,6 +497,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -494,6 +520,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingTrusteesBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(6, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -519,6 +546,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -566,13 +594,16 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -590,6 +621,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -612,6 +644,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingSSBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(7, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -637,6 +670,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -684,14 +718,17 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -709,6 +746,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -731,6 +769,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryVaryingHintsBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnonBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(7, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -756,6 +795,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnonBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -804,14 +844,17 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnonBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -829,6 +872,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnonBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -851,6 +895,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryLargeAnonBinExt(
 func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponentialBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesHintedT(9, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -876,6 +921,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponentialBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Hints",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -924,14 +970,17 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponentialBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -949,6 +998,7 @@ func EvaluateTwoLayeredHintedTOptUsedIndisRecoveryExponentialBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.noOfHints,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
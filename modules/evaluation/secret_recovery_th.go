@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -29,7 +30,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -89,7 +91,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -148,7 +150,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -208,7 +211,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -267,7 +270,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -327,7 +331,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -386,7 +390,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -446,7 +451,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -505,7 +510,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -565,7 +571,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -624,7 +630,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnon(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -684,7 +691,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnon(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -743,7 +750,8 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponential(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -803,7 +811,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponential(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -31,7 +32,8 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -91,9 +93,9 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -156,7 +158,8 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -216,9 +219,9 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -281,7 +284,8 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -341,9 +345,9 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -406,7 +410,8 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -466,9 +471,9 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
@@ -531,7 +536,8 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -591,9 +597,9 @@ func EvaluateBCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_n...)
 			accessOrder = append(accessOrder, accessOrder_a...)
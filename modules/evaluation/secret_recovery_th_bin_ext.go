@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -20,6 +22,7 @@ import (
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(1, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -45,6 +48,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -93,13 +97,16 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -118,6 +125,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -139,6 +147,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(2, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -164,6 +173,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -212,14 +222,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -238,6 +251,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -259,6 +273,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(3, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -284,6 +299,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -332,14 +348,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -358,6 +377,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -379,6 +399,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(4, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -404,6 +425,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -452,14 +474,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -478,6 +503,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -499,6 +525,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(6, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -524,6 +551,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -572,14 +600,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -598,6 +629,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -619,6 +651,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnonBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(7, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -644,6 +677,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnonBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -692,14 +726,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnonBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -718,6 +755,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnonBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -739,6 +777,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryLargeAnonBinExt(
 func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponentialBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(8, 1, false, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -764,6 +803,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponentialBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations * cfg.Iterations
@@ -812,14 +852,17 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponentialBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder := utils.GenerateIndicesSet(tc.a)
-			utils.Shuffle(accessOrder)
+			utils.Shuffle(accessOrder, rng)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -838,6 +881,7 @@ func EvaluateTwoLayeredThresholdedOptUsedIndisRecoveryExponentialBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
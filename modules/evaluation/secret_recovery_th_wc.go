@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -31,7 +32,8 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -91,9 +93,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -155,7 +157,8 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -215,9 +218,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -279,7 +282,8 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -339,9 +343,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -403,7 +407,8 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -463,9 +468,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -527,7 +532,8 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -587,9 +593,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -20,6 +22,7 @@ import (
 func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(1, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -45,6 +48,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -93,9 +97,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -106,10 +110,13 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 			//
 			// 	anonymityPackets, accessOrder,
 			// 	tc.absoluteThreshold)
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -128,6 +135,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -149,6 +157,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryBinExt(
 func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(2, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -174,6 +183,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -222,9 +232,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -235,10 +245,13 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 			//
 			// 	anonymityPackets, accessOrder,
 			// 	tc.absoluteThreshold)
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -257,6 +270,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -278,6 +292,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingThresholdBinExt(
 func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(3, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -303,6 +318,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -351,9 +367,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -364,10 +380,13 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 			//
 			// 	anonymityPackets, accessOrder,
 			// 	tc.absoluteThreshold)
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -386,6 +405,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -407,6 +427,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingATBinExt(
 func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(4, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -432,6 +453,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -480,18 +502,21 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -510,6 +535,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -531,6 +557,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingTrusteesBinExt(
 func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCasesThresholded(6, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -556,6 +583,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Subsecrets Threshold",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -604,9 +632,9 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -617,10 +645,13 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 			//
 			// 	anonymityPackets, accessOrder,
 			// 	tc.absoluteThreshold)
-			recoveredKey := secretbe.ThOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.ThOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -639,6 +670,7 @@ func EvaluateWCTwoLayeredThresholdedOptUsedIndisRecoveryVaryingSSBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				tc.percentageSubsecretsThreshold,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
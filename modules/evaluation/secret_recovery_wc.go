@@ -5,6 +5,7 @@ import (
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/secret"
 	"key_recovery/modules/utils"
 	"log"
@@ -31,7 +32,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -88,9 +90,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -151,7 +153,8 @@ func EvaluateWCBasicHashedSecretRecovery(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -182,9 +185,9 @@ func EvaluateWCBasicHashedSecretRecovery(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -238,7 +241,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -295,9 +299,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -356,7 +360,8 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThreshold(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -388,9 +393,9 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThreshold(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -445,7 +450,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -502,9 +508,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -565,7 +571,8 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrustees(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 	secretKeyBytes := crypto_protocols.ConvertKeyToBytes(secretKey)
 	secretKeyHash := crypto_protocols.GetSHA256(secretKeyBytes)
@@ -596,9 +603,9 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrustees(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -652,7 +659,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -710,9 +718,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingAT(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -774,7 +782,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -831,9 +840,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -894,7 +903,8 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSS(
 		return
 	}
 	g := edwards25519.NewBlakeSHA256Ed25519()
-	randSeedShares := g.RandomStream()
+	randSeedShares := randomness.Stream()
+	rng := randomness.NewSeededRand(cfg.Seed)
 	secretKey := g.Scalar().Pick(randSeedShares)
 
 	var data [][]interface{}
@@ -959,9 +969,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSS(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -1,10 +1,12 @@
 package evaluation
 
 import (
+	"context"
 	"fmt"
 	"key_recovery/modules/configuration"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	secretbe "key_recovery/modules/secret_binary_extension"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
@@ -20,6 +22,7 @@ import (
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(1, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -44,6 +47,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -89,18 +93,21 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -117,6 +124,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -139,6 +147,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryBinExt(
 func EvaluateWCBasicHashedSecretRecoveryBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(1, 2, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -166,6 +175,7 @@ func EvaluateWCBasicHashedSecretRecoveryBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -186,9 +196,9 @@ func EvaluateWCBasicHashedSecretRecoveryBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -196,8 +206,8 @@ func EvaluateWCBasicHashedSecretRecoveryBinExt(
 			startTime2 := time.Now()
 			// _, err := secretbe.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			_, err = secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonymityShareVals, accessOrder, secretKeyHash)
+			_, err = secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonymityShareVals, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -211,6 +221,7 @@ func EvaluateWCBasicHashedSecretRecoveryBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -234,6 +245,7 @@ func EvaluateWCBasicHashedSecretRecoveryBinExt(
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -258,6 +270,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -303,9 +316,9 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -315,9 +328,12 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 			//
 			// 	anonymityPackets, accessOrder,
 			// 	tc.absoluteThreshold)
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -334,6 +350,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -355,6 +372,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingThresholdBinExt(
 func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(2, 2, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -382,6 +400,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -403,9 +422,9 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -413,8 +432,8 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 			startTime2 := time.Now()
 			// _, err := secretbe.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			_, err = secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonymityShareVals, accessOrder, secretKeyHash)
+			_, err = secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonymityShareVals, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -428,6 +447,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -452,6 +472,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingThresholdBinExt(
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(4, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -476,6 +497,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -521,18 +543,21 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 			if !crypto_protocols.CompareUint16s(secretKey,
@@ -549,6 +574,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -570,6 +596,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingTrusteesBinExt(
 func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(4, 2, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -597,6 +624,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -617,9 +645,9 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
@@ -627,8 +655,8 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 			startTime2 := time.Now()
 			// _, err := secretbe.BasicHashedSecretRecovery(f,
 			// 	anonymityShareVals, accessOrder, secretKeyHash)
-			_, err = secretbe.BasicHashedSecretRecoveryParallelized(f,
-				anonymityShareVals, accessOrder, secretKeyHash)
+			_, err = secretbe.BasicHashedSecretRecoveryParallelized(context.Background(), f,
+				anonymityShareVals, accessOrder, secretKeyHash, cfg.NoOfWorkers)
 			if err != nil {
 				log.Fatalln(err)
 				continue
@@ -642,6 +670,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -665,6 +694,7 @@ func EvaluateWCBasicHashedSecretRecoveryVaryingTrusteesBinExt(
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(3, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -689,6 +719,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -735,18 +766,21 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -764,6 +798,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 			data = append(data, row)
 			fmt.Println("Generation:", elapsedTime1)
@@ -785,6 +820,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingATBinExt(
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(6, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -809,6 +845,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 		"Time taken for secret recovery",
 		"Absolute Threshold",
 		"Subsecrets",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -854,18 +891,21 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -882,6 +922,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 				elapsedTime2,
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -904,6 +945,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryVaryingSSBinExt(
 func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSSBinExt(
 	cfg *configuration.SimulationConfig,
 	mainDir string) {
+	rng := randomness.NewSeededRand(cfg.Seed)
 	testCases, dirSubstr := GenerateTestCases(5, 1, true, cfg)
 	csvDir := mainDir + dirSubstr
 	err, _ := files.CreateDirectory(csvDir)
@@ -929,6 +971,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSSBinExt(
 		"Absolute Threshold",
 		"Subsecrets",
 		"Shares Per Trustee",
+		"Workers",
 	}
 	data = append(data, topData)
 	totalSimulations := cfg.Iterations
@@ -981,18 +1024,21 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSSBinExt(
 			elapsedTime1 := int(time.Since(startTime1).Nanoseconds())
 
 			accessOrder_a := utils.GenerateOffsettedIndicesSet(tc.a-tc.n, tc.n)
-			utils.Shuffle(accessOrder_a)
+			utils.Shuffle(accessOrder_a, rng)
 			accessOrder_n := utils.GenerateIndicesSet(tc.n)
-			utils.Shuffle(accessOrder_n)
+			utils.Shuffle(accessOrder_n, rng)
 			var accessOrder []int
 			accessOrder = append(accessOrder, accessOrder_a...)
 			accessOrder = append(accessOrder, accessOrder_n...)
 
 			startTime2 := time.Now()
 
-			recoveredKey := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(f,
+			recoveredKey, err := secretbe.AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(), f,
 				anonymityPackets, accessOrder,
-				tc.absoluteThreshold)
+				tc.absoluteThreshold, cfg.NoOfWorkers)
+			if err != nil {
+				log.Fatalln(err)
+			}
 
 			elapsedTime2 := int(time.Since(startTime2).Nanoseconds())
 
@@ -1010,6 +1056,7 @@ func EvaluateWCTwoLayeredAdditiveOptUsedIndisRecoveryOptPerSSBinExt(
 				tc.absoluteThreshold,
 				tc.noOfSubsecrets,
 				sharesPerTrustee,
+				secretbe.NoOfWorkers(cfg.NoOfWorkers),
 			}
 
 			data = append(data, row)
@@ -3,6 +3,7 @@ package files
 import (
 	"encoding/csv"
 	"encoding/gob"
+	"io"
 	"key_recovery/modules/utils"
 	"os"
 	"strconv"
@@ -141,8 +142,6 @@ func SaveSlice16ToFile(filename string, data []uint16) error {
 }
 
 func LoadSlice16FromFile(filename string) ([]uint16, error) {
-	var data []uint16
-
 	// Open the file
 	file, err := os.Open(filename)
 	if err != nil {
@@ -150,9 +149,16 @@ func LoadSlice16FromFile(filename string) ([]uint16, error) {
 	}
 	defer file.Close()
 
+	return LoadSlice16(file)
+}
+
+// LoadSlice16 decodes a slice saved by SaveSlice16ToFile
+func LoadSlice16(r io.Reader) ([]uint16, error) {
+	var data []uint16
+
 	// Create a new decoder and decode the data into the slice
-	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(&data)
+	decoder := gob.NewDecoder(r)
+	err := decoder.Decode(&data)
 	if err != nil {
 		return nil, err
 	}
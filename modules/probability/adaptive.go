@@ -0,0 +1,64 @@
+package probability
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ***********************Adaptive***********************
+// A batch is one call of a CDF simulator, for instance
+//
+//	func() (map[int]int, map[int]int, error) {
+//		return GetAdditiveProbabilityFixedThTotalCDFParallelized(...)
+//	}
+//
+// The batches are run until the intervals of every point of both CDFs are
+// narrower than the target width or the time budget runs out
+
+// AdaptiveControl tells when to stop running the batches
+// There is no target if the width is 0 and no limit if the budget is 0
+// With neither, a single batch is run
+type AdaptiveControl struct {
+	Interval    Interval
+	TargetWidth float64
+	TimeBudget  time.Duration
+}
+
+// IsAdaptive tells if more than a single batch may be run
+func (control AdaptiveControl) IsAdaptive() bool {
+	return control.TargetWidth > 0 || control.TimeBudget > 0
+}
+
+// RunAdaptive adds up the counts of the batches until the control stops it
+func RunAdaptive(batch func() (map[int]int, map[int]int, error),
+	control AdaptiveControl) (map[int]int, map[int]int, error) {
+	results := make(map[int]int)
+	resultsAnon := make(map[int]int)
+	start := time.Now()
+	for batches := 1; ; batches++ {
+		batchResults, batchResultsAnon, err := batch()
+		if err != nil {
+			return nil, nil, err
+		}
+		for size, cases := range batchResults {
+			results[size] += cases
+		}
+		for size, cases := range batchResultsAnon {
+			resultsAnon[size] += cases
+		}
+		if !control.IsAdaptive() {
+			return results, resultsAnon, nil
+		}
+		width := math.Max(MaxIntervalWidth(results, control.Interval),
+			MaxIntervalWidth(resultsAnon, control.Interval))
+		fmt.Println("Batch", batches, "widest interval", width)
+		if control.TargetWidth > 0 && width <= control.TargetWidth {
+			return results, resultsAnon, nil
+		}
+		if control.TimeBudget > 0 && time.Since(start) >= control.TimeBudget {
+			fmt.Println("Time budget exhausted with the widest interval", width)
+			return results, resultsAnon, nil
+		}
+	}
+}
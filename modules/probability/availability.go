@@ -0,0 +1,59 @@
+package probability
+
+import (
+	"key_recovery/modules/errors"
+	randm "math/rand"
+)
+
+// ***********************Availability***********************
+// The people may not answer when they are contacted, e.g., they have died,
+// lost their packet or do not respond
+// A person who does not answer still counts as contacted but gives nothing
+// With permanent losses, who has lost their packet is drawn once when the
+// packets are created, so all the runs on the packets see the same losses
+// Otherwise, who answers is drawn again in every run
+// The secret may then never be recovered, which is counted as a failure
+
+// Availability holds the probability that each person (the trustees first)
+// answers with their packet
+type Availability struct {
+	Probabilities []float64
+	Permanent     bool
+}
+
+// NewAvailability gives the same probability to all the trustees and to all
+// the other people of the anonymity set
+func NewAvailability(trustees, anonymity int, trusteesProb,
+	nonTrusteesProb float64, permanent bool) (*Availability, error) {
+	if trustees > anonymity || !isProbability(trusteesProb) ||
+		!isProbability(nonTrusteesProb) {
+		return nil, errors.ErrInvalidInput
+	}
+	probabilities := make([]float64, anonymity)
+	for i := range probabilities {
+		probabilities[i] = nonTrusteesProb
+		if i < trustees {
+			probabilities[i] = trusteesProb
+		}
+	}
+	return &Availability{Probabilities: probabilities, Permanent: permanent},
+		nil
+}
+
+func isProbability(p float64) bool {
+	return p >= 0 && p <= 1
+}
+
+// AvailablePackets draws who answers and gives the packets with no data for
+// the people who do not
+// The packets themselves are shared with peoplePackets
+func (av *Availability) AvailablePackets(peoplePackets [][]int,
+	rng *randm.Rand) [][]int {
+	available := make([][]int, len(peoplePackets))
+	for i, packet := range peoplePackets {
+		if rng.Float64() < av.Probabilities[i] {
+			available[i] = packet
+		}
+	}
+	return available
+}
@@ -4,6 +4,7 @@ import (
 	"key_recovery/modules/errors"
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 )
 
 type ProbEval struct {
@@ -19,7 +20,8 @@ type ProbEval struct {
 // ***********************Baseline***********************
 
 func GetBaselineProbabilityCDF(simulations, threshold,
-	trustees, anonymity int) (map[int]int, map[int]int, error) {
+	trustees, anonymity int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -31,7 +33,7 @@ func GetBaselineProbabilityCDF(simulations, threshold,
 
 	for i := 0; i < simulations; i++ {
 		accessOrder := utils.GenerateIndicesSet(anonymity)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, rng)
 
 		for j := 2; j <= anonymity; j++ {
 			peopleContacted := accessOrder[:j][:]
@@ -50,7 +52,7 @@ func GetBaselineProbabilityCDF(simulations, threshold,
 func GetCompWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 	trustees, anonymity int,
 	deltaTr, deltaNonTr uint16,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -63,7 +65,7 @@ func GetCompWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 	for i := 0; i < simulations; i++ {
 		actualBitMatrix := utils.GenerateTrNonTrBitMatrix(trustees, anonymity)
 		flippedMatrix := utils.FlipBitsWithProbability(actualBitMatrix, deltaTr,
-			deltaNonTr, trustees, anonymity)
+			deltaNonTr, trustees, anonymity, rng)
 		var firstApproach []int
 		var lastApproach []int
 		for ind, f := range flippedMatrix {
@@ -73,17 +75,17 @@ func GetCompWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 				lastApproach = append(lastApproach, ind)
 			}
 		}
-		utils.Shuffle(firstApproach)
-		utils.Shuffle(lastApproach)
+		utils.Shuffle(firstApproach, rng)
+		utils.Shuffle(lastApproach, rng)
 		var accessOrder []int
 		accessOrder = append(accessOrder, firstApproach...)
 		accessOrder = append(accessOrder, lastApproach...)
 
-		obtProbs, err := utils.GenerateProbabilityArray(anonymity)
+		obtProbs, err := utils.GenerateProbabilityArray(anonymity, rng)
 		if err != nil {
 			log.Fatalln(err)
 		}
-		probsWB, err := utils.GenerateProbabilityArray(anonymity)
+		probsWB, err := utils.GenerateProbabilityArray(anonymity, rng)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -112,7 +114,7 @@ func GetCompWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 
 func GetWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 	trustees, anonymity int,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -124,13 +126,13 @@ func GetWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 
 	for i := 0; i < simulations; i++ {
 		accessOrder := utils.GenerateIndicesSet(anonymity)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, rng)
 
-		obtProbs, err := utils.GenerateProbabilityArray(anonymity)
+		obtProbs, err := utils.GenerateProbabilityArray(anonymity, rng)
 		if err != nil {
 			log.Fatalln(err)
 		}
-		probsWB, err := utils.GenerateProbabilityArray(anonymity)
+		probsWB, err := utils.GenerateProbabilityArray(anonymity, rng)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -161,7 +163,7 @@ func GetWBAdvObtBaselineProbabilityCDF(simulations, threshold,
 // ***********************Additive***********************
 func GetAdditiveProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
-	subsecretsNum int) (map[int]int, map[int]int, error) {
+	subsecretsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -184,7 +186,7 @@ func GetAdditiveProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 		// fmt.Println("*********** xx Simulation xx ****************", k)
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -195,7 +197,7 @@ func GetAdditiveProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 			// fmt.Println("*********** Simulation ****************", i)
 			TotalRecovery(peoplePackets, layerWiseChildren, layers,
 				subsecretsNum, trustees, leavesLayerThreshold,
-				results, results_anon)
+				results, results_anon, rng)
 		}
 	}
 	return results, results_anon, nil
@@ -206,7 +208,7 @@ func GetAdditiveProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 func GetThresholdedProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 	layers, threshold, upperThreshold,
 	trustees, anonymity, absoluteThreshold,
-	subsecretsNum int) (map[int]int, map[int]int, error) {
+	subsecretsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -229,7 +231,7 @@ func GetThresholdedProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -240,7 +242,7 @@ func GetThresholdedProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 		for i := 0; i < simulationsRun; i++ {
 			TotalRecovery(peoplePackets, layerWiseChildren, layers,
 				upperLayerThreshold, trustees, leavesLayerThreshold,
-				results, results_anon)
+				results, results_anon, rng)
 		}
 	}
 	return results, results_anon, nil
@@ -250,7 +252,8 @@ func GetThresholdedProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 // ***********************Hinted***********************
 func GetHintedTProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
-	subsecretsNum, noOfHints int) (map[int]int, map[int]int, error) {
+	subsecretsNum, noOfHints int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -272,7 +275,8 @@ func GetHintedTProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, err := CreatePeopleHintedTPacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum, noOfHints)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, noOfHints,
+			rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -284,7 +288,7 @@ func GetHintedTProbabilityFixedThTotalCDF(simulationsDist, simulationsRun,
 			TotalHintedTRecovery(peoplePackets, layerWiseChildren, layers,
 				subsecretsNum, trustees, leavesLayerThreshold,
 				sharePersonMap, hintPersonMap,
-				results, results_anon)
+				results, results_anon, rng)
 		}
 	}
 	return results, results_anon, nil
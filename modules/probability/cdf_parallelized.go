@@ -2,8 +2,10 @@ package probability
 
 import (
 	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 	"sync"
 )
 
@@ -11,7 +13,17 @@ import (
 // ***********************Additive***********************
 func GetAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
-	subsecretsNum int) (map[int]int, map[int]int, error) {
+	subsecretsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
+	return GetAdditiveProbabilityFixedThTotalCDFParallelizedWeighted(
+		simulationsDist, simulationsRun, layers, threshold, trustees,
+		anonymity, absoluteThreshold, subsecretsNum, nil, rng)
+}
+
+// The trustees receive the leaves in proportion to their weights
+func GetAdditiveProbabilityFixedThTotalCDFParallelizedWeighted(simulationsDist,
+	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum int, weights []int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -37,8 +49,9 @@ func GetAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist, simulati
 
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
-		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedThWeighted(
+			layers, threshold, trustees, anonymity, subsecretsNum, sharesNum,
+			weights, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -48,7 +61,7 @@ func GetAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist, simulati
 
 		go TotalRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
 			subsecretsNum, trustees, leavesLayerThreshold, simulationsRun,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -74,7 +87,17 @@ func GetAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist, simulati
 func GetThresholdedProbabilityFixedThTotalCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, upperThreshold,
 	trustees, anonymity, absoluteThreshold,
-	subsecretsNum int) (map[int]int, map[int]int, error) {
+	subsecretsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
+	return GetThresholdedProbabilityFixedThTotalCDFParallelizedWeighted(
+		simulationsDist, simulationsRun, layers, threshold, upperThreshold,
+		trustees, anonymity, absoluteThreshold, subsecretsNum, nil, rng)
+}
+
+// The trustees receive the leaves in proportion to their weights
+func GetThresholdedProbabilityFixedThTotalCDFParallelizedWeighted(
+	simulationsDist, simulationsRun, layers, threshold, upperThreshold,
+	trustees, anonymity, absoluteThreshold, subsecretsNum int,
+	weights []int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -101,8 +124,9 @@ func GetThresholdedProbabilityFixedThTotalCDFParallelized(simulationsDist, simul
 
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
-		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedThWeighted(
+			layers, threshold, trustees, anonymity, subsecretsNum, sharesNum,
+			weights, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -112,7 +136,7 @@ func GetThresholdedProbabilityFixedThTotalCDFParallelized(simulationsDist, simul
 
 		go TotalRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
 			upperLayerThreshold, trustees, leavesLayerThreshold, simulationsRun,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -136,7 +160,18 @@ func GetThresholdedProbabilityFixedThTotalCDFParallelized(simulationsDist, simul
 // ***********************Hinted***********************
 func GetHintedTProbabilityFixedThTotalCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
-	subsecretsNum, noOfHints int) (map[int]int, map[int]int, error) {
+	subsecretsNum, noOfHints int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
+	return GetHintedTProbabilityFixedThTotalCDFParallelizedWeighted(
+		simulationsDist, simulationsRun, layers, threshold, trustees,
+		anonymity, absoluteThreshold, subsecretsNum, noOfHints, nil, rng)
+}
+
+// The trustees receive the leaves in proportion to their weights
+func GetHintedTProbabilityFixedThTotalCDFParallelizedWeighted(
+	simulationsDist, simulationsRun, layers, threshold, trustees, anonymity,
+	absoluteThreshold, subsecretsNum, noOfHints int,
+	weights []int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -162,8 +197,10 @@ func GetHintedTProbabilityFixedThTotalCDFParallelized(simulationsDist, simulatio
 
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
-		peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, err := CreatePeopleHintedTPacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum, noOfHints)
+		peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, err :=
+			CreatePeopleHintedTPacketsFixedThWeighted(layers, threshold,
+				trustees, anonymity, subsecretsNum, sharesNum, noOfHints,
+				weights, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -174,7 +211,7 @@ func GetHintedTProbabilityFixedThTotalCDFParallelized(simulationsDist, simulatio
 		go TotalHintedTRecoveryParallelized(peoplePackets, layerWiseChildren,
 			layers, subsecretsNum, trustees, leavesLayerThreshold,
 			sharePersonMap, hintPersonMap, simulationsRun,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -199,7 +236,8 @@ func GetHintedTProbabilityFixedThTotalCDFParallelized(simulationsDist, simulatio
 // ***********************Additive-Expected***********************
 func GetExpectedAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist,
 	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
-	subsecretsNum, extraShares int) (map[int]int, map[int]int, error) {
+	subsecretsNum, extraShares int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -227,7 +265,7 @@ func GetExpectedAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -237,7 +275,7 @@ func GetExpectedAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist,
 
 		go TotalRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
 			subsecretsNum, trustees, leavesLayerThreshold, simulationsRun,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -298,3 +336,127 @@ func GetExpectedAdditiveProbabilityFixedThTotalCDFParallelized(simulationsDist,
 
 // 	return results, results_anon, nil
 // }
+
+// ***********************Total***********************
+// ***********************Availability***********************
+// The third value is the number of runs in which the secret could not be
+// recovered from the people who answered
+func GetAdditiveProbabilityAvailabilityCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum int, availability *Availability,
+	rng *randm.Rand) (map[int]int, map[int]int,
+	int, error) {
+	return getAvailabilityCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, 0, availability, rng)
+}
+
+func GetThresholdedProbabilityAvailabilityCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, upperThreshold, trustees, anonymity,
+	absoluteThreshold, subsecretsNum int,
+	availability *Availability,
+	rng *randm.Rand) (map[int]int, map[int]int, int, error) {
+	upperLayerThreshold := utils.FloorDivide(upperThreshold*subsecretsNum, 100)
+	return getAvailabilityCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, upperLayerThreshold, 0, availability, rng)
+}
+
+func GetHintedTProbabilityAvailabilityCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum, noOfHints int, availability *Availability,
+	rng *randm.Rand) (map[int]int,
+	map[int]int, int, error) {
+	if noOfHints < 1 {
+		return nil, nil, 0, errors.ErrInvalidInput
+	}
+	return getAvailabilityCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, noOfHints, availability, rng)
+}
+
+// The hinted packets are used if there are hints
+func getAvailabilityCDFParallelized(simulationsDist, simulationsRun, layers,
+	threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	upperLayerThreshold, noOfHints int,
+	availability *Availability,
+	rng *randm.Rand) (map[int]int, map[int]int, int, error) {
+	// The percentage threshold should not be greater than 100%
+	if threshold > 100 {
+		return nil, nil, 0, errors.ErrInvalidThreshold
+	}
+	if len(availability.Probabilities) != anonymity {
+		return nil, nil, 0, errors.ErrInvalidSliceLength
+	}
+	results := make(map[int]int)
+	results_anon := make(map[int]int)
+	for i := 0; i < anonymity; i++ {
+		results[i+1] = 0
+		results_anon[i+1] = 0
+	}
+	// Based on the value of the threshold,
+	// Obtain the number of packets in the leaves layer
+	sharesNum := utils.FloorDivide((absoluteThreshold * 100), threshold)
+
+	// Get the number of shares needed for recovering the subsecrets
+	// The subsecrets have a fixed threshold
+	leavesLayerThreshold := absoluteThreshold
+
+	trusteesNumChannel := make(chan int, simulationsDist*simulationsRun)
+	contactsNumChannel := make(chan int, simulationsDist*simulationsRun)
+	failuresChannel := make(chan int, simulationsDist*simulationsRun)
+
+	var wg sync.WaitGroup
+
+	for k := 0; k < simulationsDist; k++ {
+		// Obtain the packets to be distributed among people
+		var peoplePackets [][]int
+		var layerWiseChildren map[int]map[int][]int
+		var sharePersonMap, hintPersonMap map[int]int
+		var err error
+		if noOfHints == 0 {
+			peoplePackets, layerWiseChildren, err =
+				CreatePeoplePacketsFixedThAvailability(layers, threshold,
+					trustees, anonymity, subsecretsNum, sharesNum,
+					availability, rng)
+		} else {
+			peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap,
+				err = CreatePeopleHintedTPacketsFixedThAvailability(layers,
+				threshold, trustees, anonymity, subsecretsNum, sharesNum,
+				noOfHints, availability, rng)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wg.Add(1)
+
+		go TotalRecoveryAvailabilityParallelized(peoplePackets,
+			layerWiseChildren, layers, upperLayerThreshold, trustees,
+			leavesLayerThreshold, sharePersonMap, hintPersonMap,
+			simulationsRun, availability, randomness.Fork(rng),
+			trusteesNumChannel, contactsNumChannel, failuresChannel, &wg)
+	}
+
+	// Wait for the routines to finish
+	wg.Wait()
+
+	// Close the channels
+	close(trusteesNumChannel)
+	close(contactsNumChannel)
+	close(failuresChannel)
+
+	// Update the maps
+	for contactsNum := range contactsNumChannel {
+		results_anon[contactsNum] += 1
+	}
+	for trusteesNum := range trusteesNumChannel {
+		results[trusteesNum] += 1
+	}
+	failures := 0
+	for range failuresChannel {
+		failures += 1
+	}
+
+	return results, results_anon, failures, nil
+}
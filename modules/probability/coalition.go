@@ -0,0 +1,179 @@
+package probability
+
+import (
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/utils"
+	"log"
+	randm "math/rand"
+	"sync"
+)
+
+// ***********************Coalition***********************
+// A coalition of insiders, i.e., some of the trustees, pool their packets
+// and then contact the other people as the adversary of
+// TotalCompWBAdvObtRecoveryParallelized does, with AdvObtRecovery
+// The other people are approached in the order chosen by the strategy of
+// the coalition, which sees the pooled packets first
+// A contacted person gives the packet with the probability obtProb and
+// reports the coalition with the probability wbProb, which ends the attack
+// The number of people contacted after pooling the packets is reported if
+// the secret is recovered before the coalition is reported (0 if the pooled
+// packets are enough), and a failure otherwise
+
+// Here, you run an attack of the coalition until the secret is recovered or
+// the coalition is reported
+// The hints are decrypted if there is a map of the hints
+func TotalCoalitionRecoveryParallelized(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers,
+	upperLayerThreshold, trustees, leavesLayerThreshold int,
+	sharePersonMap, hintPersonMap map[int]int, simulationsRun, insiders int,
+	strategy AccessStrategy, obtProb, wbProb byte, rng *randm.Rand,
+	contactsNumChannel chan<- int, failuresChannel chan<- int,
+	wg *sync.WaitGroup) {
+	defer wg.Done()
+	noOfPeople := len(peoplePackets)
+	for i := 0; i < simulationsRun; i++ {
+		// The insiders are random trustees
+		trusteesOrder := utils.GenerateIndicesSet(trustees)
+		utils.Shuffle(trusteesOrder, rng)
+		coalition := trusteesOrder[:insiders]
+		run := strategy.NewRun(trustees, noOfPeople, rng)
+		state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+			upperLayerThreshold, leavesLayerThreshold, sharePersonMap,
+			hintPersonMap)
+		isRecovered := false
+		contactsNum := 0
+		// Pool the packets of the coalition
+		for _, a := range coalition {
+			if state.Add(a) {
+				isRecovered = true
+			}
+			run.Observe(a, state)
+		}
+		if !isRecovered {
+			_, contactsNum, isRecovered = AdvObtRecovery(state, run,
+				noOfPeople, obtProb, wbProb, rng)
+		}
+		if isRecovered {
+			contactsNumChannel <- contactsNum
+		} else {
+			failuresChannel <- 1
+		}
+	}
+}
+
+// ***********************Total***********************
+// ***********************Coalition***********************
+// Gives the number of runs for every number of people contacted after
+// pooling the packets (from 0) and the number of runs in which the coalition
+// is reported before recovering the secret
+func GetAdditiveCoalitionCDFParallelized(simulationsDist, simulationsRun,
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	insiders int, strategy AccessStrategy,
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, int, error) {
+	return getCoalitionCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, 0, insiders, strategy, obtProb, wbProb,
+		rng)
+}
+
+func GetThresholdedCoalitionCDFParallelized(simulationsDist, simulationsRun,
+	layers, threshold, upperThreshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum, insiders int, strategy AccessStrategy,
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, int, error) {
+	upperLayerThreshold := utils.FloorDivide(upperThreshold*subsecretsNum, 100)
+	return getCoalitionCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, upperLayerThreshold, 0, insiders, strategy, obtProb,
+		wbProb, rng)
+}
+
+func GetHintedTCoalitionCDFParallelized(simulationsDist, simulationsRun,
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	noOfHints, insiders int, strategy AccessStrategy,
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, int, error) {
+	if noOfHints < 1 {
+		return nil, 0, errors.ErrInvalidInput
+	}
+	return getCoalitionCDFParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, noOfHints, insiders, strategy,
+		obtProb, wbProb, rng)
+}
+
+// The hinted packets are used if there are hints
+func getCoalitionCDFParallelized(simulationsDist, simulationsRun, layers,
+	threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	upperLayerThreshold, noOfHints, insiders int, strategy AccessStrategy,
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, int, error) {
+	// The percentage threshold should not be greater than 100%
+	if threshold > 100 {
+		return nil, 0, errors.ErrInvalidThreshold
+	}
+	if insiders < 0 || insiders > trustees {
+		return nil, 0, errors.ErrInvalidInput
+	}
+	results := make(map[int]int)
+	for i := 0; i <= anonymity-insiders; i++ {
+		results[i] = 0
+	}
+	// Based on the value of the threshold,
+	// Obtain the number of packets in the leaves layer
+	sharesNum := utils.FloorDivide((absoluteThreshold * 100), threshold)
+
+	// Get the number of shares needed for recovering the subsecrets
+	// The subsecrets have a fixed threshold
+	leavesLayerThreshold := absoluteThreshold
+
+	contactsNumChannel := make(chan int, simulationsDist*simulationsRun)
+	failuresChannel := make(chan int, simulationsDist*simulationsRun)
+
+	var wg sync.WaitGroup
+
+	for k := 0; k < simulationsDist; k++ {
+		// Obtain the packets to be distributed among people
+		var peoplePackets [][]int
+		var layerWiseChildren map[int]map[int][]int
+		var sharePersonMap, hintPersonMap map[int]int
+		var err error
+		if noOfHints == 0 {
+			peoplePackets, layerWiseChildren, err = CreatePeoplePacketsFixedTh(
+				layers, threshold, trustees, anonymity, subsecretsNum,
+				sharesNum, rng)
+		} else {
+			peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap,
+				err = CreatePeopleHintedTPacketsFixedTh(layers, threshold,
+				trustees, anonymity, subsecretsNum, sharesNum, noOfHints, rng)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wg.Add(1)
+
+		go TotalCoalitionRecoveryParallelized(peoplePackets, layerWiseChildren,
+			layers, upperLayerThreshold, trustees, leavesLayerThreshold,
+			sharePersonMap, hintPersonMap, simulationsRun, insiders, strategy,
+			obtProb, wbProb, randomness.Fork(rng),
+			contactsNumChannel, failuresChannel, &wg)
+	}
+
+	// Wait for the routines to finish
+	wg.Wait()
+
+	// Close the channels
+	close(contactsNumChannel)
+	close(failuresChannel)
+
+	// Update the map
+	for contactsNum := range contactsNumChannel {
+		results[contactsNum] += 1
+	}
+	failures := 0
+	for range failuresChannel {
+		failures += 1
+	}
+
+	return results, failures, nil
+}
@@ -0,0 +1,208 @@
+package probability
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"math"
+)
+
+// Methods of the confidence intervals of the simulated probabilities
+const (
+	IntervalWilson         = "wilson"
+	IntervalClopperPearson = "clopper_pearson"
+)
+
+// Interval gives the confidence interval of a proportion of successes out
+// of n trials
+type Interval func(successes, n int) (float64, float64)
+
+// NewInterval gives the interval of the method ("wilson" if empty) for the
+// confidence level (0.95 if 0)
+func NewInterval(method string, confidence float64) (Interval, error) {
+	if confidence == 0 {
+		confidence = 0.95
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return nil, fmt.Errorf("%w: confidence level %v",
+			errors.ErrInvalidInput, confidence)
+	}
+	switch method {
+	case "", IntervalWilson:
+		z := ZScore(confidence)
+		return func(successes, n int) (float64, float64) {
+			return WilsonInterval(successes, n, z)
+		}, nil
+	case IntervalClopperPearson:
+		return func(successes, n int) (float64, float64) {
+			return ClopperPearsonInterval(successes, n, confidence)
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: confidence interval %q", errors.ErrInvalidInput,
+		method)
+}
+
+// ZScore gives the z score of a two-sided confidence level (1.96 for 0.95)
+func ZScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// WilsonInterval gives the Wilson score interval of a proportion of
+// successes out of n trials for the z score (1.96 for 95%)
+func WilsonInterval(successes, n int, z float64) (float64, float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	nf := float64(n)
+	p := float64(successes) / nf
+	z2 := z * z
+	centre := (p + z2/(2*nf)) / (1 + z2/nf)
+	halfWidth := z / (1 + z2/nf) * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+	lower, upper := math.Max(0, centre-halfWidth), math.Min(1, centre+halfWidth)
+	// The bounds are exact at the ends, which the rounding may miss
+	if successes == 0 {
+		lower = 0
+	}
+	if successes == n {
+		upper = 1
+	}
+	return lower, upper
+}
+
+// ClopperPearsonInterval gives the exact (conservative) interval of a
+// proportion of successes out of n trials for the confidence level
+// The bounds are the quantiles of the beta distributions
+func ClopperPearsonInterval(successes, n int,
+	confidence float64) (float64, float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	alpha := 1 - confidence
+	lower, upper := 0.0, 1.0
+	if successes > 0 {
+		lower = betaQuantile(alpha/2, float64(successes),
+			float64(n-successes+1))
+	}
+	if successes < n {
+		upper = betaQuantile(1-alpha/2, float64(successes+1),
+			float64(n-successes))
+	}
+	return lower, upper
+}
+
+// The CDF of the beta distribution is increasing, so the quantile is found
+// by bisection
+func betaQuantile(q, a, b float64) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if regularizedIncompleteBeta(mid, a, b) < q {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// I_x(a, b) with the continued fraction of Numerical Recipes (6.4)
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly on this side
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	const eps = 1e-15
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m < 100000; m++ {
+		mf := float64(m)
+		m2 := 2 * mf
+		// Even step
+		aa := mf * (b - mf) * x / ((a + m2 - 1) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step
+		aa = -(a + mf) * (a + b + mf) * x / ((a + m2) * (a + m2 + 1))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// CDFIntervals gives the simulated CDF of the counts per size (as returned
+// by the CDF simulators) from size 1 to maxSize with its intervals
+func CDFIntervals(counts map[int]int, maxSize int,
+	interval Interval) ([]float64, []float64, []float64) {
+	total := 0
+	for _, cases := range counts {
+		total += cases
+	}
+	cdf := make([]float64, maxSize+1)
+	lower := make([]float64, maxSize+1)
+	upper := make([]float64, maxSize+1)
+	cumulative := 0
+	for size := 1; size <= maxSize; size++ {
+		cumulative += counts[size]
+		if total > 0 {
+			cdf[size] = float64(cumulative) / float64(total)
+		}
+		lower[size], upper[size] = interval(cumulative, total)
+	}
+	return cdf, lower, upper
+}
+
+// MaxIntervalWidth gives the width of the widest interval of the simulated
+// CDF
+func MaxIntervalWidth(counts map[int]int, interval Interval) float64 {
+	maxSize := 0
+	for size := range counts {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	_, lower, upper := CDFIntervals(counts, maxSize, interval)
+	width := 0.0
+	for size := 1; size <= maxSize; size++ {
+		width = math.Max(width, upper[size]-lower[size])
+	}
+	return width
+}
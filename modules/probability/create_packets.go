@@ -3,6 +3,7 @@ package probability
 import (
 	"key_recovery/modules/errors"
 	"key_recovery/modules/utils"
+	randm "math/rand"
 )
 
 // Creates packets for shares for people
@@ -10,7 +11,8 @@ import (
 // When the percentage is changed, then the absolute threshold of
 // the leaves layer is changed
 func CreatePeoplePackets(layers, threshold, trustees, anonymity,
-	layerPacketsNum int) ([][]int, map[int]map[int][]int, error) {
+	layerPacketsNum int,
+	rng *randm.Rand) ([][]int, map[int]map[int][]int, error) {
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
 	}
@@ -29,7 +31,7 @@ func CreatePeoplePackets(layers, threshold, trustees, anonymity,
 	totalTrusteeData := append(leavesLayer, extraRandomTrusteeData...)
 	offset += totalTrusteeDataNums - totalShares
 	sharePackets := utils.GetSizedRandomPackets(totalTrusteeData, trustees,
-		packetsPerTrustee)
+		packetsPerTrustee, rng)
 	peoplePackets = append(peoplePackets, sharePackets...)
 	// fmt.Println("Packets per trustees", packetsPerTrustee)
 	if anonymity > trustees {
@@ -38,7 +40,7 @@ func CreatePeoplePackets(layers, threshold, trustees, anonymity,
 		anonymityData := utils.GenerateOffsettedIndicesSet(additionalPackets,
 			offset)
 		anonymityPackets := utils.GetSizedRandomPackets(anonymityData, anonymity-trustees,
-			packetsPerTrustee)
+			packetsPerTrustee, rng)
 		peoplePackets = append(peoplePackets, anonymityPackets...)
 	}
 	// fmt.Println("Leaves layer", layerWiseChildren, (leavesLayer))
@@ -52,7 +54,7 @@ func CreatePeoplePackets(layers, threshold, trustees, anonymity,
 // This function works for the additive version and the thresholded version
 // of the subsecrets
 func CreatePeoplePacketsFixedTh(layers, threshold, trustees, anonymity,
-	subsecretsNum, sharesNum int) ([][]int,
+	subsecretsNum, sharesNum int, rng *randm.Rand) ([][]int,
 	map[int]map[int][]int, error) {
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -79,7 +81,7 @@ func CreatePeoplePacketsFixedTh(layers, threshold, trustees, anonymity,
 	offset += totalTrusteeDataNums - totalShares
 	// Create packets of a constant size with random shares
 	sharePackets := utils.GetSizedRandomPackets(totalTrusteeData, trustees,
-		packetsPerTrustee)
+		packetsPerTrustee, rng)
 	peoplePackets = append(peoplePackets, sharePackets...)
 	// This is for the anonymity set
 	if anonymity > trustees {
@@ -88,13 +90,92 @@ func CreatePeoplePacketsFixedTh(layers, threshold, trustees, anonymity,
 		anonymityData := utils.GenerateOffsettedIndicesSet(additionalPackets,
 			offset)
 		anonymityPackets := utils.GetSizedRandomPackets(anonymityData, anonymity-trustees,
-			packetsPerTrustee)
+			packetsPerTrustee, rng)
 		peoplePackets = append(peoplePackets, anonymityPackets...)
 	}
 	// fmt.Println("Leaves layer", layerWiseChildren, (leavesLayer))
 	return peoplePackets, layerWiseChildren, nil
 }
 
+// Same as CreatePeoplePacketsFixedTh, but the people who are not available
+// have lost their packets if the losses are permanent
+func CreatePeoplePacketsFixedThAvailability(layers, threshold, trustees,
+	anonymity, subsecretsNum, sharesNum int,
+	availability *Availability,
+	rng *randm.Rand) ([][]int, map[int]map[int][]int, error) {
+	if len(availability.Probabilities) != anonymity {
+		return nil, nil, errors.ErrInvalidSliceLength
+	}
+	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(
+		layers, threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	if availability.Permanent {
+		peoplePackets = availability.AvailablePackets(peoplePackets, rng)
+	}
+	return peoplePackets, layerWiseChildren, nil
+}
+
+// Same as CreatePeoplePacketsFixedTh, but the trustees receive the leaves
+// in proportion to their weights (uniformly if there are no weights)
+// The packets of all the people are padded to the size of the largest
+// packet so that they remain indistinguishable
+func CreatePeoplePacketsFixedThWeighted(layers, threshold, trustees,
+	anonymity, subsecretsNum, sharesNum int, weights []int,
+	rng *randm.Rand) ([][]int,
+	map[int]map[int][]int, error) {
+	if threshold > 100 {
+		return nil, nil, errors.ErrInvalidThreshold
+	}
+	if weights == nil {
+		return CreatePeoplePacketsFixedTh(layers, threshold, trustees,
+			anonymity, subsecretsNum, sharesNum, rng)
+	}
+	if len(weights) != trustees {
+		return nil, nil, errors.ErrInvalidSliceLength
+	}
+	var peoplePackets [][]int
+	// Generate the identifiers distributed among the trustees
+	leavesLayer, layerWiseChildren, offset := utils.GenerateProbTreeFixedTh(
+		layers, subsecretsNum, sharesNum)
+	totalShares := len(leavesLayer)
+	personWiseShareDistribution, packetsPerTrustee, err :=
+		utils.GetWeightedPersonWiseShareNumber(weights, totalShares, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Randomize the leaves that the trustees should receive
+	tempLeaves := make([]int, totalShares)
+	copy(tempLeaves, leavesLayer)
+	utils.Shuffle(tempLeaves, rng)
+	currentIndex := 0
+	for _, noOfSharesReceived := range personWiseShareDistribution {
+		var sharePacket []int
+		sharePacket = append(sharePacket,
+			tempLeaves[currentIndex:currentIndex+noOfSharesReceived]...)
+		currentIndex += noOfSharesReceived
+		// Give some random blob to the people with fewer shares
+		extraRandomTrusteeData := utils.GenerateOffsettedIndicesSet(
+			packetsPerTrustee-noOfSharesReceived, offset)
+		offset += packetsPerTrustee - noOfSharesReceived
+		sharePacket = append(sharePacket, extraRandomTrusteeData...)
+		utils.Shuffle(sharePacket, rng)
+		peoplePackets = append(peoplePackets, sharePacket)
+	}
+	// This is for the anonymity set
+	if anonymity > trustees {
+		additionalPeople := anonymity - trustees
+		additionalPackets := additionalPeople * packetsPerTrustee
+		anonymityData := utils.GenerateOffsettedIndicesSet(additionalPackets,
+			offset)
+		anonymityPackets := utils.GetSizedRandomPackets(anonymityData, anonymity-trustees,
+			packetsPerTrustee, rng)
+		peoplePackets = append(peoplePackets, anonymityPackets...)
+	}
+	return peoplePackets, layerWiseChildren, nil
+}
+
 // Creates packets for shares for people
 // The absolute threshold is kept fixed in the leaves layer
 // The percentage change in the threshold changes the number of
@@ -105,14 +186,13 @@ func CreatePeoplePacketsFixedTh(layers, threshold, trustees, anonymity,
 // Specifically, we need the person who holds the corresponding share
 // And, for each trustee, we need to store the hint that they hold
 func CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees, anonymity,
-	subsecretsNum, sharesNum, noOfHints int) ([][]int,
+	subsecretsNum, sharesNum, noOfHints int, rng *randm.Rand) ([][]int,
 	map[int]map[int][]int, map[int]int, map[int]int, error) {
 	if threshold > 100 {
 		return nil, nil, nil, nil, errors.ErrInvalidThreshold
 	}
 	var peoplePackets [][]int
-	sharePersonMap := make(map[int]int)
-	hintPersonMap := make(map[int]int)
+	var sharePersonMap, hintPersonMap map[int]int
 	// Generate the identifiers distributed among the trustees
 	leavesLayer, layerWiseChildren, offset := utils.GenerateProbTreeFixedTh(
 		layers, subsecretsNum, sharesNum)
@@ -134,7 +214,7 @@ func CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees, anonymity,
 	offset += totalTrusteeDataNums - totalShares
 	// Create packets of a constant size with random shares
 	sharePackets := utils.GetSizedRandomPackets(totalTrusteeData, trustees,
-		packetsPerTrustee)
+		packetsPerTrustee, rng)
 	peoplePackets = append(peoplePackets, sharePackets...)
 	// This is for the anonymity set
 	if anonymity > trustees {
@@ -143,9 +223,44 @@ func CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees, anonymity,
 		anonymityData := utils.GenerateOffsettedIndicesSet(additionalPackets,
 			offset)
 		anonymityPackets := utils.GetSizedRandomPackets(anonymityData, anonymity-trustees,
-			packetsPerTrustee)
+			packetsPerTrustee, rng)
 		peoplePackets = append(peoplePackets, anonymityPackets...)
 	}
+	sharePersonMap, hintPersonMap = getHintedMaps(peoplePackets, trustees,
+		noOfHints, rng)
+	return peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, nil
+}
+
+// Same as CreatePeopleHintedTPacketsFixedTh, but the trustees receive the
+// leaves in proportion to their weights (uniformly if there are no weights)
+func CreatePeopleHintedTPacketsFixedThWeighted(layers, threshold, trustees,
+	anonymity, subsecretsNum, sharesNum, noOfHints int, weights []int,
+	rng *randm.Rand) (
+	[][]int, map[int]map[int][]int, map[int]int, map[int]int, error) {
+	if weights == nil {
+		return CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees,
+			anonymity, subsecretsNum, sharesNum, noOfHints, rng)
+	}
+	if noOfHints < 1 || noOfHints > trustees {
+		return nil, nil, nil, nil, errors.ErrInvalidInput
+	}
+	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedThWeighted(
+		layers, threshold, trustees, anonymity, subsecretsNum, sharesNum,
+		weights, rng)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	sharePersonMap, hintPersonMap := getHintedMaps(peoplePackets, trustees,
+		noOfHints, rng)
+	return peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, nil
+}
+
+// Gives the map between a share and the person holding it and the map
+// between a trustee and the hinted trustee whose hint they hold
+func getHintedMaps(peoplePackets [][]int, trustees,
+	noOfHints int, rng *randm.Rand) (map[int]int, map[int]int) {
+	sharePersonMap := make(map[int]int)
+	hintPersonMap := make(map[int]int)
 	// This is for the map between a share and a person
 	// The key is the share and the value is the person
 	for index, packet := range peoplePackets {
@@ -155,7 +270,7 @@ func CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees, anonymity,
 	}
 	// Create a map between the people and the hints they are holding
 	trusteesNums := utils.GenerateIndicesSet(trustees)
-	utils.Shuffle(trusteesNums)
+	utils.Shuffle(trusteesNums, rng)
 	hintedTrustees := trusteesNums[:noOfHints][:]
 	for i := 0; i < trustees; i++ {
 		if hintedTrustees[i%len(hintedTrustees)] != i {
@@ -164,5 +279,29 @@ func CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees, anonymity,
 			hintPersonMap[i] = hintedTrustees[(i+1)%len(hintedTrustees)]
 		}
 	}
-	return peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, nil
+	return sharePersonMap, hintPersonMap
+}
+
+// Same as CreatePeopleHintedTPacketsFixedTh, but the people who are not
+// available have lost their packets (and so their hints) if the losses are
+// permanent
+func CreatePeopleHintedTPacketsFixedThAvailability(layers, threshold,
+	trustees, anonymity, subsecretsNum, sharesNum, noOfHints int,
+	availability *Availability,
+	rng *randm.Rand) ([][]int, map[int]map[int][]int, map[int]int,
+	map[int]int, error) {
+	if len(availability.Probabilities) != anonymity {
+		return nil, nil, nil, nil, errors.ErrInvalidSliceLength
+	}
+	peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap, err :=
+		CreatePeopleHintedTPacketsFixedTh(layers, threshold, trustees,
+			anonymity, subsecretsNum, sharesNum, noOfHints, rng)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if availability.Permanent {
+		peoplePackets = availability.AvailablePackets(peoplePackets, rng)
+	}
+	return peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap,
+		nil
 }
@@ -0,0 +1,262 @@
+package probability
+
+import (
+	"key_recovery/modules/errors"
+	"key_recovery/modules/utils"
+	"math/big"
+)
+
+// ***********************Exact***********************
+// The simulators estimate the CDF of the number of people contacted by
+// running the recovery on random packets
+// Here the same CDF is computed exactly for the trees of
+// CreatePeoplePacketsFixedTh
+// The leaves and the random blobs are spread uniformly over the slots of the
+// trustees, so contacting k trustees gives k * packetsPerTrustee slots drawn
+// without replacement from all the slots of the trustees
+// The number of ways of drawing the leaves for which a node is recovered is
+// counted with a dynamic programming over the children of the node (which
+// are all alike) and the number of recovered children
+// The number of trustees among the first c contacts is hypergeometric, which
+// gives the CDF over the anonymity set
+
+// ExactCDF holds the probability that the secret is recovered after
+// contacting at most k trustees (Trustees[k]) or at most c people of the
+// anonymity set (Contacts[c])
+// Index 0 is for nobody contacted
+type ExactCDF struct {
+	Trustees []float64
+	Contacts []float64
+}
+
+// GetAdditiveExactCDF is the exact version of
+// GetAdditiveProbabilityFixedThTotalCDF
+func GetAdditiveExactCDF(layers, threshold, trustees, anonymity,
+	absoluteThreshold, subsecretsNum int) (*ExactCDF, error) {
+	return getExactCDF(layers, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum, subsecretsNum)
+}
+
+// GetThresholdedExactCDF is the exact version of
+// GetThresholdedProbabilityFixedThTotalCDF
+func GetThresholdedExactCDF(layers, threshold, upperThreshold, trustees,
+	anonymity, absoluteThreshold, subsecretsNum int) (*ExactCDF, error) {
+	if upperThreshold > 100 {
+		return nil, errors.ErrInvalidThreshold
+	}
+	upperLayerThreshold := utils.FloorDivide(upperThreshold*subsecretsNum, 100)
+	return getExactCDF(layers, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum, upperLayerThreshold)
+}
+
+func getExactCDF(layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum, upperLayerThreshold int) (*ExactCDF, error) {
+	// The percentage threshold should not be greater than 100%
+	if threshold <= 0 || threshold > 100 {
+		return nil, errors.ErrInvalidThreshold
+	}
+	if layers < 2 || trustees < 1 || anonymity < trustees ||
+		subsecretsNum < 1 {
+		return nil, errors.ErrInvalidInput
+	}
+	// Same leaves as in the simulators
+	sharesNum := utils.FloorDivide((absoluteThreshold * 100), threshold)
+	leavesLayerThreshold := absoluteThreshold
+	if leavesLayerThreshold < 1 || upperLayerThreshold < 1 ||
+		upperLayerThreshold > subsecretsNum {
+		return nil, errors.ErrInvalidThreshold
+	}
+
+	recovered, notRecovered := leafParentWays(sharesNum, leavesLayerThreshold)
+	for i := 0; i < layers-1; i++ {
+		recovered, notRecovered = nodeWays(recovered, notRecovered,
+			subsecretsNum, upperLayerThreshold)
+	}
+
+	totalShares := len(recovered) - 1
+	packetsPerTrustee := utils.CeilDivide(totalShares, trustees)
+	totalSlots := packetsPerTrustee * trustees
+	blobs := totalSlots - totalShares
+
+	cdf := &ExactCDF{
+		Trustees: make([]float64, trustees+1),
+		Contacts: make([]float64, anonymity+1),
+	}
+	trusteesCDF := make([]*big.Rat, trustees+1)
+	trusteesCDF[0] = new(big.Rat)
+	for k := 1; k <= trustees; k++ {
+		slots := k * packetsPerTrustee
+		ways := new(big.Int)
+		term := new(big.Int)
+		for x, w := range recovered {
+			if w.Sign() == 0 || x > slots || slots-x > blobs {
+				continue
+			}
+			term.Mul(w, binomial(blobs, slots-x))
+			ways.Add(ways, term)
+		}
+		trusteesCDF[k] = new(big.Rat).SetFrac(ways, binomial(totalSlots, slots))
+		cdf.Trustees[k], _ = trusteesCDF[k].Float64()
+	}
+	// The contacts are a random order of the anonymity set, so the number
+	// of trustees among the first c people is hypergeometric
+	for c := 1; c <= anonymity; c++ {
+		sum := new(big.Rat)
+		for j := 1; j <= trustees && j <= c; j++ {
+			if c-j > anonymity-trustees {
+				continue
+			}
+			ways := new(big.Int).Mul(binomial(trustees, j),
+				binomial(anonymity-trustees, c-j))
+			p := new(big.Rat).SetFrac(ways, binomial(anonymity, c))
+			sum.Add(sum, p.Mul(p, trusteesCDF[j]))
+		}
+		cdf.Contacts[c], _ = sum.Float64()
+	}
+	return cdf, nil
+}
+
+// Ways of obtaining x of the leaves of a subsecret (indexed by x) for which
+// the subsecret is recovered and for which it is not
+func leafParentWays(sharesNum, leavesLayerThreshold int) ([]*big.Int,
+	[]*big.Int) {
+	recovered := make([]*big.Int, sharesNum+1)
+	notRecovered := make([]*big.Int, sharesNum+1)
+	for x := range recovered {
+		recovered[x] = new(big.Int)
+		notRecovered[x] = new(big.Int)
+		if x >= leavesLayerThreshold {
+			recovered[x] = binomial(sharesNum, x)
+		} else {
+			notRecovered[x] = binomial(sharesNum, x)
+		}
+	}
+	return recovered, notRecovered
+}
+
+// Gives the ways for a node with childrenNum children, each of which has the
+// given ways, where the node is recovered with layerThreshold of its children
+func nodeWays(recovered, notRecovered []*big.Int, childrenNum,
+	layerThreshold int) ([]*big.Int, []*big.Int) {
+	// byRecovered[g] are the ways in which g children are recovered
+	// All the counts from layerThreshold onwards are kept in the last entry
+	byRecovered := make([][]*big.Int, layerThreshold+1)
+	byRecovered[0] = []*big.Int{big.NewInt(1)}
+	for i := 0; i < childrenNum; i++ {
+		next := make([][]*big.Int, layerThreshold+1)
+		for g, ways := range byRecovered {
+			if ways == nil {
+				continue
+			}
+			next[g] = addPolynomials(next[g], multiplyPolynomials(ways,
+				notRecovered))
+			up := g + 1
+			if up > layerThreshold {
+				up = layerThreshold
+			}
+			next[up] = addPolynomials(next[up], multiplyPolynomials(ways,
+				recovered))
+		}
+		byRecovered = next
+	}
+	degree := childrenNum * (len(recovered) - 1)
+	nodeRecovered := make([]*big.Int, degree+1)
+	nodeNotRecovered := make([]*big.Int, degree+1)
+	for x := 0; x <= degree; x++ {
+		nodeRecovered[x] = new(big.Int)
+		nodeNotRecovered[x] = new(big.Int)
+	}
+	for g, ways := range byRecovered {
+		target := nodeNotRecovered
+		if g == layerThreshold {
+			target = nodeRecovered
+		}
+		for x, w := range ways {
+			target[x].Add(target[x], w)
+		}
+	}
+	return nodeRecovered, nodeNotRecovered
+}
+
+func multiplyPolynomials(a, b []*big.Int) []*big.Int {
+	product := make([]*big.Int, len(a)+len(b)-1)
+	for i := range product {
+		product[i] = new(big.Int)
+	}
+	term := new(big.Int)
+	for i, x := range a {
+		if x.Sign() == 0 {
+			continue
+		}
+		for j, y := range b {
+			if y.Sign() == 0 {
+				continue
+			}
+			product[i+j].Add(product[i+j], term.Mul(x, y))
+		}
+	}
+	return product
+}
+
+func addPolynomials(a, b []*big.Int) []*big.Int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	sum := make([]*big.Int, len(a))
+	for i := range a {
+		sum[i] = new(big.Int).Set(a[i])
+		if i < len(b) {
+			sum[i].Add(sum[i], b[i])
+		}
+	}
+	return sum
+}
+
+func binomial(n, k int) *big.Int {
+	if k < 0 || k > n {
+		return new(big.Int)
+	}
+	return new(big.Int).Binomial(int64(n), int64(k))
+}
+
+// CDFDeviation is a point of a simulated CDF whose confidence interval does
+// not contain the exact value
+type CDFDeviation struct {
+	Size      int
+	Exact     float64
+	Simulated float64
+	Lower     float64
+	Upper     float64
+}
+
+// CrossCheck compares the counts of a simulator over the anonymity set (the
+// second map returned by the CDF simulators) with the exact CDF
+// Every point of the simulated CDF gets a Wilson interval with the given z
+// score and the points whose interval misses the exact value are returned
+func (cdf *ExactCDF) CrossCheck(resultsAnon map[int]int,
+	z float64) []CDFDeviation {
+	total := 0
+	for _, cases := range resultsAnon {
+		total += cases
+	}
+	var deviations []CDFDeviation
+	if total == 0 {
+		return deviations
+	}
+	cumulative := 0
+	for size := 1; size < len(cdf.Contacts); size++ {
+		cumulative += resultsAnon[size]
+		lower, upper := WilsonInterval(cumulative, total, z)
+		exact := cdf.Contacts[size]
+		if exact < lower || exact > upper {
+			deviations = append(deviations, CDFDeviation{
+				Size:      size,
+				Exact:     exact,
+				Simulated: float64(cumulative) / float64(total),
+				Lower:     lower,
+				Upper:     upper,
+			})
+		}
+	}
+	return deviations
+}
@@ -4,6 +4,7 @@ import (
 	"key_recovery/modules/errors"
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 )
 
 // ***********************Numwise***********************
@@ -14,7 +15,8 @@ import (
 // This function provides the values for a CDF
 func GetAdditiveProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 	trustees, anonymity, absoluteThreshold,
-	higherLayerPacketsNum int) (map[int]int, map[int]int, error) {
+	higherLayerPacketsNum int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -27,7 +29,7 @@ func GetAdditiveProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
 		threshold, trustees, anonymity, higherLayerPacketsNum,
-		leavesLayerPacketsNum)
+		leavesLayerPacketsNum, rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -46,7 +48,7 @@ func GetAdditiveProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 		for i := 0; i < simulations; i++ {
 			isSuccess := NumwiseRecovery(peoplePackets,
 				layerWiseChildren, layers, higherLayerPacketsNum, trustees,
-				leavesLayerThreshold, runSize)
+				leavesLayerThreshold, runSize, rng)
 			if isSuccess >= 0 {
 				results_anon[runSize] += 1
 			}
@@ -56,7 +58,7 @@ func GetAdditiveProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 		for i := 0; i < simulations; i++ {
 			isSuccess := NumwiseRecoveryTrustees(peoplePackets,
 				layerWiseChildren, layers, higherLayerPacketsNum, trustees,
-				leavesLayerThreshold, runSize)
+				leavesLayerThreshold, runSize, rng)
 			if isSuccess >= 0 {
 				results[runSize] += 1
 			}
@@ -69,7 +71,7 @@ func GetAdditiveCompWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, th
 	trustees, anonymity, absoluteThreshold,
 	higherLayerPacketsNum int,
 	deltaTr, deltaNonTr uint16,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -82,7 +84,7 @@ func GetAdditiveCompWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, th
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
 		threshold, trustees, anonymity, higherLayerPacketsNum,
-		leavesLayerPacketsNum)
+		leavesLayerPacketsNum, rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -102,7 +104,7 @@ func GetAdditiveCompWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, th
 			isSuccess := NumwiseCompWBAdvObtRecovery(peoplePackets,
 				layerWiseChildren, layers, higherLayerPacketsNum, trustees,
 				leavesLayerThreshold, runSize, deltaTr, deltaNonTr, obtProb,
-				wbProb)
+				wbProb, rng)
 			if isSuccess >= 0 {
 				results_anon[runSize] += 1
 			}
@@ -114,7 +116,7 @@ func GetAdditiveCompWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, th
 func GetAdditiveWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 	trustees, anonymity, absoluteThreshold,
 	higherLayerPacketsNum int,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -127,7 +129,7 @@ func GetAdditiveWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, thresh
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
 		threshold, trustees, anonymity, higherLayerPacketsNum,
-		leavesLayerPacketsNum)
+		leavesLayerPacketsNum, rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -146,7 +148,7 @@ func GetAdditiveWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, thresh
 		for i := 0; i < simulations; i++ {
 			isSuccess := NumwiseWBAdvObtRecovery(peoplePackets,
 				layerWiseChildren, layers, higherLayerPacketsNum, trustees,
-				leavesLayerThreshold, runSize, obtProb, wbProb)
+				leavesLayerThreshold, runSize, obtProb, wbProb, rng)
 			if isSuccess >= 0 {
 				results_anon[runSize] += 1
 			}
@@ -162,7 +164,8 @@ func GetAdditiveWBAdvObtProbabilityFixedThNumwiseCDF(simulations, layers, thresh
 func GetThresholdedProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 	upperThreshold, trustees,
 	anonymity, absoluteThreshold,
-	higherLayerPacketsNum int) (map[int]int, map[int]int, error) {
+	higherLayerPacketsNum int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 
@@ -178,7 +181,7 @@ func GetThresholdedProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
 		threshold, trustees, anonymity, higherLayerPacketsNum,
-		leavesLayerPacketsNum)
+		leavesLayerPacketsNum, rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -198,7 +201,8 @@ func GetThresholdedProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 		for i := 0; i < simulations; i++ {
 			isSuccess := NumwiseRecovery(peoplePackets,
 				layerWiseChildren, layers,
-				upperLayerThreshold, trustees, leavesLayerThreshold, runSize)
+				upperLayerThreshold, trustees, leavesLayerThreshold, runSize,
+				rng)
 			if isSuccess >= 0 {
 				results[runSize] += 1
 				// // totalNum is meant more for the anonymity set
@@ -221,7 +225,8 @@ func GetThresholdedProbabilityFixedThNumwiseCDF(simulations, layers, threshold,
 // only set for the leaves layer
 func GetAdditiveProbabilityFixedThNumwise(simulations, layers, threshold, trustees,
 	anonymity, absoluteThreshold,
-	higherLayerPacketsNum int) (map[int]int, map[int]int, error) {
+	higherLayerPacketsNum int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -234,7 +239,7 @@ func GetAdditiveProbabilityFixedThNumwise(simulations, layers, threshold, truste
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
 		threshold, trustees, anonymity, higherLayerPacketsNum,
-		leavesLayerPacketsNum)
+		leavesLayerPacketsNum, rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -253,7 +258,7 @@ func GetAdditiveProbabilityFixedThNumwise(simulations, layers, threshold, truste
 		for i := 0; i < simulations; i++ {
 			isSuccess := NumwiseRecovery(peoplePackets,
 				layerWiseChildren, layers, higherLayerPacketsNum, trustees,
-				leavesLayerThreshold, runSize)
+				leavesLayerThreshold, runSize, rng)
 			if isSuccess > 0 {
 				results[runSize] += 1
 				results_anon[runSize] += 1
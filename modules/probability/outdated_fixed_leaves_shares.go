@@ -3,6 +3,7 @@ package probability
 import (
 	"key_recovery/modules/errors"
 	"log"
+	randm "math/rand"
 )
 
 // ***********************PDF***********************
@@ -13,7 +14,7 @@ import (
 // The number of shares in the leaves layer is fixed
 func GetSimpleProbability(simulations, layers, threshold, trustees,
 	anonymity, largestShareSetSize, smallestShareSetSize,
-	layerPacketsNum int) (map[int]int, map[int]int, error) {
+	layerPacketsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	// for i := 0; i < trustees; i++ {
@@ -25,7 +26,7 @@ func GetSimpleProbability(simulations, layers, threshold, trustees,
 	}
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePackets(layers,
-		threshold, trustees, anonymity, layerPacketsNum)
+		threshold, trustees, anonymity, layerPacketsNum, rng)
 	// fmt.Println("******", len(peoplePackets))
 	if err != nil {
 		log.Fatal(err)
@@ -50,7 +51,7 @@ func GetSimpleProbability(simulations, layers, threshold, trustees,
 		// fmt.Println(i, "Threshold", leavesLayerThreshold)
 		TotalRecovery(peoplePackets, layerWiseChildren, layers,
 			layerPacketsNum, trustees, leavesLayerThreshold,
-			results, results_anon)
+			results, results_anon, rng)
 		// fmt.Println(i)
 	}
 	return results, results_anon, nil
@@ -63,7 +64,7 @@ func GetSimpleProbability(simulations, layers, threshold, trustees,
 // only set for the leaves layer
 func GetThresholdedProbability(simulations, layers, threshold, upperThreshold,
 	trustees, anonymity, largestShareSetSize, smallestShareSetSize,
-	layerPacketsNum int) (map[int]int, map[int]int, error) {
+	layerPacketsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	// for i := 0; i < trustees; i++ {
@@ -75,7 +76,7 @@ func GetThresholdedProbability(simulations, layers, threshold, upperThreshold,
 	}
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePackets(layers,
-		threshold, trustees, anonymity, layerPacketsNum)
+		threshold, trustees, anonymity, layerPacketsNum, rng)
 	// fmt.Println("******", len(peoplePackets))
 	if err != nil {
 		log.Fatal(err)
@@ -95,7 +96,7 @@ func GetThresholdedProbability(simulations, layers, threshold, upperThreshold,
 		// fmt.Println(i, "Threshold", leavesLayerThreshold)
 		TotalRecovery(peoplePackets, layerWiseChildren, layers,
 			upperLayerThreshold, trustees, leavesLayerThreshold,
-			results, results_anon)
+			results, results_anon, rng)
 		// fmt.Println(i)
 	}
 	return results, results_anon, nil
@@ -108,7 +109,7 @@ func GetThresholdedProbability(simulations, layers, threshold, upperThreshold,
 // only set for the leaves layer
 func GetSimpleProbabilityNumwiseRun(simulations, layers, threshold, trustees,
 	anonymity, largestShareSetSize, smallestShareSetSize,
-	layerPacketsNum int) (map[int]int, map[int]int, error) {
+	layerPacketsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	// for i := 0; i < trustees; i++ {
@@ -120,7 +121,7 @@ func GetSimpleProbabilityNumwiseRun(simulations, layers, threshold, trustees,
 	}
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePackets(layers,
-		threshold, trustees, anonymity, layerPacketsNum)
+		threshold, trustees, anonymity, layerPacketsNum, rng)
 	// fmt.Println("******", len(peoplePackets))
 	if err != nil {
 		log.Fatal(err)
@@ -147,7 +148,7 @@ func GetSimpleProbabilityNumwiseRun(simulations, layers, threshold, trustees,
 			// fmt.Println(i)
 			// fmt.Println(i, "Threshold", leavesLayerThreshold)
 			isSuccess := NumwiseRecovery(peoplePackets, layerWiseChildren, layers,
-				layerPacketsNum, trustees, leavesLayerThreshold, runSize)
+				layerPacketsNum, trustees, leavesLayerThreshold, runSize, rng)
 			if isSuccess == 1 {
 				results[runSize] += 1
 				results_anon[runSize] += 1
@@ -164,7 +165,7 @@ func GetSimpleProbabilityNumwiseRun(simulations, layers, threshold, trustees,
 // only set for the leaves layer
 func GetSimpleProbabilityNumwiseRunCDF(simulations, layers, threshold, trustees,
 	anonymity, largestShareSetSize, smallestShareSetSize,
-	layerPacketsNum int) (map[int]int, map[int]int, error) {
+	layerPacketsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	// for i := 0; i < trustees; i++ {
@@ -176,7 +177,7 @@ func GetSimpleProbabilityNumwiseRunCDF(simulations, layers, threshold, trustees,
 	}
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePackets(layers,
-		threshold, trustees, anonymity, layerPacketsNum)
+		threshold, trustees, anonymity, layerPacketsNum, rng)
 	// fmt.Println("******", len(peoplePackets))
 	if err != nil {
 		log.Fatal(err)
@@ -203,7 +204,7 @@ func GetSimpleProbabilityNumwiseRunCDF(simulations, layers, threshold, trustees,
 			// fmt.Println(i)
 			// fmt.Println(i, "Threshold", leavesLayerThreshold)
 			isSuccess := NumwiseRecovery(peoplePackets, layerWiseChildren, layers,
-				layerPacketsNum, trustees, leavesLayerThreshold, runSize)
+				layerPacketsNum, trustees, leavesLayerThreshold, runSize, rng)
 			if isSuccess >= 0 {
 				results[runSize] += 1
 				results_anon[runSize] += 1
@@ -221,7 +222,7 @@ func GetSimpleProbabilityNumwiseRunCDF(simulations, layers, threshold, trustees,
 func GetThresholdedProbabilityNumwiseRunCDF(simulations, layers, threshold,
 	upperThreshold, trustees,
 	anonymity, largestShareSetSize, smallestShareSetSize,
-	layerPacketsNum int) (map[int]int, map[int]int, error) {
+	layerPacketsNum int, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	// for i := 0; i < trustees; i++ {
@@ -233,7 +234,7 @@ func GetThresholdedProbabilityNumwiseRunCDF(simulations, layers, threshold,
 	}
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePackets(layers,
-		threshold, trustees, anonymity, layerPacketsNum)
+		threshold, trustees, anonymity, layerPacketsNum, rng)
 	// fmt.Println("******", len(peoplePackets))
 	if err != nil {
 		log.Fatal(err)
@@ -262,7 +263,8 @@ func GetThresholdedProbabilityNumwiseRunCDF(simulations, layers, threshold,
 			// fmt.Println(i, "Threshold", leavesLayerThreshold)
 			isSuccess := NumwiseRecovery(peoplePackets,
 				layerWiseChildren, layers,
-				upperLayerThreshold, trustees, leavesLayerThreshold, runSize)
+				upperLayerThreshold, trustees, leavesLayerThreshold, runSize,
+				rng)
 			if isSuccess >= 0 {
 				results[runSize] += 1
 				results_anon[runSize] += 1
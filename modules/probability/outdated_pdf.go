@@ -3,6 +3,7 @@ package probability
 import (
 	"key_recovery/modules/errors"
 	"log"
+	randm "math/rand"
 )
 
 // ***********************Total***********************
@@ -11,7 +12,8 @@ import (
 // only set for the leaves layer
 func GetAdditiveProbabilityFixedTh(simulations, layers, threshold, trustees,
 	anonymity, absoluteThreshold,
-	higherLayerPacketsNum int) (map[int]int, map[int]int, error) {
+	higherLayerPacketsNum int,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	results := make(map[int]int)
 	results_anon := make(map[int]int)
 	for i := 0; i < anonymity; i++ {
@@ -24,7 +26,8 @@ func GetAdditiveProbabilityFixedTh(simulations, layers, threshold, trustees,
 
 	// Obtain the packets to be distributed among people
 	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-		threshold, trustees, anonymity, higherLayerPacketsNum, leavesLayerPacketsNum)
+		threshold, trustees, anonymity, higherLayerPacketsNum, leavesLayerPacketsNum,
+		rng)
 
 	if err != nil {
 		log.Fatal(err)
@@ -43,7 +46,7 @@ func GetAdditiveProbabilityFixedTh(simulations, layers, threshold, trustees,
 	for i := 0; i < simulations; i++ {
 		TotalRecovery(peoplePackets, layerWiseChildren, layers,
 			higherLayerPacketsNum, trustees, leavesLayerThreshold,
-			results, results_anon)
+			results, results_anon, rng)
 	}
 	return results, results_anon, nil
 }
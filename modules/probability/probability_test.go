@@ -2,11 +2,17 @@ package probability
 
 import (
 	"fmt"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
+	"math"
+	randm "math/rand"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGetSimpleProbability(t *testing.T) {
+	rng := randomness.NewRand()
 	testCases := []struct {
 		s    int
 		l    int
@@ -22,7 +28,7 @@ func TestGetSimpleProbability(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		results, results_anon, err := GetSimpleProbability(tc.s, tc.l, tc.th, tc.tr, tc.a,
-			tc.lsss, tc.ssss, tc.lpn)
+			tc.lsss, tc.ssss, tc.lpn, rng)
 		if err != nil {
 			t.Error(err)
 		} else {
@@ -45,6 +51,7 @@ func TestGetSimpleProbability(t *testing.T) {
 }
 
 func TestCreatePeoplePackets(t *testing.T) {
+	rng := randomness.NewRand()
 	testCases := []struct {
 		s   int
 		l   int
@@ -57,7 +64,7 @@ func TestCreatePeoplePackets(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		output, layerWiseChildren, err := CreatePeoplePackets(tc.l, tc.th,
-			tc.tr, tc.a, tc.lpn)
+			tc.tr, tc.a, tc.lpn, rng)
 		if err != nil {
 			t.Error(err)
 		} else {
@@ -120,6 +127,7 @@ func TestCreatePeoplePackets(t *testing.T) {
 // }
 
 func TestRecoverySimulation(t *testing.T) {
+	rng := randomness.NewRand()
 	// Generate the data over which the simulations will be run
 	// simulationsDist := 1000
 	// simulationsRun := 10
@@ -133,7 +141,7 @@ func TestRecoverySimulation(t *testing.T) {
 	// layerWiseChildren[1][2] = []int{3, 4, 5}
 	sharesNum := utils.FloorDivide((tc.at * 100), tc.th)
 	_, layerWiseChildren, _ := CreatePeoplePacketsFixedTh(tc.l,
-		tc.th, tc.tr, tc.a, tc.hlpn, sharesNum)
+		tc.th, tc.tr, tc.a, tc.hlpn, sharesNum, rng)
 	fmt.Println(layerWiseChildren)
 	peoplePacketsComb := make([][][]int, 0)
 	tempPacket := make([][]int, 0)
@@ -236,3 +244,567 @@ func TestRecoverySimulation(t *testing.T) {
 	}
 	fmt.Println(results_anon)
 }
+
+func TestCreatePeoplePacketsFixedThWeighted(t *testing.T) {
+	rng := randomness.NewRand()
+	layers, threshold, trustees, anonymity, subsecretsNum, sharesNum :=
+		2, 50, 4, 10, 3, 6
+	weights := []int{3, 1, 1, 1}
+	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedThWeighted(
+		layers, threshold, trustees, anonymity, subsecretsNum, sharesNum,
+		weights, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peoplePackets) != anonymity {
+		t.Fatal("Wrong number of packets", len(peoplePackets))
+	}
+	// Every leaf is held by exactly one trustee
+	leaves := make(map[int]int)
+	for _, children := range layerWiseChildren[layers-1] {
+		for _, child := range children {
+			leaves[child] = 0
+		}
+	}
+	if len(leaves) != subsecretsNum*sharesNum {
+		t.Fatal("Wrong number of leaves", len(leaves))
+	}
+	for i, packet := range peoplePackets {
+		if len(packet) != len(peoplePackets[0]) {
+			t.Error("Packets are distinguishable", len(packet))
+		}
+		for _, share := range packet {
+			if _, ok := leaves[share]; ok {
+				if i >= trustees {
+					t.Error("Leaf given to a person outside the trustees")
+				}
+				leaves[share]++
+			}
+		}
+	}
+	for leaf, count := range leaves {
+		if count != 1 {
+			t.Error("Leaf not held by exactly one trustee", leaf, count)
+		}
+	}
+	if _, _, err := CreatePeoplePacketsFixedThWeighted(layers, threshold,
+		trustees, anonymity, subsecretsNum, sharesNum, []int{1, 1}, rng); err == nil {
+		t.Error("Wrong number of weights accepted")
+	}
+	// The hints point to the trustees and the shares to the people
+	peoplePackets, _, sharePersonMap, hintPersonMap, err :=
+		CreatePeopleHintedTPacketsFixedThWeighted(layers, threshold, trustees,
+			anonymity, subsecretsNum, sharesNum, 2, weights, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, packet := range peoplePackets {
+		for _, share := range packet {
+			if sharePersonMap[share] != i {
+				t.Error("Share mapped to the wrong person", share)
+			}
+		}
+	}
+	if len(hintPersonMap) != trustees {
+		t.Error("Wrong number of hints", hintPersonMap)
+	}
+	for trustee, hinted := range hintPersonMap {
+		if hinted == trustee || hinted >= trustees {
+			t.Error("Wrong hint", trustee, hinted)
+		}
+	}
+}
+
+func TestSeededSimulation(t *testing.T) {
+	simulate := func() (map[int]int, map[int]int) {
+		rng := randm.New(randm.NewSource(11))
+		results, resultsAnon, err := GetAdditiveProbabilityFixedThTotalCDFParallelized(
+			4, 20, 2, 50, 4, 10, 2, 3, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results, resultsAnon
+	}
+	results1, resultsAnon1 := simulate()
+	results2, resultsAnon2 := simulate()
+	if !reflect.DeepEqual(results1, results2) ||
+		!reflect.DeepEqual(resultsAnon1, resultsAnon2) {
+		t.Error("Simulation not reproduced with the same seed")
+	}
+}
+
+func TestExactCDF(t *testing.T) {
+	threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		50, 4, 10, 2, 3
+	for _, layers := range []int{2, 3} {
+		// The simulations are seeded, so the cross checks do not fail at
+		// random
+		rng := randm.New(randm.NewSource(int64(layers)))
+		additive, err := GetAdditiveExactCDF(layers, threshold, trustees,
+			anonymity, absoluteThreshold, subsecretsNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		thresholded, err := GetThresholdedExactCDF(layers, threshold, 60,
+			trustees, anonymity, absoluteThreshold, subsecretsNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cdf := range []*ExactCDF{additive, thresholded} {
+			// All the leaves are obtained from all the trustees
+			if math.Abs(cdf.Trustees[trustees]-1) > 1e-12 ||
+				math.Abs(cdf.Contacts[anonymity]-1) > 1e-12 {
+				t.Error("Secret not recovered from everyone", layers, cdf)
+			}
+			for i := 1; i < len(cdf.Contacts); i++ {
+				if cdf.Contacts[i] < cdf.Contacts[i-1] {
+					t.Error("CDF is not increasing", layers, cdf.Contacts)
+				}
+			}
+		}
+		// Fewer subsecrets are needed in the thresholded tree
+		for k := range additive.Trustees {
+			if thresholded.Trustees[k] < additive.Trustees[k] {
+				t.Error("Thresholded tree needs more trustees", layers, k)
+			}
+		}
+
+		// The runs on the same packets are not independent, so every run
+		// gets its own packets
+		_, resultsAnon, err := GetAdditiveProbabilityFixedThTotalCDFParallelized(
+			3000, 1, layers, threshold, trustees, anonymity, absoluteThreshold,
+			subsecretsNum, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deviations := additive.CrossCheck(resultsAnon, 4); len(deviations) != 0 {
+			t.Error("Simulation does not match the exact CDF", layers,
+				deviations)
+		}
+		_, resultsAnon, err = GetThresholdedProbabilityFixedThTotalCDFParallelized(
+			3000, 1, layers, threshold, 60, trustees, anonymity,
+			absoluteThreshold, subsecretsNum, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deviations := thresholded.CrossCheck(resultsAnon, 4); len(deviations) != 0 {
+			t.Error("Simulation does not match the exact CDF", layers,
+				deviations)
+		}
+	}
+	// A deeper tree needs more trustees
+	two, _ := GetAdditiveExactCDF(2, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum)
+	three, _ := GetAdditiveExactCDF(3, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum)
+	for k := 1; k < trustees; k++ {
+		if three.Trustees[k] > two.Trustees[k] {
+			t.Error("Deeper tree needs fewer trustees", k)
+		}
+	}
+	if _, err := GetAdditiveExactCDF(2, 120, trustees, anonymity,
+		absoluteThreshold, subsecretsNum); err == nil {
+		t.Error("Invalid threshold accepted")
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	lower, upper := WilsonInterval(50, 100, 1.96)
+	if math.Abs(lower-0.4038) > 1e-3 || math.Abs(upper-0.5962) > 1e-3 {
+		t.Error("Wrong interval", lower, upper)
+	}
+	lower, upper = WilsonInterval(0, 10, 1.96)
+	if lower != 0 || upper <= 0 {
+		t.Error("Wrong interval for no successes", lower, upper)
+	}
+}
+
+func TestClopperPearsonInterval(t *testing.T) {
+	lower, upper := ClopperPearsonInterval(5, 10, 0.95)
+	if math.Abs(lower-0.1871) > 1e-3 || math.Abs(upper-0.8129) > 1e-3 {
+		t.Error("Wrong interval", lower, upper)
+	}
+	lower, upper = ClopperPearsonInterval(0, 10, 0.95)
+	if lower != 0 || math.Abs(upper-0.3085) > 1e-3 {
+		t.Error("Wrong interval for no successes", lower, upper)
+	}
+	// The exact interval is wider than the Wilson interval
+	wilsonLower, wilsonUpper := WilsonInterval(4000, 10000, ZScore(0.95))
+	lower, upper = ClopperPearsonInterval(4000, 10000, 0.95)
+	if lower > wilsonLower || upper < wilsonUpper {
+		t.Error("Clopper-Pearson is narrower than Wilson", lower, upper,
+			wilsonLower, wilsonUpper)
+	}
+	if _, err := NewInterval("normal", 0.95); err == nil {
+		t.Error("Unknown interval accepted")
+	}
+}
+
+func TestRunAdaptive(t *testing.T) {
+	interval, err := NewInterval(IntervalWilson, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches := 0
+	batch := func() (map[int]int, map[int]int, error) {
+		batches++
+		return map[int]int{1: 50, 2: 50}, map[int]int{1: 20, 2: 80}, nil
+	}
+	results, resultsAnon, err := RunAdaptive(batch, AdaptiveControl{
+		Interval:    interval,
+		TargetWidth: 0.05,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if MaxIntervalWidth(results, interval) > 0.05 ||
+		MaxIntervalWidth(resultsAnon, interval) > 0.05 {
+		t.Error("Stopped before the target width")
+	}
+	// A point at 0.5 needs about 1500 runs for a width of 0.05
+	if batches < 10 || results[1] != 50*batches {
+		t.Error("Wrong number of batches", batches, results)
+	}
+	batches = 0
+	RunAdaptive(batch, AdaptiveControl{Interval: interval})
+	if batches != 1 {
+		t.Error("More than one batch without a target", batches)
+	}
+	// The time budget stops a target that is never reached
+	batches = 0
+	RunAdaptive(batch, AdaptiveControl{Interval: interval, TargetWidth: 1e-9,
+		TimeBudget: time.Nanosecond})
+	if batches != 1 {
+		t.Error("Time budget not respected", batches)
+	}
+
+	// The simulations are seeded, so the same batches are run again and the
+	// intervals are checked against the exact CDF without failing at random
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		2, 50, 4, 10, 2, 3
+	simulate := func() (map[int]int, map[int]int, int) {
+		rng := randm.New(randm.NewSource(21))
+		batches := 0
+		results, resultsAnon, err := RunAdaptive(func() (map[int]int,
+			map[int]int, error) {
+			batches++
+			return GetAdditiveProbabilityFixedThTotalCDFParallelized(100, 1,
+				layers, threshold, trustees, anonymity, absoluteThreshold,
+				subsecretsNum, rng)
+		}, AdaptiveControl{Interval: interval, TargetWidth: 0.1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results, resultsAnon, batches
+	}
+	results, resultsAnon, batches = simulate()
+	_, resultsAnonAgain, batchesAgain := simulate()
+	if batches != batchesAgain || !reflect.DeepEqual(resultsAnon, resultsAnonAgain) {
+		t.Error("Adaptive run not reproduced with the same seed", batches,
+			batchesAgain)
+	}
+	if MaxIntervalWidth(results, interval) > 0.1 ||
+		MaxIntervalWidth(resultsAnon, interval) > 0.1 {
+		t.Error("Stopped before the target width")
+	}
+	cdf, err := GetAdditiveExactCDF(layers, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, err := NewInterval(IntervalClopperPearson, 0.9999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, lower, upper := CDFIntervals(resultsAnon, anonymity, wide)
+	for size := 1; size <= anonymity; size++ {
+		if cdf.Contacts[size] < lower[size] || cdf.Contacts[size] > upper[size] {
+			t.Error("Exact CDF outside the interval", size, cdf.Contacts[size],
+				lower[size], upper[size])
+		}
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	// The simulations are seeded, so the cross check does not fail at random
+	rng := randm.New(randm.NewSource(22))
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		2, 50, 4, 10, 2, 3
+	// Everyone answers, which is the same as without the availability
+	availability, err := NewAvailability(trustees, anonymity, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, resultsAnon, failures, err := GetAdditiveProbabilityAvailabilityCDFParallelized(
+		3000, 1, layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, availability, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cdf, err := GetAdditiveExactCDF(layers, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deviations := cdf.CrossCheck(resultsAnon, 4); failures != 0 ||
+		len(deviations) != 0 {
+		t.Error("Full availability does not match the exact CDF", failures,
+			deviations)
+	}
+	// The people outside the trustees do not matter
+	for _, permanent := range []bool{false, true} {
+		availability, err = NewAvailability(trustees, anonymity, 1, 0,
+			permanent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, failures, err = GetHintedTProbabilityAvailabilityCDFParallelized(
+			20, 20, layers, threshold, trustees, anonymity, absoluteThreshold,
+			subsecretsNum, 2, availability, rng)
+		if err != nil || failures != 0 {
+			t.Error("Failures without the non-trustees", failures, err)
+		}
+	}
+	// With permanent losses, all the runs on the same packets fail or none
+	// of them does, while the transient losses only fail some of the runs
+	for _, permanent := range []bool{false, true} {
+		availability, err = NewAvailability(trustees, anonymity, 0.5, 1,
+			permanent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, _, failures, err := GetAdditiveProbabilityAvailabilityCDFParallelized(
+			20, 20, layers, threshold, trustees, anonymity, absoluteThreshold,
+			subsecretsNum, availability, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered := 0
+		for _, cases := range results {
+			recovered += cases
+		}
+		if failures == 0 || failures == 400 || recovered+failures != 400 {
+			t.Error("Wrong failures with half of the trustees", permanent,
+				failures, recovered)
+		}
+		if permanent && failures%20 != 0 {
+			t.Error("Permanent losses differ between the runs", failures)
+		}
+	}
+	// Nothing is recovered if no trustee answers
+	availability, err = NewAvailability(trustees, anonymity, 0, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _, failures, err := GetThresholdedProbabilityAvailabilityCDFParallelized(
+		20, 20, layers, threshold, 60, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, availability, rng)
+	if err != nil || failures != 400 {
+		t.Error("Recovered without the trustees", failures, results, err)
+	}
+	if _, err := NewAvailability(trustees, anonymity, 1.5, 1, false); err == nil {
+		t.Error("Invalid probability accepted")
+	}
+}
+
+func TestTimedRecovery(t *testing.T) {
+	// The simulations are seeded, so the cross check does not fail at random
+	rng := randm.New(randm.NewSource(23))
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		2, 50, 4, 10, 2, 3
+	// With a single contact at a time and answers after one unit of time,
+	// the time is the number of people contacted
+	latency, err := NewLatency(LatencyConstant, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	times, err := GetAdditiveTimedRecoveryParallelized(3000, 1, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum, 1,
+		latency, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultsAnon := make(map[int]int)
+	for _, recoveryTime := range times {
+		resultsAnon[int(math.Round(recoveryTime))] += 1
+	}
+	cdf, err := GetAdditiveExactCDF(layers, threshold, trustees, anonymity,
+		absoluteThreshold, subsecretsNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deviations := cdf.CrossCheck(resultsAnon, 4); len(times) != 3000 ||
+		len(deviations) != 0 {
+		t.Error("Timed recovery does not match the exact CDF", len(times),
+			deviations)
+	}
+	// With constant answers, people are contacted in waves of width, so the
+	// time is the number of waves needed for the people of the same order
+	peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
+		threshold, trustees, anonymity, subsecretsNum,
+		absoluteThreshold*100/threshold, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessOrder := utils.GenerateIndicesSet(anonymity)
+	contacts := RecoverInOrder(peoplePackets, layerWiseChildren, layers,
+		subsecretsNum, absoluteThreshold, accessOrder)
+	for width := 1; width <= anonymity; width++ {
+		recoveryTime, isRecovered := RecoverInTime(peoplePackets,
+			layerWiseChildren, layers, subsecretsNum, absoluteThreshold, nil,
+			nil, accessOrder, width, latency, rng)
+		waves := (contacts + width - 1) / width
+		if !isRecovered || recoveryTime != float64(waves) {
+			t.Error("Wrong time for the width", width, recoveryTime, waves)
+		}
+	}
+	// Contacting everyone at once takes a single response time
+	times, err = GetHintedTTimedRecoveryParallelized(20, 20, layers, threshold,
+		trustees, anonymity, absoluteThreshold, subsecretsNum, 2, anonymity,
+		latency, rng)
+	if err != nil || len(times) != 400 || times[0] != 1 || times[399] != 1 {
+		t.Error("Wrong times when contacting everyone", err, len(times))
+	}
+	latency, err = NewLatency(LatencyExponential, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := GetThresholdedTimedRecoveryParallelized(20, 50, layers,
+		threshold, 60, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		1, latency, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := GetThresholdedTimedRecoveryParallelized(20, 50, layers,
+		threshold, 60, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		4, latency, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fast[len(fast)/2] >= slow[len(slow)/2] {
+		t.Error("Contacting more people at once is not faster",
+			fast[len(fast)/2], slow[len(slow)/2])
+	}
+	if _, err := NewLatency("gamma", 1, 0); err == nil {
+		t.Error("Unknown distribution accepted")
+	}
+}
+
+func TestCoalition(t *testing.T) {
+	// The attacks are seeded, so the comparisons do not fail at random
+	rng := randm.New(randm.NewSource(24))
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		2, 50, 4, 10, 2, 3
+	strategy := HintFollowingStrategy{
+		Base: NoisyPriorStrategy{DeltaTr: 10, DeltaNonTr: 10}}
+	// All the trustees together recover the secret without contacting anyone
+	results, failures, err := GetAdditiveCoalitionCDFParallelized(20, 20,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, trustees, strategy, 100, 100, rng)
+	if err != nil || results[0] != 400 || failures != 0 {
+		t.Error("All the trustees did not recover the secret", results,
+			failures, err)
+	}
+	// Without reports, the secret is always recovered
+	results, failures, err = GetHintedTCoalitionCDFParallelized(20, 20, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum, 2, 0,
+		strategy, 100, 0, rng)
+	if err != nil || failures != 0 {
+		t.Error("Failures without reports", results, failures, err)
+	}
+	// A single packet is not enough before being reported
+	results, failures, err = GetThresholdedCoalitionCDFParallelized(20, 20,
+		layers, threshold, 100, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, 0, strategy, 100, 100, rng)
+	if err != nil || failures != 400 {
+		t.Error("Recovered from a single packet", results, failures, err)
+	}
+	// More insiders recover the secret more often, on the same packets
+	_, alone, err := GetAdditiveCoalitionCDFParallelized(20, 50, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum, 0,
+		strategy, 80, 20, randm.New(randm.NewSource(24)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, together, err := GetAdditiveCoalitionCDFParallelized(20, 50, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum, 2,
+		strategy, 80, 20, randm.New(randm.NewSource(24)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if together >= alone {
+		t.Error("More insiders are reported more often", together, alone)
+	}
+	if _, _, err := GetAdditiveCoalitionCDFParallelized(1, 1, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		trustees+1, strategy, 80, 20, rng); err == nil {
+		t.Error("More insiders than trustees accepted")
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	// The simulations are seeded, so the comparisons do not fail at random
+	rng := randm.New(randm.NewSource(25))
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum :=
+		2, 50, 4, 10, 2, 3
+	// The random order is the one of the exact CDF
+	additive, err := GetAdditiveExactCDF(layers, threshold, trustees,
+		anonymity, absoluteThreshold, subsecretsNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, resultsAnon, err := GetAdditiveProbabilityStrategyCDFParallelized(3000,
+		1, layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, RandomStrategy{}, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deviations := additive.CrossCheck(resultsAnon, 4); len(deviations) != 0 {
+		t.Error("Random order deviates from the exact CDF", deviations)
+	}
+	// Without noise, only the trustees are contacted
+	for _, name := range []string{StrategyNoisyPrior, StrategyBayesian} {
+		strategy, err := NewStrategy(name, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, resultsAnon, err := GetThresholdedProbabilityStrategyCDFParallelized(
+			20, 20, layers, threshold, 60, trustees, anonymity,
+			absoluteThreshold, subsecretsNum, strategy, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for contacts, cases := range resultsAnon {
+			if contacts > trustees && cases != 0 {
+				t.Error(name, "contacted non-trustees", resultsAnon)
+			}
+		}
+	}
+	// The hints spare contacts, on the same packets
+	anonymity = 40
+	meanContacts := func(strategy AccessStrategy) float64 {
+		_, resultsAnon, err := GetHintedTProbabilityStrategyCDFParallelized(50,
+			40, layers, threshold, trustees, anonymity, absoluteThreshold,
+			subsecretsNum, 2, strategy, randm.New(randm.NewSource(25)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum, total := 0, 0
+		for contacts, cases := range resultsAnon {
+			sum += contacts * cases
+			total += cases
+		}
+		return float64(sum) / float64(total)
+	}
+	random := meanContacts(RandomStrategy{})
+	following := meanContacts(HintFollowingStrategy{Base: RandomStrategy{}})
+	if following >= random {
+		t.Error("Following the hints does not help", following, random)
+	}
+	if _, err := NewStrategy("greedy", 0, 0); err == nil {
+		t.Error("Unknown strategy accepted")
+	}
+	if _, err := NewStrategy(StrategyBayesian, 101, 0); err == nil {
+		t.Error("Delta above 100 accepted")
+	}
+}
@@ -4,19 +4,20 @@ import (
 	"fmt"
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 )
 
 // Here, you run an event until the secret is recovered
 func TotalRecovery(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers int,
 	upperLayerThreshold int, trustees int, leavesLayerThreshold int,
-	results map[int]int, results_anon map[int]int) {
+	results map[int]int, results_anon map[int]int, rng *randm.Rand) {
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 	// fmt.Println(layerWiseChildren)
 	// fmt.Println(peoplePackets)
 	// fmt.Println(accessOrder)
@@ -67,13 +68,13 @@ func TotalHintedTRecovery(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
 	upperLayerThreshold, trustees, leavesLayerThreshold int,
 	sharePersonMap, hintPersonMap map[int]int,
-	results map[int]int, results_anon map[int]int) {
+	results map[int]int, results_anon map[int]int, rng *randm.Rand) {
 	var obtainedShares, usedShares, obtainedSubsecrets, usedSubsecrets,
 		peopleContacted, hintedPeople []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 	for index, a := range accessOrder {
 		obtainedLength := index + 1
 		obtainedShares = append(obtainedShares, peoplePackets[a]...)
@@ -122,13 +123,13 @@ func TotalHintedTRecovery(peoplePackets [][]int,
 // contacted by the user
 func NumwiseRecovery(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers int, upperLayerThreshold int,
-	trustees int, leavesLayerThreshold int, runSize int) int {
+	trustees int, leavesLayerThreshold int, runSize int, rng *randm.Rand) int {
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 	for _, a := range accessOrder {
 		// Total set of packets that a user has obtained
 		obtainedShares = append(obtainedShares, peoplePackets[a]...)
@@ -172,14 +173,14 @@ func NumwiseRecovery(peoplePackets [][]int,
 func NumwiseCompWBAdvObtRecovery(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
 	trustees, leavesLayerThreshold, runSize int,
-	deltaTr, deltaNonTr uint16, obtProb, wbProb byte) int {
+	deltaTr, deltaNonTr uint16, obtProb, wbProb byte, rng *randm.Rand) int {
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	actualBitMatrix := utils.GenerateTrNonTrBitMatrix(trustees, noOfPeople)
 	flippedMatrix := utils.FlipBitsWithProbability(actualBitMatrix, deltaTr,
-		deltaNonTr, trustees, noOfPeople)
+		deltaNonTr, trustees, noOfPeople, rng)
 	var firstApproach []int
 	var lastApproach []int
 	for ind, f := range flippedMatrix {
@@ -189,17 +190,17 @@ func NumwiseCompWBAdvObtRecovery(peoplePackets [][]int,
 			lastApproach = append(lastApproach, ind)
 		}
 	}
-	utils.Shuffle(firstApproach)
-	utils.Shuffle(lastApproach)
+	utils.Shuffle(firstApproach, rng)
+	utils.Shuffle(lastApproach, rng)
 	var accessOrder []int
 	accessOrder = append(accessOrder, firstApproach...)
 	accessOrder = append(accessOrder, lastApproach...)
 
-	obtProbs, err := utils.GenerateProbabilityArray(noOfPeople)
+	obtProbs, err := utils.GenerateProbabilityArray(noOfPeople, rng)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	probsWB, err := utils.GenerateProbabilityArray(noOfPeople)
+	probsWB, err := utils.GenerateProbabilityArray(noOfPeople, rng)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -244,19 +245,19 @@ func NumwiseCompWBAdvObtRecovery(peoplePackets [][]int,
 func NumwiseWBAdvObtRecovery(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
 	trustees, leavesLayerThreshold, runSize int,
-	obtProb, wbProb byte) int {
+	obtProb, wbProb byte, rng *randm.Rand) int {
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 
-	obtProbs, err := utils.GenerateProbabilityArray(noOfPeople)
+	obtProbs, err := utils.GenerateProbabilityArray(noOfPeople, rng)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	probsWB, err := utils.GenerateProbabilityArray(noOfPeople)
+	probsWB, err := utils.GenerateProbabilityArray(noOfPeople, rng)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -301,13 +302,13 @@ func NumwiseWBAdvObtRecovery(peoplePackets [][]int,
 
 func NumwiseRecoveryTrustees(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers int, upperLayerThreshold int,
-	trustees int, leavesLayerThreshold int, runSize int) int {
+	trustees int, leavesLayerThreshold int, runSize int, rng *randm.Rand) int {
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 	for _, a := range accessOrder {
 		// Total set of packets that a user has obtained
 		obtainedShares = append(obtainedShares, peoplePackets[a]...)
@@ -451,33 +452,30 @@ func CheckHintedLeavesRecovery(relevantData []int, leavesChildren map[int][]int,
 	return isLeavesRecovered
 }
 
-// Checks if some secret has been recovered in the layer above
+// Checks if some secret has been recovered in the layers above
+// The layers are checked from the bottom up, so that the subsecrets
+// recovered in a layer are used for the layer above right away
 func CheckAboveLayer(layerThreshold int, obtainedSubsecrets *[]int,
 	usedSubsecrets *[]int, layerWiseChildren map[int]map[int][]int,
 	layers int) (bool, []int) {
-	relevantHigherSet := utils.FindDifference(*obtainedSubsecrets,
-		*usedSubsecrets)
 	isSecretRecovered := false
 	var secretsRecovered []int
-	if len(relevantHigherSet) >= layerThreshold {
-		for childLevel, children := range layerWiseChildren {
-			if childLevel == (layers - 1) {
-				continue
-			}
-			// fmt.Println("Hello", childLevel)
-			for key, value := range children {
-				intersection := utils.GetIntersection(relevantHigherSet, value)
-				if len(intersection) >= layerThreshold {
-					isSecretRecovered = true
-					if !utils.IsInSlice(*obtainedSubsecrets, key) {
-						isSecretRecovered = true
-						secretsRecovered = append(secretsRecovered, childLevel)
-						(*obtainedSubsecrets) =
-							append((*obtainedSubsecrets), key)
-						(*usedSubsecrets) =
-							append((*usedSubsecrets), intersection...)
-						// fmt.Println("here above", key)
-					}
+	for childLevel := layers - 2; childLevel >= 0; childLevel-- {
+		relevantHigherSet := utils.FindDifference(*obtainedSubsecrets,
+			*usedSubsecrets)
+		if len(relevantHigherSet) < layerThreshold {
+			break
+		}
+		for key, value := range layerWiseChildren[childLevel] {
+			intersection := utils.GetIntersection(relevantHigherSet, value)
+			if len(intersection) >= layerThreshold {
+				isSecretRecovered = true
+				if !utils.IsInSlice(*obtainedSubsecrets, key) {
+					secretsRecovered = append(secretsRecovered, childLevel)
+					(*obtainedSubsecrets) =
+						append((*obtainedSubsecrets), key)
+					(*usedSubsecrets) =
+						append((*usedSubsecrets), intersection...)
 				}
 			}
 		}
@@ -3,6 +3,7 @@ package probability
 import (
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 	"sync"
 )
 
@@ -10,7 +11,8 @@ import (
 func TotalRecoveryParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
 	upperLayerThreshold, trustees, leavesLayerThreshold, simulationsRun int,
-	trusteesNumChannel chan<- int, contactsNumChannel chan<- int,
+	rng *randm.Rand, trusteesNumChannel chan<- int,
+	contactsNumChannel chan<- int,
 	wg *sync.WaitGroup) {
 	defer wg.Done()
 	for i := 0; i < simulationsRun; i++ {
@@ -20,7 +22,7 @@ func TotalRecoveryParallelized(peoplePackets [][]int,
 		usedSharesMap := make(map[int][]int)
 		noOfPeople := len(peoplePackets)
 		accessOrder := utils.GenerateIndicesSet(noOfPeople)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, rng)
 		for _, a := range accessOrder {
 			obtainedShares = append(obtainedShares, peoplePackets[a]...)
 			peopleContacted = append(peopleContacted, a)
@@ -61,7 +63,8 @@ func TotalHintedTRecoveryParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
 	upperLayerThreshold, trustees, leavesLayerThreshold int,
 	sharePersonMap, hintPersonMap map[int]int, simulationsRun int,
-	trusteesNumChannel chan<- int, contactsNumChannel chan<- int,
+	rng *randm.Rand, trusteesNumChannel chan<- int,
+	contactsNumChannel chan<- int,
 	wg *sync.WaitGroup) {
 	defer wg.Done()
 	for i := 0; i < simulationsRun; i++ {
@@ -70,7 +73,7 @@ func TotalHintedTRecoveryParallelized(peoplePackets [][]int,
 		usedSharesMap := make(map[int][]int)
 		noOfPeople := len(peoplePackets)
 		accessOrder := utils.GenerateIndicesSet(noOfPeople)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, rng)
 		for index, a := range accessOrder {
 			obtainedLength := index + 1
 			obtainedShares = append(obtainedShares, peoplePackets[a]...)
@@ -161,14 +164,14 @@ func NumwiseRecoveryTrusteesParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
 	upperLayerThreshold,
 	trustees, leavesLayerThreshold, runSize, simulations int,
-	trusteesNumChannel chan<- int, wg *sync.WaitGroup) {
+	rng *randm.Rand, trusteesNumChannel chan<- int, wg *sync.WaitGroup) {
 	defer wg.Done()
 	var obtainedShares, usedShares, obtainedSubsecrets,
 		usedSubsecrets, peopleContacted []int
 	usedSharesMap := make(map[int][]int)
 	noOfPeople := len(peoplePackets)
 	accessOrder := utils.GenerateIndicesSet(noOfPeople)
-	utils.Shuffle(accessOrder)
+	utils.Shuffle(accessOrder, rng)
 	for i := 0; i < simulations; i++ {
 		for _, a := range accessOrder {
 			// Total set of packets that a user has obtained
@@ -208,211 +211,279 @@ func NumwiseRecoveryTrusteesParallelized(peoplePackets [][]int,
 	}
 }
 
-// Here, you run an event until the secret is recovered
+// Here, you run an event until the secret is recovered, contacting the
+// people in the order chosen by the strategy
+// The fixed order of the noisy identification of the trustees is the one of
+// NoisyPriorStrategy
+// The hints are decrypted if there is a map of the hints, whether the
+// strategy follows them or not
 func TotalCompRecoveryParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
-	upperLayerThreshold, trustees, leavesLayerThreshold, simulationsRun int,
-	deltaTr, deltaNonTr uint16,
-	trusteesNumChannel chan<- int, contactsNumChannel chan<- int,
+	upperLayerThreshold, trustees, leavesLayerThreshold int,
+	sharePersonMap, hintPersonMap map[int]int, simulationsRun int,
+	strategy AccessStrategy,
+	rng *randm.Rand, trusteesNumChannel chan<- int,
+	contactsNumChannel chan<- int,
 	wg *sync.WaitGroup) {
 	defer wg.Done()
 	for i := 0; i < simulationsRun; i++ {
-		// fmt.Println(i)
-		var obtainedShares, usedShares, obtainedSubsecrets,
-			usedSubsecrets, peopleContacted []int
-		usedSharesMap := make(map[int][]int)
-		noOfPeople := len(peoplePackets)
-		actualBitMatrix := utils.GenerateTrNonTrBitMatrix(trustees, noOfPeople)
-		flippedMatrix := utils.FlipBitsWithProbability(actualBitMatrix, deltaTr,
-			deltaNonTr, trustees, noOfPeople)
-		var firstApproach []int
-		var lastApproach []int
-		for ind, f := range flippedMatrix {
-			if f == 1 {
-				firstApproach = append(firstApproach, ind)
-			} else {
-				lastApproach = append(lastApproach, ind)
-			}
-		}
-		utils.Shuffle(firstApproach)
-		utils.Shuffle(lastApproach)
-		var accessOrder []int
-		accessOrder = append(accessOrder, firstApproach...)
-		accessOrder = append(accessOrder, lastApproach...)
-		for _, a := range accessOrder {
-			obtainedShares = append(obtainedShares, peoplePackets[a]...)
+		run := strategy.NewRun(trustees, len(peoplePackets), rng)
+		state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+			upperLayerThreshold, leavesLayerThreshold, sharePersonMap,
+			hintPersonMap)
+		var peopleContacted []int
+		for a := run.Next(); a != -1; a = run.Next() {
 			peopleContacted = append(peopleContacted, a)
-			// Shares obtained from the most recently contacted person
-			newShares := peoplePackets[a]
-			// Check with the already reconstructed subsecrets
-			CheckAlreadyUsedShares(usedSharesMap, newShares,
-				layerWiseChildren[layers-1], &usedShares)
-			relevantData := utils.FindDifference(obtainedShares, usedShares)
-			// First recover the secret in the leaves layer
-			isPenRecovered := CheckLeavesRecovery(relevantData,
-				layerWiseChildren[layers-1], leavesLayerThreshold, &usedShares,
-				&obtainedSubsecrets, usedSharesMap)
-			// If you recover some subsecret (from the penultimate layer),
-			// only then run the secret recovery for the layer above
-			if isPenRecovered {
-				isSecretRecovered, secretsRecovered :=
-					CheckAboveLayer(upperLayerThreshold, &obtainedSubsecrets,
-						&usedSubsecrets, layerWiseChildren, layers)
-				if isSecretRecovered {
-					if utils.IsInSlice(secretsRecovered, 0) {
-						totalNum := len(peopleContacted)
-						contactsNumChannel <- totalNum
+			if state.Add(a) {
+				totalNum := len(peopleContacted)
+				contactsNumChannel <- totalNum
 
-						trusteesNum := utils.CountLessThan(peopleContacted, trustees)
-						trusteesNumChannel <- trusteesNum
-						break
-					}
-				}
+				trusteesNum := utils.CountLessThan(peopleContacted, trustees)
+				trusteesNumChannel <- trusteesNum
+				break
 			}
+			run.Observe(a, state)
 		}
 	}
-	// fmt.Println("done")
 }
 
-// Here, you run an event until the secret is recovered
+// Here, you run an event until the secret is recovered or the adversary is
+// reported, contacting the people in the order chosen by the strategy
+// RandomStrategy gives the random order and NoisyPriorStrategy the fixed
+// order of the noisy identification of the trustees
 func TotalCompWBAdvObtRecoveryParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
 	upperLayerThreshold, trustees, leavesLayerThreshold, simulationsRun int,
-	deltaTr, deltaNonTr uint16,
+	strategy AccessStrategy,
 	obtProb, wbProb byte,
-	trusteesNumChannel chan<- int, contactsNumChannel chan<- int,
+	rng *randm.Rand, trusteesNumChannel chan<- int,
+	contactsNumChannel chan<- int,
 	wg *sync.WaitGroup) {
 	defer wg.Done()
+	noOfPeople := len(peoplePackets)
 	for i := 0; i < simulationsRun; i++ {
-		// fmt.Println(i)
-		var obtainedShares, usedShares, obtainedSubsecrets,
-			usedSubsecrets, peopleContacted []int
-		usedSharesMap := make(map[int][]int)
-		noOfPeople := len(peoplePackets)
-		actualBitMatrix := utils.GenerateTrNonTrBitMatrix(trustees, noOfPeople)
-		flippedMatrix := utils.FlipBitsWithProbability(actualBitMatrix, deltaTr,
-			deltaNonTr, trustees, noOfPeople)
-		var firstApproach []int
-		var lastApproach []int
-		for ind, f := range flippedMatrix {
-			if f == 1 {
-				firstApproach = append(firstApproach, ind)
-			} else {
-				lastApproach = append(lastApproach, ind)
-			}
-		}
-		utils.Shuffle(firstApproach)
-		utils.Shuffle(lastApproach)
-		var accessOrder []int
-		accessOrder = append(accessOrder, firstApproach...)
-		accessOrder = append(accessOrder, lastApproach...)
+		run := strategy.NewRun(trustees, noOfPeople, rng)
+		state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+			upperLayerThreshold, leavesLayerThreshold, nil, nil)
+		peopleObtained, _, isRecovered := AdvObtRecovery(state, run,
+			noOfPeople, obtProb, wbProb, rng)
+		if isRecovered {
+			totalNum := len(peopleObtained)
+			contactsNumChannel <- totalNum
 
-		obtProbs, err := utils.GenerateProbabilityArray(noOfPeople)
-		if err != nil {
-			log.Fatalln(err)
-		}
-		probsWB, err := utils.GenerateProbabilityArray(noOfPeople)
-		if err != nil {
-			log.Fatalln(err)
+			trusteesNum := utils.CountLessThan(peopleObtained, trustees)
+			trusteesNumChannel <- trusteesNum
 		}
-		for ind, a := range accessOrder {
-			if obtProbs[ind] < obtProb {
-				obtainedShares = append(obtainedShares, peoplePackets[a]...)
-				peopleContacted = append(peopleContacted, a)
-				// Shares obtained from the most recently contacted person
-				newShares := peoplePackets[a]
-				// Check with the already reconstructed subsecrets
-				CheckAlreadyUsedShares(usedSharesMap, newShares,
-					layerWiseChildren[layers-1], &usedShares)
-				relevantData := utils.FindDifference(obtainedShares, usedShares)
-				// First recover the secret in the leaves layer
-				isPenRecovered := CheckLeavesRecovery(relevantData,
-					layerWiseChildren[layers-1], leavesLayerThreshold, &usedShares,
-					&obtainedSubsecrets, usedSharesMap)
-				// If you recover some subsecret (from the penultimate layer),
-				// only then run the secret recovery for the layer above
-				if isPenRecovered {
-					isSecretRecovered, secretsRecovered :=
-						CheckAboveLayer(upperLayerThreshold, &obtainedSubsecrets,
-							&usedSubsecrets, layerWiseChildren, layers)
-					if isSecretRecovered {
-						if utils.IsInSlice(secretsRecovered, 0) {
-							totalNum := len(peopleContacted)
-							contactsNumChannel <- totalNum
+	}
+}
 
-							trusteesNum := utils.CountLessThan(peopleContacted, trustees)
-							trusteesNumChannel <- trusteesNum
-							break
-						}
-					}
-				}
-			}
-			if probsWB[ind] < wbProb {
-				break
+// Contacts the people chosen by the run until the secret is recovered or
+// the adversary is reported
+// A contacted person gives the packet with the probability obtProb and
+// reports the adversary with the probability wbProb
+// A person who does not give the packet gives nothing to observe but is not
+// contacted again
+// Gives the people who gave their packets, the number of people contacted
+// and whether the secret is recovered
+func AdvObtRecovery(state *RecoveryState, run AccessRun, noOfPeople int,
+	obtProb, wbProb byte, rng *randm.Rand) ([]int, int, bool) {
+	obtProbs, err := utils.GenerateProbabilityArray(noOfPeople, rng)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	probsWB, err := utils.GenerateProbabilityArray(noOfPeople, rng)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	var peopleObtained []int
+	contactsNum := 0
+	for a := run.Next(); a != -1; a = run.Next() {
+		index := contactsNum
+		contactsNum++
+		if obtProbs[index] < obtProb {
+			peopleObtained = append(peopleObtained, a)
+			if state.Add(a) {
+				return peopleObtained, contactsNum, true
 			}
 		}
+		run.Observe(a, state)
+		if probsWB[index] < wbProb {
+			break
+		}
 	}
+	return peopleObtained, contactsNum, false
 }
 
-func TotalWBAdvObtRecoveryParallelized(peoplePackets [][]int,
+// Here, you run an event until the secret is recovered or everyone has been
+// contacted, where the people may not answer
+// The hints are followed if there is a map of the hints
+// A run in which the secret is never recovered goes to failuresChannel
+func TotalRecoveryAvailabilityParallelized(peoplePackets [][]int,
 	layerWiseChildren map[int]map[int][]int, layers,
-	upperLayerThreshold, trustees, leavesLayerThreshold, simulationsRun int,
-	obtProb, wbProb byte,
-	trusteesNumChannel chan<- int, contactsNumChannel chan<- int,
+	upperLayerThreshold, trustees, leavesLayerThreshold int,
+	sharePersonMap, hintPersonMap map[int]int, simulationsRun int,
+	availability *Availability, rng *randm.Rand, trusteesNumChannel chan<- int,
+	contactsNumChannel chan<- int, failuresChannel chan<- int,
 	wg *sync.WaitGroup) {
 	defer wg.Done()
 	for i := 0; i < simulationsRun; i++ {
-		// fmt.Println(i)
-		var obtainedShares, usedShares, obtainedSubsecrets,
-			usedSubsecrets, peopleContacted []int
-		usedSharesMap := make(map[int][]int)
-		noOfPeople := len(peoplePackets)
-		accessOrder := utils.GenerateIndicesSet(noOfPeople)
-		utils.Shuffle(accessOrder)
+		packets := peoplePackets
+		// The permanent losses are already in the packets
+		if !availability.Permanent {
+			packets = availability.AvailablePackets(peoplePackets, rng)
+		}
+		accessOrder := utils.GenerateIndicesSet(len(packets))
+		utils.Shuffle(accessOrder, rng)
+		var contactsNum int
+		if hintPersonMap == nil {
+			contactsNum = RecoverInOrder(packets, layerWiseChildren, layers,
+				upperLayerThreshold, leavesLayerThreshold, accessOrder)
+		} else {
+			contactsNum = RecoverHintedInOrder(packets, layerWiseChildren,
+				layers, upperLayerThreshold, leavesLayerThreshold,
+				sharePersonMap, hintPersonMap, &accessOrder)
+		}
+		if contactsNum == 0 {
+			failuresChannel <- 1
+			continue
+		}
+		contactsNumChannel <- contactsNum
+		trusteesNumChannel <- utils.CountLessThan(accessOrder[:contactsNum],
+			trustees)
+	}
+}
 
-		obtProbs, err := utils.GenerateProbabilityArray(noOfPeople)
-		if err != nil {
-			log.Fatalln(err)
+// Contacts the people in the access order until the secret is recovered
+// and gives the number of people contacted (0 if it is never recovered)
+func RecoverInOrder(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
+	leavesLayerThreshold int, accessOrder []int) int {
+	state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+		upperLayerThreshold, leavesLayerThreshold, nil, nil)
+	for index, a := range accessOrder {
+		if state.Add(a) {
+			return index + 1
 		}
-		probsWB, err := utils.GenerateProbabilityArray(noOfPeople)
-		if err != nil {
-			log.Fatalln(err)
+	}
+	return 0
+}
+
+// Same as RecoverInOrder, but the access order is updated with the hints
+func RecoverHintedInOrder(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
+	leavesLayerThreshold int, sharePersonMap, hintPersonMap map[int]int,
+	accessOrder *[]int) int {
+	state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+		upperLayerThreshold, leavesLayerThreshold, sharePersonMap,
+		hintPersonMap)
+	for index := 0; index < len(*accessOrder); index++ {
+		obtainedLength := index + 1
+		if state.Add((*accessOrder)[index]) {
+			return obtainedLength
+		}
+		// Based on the hints obtained, change the access order
+		if len(state.hintedPeople) != 0 {
+			utils.UpdateOrder(state.hintedPeople, accessOrder, obtainedLength)
 		}
-		for ind, a := range accessOrder {
-			if obtProbs[ind] < obtProb {
-				obtainedShares = append(obtainedShares, peoplePackets[a]...)
-				peopleContacted = append(peopleContacted, a)
-				// Shares obtained from the most recently contacted person
-				newShares := peoplePackets[a]
-				// Check with the already reconstructed subsecrets
-				CheckAlreadyUsedShares(usedSharesMap, newShares,
-					layerWiseChildren[layers-1], &usedShares)
-				relevantData := utils.FindDifference(obtainedShares, usedShares)
-				// First recover the secret in the leaves layer
-				isPenRecovered := CheckLeavesRecovery(relevantData,
-					layerWiseChildren[layers-1], leavesLayerThreshold, &usedShares,
-					&obtainedSubsecrets, usedSharesMap)
-				// If you recover some subsecret (from the penultimate layer),
-				// only then run the secret recovery for the layer above
-				if isPenRecovered {
-					isSecretRecovered, secretsRecovered :=
-						CheckAboveLayer(upperLayerThreshold, &obtainedSubsecrets,
-							&usedSubsecrets, layerWiseChildren, layers)
-					if isSecretRecovered {
-						if utils.IsInSlice(secretsRecovered, 0) {
-							totalNum := len(peopleContacted)
-							contactsNumChannel <- totalNum
+	}
+	return 0
+}
 
-							trusteesNum := utils.CountLessThan(peopleContacted, trustees)
-							trusteesNumChannel <- trusteesNum
-							break
-						}
-					}
-				}
-			}
-			if probsWB[ind] < wbProb {
-				break
-			}
+// RecoveryState is the state of a single recovery, in which the packets
+// are given one person at a time in any order
+// The hints are collected if there is a map of the hints
+type RecoveryState struct {
+	peoplePackets        [][]int
+	layerWiseChildren    map[int]map[int][]int
+	layers               int
+	upperLayerThreshold  int
+	leavesLayerThreshold int
+	sharePersonMap       map[int]int
+	hintPersonMap        map[int]int
+
+	obtainedShares, usedShares, obtainedSubsecrets, usedSubsecrets,
+	hintedPeople []int
+	usedSharesMap map[int][]int
+	// The person from whom each share was obtained
+	owners map[int]int
+}
+
+func NewRecoveryState(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
+	leavesLayerThreshold int, sharePersonMap,
+	hintPersonMap map[int]int) *RecoveryState {
+	return &RecoveryState{
+		peoplePackets:        peoplePackets,
+		layerWiseChildren:    layerWiseChildren,
+		layers:               layers,
+		upperLayerThreshold:  upperLayerThreshold,
+		leavesLayerThreshold: leavesLayerThreshold,
+		sharePersonMap:       sharePersonMap,
+		hintPersonMap:        hintPersonMap,
+		usedSharesMap:        make(map[int][]int),
+		owners:               make(map[int]int),
+	}
+}
+
+// Add adds the packet of the person and tells if the secret is recovered
+// Nothing is obtained from the people who do not answer (no packet)
+func (state *RecoveryState) Add(a int) bool {
+	newShares := state.peoplePackets[a]
+	if len(newShares) == 0 {
+		return false
+	}
+	leavesChildren := state.layerWiseChildren[state.layers-1]
+	state.obtainedShares = append(state.obtainedShares, newShares...)
+	for _, share := range newShares {
+		state.owners[share] = a
+	}
+	// Check with the already reconstructed subsecrets
+	var isPenRecovered bool
+	if state.hintPersonMap == nil {
+		CheckAlreadyUsedShares(state.usedSharesMap, newShares, leavesChildren,
+			&state.usedShares)
+		relevantData := utils.FindDifference(state.obtainedShares,
+			state.usedShares)
+		// First recover the secret in the leaves layer
+		isPenRecovered = CheckLeavesRecovery(relevantData, leavesChildren,
+			state.leavesLayerThreshold, &state.usedShares,
+			&state.obtainedSubsecrets, state.usedSharesMap)
+	} else {
+		CheckAlreadyUsedHintedShares(state.usedSharesMap, newShares,
+			leavesChildren, &state.usedShares, a, state.hintPersonMap,
+			&state.hintedPeople)
+		relevantData := utils.FindDifference(state.obtainedShares,
+			state.usedShares)
+		isPenRecovered = CheckHintedLeavesRecovery(relevantData,
+			leavesChildren, state.leavesLayerThreshold, &state.usedShares,
+			&state.obtainedSubsecrets, state.sharePersonMap,
+			state.hintPersonMap, &state.hintedPeople)
+	}
+	// If you recover some subsecret (from the penultimate layer),
+	// only then run the secret recovery for the layer above
+	if !isPenRecovered {
+		return false
+	}
+	isSecretRecovered, secretsRecovered := CheckAboveLayer(
+		state.upperLayerThreshold, &state.obtainedSubsecrets,
+		&state.usedSubsecrets, state.layerWiseChildren, state.layers)
+	return isSecretRecovered && utils.IsInSlice(secretsRecovered, 0)
+}
+
+// HintedPeople gives the people pointed to by the hints obtained so far
+func (state *RecoveryState) HintedPeople() []int {
+	return state.hintedPeople
+}
+
+// UsedPeople gives the people whose shares have been used for recovering
+// some subsecret, who are then known to be trustees
+func (state *RecoveryState) UsedPeople() []int {
+	var people []int
+	for _, share := range state.usedShares {
+		person := state.owners[share]
+		if !utils.IsInSlice(people, person) {
+			people = append(people, person)
 		}
 	}
+	return people
 }
@@ -0,0 +1,390 @@
+package probability
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/utils"
+	"log"
+	randm "math/rand"
+	"sync"
+)
+
+// ***********************Strategies***********************
+// A strategy chooses whom to contact next, by the user or by the adversary
+// It starts from what is known before the first contact and may change its
+// mind as the packets come in, e.g., when the hints are decrypted or some
+// subsecret is recovered with the shares of some people
+// The people are indexed as in the packets, so the first trustees people
+// are the trustees
+
+// Names of the built-in strategies
+const (
+	StrategyRandom        = "random"
+	StrategyNoisyPrior    = "noisy_prior"
+	StrategyHintFollowing = "hint_following"
+	StrategyBayesian      = "bayesian"
+)
+
+// AccessStrategy gives a new run of the strategy for every recovery
+// The strategy itself holds no state of the runs, so it can be shared by
+// the routines
+type AccessStrategy interface {
+	NewRun(trustees, noOfPeople int, rng *randm.Rand) AccessRun
+}
+
+// AccessRun chooses the people of a single recovery one at a time
+type AccessRun interface {
+	// Next gives the next person to contact (-1 if all have been contacted)
+	Next() int
+	// Observe tells that the person has been contacted and gives the state
+	// of the recovery after adding their packet
+	Observe(person int, state *RecoveryState)
+}
+
+// NewStrategy gives the built-in strategy of the name
+// The noisy identification of the trustees (deltaTr and deltaNonTr, in
+// percent, as in GetCompProbabilityCDFParallelized) is used by the noisy-prior
+// and the Bayesian strategies
+func NewStrategy(name string, deltaTr, deltaNonTr uint16) (AccessStrategy,
+	error) {
+	if deltaTr > 100 || deltaNonTr > 100 {
+		return nil, fmt.Errorf("%w: deltas %v and %v", errors.ErrInvalidInput,
+			deltaTr, deltaNonTr)
+	}
+	switch name {
+	case StrategyRandom:
+		return RandomStrategy{}, nil
+	case StrategyNoisyPrior:
+		return NoisyPriorStrategy{DeltaTr: deltaTr, DeltaNonTr: deltaNonTr}, nil
+	case StrategyHintFollowing:
+		return HintFollowingStrategy{Base: RandomStrategy{}}, nil
+	case StrategyBayesian:
+		return BayesianStrategy{DeltaTr: deltaTr, DeltaNonTr: deltaNonTr}, nil
+	}
+	return nil, fmt.Errorf("%w: strategy %q", errors.ErrInvalidInput, name)
+}
+
+// A run that goes through a fixed order, skipping the people already
+// contacted
+type orderedRun struct {
+	order     []int
+	next      int
+	contacted []bool
+}
+
+func newOrderedRun(order []int, noOfPeople int) *orderedRun {
+	return &orderedRun{order: order, contacted: make([]bool, noOfPeople)}
+}
+
+func (run *orderedRun) Next() int {
+	for ; run.next < len(run.order); run.next++ {
+		if !run.contacted[run.order[run.next]] {
+			return run.order[run.next]
+		}
+	}
+	return -1
+}
+
+func (run *orderedRun) Observe(person int, state *RecoveryState) {
+	run.contacted[person] = true
+}
+
+// RandomStrategy contacts the people in a random order
+type RandomStrategy struct{}
+
+func (RandomStrategy) NewRun(trustees, noOfPeople int,
+	rng *randm.Rand) AccessRun {
+	order := utils.GenerateIndicesSet(noOfPeople)
+	utils.Shuffle(order, rng)
+	return newOrderedRun(order, noOfPeople)
+}
+
+// NoisyPriorStrategy contacts first the people who look like trustees, where
+// a trustee does not look like one with the probability DeltaTr and a
+// non-trustee does with the probability DeltaNonTr (in percent)
+// The order is fixed once the bits are flipped
+type NoisyPriorStrategy struct {
+	DeltaTr, DeltaNonTr uint16
+}
+
+func (strategy NoisyPriorStrategy) NewRun(trustees, noOfPeople int,
+	rng *randm.Rand) AccessRun {
+	firstApproach, lastApproach := noisySplit(trustees, noOfPeople,
+		strategy.DeltaTr, strategy.DeltaNonTr, rng)
+	order := append(firstApproach, lastApproach...)
+	return newOrderedRun(order, noOfPeople)
+}
+
+// Splits the people (each part shuffled) by whether they look like trustees
+func noisySplit(trustees, noOfPeople int, deltaTr, deltaNonTr uint16,
+	rng *randm.Rand) ([]int, []int) {
+	actualBitMatrix := utils.GenerateTrNonTrBitMatrix(trustees, noOfPeople)
+	flippedMatrix := utils.FlipBitsWithProbability(actualBitMatrix,
+		deltaTr, deltaNonTr, trustees, noOfPeople, rng)
+	var firstApproach []int
+	var lastApproach []int
+	for ind, f := range flippedMatrix {
+		if f == 1 {
+			firstApproach = append(firstApproach, ind)
+		} else {
+			lastApproach = append(lastApproach, ind)
+		}
+	}
+	utils.Shuffle(firstApproach, rng)
+	utils.Shuffle(lastApproach, rng)
+	return firstApproach, lastApproach
+}
+
+// HintFollowingStrategy contacts the hinted people as soon as the hints are
+// decrypted, as utils.UpdateOrder does, and otherwise follows the base
+// strategy
+type HintFollowingStrategy struct {
+	Base AccessStrategy
+}
+
+func (strategy HintFollowingStrategy) NewRun(trustees, noOfPeople int,
+	rng *randm.Rand) AccessRun {
+	return newHintFollowingRun(strategy.Base.NewRun(trustees, noOfPeople, rng),
+		noOfPeople)
+}
+
+type hintFollowingRun struct {
+	base         AccessRun
+	contacted    []bool
+	hintedPeople []int
+}
+
+func newHintFollowingRun(base AccessRun, noOfPeople int) *hintFollowingRun {
+	return &hintFollowingRun{base: base, contacted: make([]bool, noOfPeople)}
+}
+
+func (run *hintFollowingRun) Next() int {
+	// The hinted people in the order of the hints
+	for _, person := range run.hintedPeople {
+		if !run.contacted[person] {
+			return person
+		}
+	}
+	return run.base.Next()
+}
+
+func (run *hintFollowingRun) Observe(person int, state *RecoveryState) {
+	run.contacted[person] = true
+	run.hintedPeople = state.HintedPeople()
+	run.base.Observe(person, state)
+}
+
+// BayesianStrategy follows the hints and keeps a belief of how likely a
+// person who looks like a trustee (and one who does not) is to be a trustee
+// The beliefs start from the noisy identification of the trustees and are
+// updated with the people known to be trustees, i.e., the hinted people and
+// the people whose shares have been used for recovering some subsecret
+// A trustee whose shares have not been used yet counts as a non-trustee
+// until they are
+// The next person is taken from the group with the greater belief
+type BayesianStrategy struct {
+	DeltaTr, DeltaNonTr uint16
+}
+
+// Weight of the prior beliefs in the number of people
+const bayesianPriorWeight = 10
+
+func (strategy BayesianStrategy) NewRun(trustees, noOfPeople int,
+	rng *randm.Rand) AccessRun {
+	firstApproach, lastApproach := noisySplit(trustees, noOfPeople,
+		strategy.DeltaTr, strategy.DeltaNonTr, rng)
+	run := &bayesianRun{
+		groups:    [2][]int{lastApproach, firstApproach},
+		group:     make([]int, noOfPeople),
+		contacted: make([]bool, noOfPeople),
+	}
+	for _, person := range firstApproach {
+		run.group[person] = 1
+	}
+	// The probability of being a trustee when looking like one (or not)
+	// before contacting anyone
+	pTr := float64(trustees) / float64(noOfPeople)
+	dTr := float64(strategy.DeltaTr) / 100
+	dNonTr := float64(strategy.DeltaNonTr) / 100
+	run.prior[1] = posterior((1-dTr)*pTr, dNonTr*(1-pTr))
+	run.prior[0] = posterior(dTr*pTr, (1-dNonTr)*(1-pTr))
+	return newHintFollowingRun(run, noOfPeople)
+}
+
+// The probability of being a trustee from the joint probabilities of being
+// a trustee and a non-trustee
+func posterior(trustee, nonTrustee float64) float64 {
+	if trustee+nonTrustee == 0 {
+		return 0.5
+	}
+	return trustee / (trustee + nonTrustee)
+}
+
+type bayesianRun struct {
+	// The people who do not (0) and do (1) look like trustees
+	groups [2][]int
+	next   [2]int
+	group  []int
+	prior  [2]float64
+	// The people contacted and known to be trustees per group
+	contactedNum, confirmedNum [2]int
+	contacted                  []bool
+}
+
+// The belief that the next person of the group is a trustee
+func (run *bayesianRun) belief(g int) float64 {
+	return (bayesianPriorWeight*run.prior[g] + float64(run.confirmedNum[g])) /
+		(bayesianPriorWeight + float64(run.contactedNum[g]))
+}
+
+// Skips the people of the group already contacted and gives the next one
+func (run *bayesianRun) peek(g int) int {
+	for ; run.next[g] < len(run.groups[g]); run.next[g]++ {
+		if !run.contacted[run.groups[g][run.next[g]]] {
+			return run.groups[g][run.next[g]]
+		}
+	}
+	return -1
+}
+
+func (run *bayesianRun) Next() int {
+	last, first := run.peek(0), run.peek(1)
+	if first == -1 {
+		return last
+	}
+	if last == -1 || run.belief(1) >= run.belief(0) {
+		return first
+	}
+	return last
+}
+
+func (run *bayesianRun) Observe(person int, state *RecoveryState) {
+	run.contacted[person] = true
+	run.contactedNum[run.group[person]]++
+	// The shares of the people contacted earlier may be used only now, so
+	// the trustees are counted again
+	known := make([]bool, len(run.contacted))
+	for _, p := range state.HintedPeople() {
+		known[p] = true
+	}
+	for _, p := range state.UsedPeople() {
+		known[p] = true
+	}
+	run.confirmedNum = [2]int{}
+	for p, isKnown := range known {
+		if isKnown && run.contacted[p] {
+			run.confirmedNum[run.group[p]]++
+		}
+	}
+}
+
+// ***********************Total***********************
+// ***********************Strategies***********************
+func GetAdditiveProbabilityStrategyCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum int, strategy AccessStrategy,
+	rng *randm.Rand) (map[int]int, map[int]int,
+	error) {
+	return getStrategyCDFParallelized(simulationsDist, simulationsRun, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		subsecretsNum, 0, strategy, rng)
+}
+
+func GetThresholdedProbabilityStrategyCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, upperThreshold, trustees, anonymity,
+	absoluteThreshold, subsecretsNum int,
+	strategy AccessStrategy,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
+	upperLayerThreshold := utils.FloorDivide(upperThreshold*subsecretsNum, 100)
+	return getStrategyCDFParallelized(simulationsDist, simulationsRun, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		upperLayerThreshold, 0, strategy, rng)
+}
+
+func GetHintedTProbabilityStrategyCDFParallelized(simulationsDist,
+	simulationsRun, layers, threshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum, noOfHints int, strategy AccessStrategy,
+	rng *randm.Rand) (map[int]int,
+	map[int]int, error) {
+	if noOfHints < 1 {
+		return nil, nil, errors.ErrInvalidInput
+	}
+	return getStrategyCDFParallelized(simulationsDist, simulationsRun, layers,
+		threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+		subsecretsNum, noOfHints, strategy, rng)
+}
+
+// The hinted packets are used if there are hints
+func getStrategyCDFParallelized(simulationsDist, simulationsRun, layers,
+	threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	upperLayerThreshold, noOfHints int, strategy AccessStrategy,
+	rng *randm.Rand) (map[int]int,
+	map[int]int, error) {
+	// The percentage threshold should not be greater than 100%
+	if threshold > 100 {
+		return nil, nil, errors.ErrInvalidThreshold
+	}
+	results := make(map[int]int)
+	results_anon := make(map[int]int)
+	for i := 0; i < anonymity; i++ {
+		results[i+1] = 0
+		results_anon[i+1] = 0
+	}
+	// Based on the value of the threshold,
+	// Obtain the number of packets in the leaves layer
+	sharesNum := utils.FloorDivide((absoluteThreshold * 100), threshold)
+
+	// Get the number of shares needed for recovering the subsecrets
+	// The subsecrets have a fixed threshold
+	leavesLayerThreshold := absoluteThreshold
+
+	trusteesNumChannel := make(chan int, simulationsDist*simulationsRun)
+	contactsNumChannel := make(chan int, simulationsDist*simulationsRun)
+
+	var wg sync.WaitGroup
+
+	for k := 0; k < simulationsDist; k++ {
+		// Obtain the packets to be distributed among people
+		var peoplePackets [][]int
+		var layerWiseChildren map[int]map[int][]int
+		var sharePersonMap, hintPersonMap map[int]int
+		var err error
+		if noOfHints == 0 {
+			peoplePackets, layerWiseChildren, err = CreatePeoplePacketsFixedTh(
+				layers, threshold, trustees, anonymity, subsecretsNum,
+				sharesNum, rng)
+		} else {
+			peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap,
+				err = CreatePeopleHintedTPacketsFixedTh(layers, threshold,
+				trustees, anonymity, subsecretsNum, sharesNum, noOfHints, rng)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wg.Add(1)
+
+		go TotalCompRecoveryParallelized(peoplePackets, layerWiseChildren,
+			layers, upperLayerThreshold, trustees, leavesLayerThreshold,
+			sharePersonMap, hintPersonMap, simulationsRun, strategy,
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
+	}
+
+	// Wait for the routines to finish
+	wg.Wait()
+
+	// Close the channels
+	close(trusteesNumChannel)
+	close(contactsNumChannel)
+
+	// Update the maps
+	for contactsNum := range contactsNumChannel {
+		results_anon[contactsNum] += 1
+	}
+	for trusteesNum := range trusteesNumChannel {
+		results[trusteesNum] += 1
+	}
+
+	return results, results_anon, nil
+}
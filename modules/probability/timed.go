@@ -0,0 +1,245 @@
+package probability
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/utils"
+	"log"
+	"math"
+	randm "math/rand"
+	"sort"
+	"sync"
+)
+
+// ***********************Timed***********************
+// The user contacts the people in the access order, but does not wait for
+// an answer before contacting the next one
+// Up to width people are waiting for an answer at any time, and each answer
+// comes after a random response time
+// The packets are used in the order in which the answers arrive, and the
+// hints move the hinted people forward among the people not yet contacted
+// The time at which the secret is recovered is reported, so width 1 gives
+// the time of contacting the people one at a time
+
+// Distributions of the response times
+const (
+	LatencyConstant    = "constant"
+	LatencyUniform     = "uniform"
+	LatencyExponential = "exponential"
+	LatencyLogNormal   = "lognormal"
+)
+
+// Latency draws the time that a person takes to answer
+type Latency func(rng *randm.Rand) float64
+
+// NewLatency gives the response times of the distribution with the mean
+// The spread is the half width of the uniform distribution and the standard
+// deviation of the logarithm for the log-normal one
+func NewLatency(distribution string, mean, spread float64) (Latency, error) {
+	if mean <= 0 || spread < 0 {
+		return nil, fmt.Errorf("%w: mean %v and spread %v",
+			errors.ErrInvalidInput, mean, spread)
+	}
+	switch distribution {
+	case LatencyConstant:
+		return func(rng *randm.Rand) float64 {
+			return mean
+		}, nil
+	case LatencyUniform:
+		if spread > mean {
+			return nil, fmt.Errorf("%w: negative response times",
+				errors.ErrInvalidInput)
+		}
+		return func(rng *randm.Rand) float64 {
+			return mean - spread + 2*spread*rng.Float64()
+		}, nil
+	case LatencyExponential:
+		return func(rng *randm.Rand) float64 {
+			return mean * rng.ExpFloat64()
+		}, nil
+	case LatencyLogNormal:
+		// The mean of exp(N(mu, spread^2)) is exp(mu + spread^2 / 2)
+		mu := math.Log(mean) - spread*spread/2
+		return func(rng *randm.Rand) float64 {
+			return math.Exp(mu + spread*rng.NormFloat64())
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: distribution %q", errors.ErrInvalidInput,
+		distribution)
+}
+
+// A person waiting to answer
+type pendingContact struct {
+	person int
+	time   float64
+}
+
+// Here, you run an event in time until the secret is recovered
+// The hints are followed if there is a map of the hints
+func TotalTimedRecoveryParallelized(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers,
+	upperLayerThreshold, leavesLayerThreshold int,
+	sharePersonMap, hintPersonMap map[int]int, simulationsRun, width int,
+	latency Latency, rng *randm.Rand, timesChannel chan<- float64,
+	wg *sync.WaitGroup) {
+	defer wg.Done()
+	for i := 0; i < simulationsRun; i++ {
+		accessOrder := utils.GenerateIndicesSet(len(peoplePackets))
+		utils.Shuffle(accessOrder, rng)
+		recoveryTime, isRecovered := RecoverInTime(peoplePackets,
+			layerWiseChildren, layers, upperLayerThreshold,
+			leavesLayerThreshold, sharePersonMap, hintPersonMap, accessOrder,
+			width, latency, rng)
+		if isRecovered {
+			timesChannel <- recoveryTime
+		}
+	}
+}
+
+// RecoverInTime gives the time at which the secret is recovered when up to
+// width people are contacted at once
+func RecoverInTime(peoplePackets [][]int,
+	layerWiseChildren map[int]map[int][]int, layers, upperLayerThreshold,
+	leavesLayerThreshold int, sharePersonMap, hintPersonMap map[int]int,
+	accessOrder []int, width int, latency Latency,
+	rng *randm.Rand) (float64, bool) {
+	state := NewRecoveryState(peoplePackets, layerWiseChildren, layers,
+		upperLayerThreshold, leavesLayerThreshold, sharePersonMap,
+		hintPersonMap)
+	var pending []pendingContact
+	contacted := 0
+	now := 0.0
+	contactNext := func() {
+		person := accessOrder[contacted]
+		contacted++
+		pending = append(pending, pendingContact{
+			person: person,
+			time:   now + latency(rng),
+		})
+	}
+	for contacted < len(accessOrder) && contacted < width {
+		contactNext()
+	}
+	for len(pending) != 0 {
+		// The next answer to arrive
+		next := 0
+		for j := range pending {
+			if pending[j].time < pending[next].time {
+				next = j
+			}
+		}
+		answer := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		now = answer.time
+		if state.Add(answer.person) {
+			return now, true
+		}
+		// Based on the hints obtained, change the order of the people who
+		// have not been contacted yet
+		if len(state.HintedPeople()) != 0 {
+			utils.UpdateOrder(state.HintedPeople(), &accessOrder, contacted)
+		}
+		if contacted < len(accessOrder) {
+			contactNext()
+		}
+	}
+	return now, false
+}
+
+// ***********************Total***********************
+// ***********************Timed***********************
+// Gives the sorted times of recovery of the runs
+func GetAdditiveTimedRecoveryParallelized(simulationsDist, simulationsRun,
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	width int, latency Latency, rng *randm.Rand) ([]float64, error) {
+	return getTimedRecoveryParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, 0, width, latency, rng)
+}
+
+func GetThresholdedTimedRecoveryParallelized(simulationsDist, simulationsRun,
+	layers, threshold, upperThreshold, trustees, anonymity, absoluteThreshold,
+	subsecretsNum, width int, latency Latency,
+	rng *randm.Rand) ([]float64, error) {
+	upperLayerThreshold := utils.FloorDivide(upperThreshold*subsecretsNum, 100)
+	return getTimedRecoveryParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, upperLayerThreshold, 0, width, latency, rng)
+}
+
+func GetHintedTTimedRecoveryParallelized(simulationsDist, simulationsRun,
+	layers, threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	noOfHints, width int, latency Latency, rng *randm.Rand) ([]float64, error) {
+	if noOfHints < 1 {
+		return nil, errors.ErrInvalidInput
+	}
+	return getTimedRecoveryParallelized(simulationsDist, simulationsRun,
+		layers, threshold, trustees, anonymity, absoluteThreshold,
+		subsecretsNum, subsecretsNum, noOfHints, width, latency, rng)
+}
+
+// The hinted packets are used if there are hints
+func getTimedRecoveryParallelized(simulationsDist, simulationsRun, layers,
+	threshold, trustees, anonymity, absoluteThreshold, subsecretsNum,
+	upperLayerThreshold, noOfHints, width int,
+	latency Latency, rng *randm.Rand) ([]float64, error) {
+	// The percentage threshold should not be greater than 100%
+	if threshold > 100 {
+		return nil, errors.ErrInvalidThreshold
+	}
+	if width < 1 {
+		return nil, errors.ErrInvalidInput
+	}
+	// Based on the value of the threshold,
+	// Obtain the number of packets in the leaves layer
+	sharesNum := utils.FloorDivide((absoluteThreshold * 100), threshold)
+
+	// Get the number of shares needed for recovering the subsecrets
+	// The subsecrets have a fixed threshold
+	leavesLayerThreshold := absoluteThreshold
+
+	timesChannel := make(chan float64, simulationsDist*simulationsRun)
+
+	var wg sync.WaitGroup
+
+	for k := 0; k < simulationsDist; k++ {
+		// Obtain the packets to be distributed among people
+		var peoplePackets [][]int
+		var layerWiseChildren map[int]map[int][]int
+		var sharePersonMap, hintPersonMap map[int]int
+		var err error
+		if noOfHints == 0 {
+			peoplePackets, layerWiseChildren, err = CreatePeoplePacketsFixedTh(
+				layers, threshold, trustees, anonymity, subsecretsNum,
+				sharesNum, rng)
+		} else {
+			peoplePackets, layerWiseChildren, sharePersonMap, hintPersonMap,
+				err = CreatePeopleHintedTPacketsFixedTh(layers, threshold,
+				trustees, anonymity, subsecretsNum, sharesNum, noOfHints, rng)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wg.Add(1)
+
+		go TotalTimedRecoveryParallelized(peoplePackets, layerWiseChildren,
+			layers, upperLayerThreshold, leavesLayerThreshold, sharePersonMap,
+			hintPersonMap, simulationsRun, width, latency,
+			randomness.Fork(rng), timesChannel, &wg)
+	}
+
+	// Wait for the routines to finish
+	wg.Wait()
+
+	// Close the channels
+	close(timesChannel)
+
+	var times []float64
+	for recoveryTime := range timesChannel {
+		times = append(times, recoveryTime)
+	}
+	sort.Float64s(times)
+	return times, nil
+}
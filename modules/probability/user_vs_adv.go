@@ -2,15 +2,18 @@ package probability
 
 import (
 	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
+	randm "math/rand"
 	"sync"
 )
 
 func GetCompProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
 	subsecretsNum int,
-	deltaTr, deltaNonTr uint16) (map[int]int, map[int]int, error) {
+	deltaTr, deltaNonTr uint16,
+	rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -37,7 +40,7 @@ func GetCompProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -46,9 +49,10 @@ func GetCompProbabilityCDFParallelized(simulationsDist, simulationsRun,
 		wg.Add(1)
 
 		go TotalCompRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
-			subsecretsNum, trustees, leavesLayerThreshold, simulationsRun,
-			deltaTr, deltaNonTr,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			subsecretsNum, trustees, leavesLayerThreshold, nil, nil,
+			simulationsRun, NoisyPriorStrategy{DeltaTr: deltaTr,
+				DeltaNonTr: deltaNonTr},
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -73,7 +77,7 @@ func GetCompWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
 	subsecretsNum int,
 	deltaTr, deltaNonTr uint16,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -100,7 +104,7 @@ func GetCompWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -110,9 +114,9 @@ func GetCompWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 
 		go TotalCompWBAdvObtRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
 			subsecretsNum, trustees, leavesLayerThreshold, simulationsRun,
-			deltaTr, deltaNonTr,
+			NoisyPriorStrategy{DeltaTr: deltaTr, DeltaNonTr: deltaNonTr},
 			obtProb, wbProb,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -136,7 +140,7 @@ func GetCompWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 func GetWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	layers, threshold, trustees, anonymity, absoluteThreshold,
 	subsecretsNum int,
-	obtProb, wbProb byte) (map[int]int, map[int]int, error) {
+	obtProb, wbProb byte, rng *randm.Rand) (map[int]int, map[int]int, error) {
 	// The percentage threshold should not be greater than 100%
 	if threshold > 100 {
 		return nil, nil, errors.ErrInvalidThreshold
@@ -163,7 +167,7 @@ func GetWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 	for k := 0; k < simulationsDist; k++ {
 		// Obtain the packets to be distributed among people
 		peoplePackets, layerWiseChildren, err := CreatePeoplePacketsFixedTh(layers,
-			threshold, trustees, anonymity, subsecretsNum, sharesNum)
+			threshold, trustees, anonymity, subsecretsNum, sharesNum, rng)
 
 		if err != nil {
 			log.Fatal(err)
@@ -171,10 +175,10 @@ func GetWBAdvObtProbabilityCDFParallelized(simulationsDist, simulationsRun,
 
 		wg.Add(1)
 
-		go TotalWBAdvObtRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
+		go TotalCompWBAdvObtRecoveryParallelized(peoplePackets, layerWiseChildren, layers,
 			subsecretsNum, trustees, leavesLayerThreshold, simulationsRun,
-			obtProb, wbProb,
-			trusteesNumChannel, contactsNumChannel, &wg)
+			RandomStrategy{}, obtProb, wbProb,
+			randomness.Fork(rng), trusteesNumChannel, contactsNumChannel, &wg)
 	}
 
 	// Wait for the routines to finish
@@ -0,0 +1,98 @@
+package randomness
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"log"
+	randm "math/rand"
+	"sync"
+
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// Source of the randomness of the shares, the salts and the packets
+// crypto/rand is used unless a seed is set for replaying an evaluation run,
+// in which case every byte is drawn from a single seeded stream
+// The seeded stream is not secure and must never be used for real secrets
+// The access orders and the simulations do not read the source, they draw
+// from the generator that their caller passes to them (see NewSeededRand and
+// Fork)
+var (
+	mutex  sync.Mutex
+	seeded *randm.Rand
+)
+
+// SetSeed makes all the following draws deterministic
+func SetSeed(seed int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	seeded = randm.New(randm.NewSource(seed))
+}
+
+// Reset switches back to crypto/rand
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	seeded = nil
+}
+
+// IsSeeded tells if the draws are deterministic
+func IsSeeded() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return seeded != nil
+}
+
+// Read fills b with random bytes in the same way as crypto/rand.Read
+func Read(b []byte) (int, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if seeded == nil {
+		return rand.Read(b)
+	}
+	return seeded.Read(b)
+}
+
+type reader struct{}
+
+func (reader) Read(b []byte) (int, error) {
+	return Read(b)
+}
+
+// Reader is the io.Reader form of Read
+var Reader io.Reader = reader{}
+
+// NewRand gives a math/rand generator seeded from the source, e.g., for
+// the generators of a simulation run without a seed
+func NewRand() *randm.Rand {
+	var b [8]byte
+	if _, err := io.ReadFull(Reader, b[:]); err != nil {
+		log.Fatalln(err)
+	}
+	return randm.New(randm.NewSource(int64(binary.BigEndian.Uint64(b[:]))))
+}
+
+// NewSeededRand gives the generator of a simulation run
+// The draws are replayed with the same seed, and without a seed the
+// generator is seeded from the source
+func NewSeededRand(seed *int64) *randm.Rand {
+	if seed == nil {
+		return NewRand()
+	}
+	return randm.New(randm.NewSource(*seed))
+}
+
+// Fork gives a generator seeded from the draws of rng
+// Every routine of a simulation takes its own generator before it is
+// started, so that the draws do not depend on the scheduling
+func Fork(rng *randm.Rand) *randm.Rand {
+	return randm.New(randm.NewSource(rng.Int63()))
+}
+
+// Stream gives the random stream for picking the kyber scalars
+// It replaces g.RandomStream(), which always reads from crypto/rand
+func Stream() cipher.Stream {
+	return random.New(Reader)
+}
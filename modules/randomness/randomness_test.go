@@ -0,0 +1,59 @@
+package randomness
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetSeed(t *testing.T) {
+	draw := func() ([]byte, int) {
+		b := make([]byte, 32)
+		if _, err := Read(b); err != nil {
+			t.Fatal(err)
+		}
+		return b, NewRand().Int()
+	}
+	SetSeed(42)
+	if !IsSeeded() {
+		t.Error("Seed not set")
+	}
+	b1, n1 := draw()
+	SetSeed(42)
+	b2, n2 := draw()
+	if !bytes.Equal(b1, b2) || n1 != n2 {
+		t.Error("Draws not reproduced with the same seed")
+	}
+	SetSeed(43)
+	b3, _ := draw()
+	if bytes.Equal(b1, b3) {
+		t.Error("Same draws with a different seed")
+	}
+	Reset()
+	if IsSeeded() {
+		t.Error("Seed not reset")
+	}
+	b4, _ := draw()
+	b5, _ := draw()
+	if bytes.Equal(b4, b5) {
+		t.Error("Same draws from crypto/rand")
+	}
+}
+
+func TestSeededRand(t *testing.T) {
+	seed := int64(5)
+	rng1, rng2 := NewSeededRand(&seed), NewSeededRand(&seed)
+	if rng1.Int63() != rng2.Int63() {
+		t.Error("Draws not reproduced with the same seed")
+	}
+	// The forks follow the draws of their parents
+	fork1, fork2 := Fork(rng1), Fork(rng2)
+	if fork1.Int63() != fork2.Int63() {
+		t.Error("Forks not reproduced with the same seed")
+	}
+	if Fork(rng1).Int63() == Fork(rng1).Int63() {
+		t.Error("Two forks give the same draws")
+	}
+	if NewSeededRand(nil) == nil {
+		t.Error("No generator without a seed")
+	}
+}
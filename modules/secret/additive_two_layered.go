@@ -3,8 +3,8 @@ package secret
 import (
 	"crypto/cipher"
 	"fmt"
+	"key_recovery/modules/randomness"
 	"log"
-	"time"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
@@ -20,6 +20,7 @@ type AdditivePacket struct {
 	Salt           [32]byte          // includes the list of salts used for each share
 	RelevantHashes [][32]byte        // includes the list of h(salt || parent secret)
 	ShareData      []*share.PriShare // share data (for now only one share)
+	Commitments    []ShareCommitment // commitments of the shares (verifiable mode only)
 }
 
 var routinesMap = map[int]int{
@@ -38,8 +39,7 @@ var xSpace int = 5000
 // information about which layer the secret is from
 func GenerateRandomXShares(g kyber.Group, t int, n int, secretKey kyber.Scalar,
 	randSeedShares cipher.Stream, xUsedCoords *[]int) []*share.PriShare {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	polynomial := share.NewPriPoly(g, t, secretKey, randSeedShares)
 	shareValsSet := polynomial.Shares(xSpace)
 	shareVals := make([]*share.PriShare, 0, n)
@@ -145,18 +145,17 @@ func GetAdditiveSharePackets(g *edwards25519.SuiteEd25519,
 	}
 	var anonymitySharePackets []AdditivePacket
 	// Randomness will be used for setting the x-coordinate of the share
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	totalShares := len(leavesData)
 	sharesPerPerson := totalShares / trustees
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		var addPacket AdditivePacket
@@ -236,8 +235,7 @@ func GetAdditiveAnonymityPackets(g *edwards25519.SuiteEd25519,
 	randSeedShares cipher.Stream, sharePackets []AdditivePacket,
 	anonymitySetSize int, maxSharesPerPerson int,
 	xUsedCoords *[]int) ([]AdditivePacket, error) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	var anonymityPackets []AdditivePacket
 	// First of all store all the secret share packets
 	anonymityPackets = append(anonymityPackets, sharePackets...)
@@ -2,8 +2,8 @@ package secret
 
 import (
 	"crypto/cipher"
+	"key_recovery/modules/randomness"
 	"log"
-	"time"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
@@ -32,18 +32,17 @@ func GetAdditiveSharePacketsTagged(g *edwards25519.SuiteEd25519,
 	}
 	var anonymitySharePackets []AdditivePacket
 	// Randomness will be used for setting the x-coordinate of the share
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	totalShares := len(leavesData)
 	sharesPerPerson := totalShares / trustees
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		sharesInfo[i] = make([]int, 0)
@@ -139,8 +138,7 @@ func GetAdditiveAnonymityPacketsTagged(g *edwards25519.SuiteEd25519,
 	map[int][]int, error) {
 	sharesInfo := make(map[int][]int)
 	hashesInfo := make(map[int][]int)
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	var anonymityPackets []AdditivePacket
 	// First of all store all the secret share packets
 	anonymityPackets = append(anonymityPackets, sharePackets...)
@@ -6,6 +6,7 @@ import (
 
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"testing"
 
@@ -230,7 +231,7 @@ func TestAdditiveOptIndisSecretRecovery(t *testing.T) {
 					t.Error(err)
 				} else {
 					accessOrder := utils.GenerateIndicesSet(tc.a)
-					utils.Shuffle(accessOrder)
+					utils.Shuffle(accessOrder, randomness.NewRand())
 					fmt.Println(accessOrder)
 					recoveredKey := AdditiveOptUsedIndisSecretRecovery(g,
 						randSeedShares,
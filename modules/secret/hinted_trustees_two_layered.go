@@ -2,6 +2,7 @@ package secret
 
 import (
 	"crypto/cipher"
+	"key_recovery/modules/randomness"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/utils"
@@ -9,7 +10,6 @@ import (
 	"key_recovery/modules/errors"
 	"log"
 	randm "math/rand"
-	"time"
 
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/group/edwards25519"
@@ -22,6 +22,7 @@ type HintedTPacket struct {
 	Nonce               [32]byte          // includes the list of salts used for each share
 	RelevantEncryptions [][]byte          // includes the list of h(salt || parent secret)
 	ShareData           []*share.PriShare // share data (for now only one share)
+	Commitments         []ShareCommitment // commitments of the shares (verifiable mode only)
 }
 
 // The packet generation does not require any x-coordinates
@@ -39,16 +40,15 @@ func GetHintedTSharePackets(g *edwards25519.SuiteEd25519,
 	var encryptionLength int
 	var anonymitySharePackets []HintedTPacket
 	// Randomness will be used for setting the x-coordinate of the share
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	totalShares := len(leavesData)
 	sharesPerPerson := totalShares / trustees
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	trusteesNums := utils.GenerateIndicesSet(trustees)
-	utils.Shuffle(trusteesNums)
+	utils.Shuffle(trusteesNums, rng)
 	hintedTrustees := trusteesNums[:noOfHints][:]
 	trusteesWiseHints := make(map[int]int)
 	for i := 0; i < trustees; i++ {
@@ -61,7 +61,7 @@ func GetHintedTSharePackets(g *edwards25519.SuiteEd25519,
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		var hPacket HintedTPacket
@@ -151,8 +151,7 @@ func GetHintedTAnonymityPackets(g *edwards25519.SuiteEd25519,
 	randSeedShares cipher.Stream, sharePackets []HintedTPacket,
 	anonymitySetSize int, maxSharesPerPerson int,
 	xUsedCoords *[]int, encryptionLength int) ([]HintedTPacket, error) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	var anonymityPackets []HintedTPacket
 	// First of all store all the secret share packets
 	anonymityPackets = append(anonymityPackets, sharePackets...)
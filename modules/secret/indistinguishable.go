@@ -3,9 +3,9 @@ package secret
 import (
 	"crypto/cipher"
 	"fmt"
+	"key_recovery/modules/randomness"
 	"log"
 	randm "math/rand"
-	"time"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/utils"
@@ -188,8 +188,7 @@ func GetSharePackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
 	var anonymityPackets []Packet
 	var encryptionLength int
 	// Randomness will be used for setting the x-coordinate of the share
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	offset := 500
 	maxCoordinateX := 500
 	totalShares := len(leavesData)
@@ -197,11 +196,11 @@ func GetSharePackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		var packet Packet
@@ -311,8 +310,7 @@ func GenerateRandomPackets(g *edwards25519.SuiteEd25519,
 func GetAnonymityPackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
 	sharePackets []Packet, anonymitySetSize int, maxSharesPerPerson int,
 	noOfLevels int, encryptionLength int, xUsedCoords *[]int) ([]Packet, error) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	var anonymityPackets []Packet
 	offset := 1000
 	maxCoordinateX := 500
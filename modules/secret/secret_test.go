@@ -3,6 +3,7 @@ package secret
 import (
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
 
@@ -99,7 +100,7 @@ func TestBasicHashedSecretRecovery(t *testing.T) {
 			tc.n, tc.a, maxSize, randSeedShares, shareVals)
 
 		accessOrder := utils.GenerateIndicesSet(tc.a)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, randomness.NewRand())
 
 		// _, err := secret.BasicHashedSecretRecovery(g,
 		// 	anonymityShareVals, accessOrder, secretKeyHash)
@@ -249,7 +250,7 @@ func TestFTOptDisSecretRecovery(t *testing.T) {
 		anonymitySet, anonymitySetSize := GetDisAnonymitySet(g, tc.n, tc.a,
 			anonymitySetMaxSize, randSeedShares, leavesData)
 		accessOrder := utils.GenerateIndicesSet(anonymitySetSize)
-		utils.Shuffle(accessOrder)
+		utils.Shuffle(accessOrder, randomness.NewRand())
 		recoveredKey := FTOptDisSecretRecovery(g, tc.th, tc.n, anonymitySetSize,
 			anonymitySet, accessOrder, secretKeyHash,
 			largestShareSetSize, hashesSlice)
@@ -550,7 +551,7 @@ func TestFTOptIndisSecretRecovery(t *testing.T) {
 					// 	t.Error("Not the right number of anonymity packets")
 					// }
 					accessOrder := utils.GenerateIndicesSet(tc.a)
-					utils.Shuffle(accessOrder)
+					utils.Shuffle(accessOrder, randomness.NewRand())
 					fmt.Println(accessOrder)
 					recoveredKey := FTOptIndisSecretRecovery(g,
 						anonymityPackets, accessOrder,
@@ -2,6 +2,7 @@ package secret
 
 import (
 	"crypto/cipher"
+	"key_recovery/modules/randomness"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/utils"
@@ -9,7 +10,6 @@ import (
 	"key_recovery/modules/errors"
 	"log"
 	randm "math/rand"
-	"time"
 
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/group/edwards25519"
@@ -20,6 +20,7 @@ type ThresholdedPacket struct {
 	Nonce               [32]byte          // includes the list of salts used for each share
 	RelevantEncryptions [][]byte          // includes the list of h(salt || parent secret)
 	ShareData           []*share.PriShare // share data (for now only one share)
+	Commitments         []ShareCommitment // commitments of the shares (verifiable mode only)
 }
 
 // This function generates thresholded shares of the secret
@@ -99,18 +100,17 @@ func GetThresholdedSharePackets(g *edwards25519.SuiteEd25519,
 	var sharePackets []ThresholdedPacket
 	encryptionLength := 0
 	// Randomness will be used for setting the x-coordinate of the share
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	totalShares := len(leavesData)
 	sharesPerPerson := totalShares / trustees
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		var thPacket ThresholdedPacket
@@ -201,8 +201,7 @@ func GetThresholdedAnonymityPackets(g *edwards25519.SuiteEd25519,
 	randSeedShares cipher.Stream, sharePackets []ThresholdedPacket,
 	anonymitySetSize int, maxSharesPerPerson int,
 	xUsedCoords *[]int, encryptionLength int) ([]ThresholdedPacket, error) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	rng := randomness.NewRand()
 	var anonymityPackets []ThresholdedPacket
 	// First of all store all the secret share packets
 	anonymityPackets = append(anonymityPackets, sharePackets...)
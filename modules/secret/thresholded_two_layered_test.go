@@ -6,6 +6,7 @@ import (
 
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
 	"testing"
@@ -286,7 +287,7 @@ func TestThOptUsedIndisSecretRecovery(t *testing.T) {
 					t.Error(err)
 				} else {
 					accessOrder := utils.GenerateIndicesSet(tc.a)
-					utils.Shuffle(accessOrder)
+					utils.Shuffle(accessOrder, randomness.NewRand())
 					recoveredKey := ThOptUsedIndisSecretRecovery(g,
 						randSeedShares,
 						anonymityPackets, accessOrder,
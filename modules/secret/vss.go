@@ -0,0 +1,478 @@
+package secret
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// **************************************************************************
+// ************Verifiable secret sharing of the leaves***************
+// **************************************************************************
+
+// The verifiable mode is only for the kyber backend: the shares of
+// cmd/split and of the GF(2^16) backend have no group to commit to the
+// polynomials in, so they cannot be made verifiable this way
+// Plain Feldman commitments (a_j*G) would be the same in every packet that
+// holds a share of the polynomial, so they would link the packets of the
+// trustees and tell them apart from the random packets
+// Every polynomial of a subsecret gets one Pedersen commitment vector
+// (a_j*G + b_j*H) instead, with one blinding polynomial b shared by all the
+// shares of the subsecret, so that every share is checked against the same
+// commitments as the other shares of its subsecret
+// The commitments and the opening b(x) of a share are sealed with AES-GCM
+// under a verification key of the packet, which is handed to its holder
+// apart from the packet
+// Every packet gets a key and the random packets get random bytes of the
+// size of a sealed commitment, so without the key of a packet nobody can
+// check its shares or link it to the other packets of its subsecrets
+// The holder checks their packet with the key and gives the key to the user
+// along with the packet, so that the user drops the invalid shares before
+// the recovery and compares the commitments of the shares of a subsecret
+// across the trustees
+
+// Label of the second generator of the Pedersen commitments
+var pedersenLabel = []byte("key_recovery pedersen base H")
+
+// Size of the verification key of a packet (AES-256)
+const VerificationKeySize = 32
+
+// Size of the nonce of a sealed commitment
+const openingNonceSize = 12
+
+// Size of the tag of a sealed commitment
+const openingTagSize = 16
+
+// Pedersen commitment of the polynomial of a subsecret along with its
+// blinding polynomial, which is only known to the dealer
+type PolynomialCommitment struct {
+	Commitments []kyber.Point // a_j*G + b_j*H for each coefficient
+	blinding    *share.PriPoly
+}
+
+// Sealed commitments of the polynomial of one share along with the opening
+// of the blinding polynomial at the x-coordinate of the share
+type ShareCommitment struct {
+	Sealed []byte // nonce and AES-GCM sealing of the commitments and b(x)
+}
+
+// PedersenBase gives the second generator H of the commitments
+// It is derived by hashing a fixed label, so nobody knows its discrete
+// logarithm with respect to the base point
+func PedersenBase(g *edwards25519.SuiteEd25519) kyber.Point {
+	return g.Point().Pick(g.XOF(pedersenLabel))
+}
+
+// Groups the leaves of the additive and the hinted schemes by their subsecret
+// keeping the order of the leaves
+func AdditiveLeafGroups(leavesData []*share.PriShare,
+	parentSubsecrets map[*share.PriShare]kyber.Scalar) [][]*share.PriShare {
+	var groups [][]*share.PriShare
+	groupIndices := make(map[kyber.Scalar]int)
+	for _, leaf := range leavesData {
+		parent := parentSubsecrets[leaf]
+		index, ok := groupIndices[parent]
+		if !ok {
+			index = len(groups)
+			groupIndices[parent] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], leaf)
+	}
+	return groups
+}
+
+// Groups the leaves of the thresholded scheme by their subsecret keeping
+// the order of the leaves
+func ThresholdedLeafGroups(leavesData []*share.PriShare,
+	parentSubsecrets map[*share.PriShare]*share.PriShare) [][]*share.PriShare {
+	var groups [][]*share.PriShare
+	groupIndices := make(map[*share.PriShare]int)
+	for _, leaf := range leavesData {
+		parent := parentSubsecrets[leaf]
+		index, ok := groupIndices[parent]
+		if !ok {
+			index = len(groups)
+			groupIndices[parent] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], leaf)
+	}
+	return groups
+}
+
+// LeafPolynomials recovers the polynomial of every group of leaves and maps
+// each leaf to the polynomial it lies on
+// All the leaves of a group must be on the same polynomial of degree
+// absoluteThreshold - 1
+func LeafPolynomials(g kyber.Group, absoluteThreshold int,
+	groups [][]*share.PriShare) (map[*share.PriShare]*share.PriPoly, error) {
+	polynomials := make(map[*share.PriShare]*share.PriPoly)
+	for _, group := range groups {
+		polynomial, err := share.RecoverPriPoly(g, group, absoluteThreshold,
+			xSpace)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
+		}
+		for _, leaf := range group {
+			if !polynomial.Eval(leaf.I).V.Equal(leaf.V) {
+				return nil, errors.ErrInconsistentLeaves
+			}
+			polynomials[leaf] = polynomial
+		}
+	}
+	return polynomials, nil
+}
+
+// NewVerificationKeys gives a fresh verification key for every packet
+func NewVerificationKeys(noOfPackets int) ([][]byte, error) {
+	keys := make([][]byte, noOfPackets)
+	for i := range keys {
+		key, err := crypto_protocols.GenerateRandomBytes(VerificationKeySize)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// Size of a sealed commitment of a polynomial of degree
+// absoluteThreshold - 1 for the group
+func sealedCommitmentSize(g *edwards25519.SuiteEd25519,
+	absoluteThreshold int) int {
+	return openingNonceSize + absoluteThreshold*g.PointLen() + g.ScalarLen() +
+		openingTagSize
+}
+
+// CommitPolynomials commits to every polynomial of the leaves once with a
+// fresh blinding polynomial and maps each leaf to the commitment of its
+// polynomial
+func CommitPolynomials(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
+	polynomials map[*share.PriShare]*share.PriPoly) map[*share.PriShare]*PolynomialCommitment {
+	h := PedersenBase(g)
+	committed := make(map[*share.PriPoly]*PolynomialCommitment)
+	commitments := make(map[*share.PriShare]*PolynomialCommitment)
+	for leaf, polynomial := range polynomials {
+		commitment, ok := committed[polynomial]
+		if !ok {
+			blinding := share.NewPriPoly(g, polynomial.Threshold(), nil,
+				randSeedShares)
+			_, coeffCommits := polynomial.Commit(nil).Info()
+			_, blindingCommits := blinding.Commit(h).Info()
+			points := make([]kyber.Point, len(coeffCommits))
+			for j := range points {
+				points[j] = g.Point().Add(coeffCommits[j], blindingCommits[j])
+			}
+			commitment = &PolynomialCommitment{Commitments: points,
+				blinding: blinding}
+			committed[polynomial] = commitment
+		}
+		commitments[leaf] = commitment
+	}
+	return commitments
+}
+
+// CommitShares gives the sealed commitments of the shares of one packet in
+// the order of the shares under the key of the packet
+// The shares which are not leaves (the random ones) get random bytes
+// instead of the sealed commitments
+func CommitShares(g *edwards25519.SuiteEd25519, absoluteThreshold int,
+	key []byte, shareData []*share.PriShare,
+	commitments map[*share.PriShare]*PolynomialCommitment) ([]ShareCommitment, error) {
+	sealedCommitments := make([]ShareCommitment, 0, len(shareData))
+	for _, shareVal := range shareData {
+		commitment, ok := commitments[shareVal]
+		if !ok {
+			sealed, err := crypto_protocols.GenerateRandomBytes(
+				sealedCommitmentSize(g, absoluteThreshold))
+			if err != nil {
+				return nil, err
+			}
+			sealedCommitments = append(sealedCommitments,
+				ShareCommitment{Sealed: sealed})
+			continue
+		}
+		sealed, err := sealCommitment(key, commitment.Commitments,
+			commitment.blinding.Eval(shareVal.I).V)
+		if err != nil {
+			return nil, err
+		}
+		sealedCommitments = append(sealedCommitments,
+			ShareCommitment{Sealed: sealed})
+	}
+	return sealedCommitments, nil
+}
+
+// The nonce is put in front of the ciphertext, which is the commitments
+// followed by the opening
+func sealCommitment(key []byte, commitments []kyber.Point,
+	blinding kyber.Scalar) ([]byte, error) {
+	if len(key) != VerificationKeySize {
+		return nil, errors.ErrInvalidVerificationKey
+	}
+	var message []byte
+	for _, point := range commitments {
+		pointBytes, err := point.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		message = append(message, pointBytes...)
+	}
+	blindingBytes, err := blinding.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	message = append(message, blindingBytes...)
+	nonce, err := crypto_protocols.GenerateRandomBytes(openingNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, crypto_protocols.GetAESGCMEncryption(key, nonce,
+		message, nil)...), nil
+}
+
+// OpenCommitment gives the commitments of the polynomial of a share and the
+// opening of the share sealed under the key
+// It fails for the random shares and for the keys of the other packets
+func OpenCommitment(g *edwards25519.SuiteEd25519, key []byte,
+	commitment ShareCommitment) ([]kyber.Point, kyber.Scalar, error) {
+	if len(key) != VerificationKeySize {
+		return nil, nil, errors.ErrInvalidVerificationKey
+	}
+	sealed := commitment.Sealed
+	if len(sealed) < sealedCommitmentSize(g, 1) ||
+		(len(sealed)-sealedCommitmentSize(g, 0))%g.PointLen() != 0 {
+		return nil, nil, errors.ErrInvalidOpening
+	}
+	message, err := crypto_protocols.GetAESGCMDecryption(key,
+		sealed[:openingNonceSize], sealed[openingNonceSize:], nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errors.ErrInvalidOpening, err)
+	}
+	pointsLength := len(message) - g.ScalarLen()
+	commitments := make([]kyber.Point, pointsLength/g.PointLen())
+	for j := range commitments {
+		commitments[j] = g.Point()
+		err = commitments[j].UnmarshalBinary(
+			message[j*g.PointLen() : (j+1)*g.PointLen()])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", errors.ErrInvalidOpening, err)
+		}
+	}
+	blinding := g.Scalar()
+	err = blinding.UnmarshalBinary(message[pointsLength:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errors.ErrInvalidOpening, err)
+	}
+	return commitments, blinding, nil
+}
+
+// Checks that s*G + r*H is the commitment polynomial evaluated at the
+// x-coordinate of the share
+func checkShare(g *edwards25519.SuiteEd25519, shareVal *share.PriShare,
+	commitments []kyber.Point, blinding kyber.Scalar) bool {
+	expected := share.NewPubPoly(g, nil, commitments).Eval(shareVal.I)
+	obtained := g.Point().Add(g.Point().Mul(shareVal.V, nil),
+		g.Point().Mul(blinding, PedersenBase(g)))
+	return expected.V.Equal(obtained)
+}
+
+// VerifyShare opens the commitments with the key of the packet and checks
+// the share against them
+func VerifyShare(g *edwards25519.SuiteEd25519, key []byte,
+	shareVal *share.PriShare, commitment ShareCommitment) bool {
+	if shareVal == nil {
+		return false
+	}
+	commitments, blinding, err := OpenCommitment(g, key, commitment)
+	if err != nil {
+		return false
+	}
+	return checkShare(g, shareVal, commitments, blinding)
+}
+
+// VerifiedShares gives the shares of a packet which are consistent with
+// their commitments under the key of the packet
+func VerifiedShares(g *edwards25519.SuiteEd25519, key []byte,
+	shareData []*share.PriShare, commitments []ShareCommitment) []*share.PriShare {
+	var verified []*share.PriShare
+	for i, shareVal := range shareData {
+		if i < len(commitments) &&
+			VerifyShare(g, key, shareVal, commitments[i]) {
+			verified = append(verified, shareVal)
+		}
+	}
+	return verified
+}
+
+// VerifySubsecretShares checks shares that claim to be of one subsecret,
+// e.g., the shares that the user has recovered a subsecret from, which
+// come from the packets of different trustees
+// keys[i] is the key of the packet of shareData[i] and commitments[i] is
+// the sealed commitment of that share
+// Every share has to be consistent with its commitments and all the
+// shares have to be under the same commitments, so a dealer who hands out
+// the shares of different polynomials for one subsecret is caught even if
+// the commitments of every trustee are consistent with their own shares
+func VerifySubsecretShares(g *edwards25519.SuiteEd25519, keys [][]byte,
+	shareData []*share.PriShare, commitments []ShareCommitment) error {
+	if len(keys) != len(shareData) || len(commitments) != len(shareData) {
+		return errors.ErrInvalidShareSet
+	}
+	var subsecretCommitments []kyber.Point
+	for i, shareVal := range shareData {
+		shareCommitments, blinding, err := OpenCommitment(g, keys[i],
+			commitments[i])
+		if err != nil {
+			return err
+		}
+		if !checkShare(g, shareVal, shareCommitments, blinding) {
+			return errors.ErrInconsistentLeaves
+		}
+		if i == 0 {
+			subsecretCommitments = shareCommitments
+			continue
+		}
+		if !CommitmentsEqual(subsecretCommitments, shareCommitments) {
+			return errors.ErrInconsistentCommitments
+		}
+	}
+	return nil
+}
+
+// CommitmentsEqual tells whether two shares are under the same commitments,
+// i.e., the same polynomial
+func CommitmentsEqual(commitments1, commitments2 []kyber.Point) bool {
+	if len(commitments1) != len(commitments2) {
+		return false
+	}
+	for j := range commitments1 {
+		if !commitments1[j].Equal(commitments2[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitAdditivePackets adds the commitments to the packets of the additive
+// scheme (both the share packets and the random ones) and gives the
+// verification keys of the packets to be handed to their holders
+func CommitAdditivePackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
+	absoluteThreshold int, polynomials map[*share.PriShare]*share.PriPoly,
+	packets []AdditivePacket) ([][]byte, error) {
+	keys, err := NewVerificationKeys(len(packets))
+	if err != nil {
+		return nil, err
+	}
+	commitments := CommitPolynomials(g, randSeedShares, polynomials)
+	for i := range packets {
+		packets[i].Commitments, err = CommitShares(g, absoluteThreshold,
+			keys[i], packets[i].ShareData, commitments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// CommitThresholdedPackets adds the commitments to the packets of the
+// thresholded scheme and gives the verification keys of the packets
+func CommitThresholdedPackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
+	absoluteThreshold int, polynomials map[*share.PriShare]*share.PriPoly,
+	packets []ThresholdedPacket) ([][]byte, error) {
+	keys, err := NewVerificationKeys(len(packets))
+	if err != nil {
+		return nil, err
+	}
+	commitments := CommitPolynomials(g, randSeedShares, polynomials)
+	for i := range packets {
+		packets[i].Commitments, err = CommitShares(g, absoluteThreshold,
+			keys[i], packets[i].ShareData, commitments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// CommitHintedTPackets adds the commitments to the packets of the hinted
+// scheme and gives the verification keys of the packets
+func CommitHintedTPackets(g *edwards25519.SuiteEd25519, randSeedShares cipher.Stream,
+	absoluteThreshold int, polynomials map[*share.PriShare]*share.PriPoly,
+	packets []HintedTPacket) ([][]byte, error) {
+	keys, err := NewVerificationKeys(len(packets))
+	if err != nil {
+		return nil, err
+	}
+	commitments := CommitPolynomials(g, randSeedShares, polynomials)
+	for i := range packets {
+		packets[i].Commitments, err = CommitShares(g, absoluteThreshold,
+			keys[i], packets[i].ShareData, commitments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Keeps the verified shares of a packet with commitments when the key of the
+// packet was handed over, and all the shares otherwise
+func verifiedShareData(g *edwards25519.SuiteEd25519, keys [][]byte, i int,
+	shareData []*share.PriShare, commitments []ShareCommitment) []*share.PriShare {
+	if commitments == nil || i >= len(keys) || keys[i] == nil {
+		return shareData
+	}
+	return VerifiedShares(g, keys[i], shareData, commitments)
+}
+
+// FilterVerifiedAdditivePackets keeps only the verified shares of the
+// packets, so the recovery does not go through the combinations of the
+// random shares
+// keys[i] is the verification key handed over with the i-th packet, and the
+// packets without commitments or without a key are kept as they are
+func FilterVerifiedAdditivePackets(g *edwards25519.SuiteEd25519,
+	packets []AdditivePacket, keys [][]byte) []AdditivePacket {
+	filtered := make([]AdditivePacket, len(packets))
+	for i, packet := range packets {
+		filtered[i] = packet
+		filtered[i].ShareData = verifiedShareData(g, keys, i, packet.ShareData,
+			packet.Commitments)
+		filtered[i].Commitments = nil
+	}
+	return filtered
+}
+
+// FilterVerifiedThresholdedPackets keeps only the verified shares of the
+// packets of the thresholded scheme
+func FilterVerifiedThresholdedPackets(g *edwards25519.SuiteEd25519,
+	packets []ThresholdedPacket, keys [][]byte) []ThresholdedPacket {
+	filtered := make([]ThresholdedPacket, len(packets))
+	for i, packet := range packets {
+		filtered[i] = packet
+		filtered[i].ShareData = verifiedShareData(g, keys, i, packet.ShareData,
+			packet.Commitments)
+		filtered[i].Commitments = nil
+	}
+	return filtered
+}
+
+// FilterVerifiedHintedTPackets keeps only the verified shares of the
+// packets of the hinted scheme
+func FilterVerifiedHintedTPackets(g *edwards25519.SuiteEd25519,
+	packets []HintedTPacket, keys [][]byte) []HintedTPacket {
+	filtered := make([]HintedTPacket, len(packets))
+	for i, packet := range packets {
+		filtered[i] = packet
+		filtered[i].ShareData = verifiedShareData(g, keys, i, packet.ShareData,
+			packet.Commitments)
+		filtered[i].Commitments = nil
+	}
+	return filtered
+}
@@ -0,0 +1,267 @@
+package secret
+
+import (
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/utils"
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+func TestAdditiveVerifiableShares(t *testing.T) {
+	g := edwards25519.NewBlakeSHA256Ed25519()
+	randSeedShares := g.RandomStream()
+	secretKey := g.Scalar().Pick(randSeedShares)
+	absoluteThreshold := 3
+	n, noOfSubsecrets, percentageLeavesLayerThreshold, a := 8, 3, 60, 16
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateAdditiveTwoLayeredOptIndisShares(g, n, secretKey,
+			randSeedShares, absoluteThreshold, noOfSubsecrets,
+			percentageLeavesLayerThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, err := GetAdditiveSharePackets(g,
+		randSeedShares, secretKey, n, absoluteThreshold, leavesData,
+		subsecrets, parentSubsecrets, &xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymityPackets, err := GetAdditiveAnonymityPackets(g, randSeedShares,
+		sharePackets, a, maxSharesPerPerson, &xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polynomials, err := LeafPolynomials(g, absoluteThreshold,
+		AdditiveLeafGroups(leavesData, parentSubsecrets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := CommitAdditivePackets(g, randSeedShares, absoluteThreshold,
+		polynomials, anonymityPackets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != a {
+		t.Fatal("Not one verification key per packet")
+	}
+
+	verifiedLeaves := 0
+	seenSealed := make(map[string]bool)
+	subsecretCommitments := make(map[string][]kyber.Point)
+	for i, packet := range anonymityPackets {
+		if len(packet.Commitments) != len(packet.ShareData) {
+			t.Fatal("Not one commitment per share")
+		}
+		verified := VerifiedShares(g, keys[i], packet.ShareData,
+			packet.Commitments)
+		for _, shareVal := range verified {
+			if _, ok := parentSubsecrets[shareVal]; !ok {
+				t.Error("Random share verified in packet", i)
+			}
+		}
+		verifiedLeaves += len(verified)
+		// Without its key the shares of a packet cannot be checked, so the
+		// packets of the trustees look like the random ones
+		otherKey := keys[(i+1)%len(keys)]
+		if len(VerifiedShares(g, otherKey, packet.ShareData,
+			packet.Commitments)) != 0 {
+			t.Error("Share verified with the key of another packet", i)
+		}
+		for j, commitment := range packet.Commitments {
+			if len(commitment.Sealed) != sealedCommitmentSize(g,
+				absoluteThreshold) {
+				t.Error("Sealed commitments of different sizes")
+			}
+			if seenSealed[string(commitment.Sealed)] {
+				t.Error("Same sealed commitment in two shares")
+			}
+			seenSealed[string(commitment.Sealed)] = true
+			parent, ok := parentSubsecrets[packet.ShareData[j]]
+			if !ok {
+				continue
+			}
+			// The leaves of a subsecret are under the same commitments in
+			// the packets of all the trustees
+			opened, _, err := OpenCommitment(g, keys[i], commitment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen, ok := subsecretCommitments[parent.String()]; ok &&
+				!CommitmentsEqual(seen, opened) {
+				t.Error("Leaves of a subsecret under different commitments")
+			}
+			subsecretCommitments[parent.String()] = opened
+		}
+	}
+	if verifiedLeaves != len(leavesData) {
+		t.Error("Verified", verifiedLeaves, "shares instead of", len(leavesData))
+	}
+
+	// A tampered share must not be accepted
+	tampered := &share.PriShare{I: sharePackets[0].ShareData[0].I,
+		V: g.Scalar().Add(sharePackets[0].ShareData[0].V, g.Scalar().One())}
+	if VerifyShare(g, keys[0], tampered, anonymityPackets[0].Commitments[0]) {
+		t.Error("Tampered share verified")
+	}
+
+	// The recovery still works after dropping the unverified shares
+	filtered := FilterVerifiedAdditivePackets(g, anonymityPackets, keys)
+	accessOrder := utils.GenerateIndicesSet(a)
+	utils.Shuffle(accessOrder, randomness.NewRand())
+	recoveredKey := AdditiveOptUsedIndisSecretRecovery(g, randSeedShares,
+		filtered, accessOrder, absoluteThreshold)
+	if !crypto_protocols.CheckValuesEqual(secretKey, recoveredKey) {
+		t.Error("Secret key not recovered from the verified shares")
+	}
+}
+
+func TestThresholdedVerifiableShares(t *testing.T) {
+	g := edwards25519.NewBlakeSHA256Ed25519()
+	randSeedShares := g.RandomStream()
+	secretKey := g.Scalar().Pick(randSeedShares)
+	absoluteThreshold := 3
+	n, a := 8, 16
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateTwoLayeredOptIndisShares(g, n, secretKey, randSeedShares,
+			absoluteThreshold, 4, 60, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polynomials, err := LeafPolynomials(g, absoluteThreshold,
+		ThresholdedLeafGroups(leavesData, parentSubsecrets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewVerificationKeys(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments, err := CommitShares(g, absoluteThreshold, keys[0],
+		leavesData, CommitPolynomials(g, randSeedShares, polynomials))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(VerifiedShares(g, keys[0], leavesData, commitments)) !=
+		len(leavesData) {
+		t.Error("Not all the leaves verified")
+	}
+	for _, leaf := range leavesData {
+		if !polynomials[leaf].Secret().Equal(parentSubsecrets[leaf].V) {
+			t.Error("Leaf mapped to the wrong polynomial")
+		}
+	}
+
+	// Only the leaves are left in the packets after the filter
+	sharePackets, maxSharesPerPerson, encryptionLength, err :=
+		GetThresholdedSharePackets(g, randSeedShares, secretKey, n,
+			absoluteThreshold, leavesData, subsecrets, parentSubsecrets,
+			&xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymityPackets, err := GetThresholdedAnonymityPackets(g, randSeedShares,
+		sharePackets, a, maxSharesPerPerson, &xUsedCoords, encryptionLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err = CommitThresholdedPackets(g, randSeedShares, absoluteThreshold,
+		polynomials, anonymityPackets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiedLeaves := 0
+	for _, packet := range FilterVerifiedThresholdedPackets(g,
+		anonymityPackets, keys) {
+		for _, shareVal := range packet.ShareData {
+			if _, ok := parentSubsecrets[shareVal]; !ok {
+				t.Error("Random share kept by the filter")
+			}
+		}
+		verifiedLeaves += len(packet.ShareData)
+	}
+	if verifiedLeaves != len(leavesData) {
+		t.Error("Kept", verifiedLeaves, "shares instead of", len(leavesData))
+	}
+
+	// Leaves of different subsecrets are not on one polynomial
+	mixed := [][]*share.PriShare{append([]*share.PriShare{},
+		leavesData[:absoluteThreshold]...)}
+	mixed[0] = append(mixed[0], leavesData[len(leavesData)-1])
+	if _, err := LeafPolynomials(g, absoluteThreshold, mixed); err == nil {
+		t.Error("Inconsistent leaves accepted")
+	}
+}
+
+func TestInconsistentDealer(t *testing.T) {
+	g := edwards25519.NewBlakeSHA256Ed25519()
+	randSeedShares := g.RandomStream()
+	secretKey := g.Scalar().Pick(randSeedShares)
+	absoluteThreshold, n := 3, 4
+	polynomial := share.NewPriPoly(g, absoluteThreshold, secretKey,
+		randSeedShares)
+	shares := polynomial.Shares(n)
+	polynomials := make(map[*share.PriShare]*share.PriPoly)
+	for _, shareVal := range shares {
+		polynomials[shareVal] = polynomial
+	}
+	commitments := CommitPolynomials(g, randSeedShares, polynomials)
+	// Every trustee gets one share in a packet of its own
+	keys, err := NewVerificationKeys(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := make([]ShareCommitment, n)
+	for i, shareVal := range shares {
+		shareCommitments, err := CommitShares(g, absoluteThreshold, keys[i],
+			[]*share.PriShare{shareVal}, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed[i] = shareCommitments[0]
+	}
+	if err := VerifySubsecretShares(g, keys, shares, sealed); err != nil {
+		t.Fatal("Honest shares rejected", err)
+	}
+
+	// The dealer gives the last trustee a share off the polynomial
+	last := n - 1
+	wrongShares := append([]*share.PriShare{}, shares...)
+	wrongShares[last] = &share.PriShare{I: shares[last].I,
+		V: g.Scalar().Add(shares[last].V, g.Scalar().One())}
+	if VerifyShare(g, keys[last], wrongShares[last], sealed[last]) {
+		t.Error("Share off the polynomial verified")
+	}
+	if err := VerifySubsecretShares(g, keys, wrongShares,
+		sealed); err != errors.ErrInconsistentLeaves {
+		t.Error("Share off the polynomial accepted", err)
+	}
+
+	// The dealer gives the last trustee a share of another polynomial of the
+	// same secret along with commitments to that polynomial
+	otherPolynomial := share.NewPriPoly(g, absoluteThreshold, secretKey,
+		randSeedShares)
+	otherShare := otherPolynomial.Eval(shares[last].I)
+	otherCommitments := CommitPolynomials(g, randSeedShares,
+		map[*share.PriShare]*share.PriPoly{otherShare: otherPolynomial})
+	otherSealed, err := CommitShares(g, absoluteThreshold, keys[last],
+		[]*share.PriShare{otherShare}, otherCommitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongShares[last] = otherShare
+	wrongSealed := append([]ShareCommitment{}, sealed...)
+	wrongSealed[last] = otherSealed[0]
+	// The trustee alone cannot tell
+	if !VerifyShare(g, keys[last], otherShare, otherSealed[0]) {
+		t.Error("Share of the other polynomial not verified")
+	}
+	if err := VerifySubsecretShares(g, keys, wrongShares,
+		wrongSealed); err != errors.ErrInconsistentCommitments {
+		t.Error("Shares of different polynomials accepted", err)
+	}
+}
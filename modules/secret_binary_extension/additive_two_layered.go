@@ -1,9 +1,10 @@
 package secret_binary_extension
 
 import (
+	"key_recovery/modules/randomness"
 	"log"
+	randm "math/rand"
 
-	"crypto/rand"
 	"encoding/binary"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
@@ -15,13 +16,27 @@ type AdditivePacket struct {
 	Salt           [32]byte          // includes the list of salts used for each share
 	RelevantHashes [][32]byte        // includes the list of h(salt || parent secret)
 	ShareData      []shamir.PriShare // share data (for now only one share)
+	// Parameters of the verifier of the secret key (RelevantHashes[0])
+	Verifier crypto_protocols.VerifierParams
+	// Decoded from a format version before 3, whose hashes are not derived
+	// with the role labels
+	Legacy bool
 }
 
-var routinesMap = map[int]int{
-	20:  2,
-	40:  4,
-	100: 8,
-	400: 16,
+// Without weights, the shares are distributed almost uniformly with the
+// leftovers given to random trustees
+func GetPersonWiseShareDistribution(trustees, totalShares int,
+	weights []int, rng *randm.Rand) ([]int, int, error) {
+	if weights == nil {
+		personWiseShareDistribution, maxSharesPerPerson :=
+			utils.GetPersonWiseShareNumber(trustees, totalShares,
+				totalShares/trustees, rng)
+		return personWiseShareDistribution, maxSharesPerPerson, nil
+	}
+	if len(weights) != trustees {
+		return nil, -1, errors.ErrInvalidSliceLength
+	}
+	return utils.GetWeightedPersonWiseShareNumber(weights, totalShares, rng)
 }
 
 // This function simply provides the shares
@@ -86,7 +101,7 @@ func GenerateAdditiveIndisUpperLayers(f shamir.Field, secretKey []uint16,
 	for i := 0; i < noOfSubsecrets-1; i++ {
 		(*subsecrets) = append((*subsecrets), []uint16{})
 		for j := 0; j < len(secretKey); j++ {
-			if _, err := rand.Read(buf); err != nil {
+			if _, err := randomness.Read(buf); err != nil {
 				log.Fatalln(err)
 			}
 			shareVal := binary.BigEndian.Uint16(buf)
@@ -133,20 +148,34 @@ func GetAdditiveSharePackets(f shamir.Field, secretKey []uint16,
 	leavesData []shamir.PriShare, subsecrets [][]uint16,
 	parentSubsecrets map[uint16][]uint16,
 	xUsedCoords *[]uint16) ([]AdditivePacket, int, error) {
+	return GetAdditiveSharePacketsWeighted(f, secretKey, trustees,
+		absoluteThreshold, leavesData, subsecrets, parentSubsecrets,
+		xUsedCoords, nil)
+}
+
+// The trustees receive the leaves in proportion to their weights
+// Without weights, the leaves are distributed almost uniformly
+func GetAdditiveSharePacketsWeighted(f shamir.Field, secretKey []uint16,
+	trustees, absoluteThreshold int,
+	leavesData []shamir.PriShare, subsecrets [][]uint16,
+	parentSubsecrets map[uint16][]uint16,
+	xUsedCoords *[]uint16, weights []int) ([]AdditivePacket, int, error) {
 	if absoluteThreshold > trustees {
 		return nil, -1, errors.ErrInvalidThreshold
 	}
 	var anonymitySharePackets []AdditivePacket
 	totalShares := len(leavesData)
-	sharesPerPerson := totalShares / trustees
+	rng := randomness.NewRand()
 	// Get how many shares each person should get
-	personWiseShareDistribution, maxSharesPerPerson :=
-		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+	personWiseShareDistribution, maxSharesPerPerson, err :=
+		GetPersonWiseShareDistribution(trustees, totalShares, weights, rng)
+	if err != nil {
+		return nil, -1, err
+	}
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		var addPacket AdditivePacket
@@ -177,14 +206,15 @@ func GenerateAdditivePerPersonSharePackets(noOfSharesReceived int,
 	// Convert the secret key to bytes for getting the hash
 	secretKeyBytes := shamir.Uint16sToBytes(secretKey)
 	// Get the salted hash of the secret key
-	secretHash := crypto_protocols.GetSaltedHash((*addPacket).Salt, secretKeyBytes)
+	secretHash := crypto_protocols.GetLabelledSaltedHash((*addPacket).Salt,
+		secretKeyBytes, crypto_protocols.LabelFinalSecretCheck)
 	(*addPacket).RelevantHashes = append((*addPacket).RelevantHashes, secretHash)
 	for j := 0; j < noOfSharesReceived; j++ {
 		leafShareVal := leavesData[leavesIndices[*currentIndex]]
 		parentSubsecret := parentSubsecrets[leafShareVal.X]
 		parentSubsecretBytes := shamir.Uint16sToBytes(parentSubsecret)
-		subsecretHash := crypto_protocols.GetSaltedHash((*addPacket).Salt,
-			parentSubsecretBytes)
+		subsecretHash := crypto_protocols.GetLabelledSaltedHash((*addPacket).Salt,
+			parentSubsecretBytes, crypto_protocols.LabelLeafCheck)
 		// If the share of the same subsecrets are being stored
 		// then do not store the hash twice
 		// To keep the packets indistinguishable, store some random blobs
@@ -211,7 +241,7 @@ func GenerateAdditiveRandomPackets(noOfPackets, relevantSize int,
 	// This is for getting random Y's
 	bufY := make([]byte, 2*relevantSize)
 	for j := 0; j < (noOfPackets); {
-		if _, err := rand.Read(bufX); err != nil {
+		if _, err := randomness.Read(bufX); err != nil {
 			log.Fatalln(err)
 		}
 		x := binary.BigEndian.Uint16(bufX)
@@ -226,7 +256,7 @@ func GenerateAdditiveRandomPackets(noOfPackets, relevantSize int,
 		if exists {
 			continue
 		}
-		if _, err := rand.Read(bufY); err != nil {
+		if _, err := randomness.Read(bufY); err != nil {
 			log.Fatalln(err)
 		}
 		y := shamir.BytesToUint16s(bufY)
@@ -268,3 +298,39 @@ func GetAdditiveAnonymityPackets(sharePackets []AdditivePacket,
 	}
 	return anonymityPackets, nil
 }
+
+// HardenAdditivePackets replaces the salted hash of the secret key in the
+// packets with the verifier given by the parameters
+// The packets of the trustees are the ones whose first hash is the fast
+// verifier of the secret key, while the random packets only record the
+// parameters so that all the packets look the same
+func HardenAdditivePackets(packets []AdditivePacket, secretKey []uint16,
+	params crypto_protocols.VerifierParams) error {
+	if err := params.Check(); err != nil {
+		return err
+	}
+	secretKeyBytes := shamir.Uint16sToBytes(secretKey)
+	for i := range packets {
+		if len(packets[i].RelevantHashes) == 0 {
+			return errors.ErrInvalidInput
+		}
+		currentHash, err := crypto_protocols.GetVerifierHash(packets[i].Salt,
+			secretKeyBytes, packets[i].Verifier)
+		if err != nil {
+			return err
+		}
+		if crypto_protocols.CheckHashesEqual(packets[i].RelevantHashes[0],
+			currentHash) {
+			verifierHash, err := crypto_protocols.GetVerifierHash(
+				packets[i].Salt, secretKeyBytes, params)
+			if err != nil {
+				return err
+			}
+			// The hashes may be shared with the share packets
+			packets[i].RelevantHashes = append([][32]byte{verifierHash},
+				packets[i].RelevantHashes[1:]...)
+		}
+		packets[i].Verifier = params
+	}
+	return nil
+}
@@ -1,9 +1,9 @@
 package secret_binary_extension
 
 import (
+	"key_recovery/modules/randomness"
 	"log"
 
-	"crypto/rand"
 	"encoding/binary"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
@@ -29,14 +29,15 @@ func GetAdditiveSharePacketsTagged(f shamir.Field,
 	var anonymitySharePackets []AdditivePacket
 	totalShares := len(leavesData)
 	sharesPerPerson := totalShares / trustees
+	rng := randomness.NewRand()
 	// Get how many shares each person should get
 	personWiseShareDistribution, maxSharesPerPerson :=
 		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+			totalShares, sharesPerPerson, rng)
 	// Indices of the leaves
 	leavesIndices := utils.GenerateIndicesSet(totalShares)
 	// Randomize the leaves that the trustees should receive
-	utils.Shuffle(leavesIndices)
+	utils.Shuffle(leavesIndices, rng)
 	currentIndex := 0
 	for i := 0; i < trustees; i++ {
 		sharesInfo[i] = make([]int, 0)
@@ -70,14 +71,15 @@ func GenerateAdditivePerPersonSharePacketsTagged(noOfSharesReceived int,
 	// Convert the secret key to bytes for getting the hash
 	secretKeyBytes := shamir.Uint16sToBytes(secretKey)
 	// Get the salted hash of the secret key
-	secretHash := crypto_protocols.GetSaltedHash((*addPacket).Salt, secretKeyBytes)
+	secretHash := crypto_protocols.GetLabelledSaltedHash((*addPacket).Salt,
+		secretKeyBytes, crypto_protocols.LabelFinalSecretCheck)
 	(*addPacket).RelevantHashes = append((*addPacket).RelevantHashes, secretHash)
 	for j := 0; j < noOfSharesReceived; j++ {
 		leafShareVal := leavesData[leavesIndices[*currentIndex]]
 		parentSubsecret := parentSubsecrets[leafShareVal.X]
 		parentSubsecretBytes := shamir.Uint16sToBytes(parentSubsecret)
-		subsecretHash := crypto_protocols.GetSaltedHash((*addPacket).Salt,
-			parentSubsecretBytes)
+		subsecretHash := crypto_protocols.GetLabelledSaltedHash((*addPacket).Salt,
+			parentSubsecretBytes, crypto_protocols.LabelLeafCheck)
 		// If the share of the same subsecrets are being stored
 		// then do not store the hash twice
 		// To keep the packets indistinguishable, store some random blobs
@@ -108,7 +110,7 @@ func GenerateAdditiveRandomPacketsTagged(noOfPackets int,
 		bufX := make([]byte, 2)
 		// This is for getting random Y's
 		bufY := make([]byte, 2*relevantSize)
-		if _, err := rand.Read(bufX); err != nil {
+		if _, err := randomness.Read(bufX); err != nil {
 			log.Fatalln(err)
 		}
 		x := binary.BigEndian.Uint16(bufX)
@@ -123,7 +125,7 @@ func GenerateAdditiveRandomPacketsTagged(noOfPackets int,
 		if exists {
 			continue
 		}
-		if _, err := rand.Read(bufY); err != nil {
+		if _, err := randomness.Read(bufY); err != nil {
 			log.Fatalln(err)
 		}
 		y := shamir.BytesToUint16s(bufY)
@@ -1,11 +1,13 @@
 package secret_binary_extension
 
 import (
+	"context"
 
 	// randm "math/rand"
 
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 	"log"
@@ -158,12 +160,14 @@ func TestGetAdditiveSharePackets(t *testing.T) {
 						}
 						// Check that the subsecret is stored within the relevant hashes
 						if !crypto_protocols.GetSaltedKeyMembershipBinExt(relevantHashes,
-							relevantSalt, parentSubsecrets[shareData.X]) {
+							relevantSalt, parentSubsecrets[shareData.X],
+							crypto_protocols.LabelLeafCheck) {
 							t.Error("susbecret not included")
 						}
 						// Check that the main secret is stored within the relevant hashes
 						if !crypto_protocols.GetSaltedKeyMembershipBinExt(relevantHashes,
-							relevantSalt, parentSubsecrets[shareData.X]) {
+							relevantSalt, secretKey,
+							crypto_protocols.LabelFinalSecretCheck) {
 							t.Error("secret not included")
 						}
 					}
@@ -313,12 +317,15 @@ func TestAdditiveOptIndisSecretRecovery(t *testing.T) {
 					t.Error(err)
 				} else {
 					accessOrder := utils.GenerateIndicesSet(tc.a)
-					utils.Shuffle(accessOrder)
+					utils.Shuffle(accessOrder, randomness.NewRand())
 					// accessOrder := []int{3, 7, 5, 2, 1, 0, 8, 4, 6, 9}
 					fmt.Println(accessOrder)
-					recoveredKey := AdditiveOptUsedIndisSecretRecoveryParallelized(f,
-						anonymityPackets, accessOrder,
-						absoluteThreshold)
+					recoveredKey, err := AdditiveOptUsedIndisSecretRecoveryParallelized(context.Background(),
+						f, anonymityPackets, accessOrder,
+						absoluteThreshold, 0)
+					if err != nil {
+						t.Fatal(err)
+					}
 					// fmt.Println(recoveredKey)
 					if !crypto_protocols.CompareUint16s(secretKey,
 						recoveredKey) {
@@ -329,3 +336,81 @@ func TestAdditiveOptIndisSecretRecovery(t *testing.T) {
 		}
 	}
 }
+
+func TestHardenAdditivePackets(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold := 5, 8, 3
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateAdditiveTwoLayeredOptIndisShares(f, n, secretKey,
+			absoluteThreshold, 3, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, _ := GetAdditiveSharePackets(f,
+		secretKey, n, absoluteThreshold, leavesData, subsecrets,
+		parentSubsecrets, &xUsedCoords)
+	packets, _ := GetAdditiveAnonymityPackets(sharePackets, anonymitySetSize,
+		maxSharesPerPerson, len(secretKey), &xUsedCoords)
+	params := crypto_protocols.VerifierParams{LogN: 10, R: 8, P: 1}
+	if err := HardenAdditivePackets(packets, secretKey, params); err != nil {
+		t.Fatal(err)
+	}
+	secretKeyBytes := shamir.Uint16sToBytes(secretKey)
+	for i, packet := range packets {
+		if packet.Verifier != params {
+			t.Error("Parameters not recorded", i)
+		}
+		hardened, err := crypto_protocols.GetVerifierHash(packet.Salt,
+			secretKeyBytes, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isTrustee := i < n
+		if crypto_protocols.CheckHashesEqual(packet.RelevantHashes[0],
+			hardened) != isTrustee {
+			t.Error("Verifier not replaced in the packets of the trustees", i)
+		}
+		if crypto_protocols.GetSaltedKeyMembershipBinExt(packet.RelevantHashes,
+			packet.Salt, secretKey, crypto_protocols.LabelFinalSecretCheck) {
+			t.Error("Fast verifier left in the packet", i)
+		}
+	}
+	recoverer := NewAdditiveRecoverer(f, absoluteThreshold)
+	for _, packet := range packets {
+		if err := recoverer.AddPacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret, done, err := recoverer.TryRecover(context.Background())
+	if err != nil || !done || string(secret) != string(secretKey8) {
+		t.Error("Secret not recovered with the hardened verifier", err)
+	}
+	// The dealer keeps the verifier in the packets it generates again
+	dealer, err := NewAdditiveDealer(f, secretKey, absoluteThreshold,
+		subsecrets, parentSubsecrets, xUsedCoords, packets, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := dealer.ReplaceTrustee(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoverer = NewAdditiveRecoverer(f, absoluteThreshold)
+	for _, slot := range changed {
+		if dealer.AnonymityPackets[slot].Verifier != params {
+			t.Error("Parameters not kept by the dealer", slot)
+		}
+	}
+	for _, packet := range dealer.AnonymityPackets {
+		if err := recoverer.AddPacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret, done, err = recoverer.TryRecover(context.Background())
+	if err != nil || !done || string(secret) != string(secretKey8) {
+		t.Error("Secret not recovered after replacing a trustee", err)
+	}
+}
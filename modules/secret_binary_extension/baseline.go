@@ -1,8 +1,8 @@
 package secret_binary_extension
 
 import (
-	"crypto/rand"
 	"encoding/binary"
+	"key_recovery/modules/randomness"
 	"log"
 
 	"key_recovery/modules/shamir"
@@ -47,7 +47,7 @@ func GetDisAnonymitySet(f shamir.Field, n int, size int, maxSize int,
 	// the set of shares is the anonymity set
 	if anonymitySetSize > n {
 		for i := len(shares); i < anonymitySetSize; {
-			if _, err := rand.Read(buf); err != nil {
+			if _, err := randomness.Read(buf); err != nil {
 				log.Fatalln(err)
 			}
 			x := binary.BigEndian.Uint16(buf)
@@ -63,7 +63,7 @@ func GetDisAnonymitySet(f shamir.Field, n int, size int, maxSize int,
 				continue
 			}
 			(*xUsedCoords) = append((*xUsedCoords), x)
-			if _, err := rand.Read(bufShare); err != nil {
+			if _, err := randomness.Read(bufShare); err != nil {
 				log.Fatalln(err)
 			}
 			y := shamir.BytesToUint16s(bufShare)
@@ -1,8 +1,10 @@
 package secret_binary_extension
 
 import (
+	"context"
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 	"log"
@@ -112,9 +114,9 @@ func TestBasicHashedSecretRecovery(t *testing.T) {
 		anonPackets, _ := GetDisAnonymitySet(f, tc.n, tc.a, 200, shareVals,
 			&xUsedCoords, len(secretKey))
 		accessOrder := utils.GenerateIndicesSet(tc.a)
-		utils.Shuffle(accessOrder)
-		recovered, err := BasicHashedSecretRecoveryParallelized(f, anonPackets, accessOrder,
-			secretKeyHash)
+		utils.Shuffle(accessOrder, randomness.NewRand())
+		recovered, err := BasicHashedSecretRecoveryParallelized(context.Background(), f, anonPackets, accessOrder,
+			secretKeyHash, 0)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -0,0 +1,371 @@
+package secret_binary_extension
+
+import (
+	"bytes"
+	"encoding/binary"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+)
+
+// Binary wire format of the packets (version 3)
+// All the integers are stored in big-endian order
+//
+//	magic       [4]byte   "KRPK"
+//	version     uint8     PacketFormatVersion
+//	scheme      uint8     SchemeTagAdditive, SchemeTagThresholded or
+//	                      SchemeTagHinted
+//	width       uint8     bits of a field element (16 for GF(2^16))
+//	reserved    uint8     0
+//	salt/nonce  [32]byte
+//
+// Additive packets then store
+//
+//	uint8 log2 N, uint8 r and uint8 p of the verifier of the secret key
+//	      (all 0 for the fast verifier)
+//	uint32 number of hashes, followed by the [32]byte hashes
+//	uint32 number of shares, followed by the shares
+//
+// Thresholded and hinted packets then store
+//
+//	uint32 number of encryption lists, each one being
+//	    uint32 number of encryptions, each one being
+//	        uint32 length, followed by the bytes of the encryption
+//	uint32 number of share lists, each one being
+//	    uint32 number of shares, followed by the shares
+//
+// A share is stored as
+//
+//	uint16 X, uint32 number of Y values, followed by the uint16 Y values
+//
+// Version 2 is the same without the parameters of the verifier, and such
+// packets are still decoded with the fast verifier
+// Version 1 has the same layout as version 2, but its salted hashes are
+// SHA256(secret || salt) and its marker keys are the values themselves
+// instead of being derived with the role labels
+// Such packets are decoded as legacy packets, which are still recovered with
+// these older checks, and they are encoded again as version 1 so that they
+// keep these semantics; encoding a legacy packet does not upgrade it
+//
+// The scheme tag only depends on the scheme used for generating the packets
+// Packets of the trustees and the anonymity packets are generated with the
+// same structure, so their encodings cannot be told apart from the header
+const (
+	PacketFormatVersion  = 3
+	SchemeTagAdditive    = 1
+	SchemeTagThresholded = 2
+	SchemeTagHinted      = 3
+	// Packets larger than this are rejected before decoding
+	MaxPacketSize = 16 << 20
+)
+
+var packetMagic = [4]byte{'K', 'R', 'P', 'K'}
+
+const (
+	// Last version before the role labels
+	legacyPacketFormatVersion = 1
+	// First version with the parameters of the verifier
+	verifierPacketFormatVersion = 3
+	packetHeaderSize            = 8
+	fieldWidthBits              = 16
+	// Smallest possible encoding of a share (X and the number of Y values)
+	minShareSize = 6
+)
+
+// ****************************************************************************
+// Encoding
+
+type packetWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *packetWriter) writeUint16(v uint16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	w.buf.Write(b[:])
+}
+
+func (w *packetWriter) writeUint32(v int) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	w.buf.Write(b[:])
+}
+
+func (w *packetWriter) writeHeader(schemeTag byte, saltOrNonce [32]byte,
+	legacy bool) {
+	version := byte(PacketFormatVersion)
+	if legacy {
+		version = legacyPacketFormatVersion
+	}
+	w.buf.Write(packetMagic[:])
+	w.buf.Write([]byte{version, schemeTag, fieldWidthBits, 0})
+	w.buf.Write(saltOrNonce[:])
+}
+
+func (w *packetWriter) writeShares(shares []shamir.PriShare) {
+	w.writeUint32(len(shares))
+	for _, share := range shares {
+		w.writeUint16(share.X)
+		w.writeUint32(len(share.Y))
+		for _, y := range share.Y {
+			w.writeUint16(y)
+		}
+	}
+}
+
+func (w *packetWriter) writeEncryptedPacket(schemeTag byte, nonce [32]byte,
+	relevantEncryptions [][][]byte, shareData [][]shamir.PriShare,
+	legacy bool) []byte {
+	w.writeHeader(schemeTag, nonce, legacy)
+	w.writeUint32(len(relevantEncryptions))
+	for _, encryptions := range relevantEncryptions {
+		w.writeUint32(len(encryptions))
+		for _, encryption := range encryptions {
+			w.writeUint32(len(encryption))
+			w.buf.Write(encryption)
+		}
+	}
+	w.writeUint32(len(shareData))
+	for _, shares := range shareData {
+		w.writeShares(shares)
+	}
+	return w.buf.Bytes()
+}
+
+// MarshalAdditivePacket encodes the packet in the binary wire format
+func MarshalAdditivePacket(packet AdditivePacket) []byte {
+	var w packetWriter
+	w.writeHeader(SchemeTagAdditive, packet.Salt, packet.Legacy)
+	// Legacy packets are encoded again as version 1, which has no parameters
+	// of the verifier
+	if !packet.Legacy {
+		w.buf.Write([]byte{packet.Verifier.LogN, packet.Verifier.R,
+			packet.Verifier.P})
+	}
+	w.writeUint32(len(packet.RelevantHashes))
+	for _, hash := range packet.RelevantHashes {
+		w.buf.Write(hash[:])
+	}
+	w.writeShares(packet.ShareData)
+	return w.buf.Bytes()
+}
+
+// MarshalThresholdedPacket encodes the packet in the binary wire format
+func MarshalThresholdedPacket(packet ThresholdedPacket) []byte {
+	var w packetWriter
+	return w.writeEncryptedPacket(SchemeTagThresholded, packet.Nonce,
+		packet.RelevantEncryptions, packet.ShareData, packet.Legacy)
+}
+
+// MarshalHintedTPacket encodes the packet in the binary wire format
+func MarshalHintedTPacket(packet HintedTPacket) []byte {
+	var w packetWriter
+	return w.writeEncryptedPacket(SchemeTagHinted, packet.Nonce,
+		packet.RelevantEncryptions, packet.ShareData, packet.Legacy)
+}
+
+// ****************************************************************************
+// Decoding
+
+type packetReader struct {
+	data    []byte
+	err     error
+	version byte
+}
+
+func (r *packetReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = errors.ErrTruncatedPacket
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *packetReader) readUint16() uint16 {
+	b := r.read(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+// Reads a count of items where every item takes at least minItemSize bytes
+// The count is checked against the remaining input so that a corrupted
+// count cannot lead to huge allocations
+func (r *packetReader) readCount(minItemSize int) int {
+	b := r.read(4)
+	if b == nil {
+		return 0
+	}
+	count := binary.BigEndian.Uint32(b)
+	if uint64(count)*uint64(minItemSize) > uint64(len(r.data)) {
+		r.err = errors.ErrTruncatedPacket
+		return 0
+	}
+	return int(count)
+}
+
+func (r *packetReader) readShares() []shamir.PriShare {
+	count := r.readCount(minShareSize)
+	var shares []shamir.PriShare
+	for i := 0; i < count && r.err == nil; i++ {
+		var share shamir.PriShare
+		share.X = r.readUint16()
+		noOfYs := r.readCount(2)
+		for j := 0; j < noOfYs && r.err == nil; j++ {
+			share.Y = append(share.Y, r.readUint16())
+		}
+		shares = append(shares, share)
+	}
+	return shares
+}
+
+func (r *packetReader) readEncryptedPacket() ([][][]byte, [][]shamir.PriShare) {
+	var relevantEncryptions [][][]byte
+	var shareData [][]shamir.PriShare
+	noOfLists := r.readCount(4)
+	for i := 0; i < noOfLists && r.err == nil; i++ {
+		var encryptions [][]byte
+		noOfEncryptions := r.readCount(4)
+		for j := 0; j < noOfEncryptions && r.err == nil; j++ {
+			length := r.readCount(1)
+			encryption := r.read(length)
+			if r.err == nil {
+				encryptions = append(encryptions,
+					append([]byte(nil), encryption...))
+			}
+		}
+		relevantEncryptions = append(relevantEncryptions, encryptions)
+	}
+	noOfLists = r.readCount(4)
+	for i := 0; i < noOfLists && r.err == nil; i++ {
+		shareData = append(shareData, r.readShares())
+	}
+	return relevantEncryptions, shareData
+}
+
+// Checks the header and returns the reader positioned after the salt/nonce
+func newPacketReader(data []byte, schemeTag byte) (*packetReader,
+	[32]byte, error) {
+	var saltOrNonce [32]byte
+	tag, err := PacketSchemeTag(data)
+	if err != nil {
+		return nil, saltOrNonce, err
+	}
+	if tag != schemeTag {
+		return nil, saltOrNonce, errors.ErrPacketSchemeMismatch
+	}
+	r := &packetReader{data: data[packetHeaderSize:], version: data[4]}
+	copy(saltOrNonce[:], r.read(len(saltOrNonce)))
+	return r, saltOrNonce, r.err
+}
+
+// The packets before the role labels
+func (r *packetReader) legacy() bool {
+	return r.version <= legacyPacketFormatVersion
+}
+
+// Input must be consumed completely
+func (r *packetReader) finish() error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 0 {
+		return errors.ErrTrailingPacketData
+	}
+	return nil
+}
+
+// PacketSchemeTag checks the header of an encoded packet and returns the
+// tag of the scheme that was used for generating it
+func PacketSchemeTag(data []byte) (byte, error) {
+	if len(data) > MaxPacketSize {
+		return 0, errors.ErrPacketTooLarge
+	}
+	if len(data) < packetHeaderSize {
+		return 0, errors.ErrTruncatedPacket
+	}
+	if !bytes.Equal(data[:4], packetMagic[:]) {
+		return 0, errors.ErrInvalidPacket
+	}
+	if data[4] < 1 || data[4] > PacketFormatVersion {
+		return 0, errors.ErrUnsupportedPacketVersion
+	}
+	if data[6] != fieldWidthBits || data[7] != 0 {
+		return 0, errors.ErrInvalidPacket
+	}
+	switch data[5] {
+	case SchemeTagAdditive, SchemeTagThresholded, SchemeTagHinted:
+		return data[5], nil
+	default:
+		return 0, errors.ErrUnknownScheme
+	}
+}
+
+// UnmarshalAdditivePacket decodes a packet in the binary wire format
+func UnmarshalAdditivePacket(data []byte) (AdditivePacket, error) {
+	var packet AdditivePacket
+	r, salt, err := newPacketReader(data, SchemeTagAdditive)
+	if err != nil {
+		return packet, err
+	}
+	packet.Salt = salt
+	packet.Legacy = r.legacy()
+	if r.version >= verifierPacketFormatVersion {
+		if params := r.read(3); params != nil {
+			packet.Verifier = crypto_protocols.VerifierParams{LogN: params[0],
+				R: params[1], P: params[2]}
+			if err := packet.Verifier.Check(); err != nil {
+				return AdditivePacket{}, errors.ErrInvalidPacket
+			}
+		}
+	}
+	noOfHashes := r.readCount(32)
+	for i := 0; i < noOfHashes && r.err == nil; i++ {
+		var hash [32]byte
+		copy(hash[:], r.read(32))
+		packet.RelevantHashes = append(packet.RelevantHashes, hash)
+	}
+	packet.ShareData = r.readShares()
+	if err := r.finish(); err != nil {
+		return AdditivePacket{}, err
+	}
+	return packet, nil
+}
+
+// UnmarshalThresholdedPacket decodes a packet in the binary wire format
+func UnmarshalThresholdedPacket(data []byte) (ThresholdedPacket, error) {
+	var packet ThresholdedPacket
+	r, nonce, err := newPacketReader(data, SchemeTagThresholded)
+	if err != nil {
+		return packet, err
+	}
+	packet.Nonce = nonce
+	packet.Legacy = r.legacy()
+	packet.RelevantEncryptions, packet.ShareData = r.readEncryptedPacket()
+	if err := r.finish(); err != nil {
+		return ThresholdedPacket{}, err
+	}
+	return packet, nil
+}
+
+// UnmarshalHintedTPacket decodes a packet in the binary wire format
+func UnmarshalHintedTPacket(data []byte) (HintedTPacket, error) {
+	var packet HintedTPacket
+	r, nonce, err := newPacketReader(data, SchemeTagHinted)
+	if err != nil {
+		return packet, err
+	}
+	packet.Nonce = nonce
+	packet.Legacy = r.legacy()
+	packet.RelevantEncryptions, packet.ShareData = r.readEncryptedPacket()
+	if err := r.finish(); err != nil {
+		return HintedTPacket{}, err
+	}
+	return packet, nil
+}
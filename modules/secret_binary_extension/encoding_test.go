@@ -0,0 +1,296 @@
+package secret_binary_extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+	"testing"
+)
+
+func generateEncodedTestPackets(t *testing.T, schemeTag byte) [][]byte {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	n, anonymitySetSize, absoluteThreshold, noOfSubsecrets := 5, 8, 3, 3
+	var encoded [][]byte
+	switch schemeTag {
+	case SchemeTagAdditive:
+		secretKey := shamir.KeyBytesToKeyUint16s(secretKey8)
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			GenerateAdditiveTwoLayeredOptIndisShares(f, n, secretKey,
+				absoluteThreshold, noOfSubsecrets, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sharePackets, maxSharesPerPerson, _ := GetAdditiveSharePackets(f,
+			secretKey, n, absoluteThreshold, leavesData, subsecrets,
+			parentSubsecrets, &xUsedCoords)
+		packets, _ := GetAdditiveAnonymityPackets(sharePackets,
+			anonymitySetSize, maxSharesPerPerson, len(secretKey), &xUsedCoords)
+		for _, packet := range packets {
+			encoded = append(encoded, MarshalAdditivePacket(packet))
+		}
+	case SchemeTagThresholded:
+		secretKey := shamir.KeyBytesToAESKeyUint16s(secretKey8)
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			GenerateThresholdedTwoLayeredOptIndisShares(f, n, secretKey,
+				absoluteThreshold, noOfSubsecrets, 50, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, _ :=
+			GetThresholdedSharePackets(f, secretKey, n, absoluteThreshold,
+				leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+		packets, _ := GetThresholdedAnonymityPackets(sharePackets,
+			anonymitySetSize, maxSharesPerPerson, len(secretKey[0]),
+			len(secretKey), &xUsedCoords, encryptionLength)
+		for _, packet := range packets {
+			encoded = append(encoded, MarshalThresholdedPacket(packet))
+		}
+	case SchemeTagHinted:
+		secretKey := shamir.KeyBytesToAESKeyUint16s(secretKey8)
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			GenerateHintedTTwoLayeredOptIndisShares(f, n, secretKey,
+				absoluteThreshold, noOfSubsecrets, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sharePackets, maxSharesPerPerson, encryptionLength, _ :=
+			GetHintedTSharePackets(f, secretKey, n, absoluteThreshold,
+				leavesData, subsecrets, parentSubsecrets, &xUsedCoords, 2)
+		packets, _ := GetHintedTAnonymityPackets(sharePackets,
+			anonymitySetSize, maxSharesPerPerson, len(secretKey[0]),
+			len(secretKey), &xUsedCoords, encryptionLength)
+		for _, packet := range packets {
+			encoded = append(encoded, MarshalHintedTPacket(packet))
+		}
+	}
+	return encoded
+}
+
+// Decodes and encodes the packet again
+func reencodePacket(schemeTag byte, data []byte) ([]byte, error) {
+	switch schemeTag {
+	case SchemeTagAdditive:
+		packet, err := UnmarshalAdditivePacket(data)
+		return MarshalAdditivePacket(packet), err
+	case SchemeTagThresholded:
+		packet, err := UnmarshalThresholdedPacket(data)
+		return MarshalThresholdedPacket(packet), err
+	default:
+		packet, err := UnmarshalHintedTPacket(data)
+		return MarshalHintedTPacket(packet), err
+	}
+}
+
+func TestPacketEncodingRoundTrip(t *testing.T) {
+	for _, schemeTag := range []byte{SchemeTagAdditive, SchemeTagThresholded,
+		SchemeTagHinted} {
+		encoded := generateEncodedTestPackets(t, schemeTag)
+		for _, data := range encoded {
+			// The header must be the same for trustees and anonymity packets
+			if !bytes.Equal(data[:packetHeaderSize],
+				encoded[0][:packetHeaderSize]) {
+				t.Error("Headers of the packets differ", schemeTag)
+			}
+			tag, err := PacketSchemeTag(data)
+			if err != nil || tag != schemeTag {
+				t.Error("Wrong scheme tag", tag, err)
+			}
+			reencoded, err := reencodePacket(schemeTag, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reencoded, data) {
+				t.Error("Packet changed after decoding", schemeTag)
+			}
+		}
+	}
+}
+
+func TestPacketDecodingStrict(t *testing.T) {
+	for _, schemeTag := range []byte{SchemeTagAdditive, SchemeTagThresholded,
+		SchemeTagHinted} {
+		data := generateEncodedTestPackets(t, schemeTag)[0]
+		// Every truncation must be rejected
+		for i := 0; i < len(data); i++ {
+			if _, err := reencodePacket(schemeTag, data[:i]); err == nil {
+				t.Fatal("Truncated packet accepted", schemeTag, i)
+			}
+		}
+		// Trailing data must be rejected
+		extended := append(append([]byte(nil), data...), 0)
+		if _, err := reencodePacket(schemeTag, extended); err != errors.ErrTrailingPacketData {
+			t.Error("Trailing data accepted", schemeTag, err)
+		}
+		// Packets of the other schemes must be rejected
+		otherTag := byte(SchemeTagAdditive)
+		if schemeTag == SchemeTagAdditive {
+			otherTag = SchemeTagHinted
+		}
+		if _, err := reencodePacket(otherTag, data); err != errors.ErrPacketSchemeMismatch {
+			t.Error("Packet of another scheme accepted", schemeTag, err)
+		}
+		// A huge count after the salt/nonce (and the parameters of the
+		// verifier) must not be trusted
+		countOffset := packetHeaderSize + 32
+		if schemeTag == SchemeTagAdditive {
+			countOffset += 3
+		}
+		corrupted := append([]byte(nil), data...)
+		binary.BigEndian.PutUint32(corrupted[countOffset:], 1<<31)
+		if _, err := reencodePacket(schemeTag, corrupted); err != errors.ErrTruncatedPacket {
+			t.Error("Oversized count accepted", schemeTag, err)
+		}
+		// Unknown versions must be rejected
+		corrupted = append([]byte(nil), data...)
+		corrupted[4] = PacketFormatVersion + 1
+		if _, err := reencodePacket(schemeTag, corrupted); err != errors.ErrUnsupportedPacketVersion {
+			t.Error("Unknown version accepted", schemeTag, err)
+		}
+	}
+	if _, err := PacketSchemeTag(make([]byte, MaxPacketSize+1)); err != errors.ErrPacketTooLarge {
+		t.Error("Oversized packet accepted", err)
+	}
+}
+
+func TestAdditiveVerifierEncoding(t *testing.T) {
+	data := generateEncodedTestPackets(t, SchemeTagAdditive)[0]
+	packet, err := UnmarshalAdditivePacket(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet.Verifier = crypto_protocols.VerifierParams{LogN: 10, R: 8, P: 1}
+	decoded, err := UnmarshalAdditivePacket(MarshalAdditivePacket(packet))
+	if err != nil || decoded.Verifier != packet.Verifier {
+		t.Error("Parameters of the verifier not decoded", err)
+	}
+	// Version 2 packets do not store the parameters
+	v2 := append([]byte(nil), data[:packetHeaderSize+32]...)
+	v2 = append(v2, data[packetHeaderSize+35:]...)
+	v2[4] = 2
+	decoded, err = UnmarshalAdditivePacket(v2)
+	if err != nil || decoded.Verifier.Hardened() || decoded.Legacy ||
+		len(decoded.RelevantHashes) != len(packet.RelevantHashes) {
+		t.Error("Version 2 packet not decoded", err)
+	}
+	// Parameters which would exhaust the memory are rejected
+	corrupted := append([]byte(nil), data...)
+	copy(corrupted[packetHeaderSize+32:], []byte{30, 255, 1})
+	if _, err := UnmarshalAdditivePacket(corrupted); err != errors.ErrInvalidPacket {
+		t.Error("Invalid parameters of the verifier accepted", err)
+	}
+}
+
+// Additive packets encoded as version 1, before the role labels, sharing
+// "testasdfghjklqwertyu" among 3 trustees with an absolute threshold of 2
+// (the last packet is a random one)
+var legacyAdditivePackets = []string{
+	"4b52504b01011000fbd356fc898b107c33a90333c1013172f65cee1626cba6ab" +
+		"83cac2be7748d7e300000003e3a71fbc1b51c169ec0902a7205d9be84725e752" +
+		"b6eb68a16a6d8a9fc159c11ef4f454d231c4f77034f63a59f7a4a216e1daa8fe" +
+		"1c459ea911a3a0efb467a89dc7790135175a738387fd0f34225780f913cf6f3f" +
+		"5b59b4b77b8a55fc9003fd1100000002e48c0000000b2b9e66923b005faeeeb9" +
+		"ee62ec5e669f191ea74cdce4ddea0000000bfcf5d73b9986c47e4ef7c4e952c8" +
+		"e1040bb0201ff007",
+	"4b52504b01011000384b0c332357a223b7d133a7850fbcc318e0d057abbcaac4" +
+		"b07f0ec7397eda6c00000003b114f35c51c0fde3f280a1c826a8fa22e1bf4f55" +
+		"2f1e36762bf03cbcaa260c04176e27c84eb5aa293880aee32c9064ed72522ea6" +
+		"bf153ba548d2b505db60ff42a345996f407424718031e9e0a66578bec9382e5d" +
+		"f194d673a812cdd4d22903e70000000224530000000b27db64d48247ac35e13f" +
+		"eb45c6c11bd12b4102041b3192f40000000b68b81aef42237e3841a08f0828c2" +
+		"933e13ed6aa349ec",
+	"4b52504b01011000394d8166df1fc8c3646fc478e781d1fc71d0297b70d09586" +
+		"4cbf6f93a13fa8a5000000031ad7bd0d941f919446b72a920be2e1fde0ff9ff6" +
+		"817b38536aa2e9ba010b3b07c2ab9bcf7e8b7f2b3f3fdfddd0b6cabb5d0a92ad" +
+		"01c24dc0949000123a907463a8a1c4d31cc8fc6884b9bde3040b96e414af4978" +
+		"6a1c6785a03bf0a32739c006000000020ab90000000bf263d5f06f015f50066a" +
+		"9ec5a789509f630282f7c835623a0000000be14ffa14a9c148cd2a77c97d25b9" +
+		"01e4fd567774b5c1",
+	"4b52504b010110001dfc8ebf6b8b06d9a9a61cfbe658e92c5c0b2549d8cd6a7e" +
+		"4717e7a6a56dc3d70000000390e011dbf72ed8d1fbe4202fcd739da6f77b3e2c" +
+		"f18aff87f1a96c66a2df1212afc61b882bccf8bfb453858613e1d734ba3af45d" +
+		"ad3d98e3e74c72dac59f873405c0f76f6e1358424f0fb588b75d17fe207f6b86" +
+		"dd8587fe529f473c63ade5f2000000025cc70000000b3f9d36b36840beaa4126" +
+		"e74028bc8244a2e6dfcd19c657550000000b991b6a6d482406f27b7c2c7c1184" +
+		"40237aee9e815d1c",
+}
+
+func TestLegacyAdditivePacketRecovery(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey := shamir.KeyBytesToKeyUint16s([]byte("testasdfghjklqwertyu"))
+	var packets []AdditivePacket
+	for _, encoded := range legacyAdditivePackets {
+		data, err := hex.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packet, err := UnmarshalAdditivePacket(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !packet.Legacy {
+			t.Error("Version 1 packet not decoded as a legacy packet")
+		}
+		// The packet is encoded again as the last version before the labels
+		reencoded := MarshalAdditivePacket(packet)
+		if reencoded[4] != legacyPacketFormatVersion {
+			t.Error("Legacy packet encoded as version", reencoded[4])
+		}
+		decoded, err := UnmarshalAdditivePacket(reencoded)
+		if err != nil || !decoded.Legacy {
+			t.Fatal("Legacy packet not decoded after the round trip", err)
+		}
+		packets = append(packets, decoded)
+	}
+	accessOrder := utils.GenerateIndicesSet(len(packets))
+	utils.Shuffle(accessOrder, randomness.NewRand())
+	recoveredKey, err := AdditiveOptUsedIndisSecretRecovery(
+		context.Background(), f, packets, accessOrder, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto_protocols.CompareUint16s(secretKey, recoveredKey) {
+		t.Error("Secret key not recovered from the legacy packets")
+	}
+	recoveredKey, err = AdditiveOptUsedIndisSecretRecoveryParallelized(
+		context.Background(), f, packets, accessOrder, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto_protocols.CompareUint16s(secretKey, recoveredKey) {
+		t.Error("Secret key not recovered in parallel from the legacy packets")
+	}
+	// The hashes of the legacy packets are not the labelled ones
+	for i := range packets {
+		packets[i].Legacy = false
+	}
+	recoveredKey, _ = AdditiveOptUsedIndisSecretRecovery(context.Background(),
+		f, packets, accessOrder, 2)
+	if crypto_protocols.CompareUint16s(secretKey, recoveredKey) {
+		t.Error("Legacy hashes matched as labelled hashes")
+	}
+}
+
+func TestSeededPacketGeneration(t *testing.T) {
+	defer randomness.Reset()
+	for _, schemeTag := range []byte{SchemeTagAdditive, SchemeTagThresholded,
+		SchemeTagHinted} {
+		randomness.SetSeed(7)
+		first := generateEncodedTestPackets(t, schemeTag)
+		randomness.SetSeed(7)
+		second := generateEncodedTestPackets(t, schemeTag)
+		for i := range first {
+			if !bytes.Equal(first[i], second[i]) {
+				t.Error("Packets not reproduced with the same seed", schemeTag, i)
+			}
+		}
+	}
+}
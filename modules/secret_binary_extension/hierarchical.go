@@ -0,0 +1,484 @@
+package secret_binary_extension
+
+import (
+	"context"
+	"encoding/binary"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+)
+
+// Combination of the children of a node in the hierarchical tree
+const (
+	AdditiveCombination = iota
+	ThresholdCombination
+)
+
+// A node of the hierarchical tree
+// The secret of a node is shared among its children, either additively or
+// with a threshold
+// Nodes without children are the leaves and are handed to the trustees
+type HierarchicalNode struct {
+	Combination int                 // AdditiveCombination or ThresholdCombination
+	Threshold   int                 // only used with ThresholdCombination
+	Children    []*HierarchicalNode // empty for the leaves
+	Trustee     int                 // trustee receiving the leaf
+}
+
+// Every packet stores the markers of all the nodes above its leaves
+// The markers are encrypted with the secret of the node and reveal the
+// x-coordinate of the node, which is 0 for the secret key
+// The secret key is then checked with the salted hash, like in the additive
+// packets
+type HierarchicalPacket struct {
+	Nonce               [32]byte          // nonce used inside the markers and as the salt
+	Hash                [32]byte          // salted hash of the secret key
+	RelevantEncryptions [][]byte          // markers of the ancestors of the leaves
+	ShareData           []shamir.PriShare // leaves received by the person
+}
+
+// Checks that the tree can be used for sharing among the trustees
+func CheckHierarchicalTree(root *HierarchicalNode, trustees int) error {
+	if root == nil || len(root.Children) == 0 {
+		return errors.ErrInvalidInput
+	}
+	return checkHierarchicalNode(root, trustees)
+}
+
+func checkHierarchicalNode(node *HierarchicalNode, trustees int) error {
+	if len(node.Children) == 0 {
+		if node.Trustee < 0 || node.Trustee >= trustees {
+			return errors.ErrInvalidInput
+		}
+		return nil
+	}
+	if len(node.Children) < 2 {
+		return errors.ErrInvalidInput
+	}
+	switch node.Combination {
+	case AdditiveCombination:
+	case ThresholdCombination:
+		if node.Threshold < 2 {
+			return errors.ErrInvalidInput
+		}
+		if node.Threshold > len(node.Children) {
+			return errors.ErrInvalidThreshold
+		}
+	default:
+		return errors.ErrInvalidInput
+	}
+	for _, child := range node.Children {
+		if err := checkHierarchicalNode(child, trustees); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ****************************************************************************
+// Share generation
+
+// This function generates the secrets of all the nodes of the tree
+// The secret key is stored in the root with the x-coordinate 0
+func GenerateHierarchicalShares(f shamir.Field, root *HierarchicalNode,
+	trustees int, secretKey []uint16) (map[*HierarchicalNode]shamir.PriShare,
+	[]uint16, error) {
+	if err := CheckHierarchicalTree(root, trustees); err != nil {
+		return nil, nil, err
+	}
+	f.InitializeTables()
+	nodeSecrets := make(map[*HierarchicalNode]shamir.PriShare)
+	// 0 is used for the key itself
+	xUsedCoords := []uint16{0}
+	nodeSecrets[root] = shamir.PriShare{X: 0, Y: secretKey}
+	err := generateHierarchicalChildren(f, root, nodeSecrets, &xUsedCoords)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodeSecrets, xUsedCoords, nil
+}
+
+func generateHierarchicalChildren(f shamir.Field, node *HierarchicalNode,
+	nodeSecrets map[*HierarchicalNode]shamir.PriShare,
+	xUsedCoords *[]uint16) error {
+	if len(node.Children) == 0 {
+		return nil
+	}
+	secret := nodeSecrets[node].Y
+	var childSecrets []shamir.PriShare
+	if node.Combination == ThresholdCombination {
+		shareVals, err := GenerateRandomXShares(f, node.Threshold,
+			len(node.Children), secret, xUsedCoords)
+		if err != nil {
+			return err
+		}
+		childSecrets = shareVals
+	} else {
+		// Even the additive children get a unique x-coordinate because their
+		// parent can be combined with a threshold
+		var subsecrets [][]uint16
+		GenerateAdditiveIndisUpperLayers(f, secret, len(node.Children),
+			&subsecrets)
+		for _, subsecret := range subsecrets {
+			x, err := getUnusedX(xUsedCoords)
+			if err != nil {
+				return err
+			}
+			childSecrets = append(childSecrets,
+				shamir.PriShare{X: x, Y: subsecret})
+		}
+	}
+	for i, child := range node.Children {
+		nodeSecrets[child] = childSecrets[i]
+		err := generateHierarchicalChildren(f, child, nodeSecrets, xUsedCoords)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a random non-zero x-coordinate which has not been used yet
+func getUnusedX(xUsedCoords *[]uint16) (uint16, error) {
+	buf := make([]byte, 2)
+	for {
+		if _, err := randomness.Read(buf); err != nil {
+			return 0, err
+		}
+		x := binary.BigEndian.Uint16(buf)
+		if x != 0 && !utils.IsInSliceUint16(*xUsedCoords, x) {
+			*xUsedCoords = append(*xUsedCoords, x)
+			return x, nil
+		}
+	}
+}
+
+// Collects the leaves of every trustee along with the nodes above them
+func collectHierarchicalLeaves(node *HierarchicalNode,
+	ancestors []*HierarchicalNode, leaves map[int][]*HierarchicalNode,
+	leafAncestors map[*HierarchicalNode][]*HierarchicalNode) {
+	if len(node.Children) == 0 {
+		leaves[node.Trustee] = append(leaves[node.Trustee], node)
+		leafAncestors[node] = append([]*HierarchicalNode(nil), ancestors...)
+		return
+	}
+	ancestors = append(ancestors, node)
+	for _, child := range node.Children {
+		collectHierarchicalLeaves(child, ancestors, leaves, leafAncestors)
+	}
+}
+
+// This function generates the packets of the trustees
+// All the packets are padded to the same number of shares and markers
+func GetHierarchicalSharePackets(root *HierarchicalNode, trustees int,
+	nodeSecrets map[*HierarchicalNode]shamir.PriShare,
+	xUsedCoords *[]uint16) ([]HierarchicalPacket, int, int, int, error) {
+	if err := CheckHierarchicalTree(root, trustees); err != nil {
+		return nil, 0, 0, 0, err
+	}
+	leaves := make(map[int][]*HierarchicalNode)
+	leafAncestors := make(map[*HierarchicalNode][]*HierarchicalNode)
+	collectHierarchicalLeaves(root, nil, leaves, leafAncestors)
+	relevantSize := len(nodeSecrets[root].Y)
+	secretKeyBytes := shamir.Uint16sToBytes(nodeSecrets[root].Y)
+
+	// Nodes whose markers should be stored by each trustee
+	trusteeMarkers := make([][]*HierarchicalNode, trustees)
+	maxSharesPerPerson, maxMarkersPerPerson := 0, 0
+	for i := 0; i < trustees; i++ {
+		for _, leaf := range leaves[i] {
+			for _, ancestor := range leafAncestors[leaf] {
+				if !containsHierarchicalNode(trusteeMarkers[i], ancestor) {
+					trusteeMarkers[i] = append(trusteeMarkers[i], ancestor)
+				}
+			}
+		}
+		if len(leaves[i]) > maxSharesPerPerson {
+			maxSharesPerPerson = len(leaves[i])
+		}
+		if len(trusteeMarkers[i]) > maxMarkersPerPerson {
+			maxMarkersPerPerson = len(trusteeMarkers[i])
+		}
+	}
+
+	var sharePackets []HierarchicalPacket
+	encryptionLength := 0
+	for i := 0; i < trustees; i++ {
+		var hPacket HierarchicalPacket
+		nonce, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return nil, 0, 0, 0, err
+		}
+		hPacket.Nonce = nonce
+		hPacket.Hash = crypto_protocols.GetLabelledSaltedHash(nonce,
+			secretKeyBytes, crypto_protocols.LabelFinalSecretCheck)
+		for _, leaf := range leaves[i] {
+			hPacket.ShareData = append(hPacket.ShareData, nodeSecrets[leaf])
+		}
+		for _, node := range trusteeMarkers[i] {
+			encryption, length, err :=
+				crypto_protocols.GetHierarchicalEncryptionBinExt(nonce,
+					nodeSecrets[node], hasOnlyLeaves(node))
+			if err != nil {
+				return nil, 0, 0, 0, err
+			}
+			encryptionLength = length
+			hPacket.RelevantEncryptions = append(hPacket.RelevantEncryptions,
+				encryption)
+		}
+		sharePackets = append(sharePackets, hPacket)
+	}
+	for i := range sharePackets {
+		err := GenerateHierarchicalRandomPackets(
+			maxSharesPerPerson-len(sharePackets[i].ShareData),
+			maxMarkersPerPerson-len(sharePackets[i].RelevantEncryptions),
+			relevantSize, encryptionLength, &sharePackets[i], xUsedCoords)
+		if err != nil {
+			return nil, 0, 0, 0, err
+		}
+		shuffleHierarchicalPacket(&sharePackets[i])
+	}
+	return sharePackets, maxSharesPerPerson, maxMarkersPerPerson,
+		encryptionLength, nil
+}
+
+// Tells whether all the children of the node are leaves
+func hasOnlyLeaves(node *HierarchicalNode) bool {
+	for _, child := range node.Children {
+		if len(child.Children) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func containsHierarchicalNode(nodes []*HierarchicalNode,
+	node *HierarchicalNode) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// The order of the leaves and the markers would otherwise reveal the
+// position of the trustee in the tree
+func shuffleHierarchicalPacket(hPacket *HierarchicalPacket) {
+	rng := randomness.NewRand()
+	shareIndices := utils.GenerateIndicesSet(len(hPacket.ShareData))
+	utils.Shuffle(shareIndices, rng)
+	var shareData []shamir.PriShare
+	for _, index := range shareIndices {
+		shareData = append(shareData, hPacket.ShareData[index])
+	}
+	hPacket.ShareData = shareData
+	encryptionIndices := utils.GenerateIndicesSet(
+		len(hPacket.RelevantEncryptions))
+	utils.Shuffle(encryptionIndices, rng)
+	var encryptions [][]byte
+	for _, index := range encryptionIndices {
+		encryptions = append(encryptions, hPacket.RelevantEncryptions[index])
+	}
+	hPacket.RelevantEncryptions = encryptions
+}
+
+// Adds random shares and random markers to the packet
+func GenerateHierarchicalRandomPackets(noOfShares, noOfMarkers,
+	relevantSize, encryptionLength int, hPacket *HierarchicalPacket,
+	xUsedCoords *[]uint16) error {
+	bufY := make([]byte, 2*relevantSize)
+	for j := 0; j < noOfShares; j++ {
+		x, err := getUnusedX(xUsedCoords)
+		if err != nil {
+			return err
+		}
+		if _, err := randomness.Read(bufY); err != nil {
+			return err
+		}
+		hPacket.ShareData = append(hPacket.ShareData,
+			shamir.PriShare{X: x, Y: shamir.BytesToUint16s(bufY)})
+	}
+	for j := 0; j < noOfMarkers; j++ {
+		randomBytes, err := crypto_protocols.GenerateRandomBytes(encryptionLength)
+		if err != nil {
+			return err
+		}
+		hPacket.RelevantEncryptions = append(hPacket.RelevantEncryptions,
+			randomBytes)
+	}
+	return nil
+}
+
+func GetHierarchicalAnonymityPackets(sharePackets []HierarchicalPacket,
+	anonymitySetSize, maxSharesPerPerson, maxMarkersPerPerson, relevantSize,
+	encryptionLength int, xUsedCoords *[]uint16) ([]HierarchicalPacket, error) {
+	if anonymitySetSize < len(sharePackets) {
+		return nil, errors.ErrInvalidInput
+	}
+	var anonymityPackets []HierarchicalPacket
+	// First of all store all the secret share packets
+	anonymityPackets = append(anonymityPackets, sharePackets...)
+	// Then. store the random packets
+	for i := 0; i < anonymitySetSize-len(sharePackets); i++ {
+		var hPacket HierarchicalPacket
+		nonce, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return nil, err
+		}
+		hPacket.Nonce = nonce
+		// The hash of a random packet is random as well
+		hash, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return nil, err
+		}
+		hPacket.Hash = hash
+		err = GenerateHierarchicalRandomPackets(maxSharesPerPerson,
+			maxMarkersPerPerson, relevantSize, encryptionLength, &hPacket,
+			xUsedCoords)
+		if err != nil {
+			return nil, err
+		}
+		anonymityPackets = append(anonymityPackets, hPacket)
+	}
+	return anonymityPackets, nil
+}
+
+// ****************************************************************************
+// Secret recovery
+
+// A share which can be combined with others for recovering a node
+// Leaves come from the packets and the other ones are recovered nodes
+type hierarchicalCandidate struct {
+	share       shamir.PriShare
+	packetIndex int // packet storing the markers of the parent
+	used        bool
+}
+
+// This function recovers the secret key from the packets in the access order
+// The tree is not known during recovery, so the recovered nodes are
+// identified only with the markers and combined bottom-up
+// largestShareSetSize is the largest number of shares that are combined at a
+// time, i.e., the largest threshold or number of additive children
+func HierarchicalSecretRecovery(ctx context.Context, f shamir.Field,
+	anonymityPackets []HierarchicalPacket, accessOrder []int,
+	largestShareSetSize int) ([]uint16, error) {
+	recoverer := NewHierarchicalRecoverer(f, largestShareSetSize)
+	for _, packetIndex := range accessOrder {
+		if err := recoverer.AddPacket(anonymityPackets[packetIndex]); err != nil {
+			return nil, err
+		}
+	}
+	_, found, err := recoverer.TryRecover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.ErrSecretNotFound
+	}
+	return recoverer.recoveredKey, nil
+}
+
+// Tries all the subsets of the unused candidates which include at least one
+// candidate that has not been tried before
+func personwiseHierarchicalSecretRecovery(ctx context.Context, f shamir.Field,
+	anonymityPackets []HierarchicalPacket,
+	candidates *[]hierarchicalCandidate, recoveredX *[]uint16,
+	triedLength, largestShareSetSize int) ([]uint16, bool, error) {
+	currentLength := len(*candidates)
+	for th := 2; th <= utils.GetSmallerValue(currentLength,
+		largestShareSetSize); th++ {
+		// Only the subsets with some of the untried candidates, which come
+		// last in the colexicographic order
+		first, count, err := utils.GetCombinationRangeWithLast(currentLength, th,
+			currentLength-triedLength)
+		if err != nil {
+			return nil, false, err
+		}
+		it := utils.NewCombinationIterator(currentLength, th, first, count)
+		for it.Next() {
+			indicesSet := it.Combination()
+			if err := RecoveryCancelled(ctx); err != nil {
+				return nil, false, err
+			}
+			var relevantSubset []shamir.PriShare
+			isUsed := false
+			for _, index := range indicesSet {
+				if (*candidates)[index].used {
+					isUsed = true
+					break
+				}
+				relevantSubset = append(relevantSubset, (*candidates)[index].share)
+			}
+			if isUsed {
+				continue
+			}
+			// Considering the markers of only one packet in the subset is
+			// enough as it stores the markers of all the ancestors
+			packet := anonymityPackets[(*candidates)[indicesSet[0]].packetIndex]
+			recovered, x, matched, err := combineHierarchicalSubset(f,
+				relevantSubset, packet)
+			if err != nil {
+				return nil, false, err
+			}
+			if !matched {
+				continue
+			}
+			if x == 0 {
+				// The marker of the secret key is confirmed with the
+				// salted hash
+				if crypto_protocols.CheckHashesEqual(packet.Hash,
+					crypto_protocols.GetLabelledSaltedHash(packet.Nonce,
+						shamir.Uint16sToBytes(recovered),
+						crypto_protocols.LabelFinalSecretCheck)) {
+					return recovered, true, nil
+				}
+				continue
+			}
+			for _, index := range indicesSet {
+				(*candidates)[index].used = true
+			}
+			if utils.IsInSliceUint16(*recoveredX, x) {
+				continue
+			}
+			*recoveredX = append(*recoveredX, x)
+			*candidates = append(*candidates, hierarchicalCandidate{
+				share:       shamir.PriShare{X: x, Y: recovered},
+				packetIndex: (*candidates)[indicesSet[0]].packetIndex,
+			})
+		}
+	}
+	return nil, false, nil
+}
+
+// Combines the subset both with the threshold and additively as the
+// combination of the parent is not known
+func combineHierarchicalSubset(f shamir.Field, relevantSubset []shamir.PriShare,
+	packet HierarchicalPacket) ([]uint16, uint16, bool, error) {
+	if recovered, err := f.CombineUniqueX(relevantSubset); err == nil {
+		matched, x, err := crypto_protocols.GetHierarchicalShareMatchBinExt(
+			recovered, packet.Nonce, packet.RelevantEncryptions)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if matched {
+			return recovered, x, true, nil
+		}
+	}
+	recovered := make([]uint16, len(relevantSubset[0].Y))
+	for _, shareVal := range relevantSubset {
+		sum, err := shamir.SliceAdd(recovered, shareVal.Y)
+		if err != nil {
+			return nil, 0, false, nil
+		}
+		recovered = sum
+	}
+	matched, x, err := crypto_protocols.GetHierarchicalShareMatchBinExt(
+		recovered, packet.Nonce, packet.RelevantEncryptions)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return recovered, x, matched, nil
+}
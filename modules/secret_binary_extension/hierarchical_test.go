@@ -0,0 +1,151 @@
+package secret_binary_extension
+
+import (
+	"context"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+	"testing"
+)
+
+func leaf(trustee int) *HierarchicalNode {
+	return &HierarchicalNode{Trustee: trustee}
+}
+
+func thresholdNode(threshold int, children ...*HierarchicalNode) *HierarchicalNode {
+	return &HierarchicalNode{Combination: ThresholdCombination,
+		Threshold: threshold, Children: children}
+}
+
+func additiveNode(children ...*HierarchicalNode) *HierarchicalNode {
+	return &HierarchicalNode{Combination: AdditiveCombination,
+		Children: children}
+}
+
+func generateHierarchicalTestPackets(t *testing.T, root *HierarchicalNode,
+	trustees, anonymitySetSize int,
+	secretKey []uint16) []HierarchicalPacket {
+	var f shamir.Field
+	nodeSecrets, xUsedCoords, err := GenerateHierarchicalShares(f, root,
+		trustees, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, maxMarkersPerPerson, encryptionLength,
+		err := GetHierarchicalSharePackets(root, trustees, nodeSecrets,
+		&xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymityPackets, err := GetHierarchicalAnonymityPackets(sharePackets,
+		anonymitySetSize, maxSharesPerPerson, maxMarkersPerPerson,
+		len(secretKey), encryptionLength, &xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, packet := range anonymityPackets {
+		if len(packet.ShareData) != maxSharesPerPerson ||
+			len(packet.RelevantEncryptions) != maxMarkersPerPerson {
+			t.Fatal("Packets are distinguishable")
+		}
+	}
+	return anonymityPackets
+}
+
+func TestHierarchicalSecretRecovery(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey := shamir.KeyBytesToKeyUint16s([]byte("testasdfghjklqwertyu"))
+	testCases := []struct {
+		root     *HierarchicalNode
+		trustees int
+	}{
+		// Three levels with mixed combinations
+		{thresholdNode(2,
+			additiveNode(leaf(0), leaf(1)),
+			thresholdNode(2, leaf(2), leaf(3), leaf(4)),
+			leaf(5)), 6},
+		// Four levels where a trustee holds leaves in several branches
+		{additiveNode(
+			thresholdNode(2,
+				additiveNode(leaf(0), leaf(1)),
+				thresholdNode(2, leaf(2), leaf(3), leaf(0))),
+			thresholdNode(3, leaf(4), leaf(5), leaf(1), leaf(2))), 6},
+	}
+	for _, tc := range testCases {
+		anonymityPackets := generateHierarchicalTestPackets(t, tc.root,
+			tc.trustees, 10, secretKey)
+		accessOrder := utils.GenerateIndicesSet(len(anonymityPackets))
+		utils.Shuffle(accessOrder, randomness.NewRand())
+		recovered, err := HierarchicalSecretRecovery(context.Background(), f, anonymityPackets,
+			accessOrder, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !crypto_protocols.CompareUint16s(recovered, secretKey) {
+			t.Error("Recovered secret does not match", recovered)
+		}
+	}
+}
+
+func TestHierarchicalSecretRecoveryInsufficient(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey := shamir.KeyBytesToKeyUint16s([]byte("testasdfghjklqwertyu"))
+	root := thresholdNode(2,
+		additiveNode(leaf(0), leaf(1)),
+		thresholdNode(2, leaf(2), leaf(3), leaf(4)),
+		leaf(5))
+	anonymityPackets := generateHierarchicalTestPackets(t, root, 6, 10,
+		secretKey)
+	// Trustees 0 and 2 along with the fillers cannot recover any child
+	accessOrder := []int{0, 2, 6, 7, 8, 9}
+	_, err := HierarchicalSecretRecovery(context.Background(), f, anonymityPackets, accessOrder, 4)
+	if err != errors.ErrSecretNotFound {
+		t.Error("Secret recovered with insufficient packets", err)
+	}
+	// Trustee 3 completes the second child and trustee 5 holds the third
+	accessOrder = append(accessOrder, 3, 5)
+	recovered, err := HierarchicalSecretRecovery(context.Background(), f, anonymityPackets,
+		accessOrder, 4)
+	if err != nil || !crypto_protocols.CompareUint16s(recovered, secretKey) {
+		t.Error("Secret not recovered", err)
+	}
+}
+
+func TestHierarchicalSaltedHash(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey := shamir.KeyBytesToKeyUint16s([]byte("testasdfghjklqwertyu"))
+	root := thresholdNode(2, additiveNode(leaf(0), leaf(1)), leaf(2))
+	anonymityPackets := generateHierarchicalTestPackets(t, root, 3, 5,
+		secretKey)
+	// The markers of the secret key alone are not enough
+	for i := range anonymityPackets {
+		anonymityPackets[i].Hash[0] ^= 1
+	}
+	accessOrder := utils.GenerateIndicesSet(len(anonymityPackets))
+	_, err := HierarchicalSecretRecovery(context.Background(), f,
+		anonymityPackets, accessOrder, 2)
+	if err != errors.ErrSecretNotFound {
+		t.Error("Secret accepted without the salted hash", err)
+	}
+}
+
+func TestCheckHierarchicalTree(t *testing.T) {
+	testCases := []*HierarchicalNode{
+		nil,
+		leaf(0),
+		thresholdNode(2, leaf(0)),
+		thresholdNode(3, leaf(0), leaf(1)),
+		thresholdNode(1, leaf(0), leaf(1)),
+		additiveNode(leaf(0), leaf(5)),
+	}
+	for _, tc := range testCases {
+		if CheckHierarchicalTree(tc, 3) == nil {
+			t.Error("Invalid tree accepted")
+		}
+	}
+}
@@ -2,12 +2,12 @@ package secret_binary_extension
 
 import (
 	"encoding/binary"
+	"key_recovery/modules/randomness"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 
-	"crypto/rand"
 	"key_recovery/modules/errors"
 	"log"
 )
@@ -18,6 +18,9 @@ type HintedTPacket struct {
 	Nonce               [32]byte            // includes the list of salts used for each share
 	RelevantEncryptions [][][]byte          // includes the list of h(salt || parent secret)
 	ShareData           [][]shamir.PriShare // share data (for now only one share)
+	// Decoded from a format version before 2, whose marker keys are not
+	// derived with the role labels
+	Legacy bool
 }
 
 // This function generates thresholded shares of the secret
@@ -66,7 +69,7 @@ func GenerateHintedTIndisUpperLayers(f shamir.Field, secretKey [][]uint16,
 		for i := 0; i < noOfSubsecrets-1; i++ {
 			(*subsecrets)[ind] = append((*subsecrets)[ind], []uint16{})
 			for j := 0; j < len(secretKey[ind]); j++ {
-				if _, err := rand.Read(buf); err != nil {
+				if _, err := randomness.Read(buf); err != nil {
 					log.Fatalln(err)
 				}
 				shareVal := binary.BigEndian.Uint16(buf)
@@ -118,20 +121,36 @@ func GetHintedTSharePackets(f shamir.Field,
 	leavesData [][]shamir.PriShare, subsecrets [][][]uint16,
 	parentSubsecrets map[int]map[uint16][]uint16,
 	xUsedCoords *[]uint16, noOfHints int) ([]HintedTPacket, int, int, error) {
+	return GetHintedTSharePacketsWeighted(f, secretKey, trustees,
+		absoluteThreshold, leavesData, subsecrets, parentSubsecrets,
+		xUsedCoords, noOfHints, nil)
+}
+
+// The trustees receive the leaves in proportion to their weights
+// Without weights, the leaves are distributed almost uniformly
+func GetHintedTSharePacketsWeighted(f shamir.Field,
+	secretKey [][]uint16,
+	trustees, absoluteThreshold int,
+	leavesData [][]shamir.PriShare, subsecrets [][][]uint16,
+	parentSubsecrets map[int]map[uint16][]uint16,
+	xUsedCoords *[]uint16, noOfHints int,
+	weights []int) ([]HintedTPacket, int, int, error) {
 	if absoluteThreshold > trustees {
 		return nil, -1, -1, errors.ErrInvalidThreshold
 	}
 	var encryptionLength int
 	var anonymitySharePackets []HintedTPacket
 	totalShares := len(leavesData[0])
-	sharesPerPerson := totalShares / trustees
+	rng := randomness.NewRand()
 	// Get how many shares each person should get
-	personWiseShareDistribution, maxSharesPerPerson :=
-		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+	personWiseShareDistribution, maxSharesPerPerson, err :=
+		GetPersonWiseShareDistribution(trustees, totalShares, weights, rng)
+	if err != nil {
+		return nil, -1, -1, err
+	}
 	// Get the trustees who should be hinted
 	trusteesNums := utils.GenerateIndicesSet(trustees)
-	utils.Shuffle(trusteesNums)
+	utils.Shuffle(trusteesNums, rng)
 	var hintedTrustees []uint16
 	for i := 0; i < noOfHints; i++ {
 		hintedTrustees = append(hintedTrustees, uint16(trusteesNums[i]))
@@ -143,7 +162,7 @@ func GetHintedTSharePackets(f shamir.Field,
 		// Indices of the leaves
 		leavesIndices := utils.GenerateIndicesSet(totalShares)
 		// Randomize the leaves that the trustees should receive
-		utils.Shuffle(leavesIndices)
+		utils.Shuffle(leavesIndices, rng)
 		allLeavesIndices = append(allLeavesIndices, leavesIndices)
 	}
 	currentIndices := make([]int, len(secretKey))
@@ -182,7 +201,8 @@ func GenerateHintedTPerPersonSharePackets(noOfSharesReceived int,
 		// Firstly, add the encryption for that part of the key
 		(*hPacket).RelevantEncryptions = append((*hPacket).RelevantEncryptions, [][]byte{})
 		(*hPacket).ShareData = append((*hPacket).ShareData, []shamir.PriShare{})
-		noncedEncSecretKey, el, err := crypto_protocols.GetHintedRelevantEncryptionBinExt((*hPacket).Nonce, keyPart, recoveryHint)
+		noncedEncSecretKey, el, err := crypto_protocols.GetHintedRelevantEncryptionBinExt((*hPacket).Nonce, keyPart, recoveryHint,
+			crypto_protocols.LabelFinalSecretCheck)
 		if err != nil {
 			return -1, err
 		}
@@ -190,7 +210,7 @@ func GenerateHintedTPerPersonSharePackets(noOfSharesReceived int,
 			noncedEncSecretKey)
 		encryptionLength = el
 		// Generate a random integer in the range [0, max)
-		_, err = rand.Read(buf)
+		_, err = randomness.Read(buf)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -202,7 +222,8 @@ func GenerateHintedTPerPersonSharePackets(noOfSharesReceived int,
 			leafShareVal := leavesData[ind][allLeavesIndices[ind][(*currentIndices)[ind]]]
 			parentSubsecret := parentSubsecrets[ind][leafShareVal.X]
 
-			noncedEncryption, _, err := crypto_protocols.GetHintedRelevantEncryptionBinExt((*hPacket).Nonce, parentSubsecret, hint+uint16(1))
+			noncedEncryption, _, err := crypto_protocols.GetHintedRelevantEncryptionBinExt((*hPacket).Nonce, parentSubsecret, hint+uint16(1),
+				crypto_protocols.LabelHint)
 			if err != nil {
 				log.Fatal(err)
 				return -1, err
@@ -241,7 +262,7 @@ func GenerateHintedTRandomPackets(noOfPackets, relevantSize, keySize int,
 			(*hPacket).RelevantEncryptions = append((*hPacket).RelevantEncryptions, [][]byte{})
 		}
 		for j := 0; j < (noOfPackets); j++ {
-			if _, err := rand.Read(bufX); err != nil {
+			if _, err := randomness.Read(bufX); err != nil {
 				log.Fatalln(err)
 			}
 			x := binary.BigEndian.Uint16(bufX)
@@ -256,7 +277,7 @@ func GenerateHintedTRandomPackets(noOfPackets, relevantSize, keySize int,
 			if exists {
 				continue
 			}
-			if _, err := rand.Read(bufY); err != nil {
+			if _, err := randomness.Read(bufY); err != nil {
 				log.Fatalln(err)
 			}
 			y := shamir.BytesToUint16s(bufY)
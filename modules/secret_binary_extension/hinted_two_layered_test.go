@@ -1,8 +1,10 @@
 package secret_binary_extension
 
 import (
+	"context"
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 	"log"
@@ -157,7 +159,8 @@ func TestGetHintedTSharePackets(t *testing.T) {
 								continue
 							}
 							parentSubsecret := parentSubsecrets[ind1][tempShare.X]
-							match1, hint, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(parentSubsecret, relevantNonce, relevantEncryptions)
+							match1, hint, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(parentSubsecret, relevantNonce, relevantEncryptions,
+								crypto_protocols.LabelHint, false)
 							if err != nil {
 								t.Error(err)
 							}
@@ -168,7 +171,8 @@ func TestGetHintedTSharePackets(t *testing.T) {
 								t.Error("No encryption found")
 							}
 
-							match2, correctX2, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(secretKey[ind1], relevantNonce, relevantEncryptions)
+							match2, correctX2, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(secretKey[ind1], relevantNonce, relevantEncryptions,
+								crypto_protocols.LabelFinalSecretCheck, false)
 							if err != nil {
 								t.Error(err)
 							}
@@ -304,10 +308,13 @@ func TestHintedTOptUsedIndisSecretRecovery(t *testing.T) {
 					t.Error(err)
 				} else {
 					accessOrder := utils.GenerateIndicesSet(tc.a)
-					utils.Shuffle(accessOrder)
-					recoveredKey := HintedTOptUsedIndisSecretRecoveryParallelized(f,
-						anonymityPackets, accessOrder,
-						tc.absoluteThreshold)
+					utils.Shuffle(accessOrder, randomness.NewRand())
+					recoveredKey, err := HintedTOptUsedIndisSecretRecoveryParallelized(context.Background(),
+						f, anonymityPackets, accessOrder,
+						tc.absoluteThreshold, 0)
+					if err != nil {
+						t.Fatal(err)
+					}
 					recoveredSecretKey := shamir.AESKeyUint16sToKeyBytes(recoveredKey)
 					if !crypto_protocols.CheckByteArrayEqual(secretKey8, recoveredSecretKey) {
 						t.Error("wrong recovery")
@@ -1,9 +1,8 @@
 package secret_binary_extension
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"sync"
 
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
@@ -11,9 +10,9 @@ import (
 	"key_recovery/modules/utils"
 )
 
-func AdditiveOptUsedIndisSecretRecovery(f shamir.Field,
+func AdditiveOptUsedIndisSecretRecovery(ctx context.Context, f shamir.Field,
 	anonymityPackets []AdditivePacket, accessOrder []int,
-	absoluteThreshold int) []uint16 {
+	absoluteThreshold int) ([]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	secretRecovered := false
 	var usedShares [][]shamir.PriShare
@@ -23,6 +22,9 @@ func AdditiveOptUsedIndisSecretRecovery(f shamir.Field,
 	// The user tries to recover as soon as she has obtained information from
 	// two people in the anonymity set
 	for obtainedLength := 2; obtainedLength <= anonymitySetSize; obtainedLength++ {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return nil, err
+		}
 		f.InitializeTables()
 		obtainedPacketsIndices := accessOrder[:obtainedLength]
 		var peoplePackets []AdditivePacket
@@ -30,20 +32,23 @@ func AdditiveOptUsedIndisSecretRecovery(f shamir.Field,
 			peoplePackets = append(peoplePackets,
 				anonymityPackets[obtainedPacketIndex])
 		}
-		PersonwiseAdditiveOptUsedIndisSecretRecovery(f, peoplePackets,
-			absoluteThreshold, &usedShares, &obtainedSubsecrets,
-			&secretRecovered, &recoveredKey)
+		err := PersonwiseAdditiveOptUsedIndisSecretRecovery(ctx, f,
+			peoplePackets, absoluteThreshold, &usedShares,
+			&obtainedSubsecrets, &secretRecovered, &recoveredKey)
+		if err != nil {
+			return nil, err
+		}
 		if secretRecovered {
 			break
 		}
 	}
-	return recoveredKey
+	return recoveredKey, nil
 }
 
-func PersonwiseAdditiveOptUsedIndisSecretRecovery(f shamir.Field,
-	peoplePackets []AdditivePacket, absoluteThreshold int,
+func PersonwiseAdditiveOptUsedIndisSecretRecovery(ctx context.Context,
+	f shamir.Field, peoplePackets []AdditivePacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
-	secretRecovered *bool, recoveredKey *[]uint16) {
+	secretRecovered *bool, recoveredKey *[]uint16) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket AdditivePacket
@@ -63,37 +68,39 @@ func PersonwiseAdditiveOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 	}
 
-	CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
+	err := CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
 		obtainedSubsecrets, mostRecentPacket)
+	if err != nil {
+		return err
+	}
 
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
-	}
-
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
-
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
+	subsets := utils.NewCombinationIterator(len(relevantShareData),
+		absoluteThreshold, firstRank, noOfSubsets)
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return err
+		}
+		indicesSet := subsets.Combination()
 		// Create the subset for running the recovery
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
@@ -101,29 +108,35 @@ func PersonwiseAdditiveOptUsedIndisSecretRecovery(f shamir.Field,
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset, indicesSet)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantHashes := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantHashes
 		runRelevantSalt := peoplePackets[shareDataMap[relevantSubset[0].X]].Salt
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
+		noOfSubsecrets := len(*obtainedSubsecrets)
 		isHashMatched, _, err := LeavesAdditiveOptUsedIndisRecovery(f,
 			recovered, relevantSubset, runRelevantHashes,
-			runRelevantSalt, obtainedSubsecrets, usedShares, -1)
+			runRelevantSalt, runRelevantLegacy, obtainedSubsecrets, usedShares, -1)
 		if err != nil {
-			log.Fatalln(err)
-			return
-		}
-		if isHashMatched && len((*obtainedSubsecrets)) > 1 {
-			SubsecretsAdditiveIndisRecovery(runRelevantHashes,
-				runRelevantSalt, *obtainedSubsecrets, secretRecovered,
-				recoveredKey)
+			return err
+		}
+		// The secret key is only checked again with a new subsecret
+		if isHashMatched && len(*obtainedSubsecrets) > noOfSubsecrets &&
+			len((*obtainedSubsecrets)) > 1 {
+			if err := SubsecretsAdditiveIndisRecovery(runRelevantHashes,
+				runRelevantSalt, runRelevantLegacy, *obtainedSubsecrets,
+				peoplePackets[shareDataMap[relevantSubset[0].X]].Verifier,
+				secretRecovered, recoveredKey); err != nil {
+				return err
+			}
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
 func GetRelevantShareData(allShareData []shamir.PriShare,
@@ -133,7 +146,6 @@ func GetRelevantShareData(allShareData []shamir.PriShare,
 		outputShareSet, err := crypto_protocols.GetSharesSetDifferenceBinExt(allShareDataCopy,
 			usedShareSet)
 		if err != nil {
-			log.Fatalln(err)
 			return nil, err
 		}
 		allShareDataCopy = outputShareSet[:]
@@ -144,7 +156,7 @@ func GetRelevantShareData(allShareData []shamir.PriShare,
 func CheckAlreadyObtainedSubsecrets(f shamir.Field,
 	absoluteThreshold int,
 	usedShares *[][]shamir.PriShare,
-	obtainedSubsecrets *[][]uint16, mostRecentPacket AdditivePacket) {
+	obtainedSubsecrets *[][]uint16, mostRecentPacket AdditivePacket) error {
 	mostRecentShareVals := mostRecentPacket.ShareData
 	for _, shareVal := range mostRecentShareVals {
 		for index, usedShareSet := range *usedShares {
@@ -154,36 +166,35 @@ func CheckAlreadyObtainedSubsecrets(f shamir.Field,
 			// Get the recovered secret from the absoluteThreshold number of shares
 			recovered, err := f.CombineUniqueX(relevantShares)
 			if err != nil {
-				fmt.Println(relevantShares)
-				log.Fatalln(err)
+				return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 			}
 			// Considering the hashes and marker info of only one person in
 			// the subset is enough
 			runRelevantHashes := mostRecentPacket.RelevantHashes
 			runRelevantSalt := mostRecentPacket.Salt
+			runRelevantLegacy := mostRecentPacket.Legacy
 			isHashMatched, _, err := LeavesAdditiveOptUsedIndisRecovery(f,
 				recovered, relevantShares, runRelevantHashes,
-				runRelevantSalt, obtainedSubsecrets, usedShares, index)
+				runRelevantSalt, runRelevantLegacy, obtainedSubsecrets, usedShares, index)
 			if err != nil {
-				log.Fatalln(err)
-				return
+				return err
 			}
 			if isHashMatched {
 				break
 			}
 		}
 	}
+	return nil
 }
 
 func LeavesAdditiveOptUsedIndisRecovery(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
-	runRelevantHashes [][32]byte, runRelevantSalt [32]byte,
+	runRelevantHashes [][32]byte, runRelevantSalt [32]byte, legacy bool,
 	obtainedSubsecrets *[][]uint16,
 	usedShares *[][]shamir.PriShare, index int) (bool, [32]byte, error) {
 	isHashMatched, matchedHash, err := crypto_protocols.GetAdditiveIndisShareMatchBinExt(
-		recovered, runRelevantHashes, runRelevantSalt)
+		recovered, runRelevantHashes, runRelevantSalt, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return false, matchedHash, err
 	}
 	if isHashMatched {
@@ -216,13 +227,15 @@ func LeavesAdditiveOptUsedIndisRecovery(f shamir.Field,
 
 func SubsecretsAdditiveIndisRecovery(
 	runRelevantHashes [][32]byte,
-	runRelevantSalt [32]byte,
-	obtainedSubsecrets [][]uint16, secretRecovered *bool,
-	recoveredKey *[]uint16) {
-	*secretRecovered, *recoveredKey = crypto_protocols.GetAdditiveSaltedHashMatchBinExt(runRelevantHashes, runRelevantSalt, obtainedSubsecrets)
+	runRelevantSalt [32]byte, legacy bool,
+	obtainedSubsecrets [][]uint16, verifier crypto_protocols.VerifierParams,
+	secretRecovered *bool, recoveredKey *[]uint16) error {
+	var err error
+	*secretRecovered, *recoveredKey, err = crypto_protocols.GetAdditiveSaltedHashMatchBinExt(runRelevantHashes, runRelevantSalt, obtainedSubsecrets, verifier, legacy)
+	return err
 }
 
-func BasicHashedSecretRecovery(f shamir.Field,
+func BasicHashedSecretRecovery(ctx context.Context, f shamir.Field,
 	anonymitySet []shamir.PriShare, accessOrder []int,
 	secretKeyHash [32]byte) ([]uint16, error) {
 	f.InitializeTables()
@@ -233,20 +246,29 @@ func BasicHashedSecretRecovery(f shamir.Field,
 		relevantIndex := accessOrder[obtainedLength-1]
 		// The user tries different thresholds
 		for threshold := 2; threshold <= obtainedLength; threshold++ {
-			// Generate different combinations of the secret shares
-			// Generate combinations without the last obtained share
-			thresholdIndicesSubsets :=
-				utils.GenerateSubsetsOfSize(indicesSet[:obtainedLength-1], threshold-1)
+			// Go through the combinations of the secret shares
+			// without the last obtained share
+			noOfSubsets, err := utils.GetCombinationUint64(obtainedLength-1,
+				threshold-1)
+			if err != nil {
+				return nil, err
+			}
+			subsets := utils.NewCombinationIterator(obtainedLength-1,
+				threshold-1, 0, noOfSubsets)
 			// Try different combinations for recovery
-			for _, relevantIndices := range thresholdIndicesSubsets {
-				relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices))
+			for subsets.Next() {
+				if err := RecoveryCancelled(ctx); err != nil {
+					return nil, err
+				}
+				relevantIndices := subsets.Combination()
+				relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices)+1)
 				for _, ind := range relevantIndices {
-					relevantSubset = append(relevantSubset, anonymitySet[ind])
+					relevantSubset = append(relevantSubset, anonymitySet[indicesSet[ind]])
 				}
 				relevantSubset = append(relevantSubset, anonymitySet[relevantIndex])
 				recovered, err := f.CombineUniqueX(relevantSubset)
 				if err != nil {
-					log.Fatal(err)
+					return nil, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 				}
 				if crypto_protocols.CheckRecSecretKeyBinExt(secretKeyHash,
 					recovered) {
@@ -263,9 +285,9 @@ func BasicHashedSecretRecovery(f shamir.Field,
 // we break the shares into smaller pieces and distribute it among people
 // This function does not use any additional information during the
 // recovery - that is the user only hashes and the anonymity set
-func ThOptUsedIndisSecretRecovery(f shamir.Field,
+func ThOptUsedIndisSecretRecovery(ctx context.Context, f shamir.Field,
 	anonymityPackets []ThresholdedPacket, accessOrder []int,
-	absoluteThreshold int) [][]uint16 {
+	absoluteThreshold int) ([][]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	var usedShares [][][]shamir.PriShare
 	var obtainedSubsecrets [][]shamir.PriShare
@@ -287,6 +309,9 @@ func ThOptUsedIndisSecretRecovery(f shamir.Field,
 	// two people in the anonymity set
 	// First of all, recover the first part of the secret
 	for obtainedLength := 2; obtainedLength <= anonymitySetSize; obtainedLength++ {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return nil, err
+		}
 		f.InitializeTables()
 		obtainedPacketsIndices := accessOrder[:obtainedLength]
 		var peoplePackets []ThresholdedPacket
@@ -296,14 +321,16 @@ func ThOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 		for ind1 := 0; ind1 < len(anonymityPackets[0].ShareData); ind1++ {
 			if !secretRecovered[ind1] {
-				PersonwiseThOptUsedIndisSecretRecovery(f, peoplePackets,
-					absoluteThreshold, &(usedShares[ind1]), &(obtainedSubsecrets[ind1]),
-					&(secretRecovered[ind1]), &recoveredSubKey, &trusteesApproached, ind1)
+				err := PersonwiseThOptUsedIndisSecretRecovery(ctx, f,
+					peoplePackets, absoluteThreshold, &(usedShares[ind1]),
+					&(obtainedSubsecrets[ind1]), &(secretRecovered[ind1]),
+					&recoveredSubKey, &trusteesApproached, ind1)
+				if err != nil {
+					return nil, err
+				}
 
 				if secretRecovered[ind1] {
-					fmt.Println(recoveredSubKey)
 					recoveredKey[ind1] = recoveredSubKey
-					fmt.Println(recoveredKey)
 				}
 			}
 			if utils.AllTrue(secretRecovered) {
@@ -315,18 +342,18 @@ func ThOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 	}
 
-	return recoveredKey
+	return recoveredKey, nil
 }
 
 // In this case, the idea of recovery is the following:
 // Recover only one part of the key and if you are able to recover
 // that part, then start going back and try to recover the rest of
 // the key
-func PersonwiseThOptUsedIndisSecretRecovery(f shamir.Field,
-	peoplePackets []ThresholdedPacket, absoluteThreshold int,
+func PersonwiseThOptUsedIndisSecretRecovery(ctx context.Context,
+	f shamir.Field, peoplePackets []ThresholdedPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[]shamir.PriShare,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	trusteesApproached *[]int, secretIndex int) {
+	trusteesApproached *[]int, secretIndex int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket ThresholdedPacket
@@ -346,59 +373,62 @@ func PersonwiseThOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 	}
 
-	isMatched1 := CheckAlreadyObtainedThresholdedSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex)
+	isMatched1, err := CheckAlreadyObtainedThresholdedSubsecrets(f,
+		absoluteThreshold, usedShares, obtainedSubsecrets, mostRecentPacket,
+		secretIndex)
+	if err != nil {
+		return err
+	}
 	if isMatched1 {
 		if !utils.IsInSlice((*trusteesApproached), len(peoplePackets)-1) {
 			(*trusteesApproached) = append((*trusteesApproached), len(peoplePackets)-1)
 		}
 	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
-	}
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
-
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
+	subsets := utils.NewCombinationIterator(len(relevantShareData),
+		absoluteThreshold, firstRank, noOfSubsets)
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return err
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, _, err := LeavesThresholdedOptUsedIndisRecovery(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce, obtainedSubsecrets, usedShares, -1)
+			runRelevantNonce, runRelevantLegacy, obtainedSubsecrets, usedShares, -1)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			// Store the packets of the people whose packets
@@ -411,7 +441,7 @@ func PersonwiseThOptUsedIndisSecretRecovery(f shamir.Field,
 			}
 			if len((*obtainedSubsecrets)) > 1 {
 				SubsecretsThresholdedIndisRecovery(f, runRelevantEncryptions,
-					runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+					runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 					recoveredKey)
 				if *secretRecovered {
 					break
@@ -419,12 +449,13 @@ func PersonwiseThOptUsedIndisSecretRecovery(f shamir.Field,
 			}
 		}
 	}
+	return nil
 }
 
 func CheckAlreadyObtainedThresholdedSubsecrets(f shamir.Field,
 	absoluteThreshold int, usedShares *[][]shamir.PriShare,
 	obtainedSubsecrets *[]shamir.PriShare, mostRecentPacket ThresholdedPacket,
-	secretIndex int) bool {
+	secretIndex int) (bool, error) {
 	mostRecentShareVals := mostRecentPacket.ShareData[secretIndex]
 	for _, shareVal := range mostRecentShareVals {
 		for index, usedShareSet := range *usedShares {
@@ -434,36 +465,36 @@ func CheckAlreadyObtainedThresholdedSubsecrets(f shamir.Field,
 			// Get the recovered secret from the absoluteThreshold number of shares
 			recovered, err := f.CombineUniqueX(relevantShares)
 			if err != nil {
-				fmt.Println(relevantShares)
-				log.Fatal(err)
+				return false, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 			}
 			// Considering the hashes and marker info of only one person in
 			// the subset is enough
 			runRelevantEncryptions := mostRecentPacket.RelevantEncryptions[secretIndex]
 			runRelevantNonce := mostRecentPacket.Nonce
+			runRelevantLegacy := mostRecentPacket.Legacy
 			isHashMatched, _, err := LeavesThresholdedOptUsedIndisRecovery(f,
 				recovered, relevantShares, runRelevantEncryptions,
-				runRelevantNonce, obtainedSubsecrets, usedShares, index)
+				runRelevantNonce, runRelevantLegacy, obtainedSubsecrets, usedShares, index)
 			if err != nil {
-				log.Fatalln(err)
+				return false, err
 			}
 			if isHashMatched {
-				return isHashMatched
+				return isHashMatched, nil
 			}
 		}
 	}
-	return false
+	return false, nil
 }
 
 func LeavesThresholdedOptUsedIndisRecovery(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
-	runRelevantEncryptions [][]byte, runRelevantNonce [32]byte,
+	runRelevantEncryptions [][]byte, runRelevantNonce [32]byte, legacy bool,
 	obtainedSubsecrets *[]shamir.PriShare,
 	usedShares *[][]shamir.PriShare, index int) (bool, []byte, error) {
 	isEncryptionMatched, correctX, matchedEncryption, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(
-		recovered, runRelevantNonce, runRelevantEncryptions)
+		recovered, runRelevantNonce, runRelevantEncryptions,
+		crypto_protocols.LabelLeafCheck, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return false, matchedEncryption, err
 	}
 	if isEncryptionMatched {
@@ -498,10 +529,10 @@ func LeavesThresholdedOptUsedIndisRecovery(f shamir.Field,
 func SubsecretsThresholdedIndisRecovery(
 	f shamir.Field,
 	runRelevantEncryptions [][]byte,
-	runRelevantNonce [32]byte,
+	runRelevantNonce [32]byte, legacy bool,
 	obtainedSubsecrets []shamir.PriShare, secretRecovered *bool,
 	recoveredKey *[]uint16) {
-	*secretRecovered, *recoveredKey = crypto_protocols.GetThresholdedNoncedSubsecretMatchBinExt(f, runRelevantEncryptions, runRelevantNonce, obtainedSubsecrets)
+	*secretRecovered, *recoveredKey = crypto_protocols.GetThresholdedNoncedSubsecretMatchBinExt(f, runRelevantEncryptions, runRelevantNonce, obtainedSubsecrets, legacy)
 }
 
 // This function is meant to work for
@@ -509,14 +540,14 @@ func SubsecretsThresholdedIndisRecovery(
 // we break the shares into smaller pieces and distribute it among people
 // This function does not use any additional information during the
 // recovery - that is the user only hashes and the anonymity set
-func HintedTOptUsedIndisSecretRecovery(f shamir.Field,
+func HintedTOptUsedIndisSecretRecovery(ctx context.Context, f shamir.Field,
 	anonymityPackets []HintedTPacket, accessOrder []int,
-	absoluteThreshold int) [][]uint16 {
+	absoluteThreshold int) ([][]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	var usedShares [][][]shamir.PriShare
 	var hintedTrustees []int
 	var obtainedSubsecrets [][][]uint16
-	var recoveredKey [][]uint16
+	recoveredKey := make([][]uint16, len(anonymityPackets[0].ShareData))
 	var recoveredSubKey []uint16
 	var secretRecovered []bool
 	var trusteesApproached []int
@@ -529,6 +560,9 @@ func HintedTOptUsedIndisSecretRecovery(f shamir.Field,
 	// The user tries to recover as soon as she has obtained information from
 	// two people in the anonymity set
 	for obtainedLength := 2; obtainedLength <= anonymitySetSize; obtainedLength++ {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return nil, err
+		}
 		f.InitializeTables()
 		obtainedPacketsIndices := accessOrder[:obtainedLength]
 		var peoplePackets []HintedTPacket
@@ -538,15 +572,17 @@ func HintedTOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 		for ind1 := 0; ind1 < len(anonymityPackets[0].ShareData); ind1++ {
 			if !secretRecovered[ind1] {
-				PersonwiseHintedTOptUsedIndisSecretRecovery(f, peoplePackets,
-					absoluteThreshold, &(usedShares[ind1]), &(obtainedSubsecrets[ind1]),
-					&(secretRecovered[ind1]), &recoveredSubKey,
-					&trusteesApproached, ind1, &hintedTrustees)
+				err := PersonwiseHintedTOptUsedIndisSecretRecovery(ctx, f,
+					peoplePackets, absoluteThreshold, &(usedShares[ind1]),
+					&(obtainedSubsecrets[ind1]), &(secretRecovered[ind1]),
+					&recoveredSubKey, &trusteesApproached, ind1,
+					&hintedTrustees)
+				if err != nil {
+					return nil, err
+				}
 
 				if secretRecovered[ind1] {
-					fmt.Println(recoveredSubKey)
 					recoveredKey[ind1] = recoveredSubKey
-					fmt.Println(recoveredKey)
 				}
 			}
 			if utils.AllTrue(secretRecovered) {
@@ -557,15 +593,15 @@ func HintedTOptUsedIndisSecretRecovery(f shamir.Field,
 			utils.UpdateOrderBinExt(hintedTrustees, &accessOrder, obtainedLength)
 		}
 	}
-	return recoveredKey
+	return recoveredKey, nil
 }
 
-func PersonwiseHintedTOptUsedIndisSecretRecovery(f shamir.Field,
-	peoplePackets []HintedTPacket, absoluteThreshold int,
+func PersonwiseHintedTOptUsedIndisSecretRecovery(ctx context.Context,
+	f shamir.Field, peoplePackets []HintedTPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
 	secretRecovered *bool, recoveredKey *[]uint16,
 	trusteesApproached *[]int, secretIndex int,
-	hintedTrustees *[]int) {
+	hintedTrustees *[]int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket HintedTPacket
@@ -585,54 +621,57 @@ func PersonwiseHintedTOptUsedIndisSecretRecovery(f shamir.Field,
 		}
 	}
 
-	CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex, hintedTrustees)
+	err := CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold,
+		usedShares, obtainedSubsecrets, mostRecentPacket, secretIndex,
+		hintedTrustees)
+	if err != nil {
+		return err
+	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
-	}
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
-
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
+	subsets := utils.NewCombinationIterator(len(relevantShareData),
+		absoluteThreshold, firstRank, noOfSubsets)
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return err
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, _, err := LeavesHintedTOptUsedIndisRecovery(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce, obtainedSubsecrets, usedShares, -1, hintedTrustees)
+			runRelevantNonce, runRelevantLegacy, obtainedSubsecrets, usedShares, -1, hintedTrustees)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			// Store the packets of the people whose packets
@@ -645,7 +684,7 @@ func PersonwiseHintedTOptUsedIndisSecretRecovery(f shamir.Field,
 			}
 			if len((*obtainedSubsecrets)) > 1 {
 				SubsecretsHintedTIndisRecovery(runRelevantEncryptions,
-					runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+					runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 					recoveredKey)
 				if *secretRecovered {
 					break
@@ -653,12 +692,13 @@ func PersonwiseHintedTOptUsedIndisSecretRecovery(f shamir.Field,
 			}
 		}
 	}
+	return nil
 }
 
 func CheckAlreadyObtainedHintedTSubsecrets(f shamir.Field,
 	absoluteThreshold int, usedShares *[][]shamir.PriShare,
 	obtainedSubsecrets *[][]uint16, mostRecentPacket HintedTPacket,
-	secretIndex int, hintedTrustees *[]int) {
+	secretIndex int, hintedTrustees *[]int) error {
 	mostRecentShareVals := mostRecentPacket.ShareData[secretIndex]
 	for _, shareVal := range mostRecentShareVals {
 		for index, usedShareSet := range *usedShares {
@@ -668,31 +708,31 @@ func CheckAlreadyObtainedHintedTSubsecrets(f shamir.Field,
 			// Get the recovered secret from the absoluteThreshold number of shares
 			recovered, err := f.CombineUniqueX(relevantShares)
 			if err != nil {
-				fmt.Println(relevantShares)
-				log.Fatal(err)
+				return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 			}
 			// Considering the hashes and marker info of only one person in
 			// the subset is enough
 			runRelevantEncryptions := mostRecentPacket.RelevantEncryptions[secretIndex]
 			runRelevantNonce := mostRecentPacket.Nonce
+			runRelevantLegacy := mostRecentPacket.Legacy
 			isHashMatched, _, err := LeavesHintedTOptUsedIndisRecovery(f,
 				recovered, relevantShares, runRelevantEncryptions,
-				runRelevantNonce, obtainedSubsecrets, usedShares, index,
+				runRelevantNonce, runRelevantLegacy, obtainedSubsecrets, usedShares, index,
 				hintedTrustees)
 			if err != nil {
-				log.Fatalln(err)
-				return
+				return err
 			}
 			if isHashMatched {
 				break
 			}
 		}
 	}
+	return nil
 }
 
 func LeavesHintedTOptUsedIndisRecovery(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
-	runRelevantEncryptions [][]byte, runRelevantNonce [32]byte,
+	runRelevantEncryptions [][]byte, runRelevantNonce [32]byte, legacy bool,
 	obtainedSubsecrets *[][]uint16,
 	usedShares *[][]shamir.PriShare, index int,
 	hintedTrustees *[]int) (bool, []byte, error) {
@@ -700,9 +740,9 @@ func LeavesHintedTOptUsedIndisRecovery(f shamir.Field,
 	// Therefore, we can use the function as is
 	isEncryptionMatched, hint, matchedEncryption, err :=
 		crypto_protocols.GetThresholdedIndisShareMatchBinExt(
-			recovered, runRelevantNonce, runRelevantEncryptions)
+			recovered, runRelevantNonce, runRelevantEncryptions,
+			crypto_protocols.LabelHint, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return false, matchedEncryption, err
 	}
 	if isEncryptionMatched {
@@ -741,16 +781,17 @@ func LeavesHintedTOptUsedIndisRecovery(f shamir.Field,
 }
 
 func SubsecretsHintedTIndisRecovery(runRelevantEncryptions [][]byte,
-	runRelevantNonce [32]byte,
+	runRelevantNonce [32]byte, legacy bool,
 	obtainedSubsecrets [][]uint16, secretRecovered *bool,
 	recoveredKey *[]uint16) {
-	*secretRecovered, *recoveredKey = crypto_protocols.GetHintedTNoncedSubsecretMatchBinExt(runRelevantEncryptions, runRelevantNonce, obtainedSubsecrets, recoveryHint)
+	*secretRecovered, *recoveredKey = crypto_protocols.GetHintedTNoncedSubsecretMatchBinExt(runRelevantEncryptions, runRelevantNonce, obtainedSubsecrets, recoveryHint, legacy)
 }
 
-func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
+func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelized(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []AdditivePacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
-	secretRecovered *bool, recoveredKey *[]uint16) {
+	secretRecovered *bool, recoveredKey *[]uint16, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket AdditivePacket
@@ -770,75 +811,57 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 	}
 
-	CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
+	err := CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
 		obtainedSubsecrets, mostRecentPacket)
+	if err != nil {
+		return err
+	}
 
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
-	}
-
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
-
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
-	noOfSubsets := len(relevantIndicesSubsets)
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][][]int
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:(i+1)*perSubsetLen])
-		}
-	}
+	chunks := newCombinationChunks(len(relevantShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		go ComputeCombinationsAdditive(f, smallerSubsets[i], relevantShareData,
-			peoplePackets, shareDataMap, absoluteThreshold, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsAdditive(ctx, f, subsets,
+					relevantShareData, peoplePackets, shareDataMap,
+					absoluteThreshold, usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recovered, err := f.CombineUniqueX(usedShareData)
 		if err != nil {
-			fmt.Println(usedShareData)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		emptyShares := make([]shamir.PriShare, 0)
 		(*usedShares) = append((*usedShares), emptyShares)
@@ -848,61 +871,73 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 				(*usedShares)[l-1] = append((*usedShares)[l-1], relevantShare)
 			}
 		}
-		if !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(*obtainedSubsecrets,
-			recovered) {
+		newSubsecret := !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(
+			*obtainedSubsecrets, recovered)
+		if newSubsecret {
 			*obtainedSubsecrets = append(*obtainedSubsecrets, recovered)
 		}
-		if len((*obtainedSubsecrets)) > 1 {
-			runRelevantHashes := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantHashes
-			runRelevantSalt := peoplePackets[shareDataMap[usedShareData[0].X]].Salt
-			SubsecretsAdditiveIndisRecovery(runRelevantHashes,
-				runRelevantSalt, *obtainedSubsecrets, secretRecovered,
-				recoveredKey)
+		// The secret key is only checked again with a new subsecret
+		if newSubsecret && len((*obtainedSubsecrets)) > 1 {
+			relevantPacket := peoplePackets[shareDataMap[usedShareData[0].X]]
+			if err := SubsecretsAdditiveIndisRecovery(relevantPacket.RelevantHashes,
+				relevantPacket.Salt, relevantPacket.Legacy, *obtainedSubsecrets, relevantPacket.Verifier,
+				secretRecovered, recoveredKey); err != nil {
+				return err
+			}
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsAdditive(f shamir.Field,
-	relevantIndicesSubsets [][]int,
+func ComputeCombinationsAdditive(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []AdditivePacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
-	usedSharesChannel chan<- []shamir.PriShare, wg *sync.WaitGroup) {
-	defer wg.Done()
+	usedSharesChannel chan<- []shamir.PriShare) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset, indicesSet)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		runRelevantHashes := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantHashes
 		runRelevantSalt := peoplePackets[shareDataMap[relevantSubset[0].X]].Salt
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isHashMatched, _, err := LeavesAdditiveOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantHashes,
-			runRelevantSalt)
+			runRelevantSalt, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isHashMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold)
 			copy(outputSubset, relevantSubset)
-			usedSharesChannel <- outputSubset
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
-func BasicHashedSecretRecoveryParallelized(f shamir.Field,
+func BasicHashedSecretRecoveryParallelized(ctx context.Context, f shamir.Field,
 	anonymitySet []shamir.PriShare, accessOrder []int,
-	secretKeyHash [32]byte) ([]uint16, error) {
+	secretKeyHash [32]byte, noOfWorkers int) ([]uint16, error) {
 	// The user obtains the information of the anonymity set one-by-one
 	// After obtaining two elements, the user tries to recover the secret
 	anonymitySetSize := len(anonymitySet)
@@ -917,45 +952,33 @@ func BasicHashedSecretRecoveryParallelized(f shamir.Field,
 		for threshold := 2; threshold <= obtainedLength; threshold++ {
 			// Generate different combinations of the secret shares
 			// Generate combinations without the last obtained share
-			relevantIndicesSubsets :=
-				utils.GenerateSubsetsOfSize(indicesSet[:obtainedLength-1], threshold-1)
-
-			// Get the required number of routines
-			noOfSubsets := len(relevantIndicesSubsets)
-			// noOfRoutines := 1
-			// for key, value := range routinesMap {
-			// 	if noOfSubsets >= key {
-			// 		noOfRoutines = value
-			// 		break
-			// 	}
-			// }
-			noOfRoutines := 16
-
-			// fmt.Println(noOfRoutines, len(anonymitySet), obtainedLength)
-
-			perSubsetLen := noOfSubsets / noOfRoutines
-			// Distribute the subsets into smaller slices
-			var smallerSubsets [][][]int
-			for i := 0; i < noOfRoutines; i++ {
-				if i == (noOfRoutines - 1) {
-					smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:])
-				} else {
-					smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:(i+1)*perSubsetLen])
-				}
+			// Go through the combinations of the secret shares
+			// without the last obtained share
+			noOfSubsets, err := utils.GetCombinationUint64(obtainedLength-1,
+				threshold-1)
+			if err != nil {
+				return nil, err
 			}
+			noOfRoutines := NoOfWorkers(noOfWorkers)
 
-			recoveredChannel := make(chan []uint16, 1000)
-			var wg sync.WaitGroup
-			// For each subset, run it in a separate subroutine
-			for i := 0; i < noOfRoutines; i++ {
-				wg.Add(1)
-				go ComputeCombinationsBasic(f, smallerSubsets[i], anonymitySet,
-					relevantIndex, secretKeyHash, recoveredChannel, &wg)
-			}
+			chunks := newCombinationChunks(obtainedLength-1, threshold-1, 0,
+				noOfSubsets, noOfRoutines)
 
-			wg.Wait()
+			recoveredChannel := make(chan []uint16, 1000)
+			// The routines take the chunks of the subsets until none are left
+			err = RunCombinationRoutines(ctx, noOfRoutines,
+				func(ctx context.Context, _ int) error {
+					return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+						return ComputeCombinationsBasic(ctx, f, subsets,
+							indicesSet, anonymitySet, relevantIndex, secretKeyHash,
+							recoveredChannel)
+					})
+				})
 
 			close(recoveredChannel)
+			if err != nil {
+				return nil, err
+			}
 
 			for obtainedData := range recoveredChannel {
 				recovered = obtainedData
@@ -973,9 +996,10 @@ func BasicHashedSecretRecoveryParallelized(f shamir.Field,
 	return nil, errors.ErrSecretNotFound
 }
 
-func BasicHashedSecretRecoveryParallelizedAlternate(f shamir.Field,
+func BasicHashedSecretRecoveryParallelizedAlternate(ctx context.Context,
+	f shamir.Field,
 	anonymitySet []shamir.PriShare, accessOrder []int,
-	secretKeyHash [32]byte, threshold int) ([]uint16, error) {
+	secretKeyHash [32]byte, threshold, noOfWorkers int) ([]uint16, error) {
 	// The user obtains the information of the anonymity set one-by-one
 	// After obtaining two elements, the user tries to recover the secret
 	anonymitySetSize := len(anonymitySet)
@@ -993,43 +1017,33 @@ func BasicHashedSecretRecoveryParallelizedAlternate(f shamir.Field,
 
 		// Generate different combinations of the secret shares
 		// Generate combinations without the last obtained share
-		relevantIndicesSubsets :=
-			utils.GenerateSubsetsOfSize(indicesSet[:obtainedLength-1], threshold-1)
-
-		// Get the required number of routines
-		noOfSubsets := len(relevantIndicesSubsets)
-		// noOfRoutines := 1
-		// for key, value := range routinesMap {
-		// 	if noOfSubsets >= key {
-		// 		noOfRoutines = value
-		// 		break
-		// 	}
-		// }
-		noOfRoutines := 16
-
-		perSubsetLen := noOfSubsets / noOfRoutines
-		// Distribute the subsets into smaller slices
-		var smallerSubsets [][][]int
-		for i := 0; i < noOfRoutines; i++ {
-			if i == (noOfRoutines - 1) {
-				smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:])
-			} else {
-				smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:(i+1)*perSubsetLen])
-			}
+		// Go through the combinations of the secret shares
+		// without the last obtained share
+		noOfSubsets, err := utils.GetCombinationUint64(obtainedLength-1,
+			threshold-1)
+		if err != nil {
+			return nil, err
 		}
+		noOfRoutines := NoOfWorkers(noOfWorkers)
 
-		recoveredChannel := make(chan []uint16, 1000)
-		var wg sync.WaitGroup
-		// For each subset, run it in a separate subroutine
-		for i := 0; i < noOfRoutines; i++ {
-			wg.Add(1)
-			go ComputeCombinationsBasic(f, smallerSubsets[i], anonymitySet,
-				relevantIndex, secretKeyHash, recoveredChannel, &wg)
-		}
+		chunks := newCombinationChunks(obtainedLength-1, threshold-1, 0,
+			noOfSubsets, noOfRoutines)
 
-		wg.Wait()
+		recoveredChannel := make(chan []uint16, 1000)
+		// The routines take the chunks of the subsets until none are left
+		err = RunCombinationRoutines(ctx, noOfRoutines,
+			func(ctx context.Context, _ int) error {
+				return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+					return ComputeCombinationsBasic(ctx, f, subsets,
+						indicesSet, anonymitySet, relevantIndex, secretKeyHash,
+						recoveredChannel)
+				})
+			})
 
 		close(recoveredChannel)
+		if err != nil {
+			return nil, err
+		}
 
 		for obtainedData := range recoveredChannel {
 			recovered = obtainedData
@@ -1047,40 +1061,47 @@ func BasicHashedSecretRecoveryParallelizedAlternate(f shamir.Field,
 	return nil, errors.ErrSecretNotFound
 }
 
-func ComputeCombinationsBasic(f shamir.Field,
-	thresholdIndicesSubsets [][]int,
+func ComputeCombinationsBasic(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
+	indicesSet []int,
 	anonymitySet []shamir.PriShare,
 	relevantIndex int,
 	secretKeyHash [32]byte,
-	recoveredChannel chan<- []uint16,
-	wg *sync.WaitGroup) {
-	defer wg.Done()
+	recoveredChannel chan<- []uint16) error {
 	// Try different combinations for recovery
-	for _, relevantIndices := range thresholdIndicesSubsets {
-		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices))
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		relevantIndices := subsets.Combination()
+		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices)+1)
 		for _, ind := range relevantIndices {
-			relevantSubset = append(relevantSubset, anonymitySet[ind])
+			relevantSubset = append(relevantSubset, anonymitySet[indicesSet[ind]])
 		}
 		relevantSubset = append(relevantSubset, anonymitySet[relevantIndex])
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			// TODO: log.Fatal immediately exit, so the return after it
-			// is useless
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		if crypto_protocols.CheckHashesEqual(secretKeyHash,
 			crypto_protocols.GetSHA256(shamir.Uint16sToBytes(recovered))) {
-			recoveredChannel <- recovered
-			break
+			select {
+			case recoveredChannel <- recovered:
+			case <-ctx.Done():
+			}
+			return nil
 		}
 	}
+	return nil
 }
 
-func PersonwiseThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
+func PersonwiseThOptUsedIndisSecretRecoveryParallelized(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []ThresholdedPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[]shamir.PriShare,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	trusteesApproached *[]int, secretIndex int) {
+	trusteesApproached *[]int, secretIndex, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket ThresholdedPacket
@@ -1100,75 +1121,59 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 	}
 
-	isMatched1 := CheckAlreadyObtainedThresholdedSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex)
+	isMatched1, err := CheckAlreadyObtainedThresholdedSubsecrets(f,
+		absoluteThreshold, usedShares, obtainedSubsecrets, mostRecentPacket,
+		secretIndex)
+	if err != nil {
+		return err
+	}
 	if isMatched1 {
 		if !utils.IsInSlice((*trusteesApproached), len(peoplePackets)-1) {
 			(*trusteesApproached) = append((*trusteesApproached), len(peoplePackets)-1)
 		}
 	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
 
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
-	// Get the required number of routines
-	noOfSubsets := len(relevantIndicesSubsets)
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
-
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][][]int
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:(i+1)*perSubsetLen])
-		}
-	}
+	chunks := newCombinationChunks(len(relevantShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		copySlice := make([]ThresholdedPacket, len(peoplePackets))
-		copy(copySlice, peoplePackets)
-		go ComputeCombinationsThresholded(f, smallerSubsets[i], relevantShareData,
-			copySlice, shareDataMap, absoluteThreshold, secretIndex, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			copySlice := make([]ThresholdedPacket, len(peoplePackets))
+			copy(copySlice, peoplePackets)
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsThresholded(ctx, f, subsets,
+					relevantShareData, copySlice, shareDataMap, absoluteThreshold,
+					secretIndex, usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recoveredShare := usedShareData[len(usedShareData)-1]
@@ -1195,27 +1200,33 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		if len((*obtainedSubsecrets)) > 1 {
 			runRelevantEncryptions := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantEncryptions[secretIndex]
 			runRelevantNonce := peoplePackets[shareDataMap[usedShareData[0].X]].Nonce
+			runRelevantLegacy := peoplePackets[shareDataMap[usedShareData[0].X]].Legacy
 			SubsecretsThresholdedIndisRecovery(f, runRelevantEncryptions,
-				runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+				runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 				recoveredKey)
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsThresholded(f shamir.Field,
-	relevantIndicesSubsets [][]int,
+func ComputeCombinationsThresholded(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []ThresholdedPacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
 	secretIndex int,
-	usedSharesChannel chan<- []shamir.PriShare, wg *sync.WaitGroup) {
-	defer wg.Done()
+	usedSharesChannel chan<- []shamir.PriShare) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		// Create the subset for running the recovery
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
@@ -1223,35 +1234,40 @@ func ComputeCombinationsThresholded(f shamir.Field,
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset, indicesSet)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, correctX, err := LeavesThresholdedOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce)
+			runRelevantNonce, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold, absoluteThreshold+1)
 			copy(outputSubset, relevantSubset)
 			subsecretShare := shamir.PriShare{X: correctX, Y: recovered}
 			outputSubset = append(outputSubset, subsecretShare)
-			usedSharesChannel <- outputSubset
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
-func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
+func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelized(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []HintedTPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	secretIndex int, hintedTrustees *[]int) {
+	secretIndex int, hintedTrustees *[]int, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket HintedTPacket
@@ -1271,83 +1287,63 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 	}
 
-	CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex, hintedTrustees)
+	err := CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold,
+		usedShares, obtainedSubsecrets, mostRecentPacket, secretIndex,
+		hintedTrustees)
+	if err != nil {
+		return err
+	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []int
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, i)
-		}
-	}
-	shareIndicesSet := utils.GenerateIndicesSet(len(relevantShareData))
-
-	allIndicesSubset := utils.GenerateSubsetsOfSize(shareIndicesSet, absoluteThreshold)
-	var relevantIndicesSubsets [][]int
-	for _, indicesSubset := range allIndicesSubset {
-		if len(utils.GetIntersection(indicesSubset, relevantIndices)) > 0 {
-			relevantIndicesSubsets = append(relevantIndicesSubsets, indicesSubset)
-		}
-	}
-
-	// Get the required number of routines
-	noOfSubsets := len(relevantIndicesSubsets)
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
-
-	// Number of combinations each routine needs to run
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][][]int
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen:(i+1)*perSubsetLen])
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	relevantShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(relevantShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
+	noOfRoutines := NoOfWorkers(noOfWorkers)
+
+	chunks := newCombinationChunks(len(relevantShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+
 	// Channel for the user shares
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 	// Channel for the hinted people
 	hintedPeopleChannel := make(chan uint16, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		copySlice := make([]HintedTPacket, len(peoplePackets))
-		copy(copySlice, peoplePackets)
-		go ComputeCombinationsHintedT(f, smallerSubsets[i], relevantShareData,
-			copySlice, shareDataMap, absoluteThreshold,
-			secretIndex, usedSharesChannel,
-			hintedPeopleChannel, &wg)
-	}
-
-	wg.Wait()
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			copySlice := make([]HintedTPacket, len(peoplePackets))
+			copy(copySlice, peoplePackets)
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsHintedT(ctx, f, subsets,
+					relevantShareData, copySlice, shareDataMap, absoluteThreshold,
+					secretIndex, usedSharesChannel, hintedPeopleChannel)
+			})
+		})
 
 	close(usedSharesChannel)
 	close(hintedPeopleChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recovered, err := f.CombineUniqueX(usedShareData)
 		if err != nil {
-			fmt.Println(usedShareData)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		emptyShares := make([]shamir.PriShare, 0)
 		(*usedShares) = append((*usedShares), emptyShares)
@@ -1357,7 +1353,11 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 				(*usedShares)[l-1] = append((*usedShares)[l-1], relevantShare)
 			}
 			relevantPacket := peoplePackets[shareDataMap[relevantShare.X]]
-			UpdateHints(relevantPacket, recovered, hintedTrustees, secretIndex)
+			err := UpdateHints(relevantPacket, recovered, hintedTrustees,
+				secretIndex)
+			if err != nil {
+				return err
+			}
 		}
 		if !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(*obtainedSubsecrets,
 			recovered) {
@@ -1366,54 +1366,67 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		if len((*obtainedSubsecrets)) > 1 {
 			runRelevantEncryptions := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantEncryptions[secretIndex]
 			runRelevantNonce := peoplePackets[shareDataMap[usedShareData[0].X]].Nonce
+			runRelevantLegacy := peoplePackets[shareDataMap[usedShareData[0].X]].Legacy
 			SubsecretsHintedTIndisRecovery(runRelevantEncryptions,
-				runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+				runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 				recoveredKey)
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsHintedTUint16(f shamir.Field,
-	relevantIndicesSubsets []uint16,
+func ComputeCombinationsHintedTUint16(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []HintedTPacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
 	secretIndex int,
 	usedSharesChannel chan<- []shamir.PriShare,
-	hintedPeopleChannel chan<- uint16, wg *sync.WaitGroup) {
-	defer wg.Done()
+	hintedPeopleChannel chan<- uint16) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for i := 0; i < len(relevantIndicesSubsets)/absoluteThreshold; i++ {
-		indicesSet := relevantIndicesSubsets[i*absoluteThreshold : (i+1)*absoluteThreshold]
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, hint, err := LeavesHintedTOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce)
+			runRelevantNonce, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold)
 			copy(outputSubset, relevantSubset)
-			usedSharesChannel <- outputSubset
-			hintedPeopleChannel <- hint
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
+			select {
+			case hintedPeopleChannel <- hint:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
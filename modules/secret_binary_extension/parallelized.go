@@ -1,12 +1,12 @@
 package secret_binary_extension
 
 import (
+	"context"
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
 	"key_recovery/modules/errors"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
-	"log"
 	"sync"
 )
 
@@ -15,9 +15,9 @@ import (
 
 // ************Functions for additive***************
 // **************************************************************************
-func AdditiveOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
-	anonymityPackets []AdditivePacket, accessOrder []int,
-	absoluteThreshold int) []uint16 {
+func AdditiveOptUsedIndisSecretRecoveryParallelized(ctx context.Context,
+	f shamir.Field, anonymityPackets []AdditivePacket, accessOrder []int,
+	absoluteThreshold, noOfWorkers int) ([]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	secretRecovered := false
 	var usedShares [][]shamir.PriShare
@@ -34,20 +34,24 @@ func AdditiveOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 			peoplePackets = append(peoplePackets,
 				anonymityPackets[obtainedPacketIndex])
 		}
-		PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(f, peoplePackets,
-			absoluteThreshold, &usedShares, &obtainedSubsecrets,
-			&secretRecovered, &recoveredKey)
+		err := PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(ctx,
+			f, peoplePackets, absoluteThreshold, &usedShares,
+			&obtainedSubsecrets, &secretRecovered, &recoveredKey, noOfWorkers)
+		if err != nil {
+			return nil, err
+		}
 		if secretRecovered {
 			break
 		}
 	}
-	return recoveredKey
+	return recoveredKey, nil
 }
 
-func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Field,
+func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []AdditivePacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
-	secretRecovered *bool, recoveredKey *[]uint16) {
+	secretRecovered *bool, recoveredKey *[]uint16, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket AdditivePacket
@@ -67,69 +71,56 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Fie
 		}
 	}
 
-	CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
+	err := CheckAlreadyObtainedSubsecrets(f, absoluteThreshold, usedShares,
 		obtainedSubsecrets, mostRecentPacket)
+	if err != nil {
+		return err
+	}
 
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []uint16
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, uint16(i))
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	orderedShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(orderedShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
-	shareIndicesSet := utils.GenerateIndicesSetUint16(len(relevantShareData))
-
-	relevantIndicesSubsets := utils.GenerateSubsetsOfSizeUint16Filtered(shareIndicesSet, absoluteThreshold, relevantIndices)
-
-	noOfSubsets := len(relevantIndicesSubsets) / absoluteThreshold
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
-
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][]uint16
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:(i+1)*perSubsetLen*absoluteThreshold])
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		go ComputeCombinationsAdditiveUint16(f, smallerSubsets[i], relevantShareData,
-			peoplePackets, shareDataMap, absoluteThreshold, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	chunks := newCombinationChunks(len(orderedShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsAdditiveUint16(ctx, f, subsets,
+					orderedShareData, peoplePackets, shareDataMap,
+					absoluteThreshold, usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recovered, err := f.CombineUniqueX(usedShareData)
 		if err != nil {
-			fmt.Println(usedShareData)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		emptyShares := make([]shamir.PriShare, 0)
 		(*usedShares) = append((*usedShares), emptyShares)
@@ -139,67 +130,77 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Fie
 				(*usedShares)[l-1] = append((*usedShares)[l-1], relevantShare)
 			}
 		}
-		if !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(*obtainedSubsecrets,
-			recovered) {
+		newSubsecret := !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(
+			*obtainedSubsecrets, recovered)
+		if newSubsecret {
 			*obtainedSubsecrets = append(*obtainedSubsecrets, recovered)
 		}
-		if len((*obtainedSubsecrets)) > 1 {
-			runRelevantHashes := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantHashes
-			runRelevantSalt := peoplePackets[shareDataMap[usedShareData[0].X]].Salt
-			SubsecretsAdditiveIndisRecovery(runRelevantHashes,
-				runRelevantSalt, *obtainedSubsecrets, secretRecovered,
-				recoveredKey)
+		// The secret key is only checked again with a new subsecret
+		if newSubsecret && len((*obtainedSubsecrets)) > 1 {
+			relevantPacket := peoplePackets[shareDataMap[usedShareData[0].X]]
+			if err := SubsecretsAdditiveIndisRecovery(relevantPacket.RelevantHashes,
+				relevantPacket.Salt, relevantPacket.Legacy, *obtainedSubsecrets, relevantPacket.Verifier,
+				secretRecovered, recoveredKey); err != nil {
+				return err
+			}
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsAdditiveUint16(f shamir.Field,
-	relevantIndicesSubsets []uint16,
+func ComputeCombinationsAdditiveUint16(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []AdditivePacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
-	usedSharesChannel chan<- []shamir.PriShare, wg *sync.WaitGroup) {
-	defer wg.Done()
+	usedSharesChannel chan<- []shamir.PriShare) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for i := 0; i < len(relevantIndicesSubsets)/absoluteThreshold; i++ {
-		indicesSet := relevantIndicesSubsets[i*absoluteThreshold : (i+1)*absoluteThreshold]
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset, indicesSet)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		runRelevantHashes := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantHashes
 		runRelevantSalt := peoplePackets[shareDataMap[relevantSubset[0].X]].Salt
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isHashMatched, _, err := LeavesAdditiveOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantHashes,
-			runRelevantSalt)
+			runRelevantSalt, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isHashMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold)
 			copy(outputSubset, relevantSubset)
-			usedSharesChannel <- outputSubset
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
 func LeavesAdditiveOptUsedIndisRecoveryParallelized(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
 	runRelevantHashes [][32]byte,
-	runRelevantSalt [32]byte) (bool, [32]byte, error) {
+	runRelevantSalt [32]byte, legacy bool) (bool, [32]byte, error) {
 	isHashMatched, matchedHash, err := crypto_protocols.GetAdditiveIndisShareMatchBinExt(
-		recovered, runRelevantHashes, runRelevantSalt)
+		recovered, runRelevantHashes, runRelevantSalt, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return false, matchedHash, err
 	}
 	return isHashMatched, matchedHash, nil
@@ -211,9 +212,9 @@ func LeavesAdditiveOptUsedIndisRecoveryParallelized(f shamir.Field,
 // ************Functions for basic***************
 // **************************************************************************
 
-func BasicHashedSecretRecoveryParallelizedUint16(f shamir.Field,
-	anonymitySet []shamir.PriShare, accessOrder []int,
-	secretKeyHash [32]byte) ([]uint16, error) {
+func BasicHashedSecretRecoveryParallelizedUint16(ctx context.Context,
+	f shamir.Field, anonymitySet []shamir.PriShare, accessOrder []int,
+	secretKeyHash [32]byte, noOfWorkers int) ([]uint16, error) {
 	// The user obtains the information of the anonymity set one-by-one
 	// After obtaining two elements, the user tries to recover the secret
 	anonymitySetSize := len(anonymitySet)
@@ -229,45 +230,33 @@ func BasicHashedSecretRecoveryParallelizedUint16(f shamir.Field,
 		relevantIndex := accessOrder[obtainedLength-1]
 		// The user tries different thresholds
 		for threshold := 2; threshold <= obtainedLength; threshold++ {
-			// Generate different combinations of the secret shares
-			// Generate combinations without the last obtained share
-			relevantIndicesSubsets :=
-				utils.GenerateSubsetsOfSizeUint16(indicesSet[:obtainedLength-1], threshold-1)
-
-			// Get the required number of routines
-			noOfSubsets := len(relevantIndicesSubsets) / (threshold - 1)
-			// noOfRoutines := 1
-			// for key, value := range routinesMap {
-			// 	if noOfSubsets >= key {
-			// 		noOfRoutines = value
-			// 		break
-			// 	}
-			// }
-			noOfRoutines := 16
-
-			perSubsetLen := noOfSubsets / noOfRoutines
-			// Distribute the subsets into smaller slices
-			var smallerSubsets [][]uint16
-			for i := 0; i < noOfRoutines; i++ {
-				if i == (noOfRoutines - 1) {
-					smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*(threshold-1):])
-				} else {
-					smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*(threshold-1):(i+1)*perSubsetLen*(threshold-1)])
-				}
+			// Go through the combinations of the secret shares
+			// without the last obtained share
+			noOfSubsets, err := utils.GetCombinationUint64(obtainedLength-1,
+				threshold-1)
+			if err != nil {
+				return nil, err
 			}
+			noOfRoutines := NoOfWorkers(noOfWorkers)
 
-			recoveredChannel := make(chan []uint16, 1000)
-			var wg sync.WaitGroup
-			// For each subset, run it in a separate subroutine
-			for i := 0; i < noOfRoutines; i++ {
-				wg.Add(1)
-				go ComputeCombinationsBasicUint16(f, smallerSubsets[i], anonymitySet,
-					relevantIndex, threshold-1, secretKeyHash, recoveredChannel, &wg)
-			}
+			chunks := newCombinationChunks(obtainedLength-1, threshold-1, 0,
+				noOfSubsets, noOfRoutines)
 
-			wg.Wait()
+			recoveredChannel := make(chan []uint16, 1000)
+			// The routines take the chunks of the subsets until none are left
+			err = RunCombinationRoutines(ctx, noOfRoutines,
+				func(ctx context.Context, _ int) error {
+					return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+						return ComputeCombinationsBasicUint16(ctx, f, subsets,
+							indicesSet, anonymitySet, relevantIndex, secretKeyHash,
+							recoveredChannel)
+					})
+				})
 
 			close(recoveredChannel)
+			if err != nil {
+				return nil, err
+			}
 
 			for obtainedData := range recoveredChannel {
 				recovered = obtainedData
@@ -287,35 +276,39 @@ func BasicHashedSecretRecoveryParallelizedUint16(f shamir.Field,
 
 // This function is meant for benchmarking the function
 
-func ComputeCombinationsBasicUint16(f shamir.Field,
-	thresholdIndicesSubsets []uint16,
+func ComputeCombinationsBasicUint16(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
+	indicesSet []uint16,
 	anonymitySet []shamir.PriShare,
 	relevantIndex int,
-	subThreshold int,
 	secretKeyHash [32]byte,
-	recoveredChannel chan<- []uint16,
-	wg *sync.WaitGroup) {
-	defer wg.Done()
+	recoveredChannel chan<- []uint16) error {
 	// Try different combinations for recovery
-	for i := 0; i < len(thresholdIndicesSubsets)/subThreshold; i++ {
-		relevantIndices := thresholdIndicesSubsets[i*subThreshold : (i+1)*subThreshold]
-		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices))
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		relevantIndices := subsets.Combination()
+		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices)+1)
 		for _, ind := range relevantIndices {
-			relevantSubset = append(relevantSubset, anonymitySet[ind])
+			relevantSubset = append(relevantSubset, anonymitySet[indicesSet[ind]])
 		}
 		relevantSubset = append(relevantSubset, anonymitySet[relevantIndex])
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			// TODO: log.Fatal immediately exit, so the return after it
-			// is useless
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		if crypto_protocols.CheckHashesEqual(secretKeyHash,
 			crypto_protocols.GetSHA256(shamir.Uint16sToBytes(recovered))) {
-			recoveredChannel <- recovered
-			break
+			select {
+			case recoveredChannel <- recovered:
+			case <-ctx.Done():
+			}
+			return nil
 		}
 	}
+	return nil
 }
 
 // **************************************************************************
@@ -323,9 +316,9 @@ func ComputeCombinationsBasicUint16(f shamir.Field,
 
 // ************Functions for thresholded***************
 // **************************************************************************
-func ThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
-	anonymityPackets []ThresholdedPacket, accessOrder []int,
-	absoluteThreshold int) [][]uint16 {
+func ThOptUsedIndisSecretRecoveryParallelized(ctx context.Context,
+	f shamir.Field, anonymityPackets []ThresholdedPacket, accessOrder []int,
+	absoluteThreshold, noOfWorkers int) ([][]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	var usedShares [][][]shamir.PriShare
 	var obtainedSubsecrets [][]shamir.PriShare
@@ -356,9 +349,13 @@ func ThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 		for ind1 := 0; ind1 < len(anonymityPackets[0].ShareData); ind1++ {
 			if !secretRecovered[ind1] {
-				PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(f, peoplePackets,
-					absoluteThreshold, &(usedShares[ind1]), &(obtainedSubsecrets[ind1]),
-					&(secretRecovered[ind1]), &recoveredSubKey, &trusteesApproached, ind1)
+				err := PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(ctx,
+					f, peoplePackets, absoluteThreshold, &(usedShares[ind1]),
+					&(obtainedSubsecrets[ind1]), &(secretRecovered[ind1]),
+					&recoveredSubKey, &trusteesApproached, ind1, noOfWorkers)
+				if err != nil {
+					return nil, err
+				}
 
 				if secretRecovered[ind1] {
 					recoveredKey[ind1] = recoveredSubKey
@@ -370,14 +367,15 @@ func ThOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 	}
 
-	return recoveredKey
+	return recoveredKey, nil
 }
 
-func PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Field,
+func PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []ThresholdedPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[]shamir.PriShare,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	trusteesApproached *[]int, secretIndex int) {
+	trusteesApproached *[]int, secretIndex, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket ThresholdedPacket
@@ -397,69 +395,56 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Field,
 		}
 	}
 
-	isMatched1 := CheckAlreadyObtainedThresholdedSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex)
+	isMatched1, err := CheckAlreadyObtainedThresholdedSubsecrets(f,
+		absoluteThreshold, usedShares, obtainedSubsecrets, mostRecentPacket,
+		secretIndex)
+	if err != nil {
+		return err
+	}
 	if isMatched1 {
 		if !utils.IsInSlice((*trusteesApproached), len(peoplePackets)-1) {
 			(*trusteesApproached) = append((*trusteesApproached), len(peoplePackets)-1)
 		}
 	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []uint16
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, uint16(i))
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	orderedShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(orderedShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
-	shareIndicesSet := utils.GenerateIndicesSetUint16(len(relevantShareData))
-
-	relevantIndicesSubsets := utils.GenerateSubsetsOfSizeUint16Filtered(shareIndicesSet, absoluteThreshold, relevantIndices)
-
-	// Get the required number of routines
-	noOfSubsets := len(relevantIndicesSubsets) / absoluteThreshold
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
 
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][]uint16
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:(i+1)*perSubsetLen*absoluteThreshold])
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		copySlice := make([]ThresholdedPacket, len(peoplePackets))
-		copy(copySlice, peoplePackets)
-		go ComputeCombinationsThresholdedUint16(f, smallerSubsets[i], relevantShareData,
-			copySlice, shareDataMap, absoluteThreshold, secretIndex, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	chunks := newCombinationChunks(len(orderedShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsThresholdedUint16(ctx, f, subsets,
+					orderedShareData, peoplePackets, shareDataMap,
+					absoluteThreshold, secretIndex, usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recoveredShare := usedShareData[len(usedShareData)-1]
@@ -486,28 +471,33 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Field,
 		if len((*obtainedSubsecrets)) > 1 {
 			runRelevantEncryptions := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantEncryptions[secretIndex]
 			runRelevantNonce := peoplePackets[shareDataMap[usedShareData[0].X]].Nonce
+			runRelevantLegacy := peoplePackets[shareDataMap[usedShareData[0].X]].Legacy
 			SubsecretsThresholdedIndisRecovery(f, runRelevantEncryptions,
-				runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+				runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 				recoveredKey)
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsThresholdedUint16(f shamir.Field,
-	relevantIndicesSubsets []uint16,
+func ComputeCombinationsThresholdedUint16(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []ThresholdedPacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
 	secretIndex int,
-	usedSharesChannel chan<- []shamir.PriShare, wg *sync.WaitGroup) {
-	defer wg.Done()
+	usedSharesChannel chan<- []shamir.PriShare) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for i := 0; i < len(relevantIndicesSubsets)/absoluteThreshold; i++ {
-		indicesSet := relevantIndicesSubsets[i*absoluteThreshold : (i+1)*absoluteThreshold]
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		// Create the subset for running the recovery
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
@@ -515,38 +505,42 @@ func ComputeCombinationsThresholdedUint16(f shamir.Field,
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset, indicesSet)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, correctX, err := LeavesThresholdedOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce)
+			runRelevantNonce, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold, absoluteThreshold+1)
 			copy(outputSubset, relevantSubset)
 			subsecretShare := shamir.PriShare{X: correctX, Y: recovered}
 			outputSubset = append(outputSubset, subsecretShare)
-			usedSharesChannel <- outputSubset
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
 func LeavesThresholdedOptUsedIndisRecoveryParallelized(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
 	runRelevantEncryptions [][]byte,
-	runRelevantNonce [32]byte) (bool, uint16, error) {
+	runRelevantNonce [32]byte, legacy bool) (bool, uint16, error) {
 	isEncryptionMatched, correctX, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(
-		recovered, runRelevantNonce, runRelevantEncryptions)
+		recovered, runRelevantNonce, runRelevantEncryptions,
+		crypto_protocols.LabelLeafCheck, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return isEncryptionMatched, 0, err
 	}
 	return isEncryptionMatched, correctX, nil
@@ -557,9 +551,9 @@ func LeavesThresholdedOptUsedIndisRecoveryParallelized(f shamir.Field,
 
 // ************Functions for hinted***************
 // **************************************************************************
-func HintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
-	anonymityPackets []HintedTPacket, accessOrder []int,
-	absoluteThreshold int) [][]uint16 {
+func HintedTOptUsedIndisSecretRecoveryParallelized(ctx context.Context,
+	f shamir.Field, anonymityPackets []HintedTPacket, accessOrder []int,
+	absoluteThreshold, noOfWorkers int) ([][]uint16, error) {
 	anonymitySetSize := len(anonymityPackets)
 	var usedShares [][][]shamir.PriShare
 	var hintedTrustees []int
@@ -589,10 +583,13 @@ func HintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 		}
 		for ind1 := 0; ind1 < len(anonymityPackets[0].ShareData); ind1++ {
 			if !secretRecovered[ind1] {
-				PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(f, peoplePackets,
-					absoluteThreshold, &(usedShares[ind1]), &(obtainedSubsecrets[ind1]),
-					&(secretRecovered[ind1]), &recoveredSubKey,
-					ind1, &hintedTrustees)
+				err := PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(ctx,
+					f, peoplePackets, absoluteThreshold, &(usedShares[ind1]),
+					&(obtainedSubsecrets[ind1]), &(secretRecovered[ind1]),
+					&recoveredSubKey, ind1, &hintedTrustees, noOfWorkers)
+				if err != nil {
+					return nil, err
+				}
 
 				if secretRecovered[ind1] {
 					recoveredKey[ind1] = recoveredSubKey
@@ -606,14 +603,15 @@ func HintedTOptUsedIndisSecretRecoveryParallelized(f shamir.Field,
 			utils.UpdateOrderBinExt(hintedTrustees, &accessOrder, obtainedLength)
 		}
 	}
-	return recoveredKey
+	return recoveredKey, nil
 }
 
-func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Field,
+func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []HintedTPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	secretIndex int, hintedTrustees *[]int) {
+	secretIndex int, hintedTrustees *[]int, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData, relevantShareData []shamir.PriShare
 	var mostRecentPacket HintedTPacket
@@ -633,77 +631,61 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Fiel
 		}
 	}
 
-	CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold, usedShares,
-		obtainedSubsecrets, mostRecentPacket, secretIndex, hintedTrustees)
+	err := CheckAlreadyObtainedHintedTSubsecrets(f, absoluteThreshold,
+		usedShares, obtainedSubsecrets, mostRecentPacket, secretIndex,
+		hintedTrustees)
+	if err != nil {
+		return err
+	}
 	// Do not use the shares which have been already used for recovery
-	relevantShareData, err := GetRelevantShareData(allShareData,
+	relevantShareData, err = GetRelevantShareData(allShareData,
 		usedShares)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if len(relevantShareData) < absoluteThreshold {
-		return
-	}
-	var relevantIndices []uint16
-	for i := 0; i < len(relevantShareData); i++ {
-		if shareDataMap[relevantShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, uint16(i))
-		}
+		return nil
+	}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	orderedShareData, noOfRecentShares := orderRecentSharesLast(relevantShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(orderedShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
-	shareIndicesSet := utils.GenerateIndicesSetUint16(len(relevantShareData))
-
-	relevantIndicesSubsets := utils.GenerateSubsetsOfSizeUint16Filtered(shareIndicesSet, absoluteThreshold, relevantIndices)
-
-	// Get the required number of routines
-	noOfSubsets := len(relevantIndicesSubsets) / absoluteThreshold
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
 
-	// Number of combinations each routine needs to run
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][]uint16
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:(i+1)*perSubsetLen*absoluteThreshold])
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
 	// Channel for the user shares
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 	// Channel for the hinted people
 	hintedPeopleChannel := make(chan uint16, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		copySlice := make([]HintedTPacket, len(peoplePackets))
-		copy(copySlice, peoplePackets)
-		go ComputeCombinationsHintedTUint16(f, smallerSubsets[i], relevantShareData,
-			copySlice, shareDataMap, absoluteThreshold,
-			secretIndex, usedSharesChannel,
-			hintedPeopleChannel, &wg)
-	}
-
-	wg.Wait()
+	chunks := newCombinationChunks(len(orderedShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsHintedTUint16(ctx, f, subsets,
+					orderedShareData, peoplePackets, shareDataMap,
+					absoluteThreshold, secretIndex, usedSharesChannel,
+					hintedPeopleChannel)
+			})
+		})
 
 	close(usedSharesChannel)
 	close(hintedPeopleChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recovered, err := f.CombineUniqueX(usedShareData)
 		if err != nil {
-			fmt.Println(usedShareData)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		emptyShares := make([]shamir.PriShare, 0)
 		(*usedShares) = append((*usedShares), emptyShares)
@@ -713,7 +695,11 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Fiel
 				(*usedShares)[l-1] = append((*usedShares)[l-1], relevantShare)
 			}
 			relevantPacket := peoplePackets[shareDataMap[relevantShare.X]]
-			UpdateHints(relevantPacket, recovered, hintedTrustees, secretIndex)
+			err := UpdateHints(relevantPacket, recovered, hintedTrustees,
+				secretIndex)
+			if err != nil {
+				return err
+			}
 		}
 		if !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(*obtainedSubsecrets,
 			recovered) {
@@ -722,82 +708,153 @@ func PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(f shamir.Fiel
 		if len((*obtainedSubsecrets)) > 1 {
 			runRelevantEncryptions := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantEncryptions[secretIndex]
 			runRelevantNonce := peoplePackets[shareDataMap[usedShareData[0].X]].Nonce
+			runRelevantLegacy := peoplePackets[shareDataMap[usedShareData[0].X]].Legacy
 			SubsecretsHintedTIndisRecovery(runRelevantEncryptions,
-				runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+				runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 				recoveredKey)
 			if *secretRecovered {
 				break
 			}
 		}
 	}
+	return nil
 }
 
-func ComputeCombinationsHintedT(f shamir.Field,
-	relevantIndicesSubsets [][]int,
+func ComputeCombinationsHintedT(ctx context.Context, f shamir.Field,
+	subsets *utils.CombinationIterator,
 	relevantShareData []shamir.PriShare,
 	peoplePackets []HintedTPacket,
 	shareDataMap map[uint16]int,
 	absoluteThreshold int,
 	secretIndex int,
 	usedSharesChannel chan<- []shamir.PriShare,
-	hintedPeopleChannel chan<- uint16, wg *sync.WaitGroup) {
-	defer wg.Done()
+	hintedPeopleChannel chan<- uint16) error {
 	relevantSubset := make([]shamir.PriShare, absoluteThreshold)
-	for _, indicesSet := range relevantIndicesSubsets {
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		indicesSet := subsets.Combination()
 		for iVal, index := range indicesSet {
 			relevantSubset[iVal] = relevantShareData[index]
 		}
 		// Get the recovered secret from the absoluteThreshold number of shares
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			fmt.Println(relevantSubset)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		// Considering the hashes and marker info of only one person in
 		// the subset is enough
 		runRelevantEncryptions := peoplePackets[shareDataMap[relevantSubset[0].X]].RelevantEncryptions[secretIndex]
 		runRelevantNonce := peoplePackets[shareDataMap[relevantSubset[0].X]].Nonce
+		runRelevantLegacy := peoplePackets[shareDataMap[relevantSubset[0].X]].Legacy
 		isEncryptionMatched, hint, err := LeavesHintedTOptUsedIndisRecoveryParallelized(f,
 			recovered, relevantSubset, runRelevantEncryptions,
-			runRelevantNonce)
+			runRelevantNonce, runRelevantLegacy)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return err
 		}
 		if isEncryptionMatched {
 			outputSubset := make([]shamir.PriShare, absoluteThreshold)
 			copy(outputSubset, relevantSubset)
-			usedSharesChannel <- outputSubset
-			hintedPeopleChannel <- hint
+			select {
+			case usedSharesChannel <- outputSubset:
+			case <-ctx.Done():
+				return nil
+			}
+			select {
+			case hintedPeopleChannel <- hint:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
 func LeavesHintedTOptUsedIndisRecoveryParallelized(f shamir.Field,
 	recovered []uint16, relevantSubset []shamir.PriShare,
 	runRelevantEncryptions [][]byte,
-	runRelevantNonce [32]byte) (bool, uint16, error) {
+	runRelevantNonce [32]byte, legacy bool) (bool, uint16, error) {
 	isEncryptionMatched, hint, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(
-		recovered, runRelevantNonce, runRelevantEncryptions)
+		recovered, runRelevantNonce, runRelevantEncryptions,
+		crypto_protocols.LabelHint, legacy)
 	if err != nil {
-		log.Fatalln(err)
 		return isEncryptionMatched, 0, err
 	}
 	return isEncryptionMatched, hint, nil
 }
 
 func UpdateHints(packet HintedTPacket, recovered []uint16,
-	hintedTrustees *[]int, secretIndex int) {
+	hintedTrustees *[]int, secretIndex int) error {
 	runRelevantEncryptions := packet.RelevantEncryptions[secretIndex]
 	runRelevantNonce := packet.Nonce
 	isEncryptionMatched, hint, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(
-		recovered, runRelevantNonce, runRelevantEncryptions)
+		recovered, runRelevantNonce, runRelevantEncryptions,
+		crypto_protocols.LabelHint, packet.Legacy)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	if isEncryptionMatched {
 		if !crypto_protocols.CheckHintTAlreadyUsed(*hintedTrustees, int(hint)) {
 			(*hintedTrustees) = append((*hintedTrustees), int(hint))
 		}
 	}
+	return nil
+}
+
+// Orders the shares such that the shares of the most recent packet come last
+// and gives the number of such shares
+func orderRecentSharesLast(shareData []shamir.PriShare,
+	shareDataMap map[uint16]int,
+	recentPacketIndex int) ([]shamir.PriShare, int) {
+	orderedShareData := make([]shamir.PriShare, 0, len(shareData))
+	var recentShareData []shamir.PriShare
+	for _, shareVal := range shareData {
+		if shareDataMap[shareVal.X] == recentPacketIndex {
+			recentShareData = append(recentShareData, shareVal)
+		} else {
+			orderedShareData = append(orderedShareData, shareVal)
+		}
+	}
+	orderedShareData = append(orderedShareData, recentShareData...)
+	return orderedShareData, len(recentShareData)
+}
+
+// RunCombinationRoutines runs the routines going through the combinations
+// and waits for them
+// The routines stop once the context is cancelled or one of them fails
+func RunCombinationRoutines(ctx context.Context, noOfRoutines int,
+	routine func(ctx context.Context, i int) error) error {
+	routinesCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errChannel := make(chan error, noOfRoutines)
+	var wg sync.WaitGroup
+	for i := 0; i < noOfRoutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := routine(routinesCtx, i); err != nil {
+				errChannel <- err
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errChannel)
+	if err, ok := <-errChannel; ok {
+		return err
+	}
+	return RecoveryCancelled(ctx)
+}
+
+// RecoveryCancelled returns the error for stopping the recovery if the
+// context has been cancelled or its deadline has passed
+// The error wraps both ErrRecoveryCancelled and the error of the context
+func RecoveryCancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", errors.ErrRecoveryCancelled, err)
+	}
+	return nil
 }
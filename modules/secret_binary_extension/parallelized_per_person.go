@@ -1,12 +1,12 @@
 package secret_binary_extension
 
 import (
+	"context"
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
-	"log"
-	"sync"
 )
 
 // **************************************************************************
@@ -14,9 +14,9 @@ import (
 
 // ************Functions for additive***************
 // **************************************************************************
-func AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f shamir.Field,
-	anonymityPackets []AdditivePacket, accessOrder []int,
-	absoluteThreshold, obtainedLength int) {
+func AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(ctx context.Context,
+	f shamir.Field, anonymityPackets []AdditivePacket, accessOrder []int,
+	absoluteThreshold, obtainedLength, noOfWorkers int) error {
 	secretRecovered := false
 	var usedShares [][]shamir.PriShare
 	var obtainedSubsecrets [][]uint16
@@ -32,15 +32,16 @@ func AdditiveOptUsedIndisSecretRecoveryParallelizedPerPerson(f shamir.Field,
 		peoplePackets = append(peoplePackets,
 			anonymityPackets[obtainedPacketIndex])
 	}
-	PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f,
-		peoplePackets, absoluteThreshold, &usedShares, &obtainedSubsecrets,
-		&secretRecovered, &recoveredKey)
+	return PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(ctx,
+		f, peoplePackets, absoluteThreshold, &usedShares, &obtainedSubsecrets,
+		&secretRecovered, &recoveredKey, noOfWorkers)
 }
 
-func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f shamir.Field,
+func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []AdditivePacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[][]uint16,
-	secretRecovered *bool, recoveredKey *[]uint16) {
+	secretRecovered *bool, recoveredKey *[]uint16, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData []shamir.PriShare
 	// Store which shareData corresponds to which person
@@ -57,59 +58,43 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f s
 	}
 
 	if len(allShareData) < absoluteThreshold {
-		return
+		return nil
 	}
-	var relevantIndices []uint16
-	for i := 0; i < len(allShareData); i++ {
-		if shareDataMap[allShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, uint16(i))
-		}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	orderedShareData, noOfRecentShares := orderRecentSharesLast(allShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(orderedShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
 
-	shareIndicesSet := utils.GenerateIndicesSetUint16(len(allShareData))
-
-	relevantIndicesSubsets := utils.GenerateSubsetsOfSizeUint16Filtered(shareIndicesSet, absoluteThreshold, relevantIndices)
-
-	noOfSubsets := len(relevantIndicesSubsets) / absoluteThreshold
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
-
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][]uint16
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:(i+1)*perSubsetLen*absoluteThreshold])
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		go ComputeCombinationsAdditiveUint16(f, smallerSubsets[i], allShareData,
-			peoplePackets, shareDataMap, absoluteThreshold, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	chunks := newCombinationChunks(len(orderedShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsAdditiveUint16(ctx, f, subsets,
+					orderedShareData, peoplePackets, shareDataMap, absoluteThreshold,
+					usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recovered, err := f.CombineUniqueX(usedShareData)
 		if err != nil {
-			fmt.Println(usedShareData)
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		emptyShares := make([]shamir.PriShare, 0)
 		(*usedShares) = append((*usedShares), emptyShares)
@@ -119,18 +104,22 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f s
 				(*usedShares)[l-1] = append((*usedShares)[l-1], relevantShare)
 			}
 		}
-		if !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(*obtainedSubsecrets,
-			recovered) {
+		newSubsecret := !crypto_protocols.CheckSubsecretAlreadyRecoveredBinExt(
+			*obtainedSubsecrets, recovered)
+		if newSubsecret {
 			*obtainedSubsecrets = append(*obtainedSubsecrets, recovered)
 		}
-		if len((*obtainedSubsecrets)) > 1 {
-			runRelevantHashes := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantHashes
-			runRelevantSalt := peoplePackets[shareDataMap[usedShareData[0].X]].Salt
-			SubsecretsAdditiveIndisRecovery(runRelevantHashes,
-				runRelevantSalt, *obtainedSubsecrets, secretRecovered,
-				recoveredKey)
+		// The secret key is only checked again with a new subsecret
+		if newSubsecret && len((*obtainedSubsecrets)) > 1 {
+			relevantPacket := peoplePackets[shareDataMap[usedShareData[0].X]]
+			if err := SubsecretsAdditiveIndisRecovery(relevantPacket.RelevantHashes,
+				relevantPacket.Salt, relevantPacket.Legacy, *obtainedSubsecrets, relevantPacket.Verifier,
+				secretRecovered, recoveredKey); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // **************************************************************************
@@ -139,9 +128,9 @@ func PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f s
 // ************Functions for basic***************
 // **************************************************************************
 
-func BasicHashedSecretRecoveryParallelizedPerPersonUint16(f shamir.Field,
-	anonymitySet []shamir.PriShare, accessOrder []int,
-	secretKeyHash [32]byte, obtainedLength int) {
+func BasicHashedSecretRecoveryParallelizedPerPersonUint16(ctx context.Context,
+	f shamir.Field, anonymitySet []shamir.PriShare, accessOrder []int,
+	secretKeyHash [32]byte, obtainedLength, noOfWorkers int) error {
 	// The user obtains the information of the anonymity set one-by-one
 	// After obtaining two elements, the user tries to recover the secret
 
@@ -154,76 +143,71 @@ func BasicHashedSecretRecoveryParallelizedPerPersonUint16(f shamir.Field,
 	relevantIndex := accessOrder[obtainedLength-1]
 	// The user tries different thresholds
 	for threshold := 2; threshold <= obtainedLength; threshold++ {
-		// Generate different combinations of the secret shares
-		// Generate combinations without the last obtained share
-		relevantIndicesSubsets :=
-			utils.GenerateSubsetsOfSizeUint16(indicesSet[:obtainedLength-1], threshold-1)
-
-		// Get the required number of routines
-		noOfSubsets := len(relevantIndicesSubsets) / (threshold - 1)
-		// noOfRoutines := 1
-		// for key, value := range routinesMap {
-		// 	if noOfSubsets >= key {
-		// 		noOfRoutines = value
-		// 		break
-		// 	}
-		// }
-		noOfRoutines := 16
-
-		perSubsetLen := noOfSubsets / noOfRoutines
-		// Distribute the subsets into smaller slices
-		var smallerSubsets [][]uint16
-		for i := 0; i < noOfRoutines; i++ {
-			if i == (noOfRoutines - 1) {
-				smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*(threshold-1):])
-			} else {
-				smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*(threshold-1):(i+1)*perSubsetLen*(threshold-1)])
-			}
+		// Go through the combinations of the secret shares
+		// without the last obtained share
+		noOfSubsets, err := utils.GetCombinationUint64(obtainedLength-1,
+			threshold-1)
+		if err != nil {
+			return err
 		}
+		noOfRoutines := NoOfWorkers(noOfWorkers)
 
-		recoveredChannel := make(chan []uint16, 1000)
-		var wg sync.WaitGroup
-		// For each subset, run it in a separate subroutine
-		for i := 0; i < noOfRoutines; i++ {
-			wg.Add(1)
-			go ComputeCombinationsBasicPerPersonUint16(f, smallerSubsets[i], anonymitySet,
-				relevantIndex, threshold-1, secretKeyHash, recoveredChannel, &wg)
-		}
+		chunks := newCombinationChunks(obtainedLength-1, threshold-1, 0,
+			noOfSubsets, noOfRoutines)
 
-		wg.Wait()
+		recoveredChannel := make(chan []uint16, 1000)
+		// The routines take the chunks of the subsets until none are left
+		err = RunCombinationRoutines(ctx, noOfRoutines,
+			func(ctx context.Context, _ int) error {
+				return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+					return ComputeCombinationsBasicPerPersonUint16(ctx, f, subsets,
+						indicesSet, anonymitySet, relevantIndex, secretKeyHash,
+						recoveredChannel)
+				})
+			})
 
 		close(recoveredChannel)
+		if err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func ComputeCombinationsBasicPerPersonUint16(f shamir.Field,
-	thresholdIndicesSubsets []uint16,
+func ComputeCombinationsBasicPerPersonUint16(ctx context.Context,
+	f shamir.Field,
+	subsets *utils.CombinationIterator,
+	indicesSet []uint16,
 	anonymitySet []shamir.PriShare,
 	relevantIndex int,
-	subThreshold int,
 	secretKeyHash [32]byte,
-	recoveredChannel chan<- []uint16,
-	wg *sync.WaitGroup) {
-	defer wg.Done()
+	recoveredChannel chan<- []uint16) error {
 	// Try different combinations for recovery
-	for i := 0; i < len(thresholdIndicesSubsets)/subThreshold; i++ {
-		relevantIndices := thresholdIndicesSubsets[i*subThreshold : (i+1)*subThreshold]
-		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices))
+	for subsets.Next() {
+		// Stop once the recovery has been cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		relevantIndices := subsets.Combination()
+		relevantSubset := make([]shamir.PriShare, 0, len(relevantIndices)+1)
 		for _, ind := range relevantIndices {
-			relevantSubset = append(relevantSubset, anonymitySet[ind])
+			relevantSubset = append(relevantSubset, anonymitySet[indicesSet[ind]])
 		}
 		relevantSubset = append(relevantSubset, anonymitySet[relevantIndex])
 		recovered, err := f.CombineUniqueX(relevantSubset)
 		if err != nil {
-			// TODO: log.Fatal immediately exit, so the return after it
-			// is useless
-			log.Fatal(err)
+			return fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
 		}
 		if crypto_protocols.CheckHashesEqual(secretKeyHash,
 			crypto_protocols.GetSHA256(shamir.Uint16sToBytes(recovered))) {
-			recoveredChannel <- recovered
+			select {
+			case recoveredChannel <- recovered:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 	}
+	return nil
 }
 
 // **************************************************************************
@@ -231,9 +215,9 @@ func ComputeCombinationsBasicPerPersonUint16(f shamir.Field,
 
 // ************Functions for thresholded***************
 // **************************************************************************
-func ThOptUsedIndisSecretRecoveryParallelizedPerPerson(f shamir.Field,
-	anonymityPackets []ThresholdedPacket, accessOrder []int,
-	absoluteThreshold, obtainedLength int) {
+func ThOptUsedIndisSecretRecoveryParallelizedPerPerson(ctx context.Context,
+	f shamir.Field, anonymityPackets []ThresholdedPacket, accessOrder []int,
+	absoluteThreshold, obtainedLength, noOfWorkers int) error {
 	var usedShares [][][]shamir.PriShare
 	var obtainedSubsecrets [][]shamir.PriShare
 	emptySubKey := make([]uint16, len(anonymityPackets[0].ShareData[0][0].Y))
@@ -261,23 +245,28 @@ func ThOptUsedIndisSecretRecoveryParallelizedPerPerson(f shamir.Field,
 	}
 	for ind1 := 0; ind1 < len(anonymityPackets[0].ShareData); ind1++ {
 		if !secretRecovered[ind1] {
-			PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f, peoplePackets,
-				absoluteThreshold, &(usedShares[ind1]), &(obtainedSubsecrets[ind1]),
-				&(secretRecovered[ind1]), &recoveredSubKey, ind1)
+			err := PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(ctx,
+				f, peoplePackets, absoluteThreshold, &(usedShares[ind1]),
+				&(obtainedSubsecrets[ind1]), &(secretRecovered[ind1]),
+				&recoveredSubKey, ind1, noOfWorkers)
+			if err != nil {
+				return err
+			}
 
 			if secretRecovered[ind1] {
 				recoveredKey[ind1] = recoveredSubKey
 			}
 		}
 	}
-
+	return nil
 }
 
-func PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f shamir.Field,
+func PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(
+	ctx context.Context, f shamir.Field,
 	peoplePackets []ThresholdedPacket, absoluteThreshold int,
 	usedShares *[][]shamir.PriShare, obtainedSubsecrets *[]shamir.PriShare,
 	secretRecovered *bool, recoveredKey *[]uint16,
-	secretIndex int) {
+	secretIndex, noOfWorkers int) error {
 	// Put all the share data into a slice
 	var allShareData []shamir.PriShare
 	// Store which shareData corresponds to which person
@@ -290,55 +279,38 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f shamir.
 		}
 	}
 	if len(allShareData) < absoluteThreshold {
-		return
+		return nil
 	}
-	var relevantIndices []uint16
-	for i := 0; i < len(allShareData); i++ {
-		if shareDataMap[allShareData[i].X] == len(peoplePackets)-1 {
-			relevantIndices = append(relevantIndices, uint16(i))
-		}
+	// The subsets containing at least one share of the most recent packet
+	// form a continuous range of ranks once its shares are ordered last
+	orderedShareData, noOfRecentShares := orderRecentSharesLast(allShareData,
+		shareDataMap, len(peoplePackets)-1)
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(
+		len(orderedShareData), absoluteThreshold, noOfRecentShares)
+	if err != nil {
+		return err
 	}
-	shareIndicesSet := utils.GenerateIndicesSetUint16(len(allShareData))
-
-	relevantIndicesSubsets := utils.GenerateSubsetsOfSizeUint16Filtered(shareIndicesSet, absoluteThreshold, relevantIndices)
 
-	// Get the required number of routines
-	noOfSubsets := len(relevantIndicesSubsets) / absoluteThreshold
-	// noOfRoutines := 1
-	// for key, value := range routinesMap {
-	// 	if noOfSubsets >= key {
-	// 		noOfRoutines = value
-	// 		break
-	// 	}
-	// }
-	noOfRoutines := 16
-
-	perSubsetLen := noOfSubsets / noOfRoutines
-	// Distribute the subsets into smaller slices
-	var smallerSubsets [][]uint16
-	for i := 0; i < noOfRoutines; i++ {
-		if i == (noOfRoutines - 1) {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:])
-		} else {
-			smallerSubsets = append(smallerSubsets, relevantIndicesSubsets[i*perSubsetLen*absoluteThreshold:(i+1)*perSubsetLen*absoluteThreshold])
-		}
-	}
+	noOfRoutines := NoOfWorkers(noOfWorkers)
 
 	usedSharesChannel := make(chan []shamir.PriShare, absoluteThreshold*1000)
 
-	var wg sync.WaitGroup
-	// For each subset, run it in a separate subroutine
-	for i := 0; i < noOfRoutines; i++ {
-		wg.Add(1)
-		copySlice := make([]ThresholdedPacket, len(peoplePackets))
-		copy(copySlice, peoplePackets)
-		go ComputeCombinationsThresholdedUint16(f, smallerSubsets[i], allShareData,
-			copySlice, shareDataMap, absoluteThreshold, secretIndex, usedSharesChannel, &wg)
-	}
-
-	wg.Wait()
+	chunks := newCombinationChunks(len(orderedShareData), absoluteThreshold,
+		firstRank, noOfSubsets, noOfRoutines)
+	// The routines take the chunks of the subsets until none are left
+	err = RunCombinationRoutines(ctx, noOfRoutines,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				return ComputeCombinationsThresholdedUint16(ctx, f, subsets,
+					orderedShareData, peoplePackets, shareDataMap, absoluteThreshold,
+					secretIndex, usedSharesChannel)
+			})
+		})
 
 	close(usedSharesChannel)
+	if err != nil {
+		return err
+	}
 
 	for usedShareData := range usedSharesChannel {
 		recoveredShare := usedShareData[len(usedShareData)-1]
@@ -360,9 +332,11 @@ func PersonwiseThOptUsedIndisSecretRecoveryParallelizedPerPersonUint16(f shamir.
 		if len((*obtainedSubsecrets)) > 1 {
 			runRelevantEncryptions := peoplePackets[shareDataMap[usedShareData[0].X]].RelevantEncryptions[secretIndex]
 			runRelevantNonce := peoplePackets[shareDataMap[usedShareData[0].X]].Nonce
+			runRelevantLegacy := peoplePackets[shareDataMap[usedShareData[0].X]].Legacy
 			SubsecretsThresholdedIndisRecovery(f, runRelevantEncryptions,
-				runRelevantNonce, *obtainedSubsecrets, secretRecovered,
+				runRelevantNonce, runRelevantLegacy, *obtainedSubsecrets, secretRecovered,
 				recoveredKey)
 		}
 	}
+	return nil
 }
@@ -0,0 +1,487 @@
+package secret_binary_extension
+
+import (
+	"context"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+)
+
+// Recoverer recovers the secret from the packets that the user obtains one
+// contact at a time
+// The bookkeeping of the used shares and the obtained subsecrets stays
+// inside the recoverer
+type Recoverer interface {
+	// AddPacket stores the packet obtained from the next contact
+	// The packet can be either the packet struct of the scheme or its
+	// binary encoding
+	AddPacket(packet interface{}) error
+	// TryRecover runs the recovery on the packets which have been added
+	// since the last call
+	// done is true once the secret has been recovered
+	TryRecover(ctx context.Context) (secret []byte, done bool, err error)
+	// Stats returns the progress of the recovery
+	Stats() RecoveryStats
+}
+
+// Progress of the recovery
+type RecoveryStats struct {
+	PacketsObtained     int  // packets added to the recoverer
+	PacketsProcessed    int  // packets on which the recovery has been run
+	SubsecretsRecovered int  // subsecrets (or nodes) recovered over all the parts
+	PartsRecovered      int  // parts of the secret recovered
+	TotalParts          int  // parts of the secret (AES-sized chunks)
+	SecretRecovered     bool // true once the secret has been recovered
+	InconsistentShares  int  // shares found inconsistent (robust mode only)
+}
+
+// Runs the recovery for every packet which has not been processed yet
+// The recovery for a packet only considers the combinations including the
+// shares of that packet, so the packets are processed one after another
+// A packet whose recovery has been interrupted is processed again by the
+// next call
+func processPackets(ctx context.Context, processed *int, obtained int,
+	process func(obtainedLength int) (bool, error)) error {
+	for *processed < obtained {
+		if err := RecoveryCancelled(ctx); err != nil {
+			return err
+		}
+		done, err := process(*processed + 1)
+		if err != nil {
+			return err
+		}
+		*processed++
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Checks that a packet of the thresholded or the hinted scheme has the same
+// number of encryption lists and share lists, i.e., one of each per part of
+// the secret
+// noOfParts is the number of parts known from the previous packets, or 0 for
+// the first packet
+func checkPacketParts(relevantEncryptions [][][]byte,
+	shareData [][]shamir.PriShare, noOfParts int) error {
+	if len(shareData) == 0 || len(relevantEncryptions) != len(shareData) {
+		return errors.ErrInvalidPacket
+	}
+	if noOfParts != 0 && len(shareData) != noOfParts {
+		return errors.ErrInvalidPacket
+	}
+	return nil
+}
+
+// ****************************************************************************
+// Additive
+
+type AdditiveRecoverer struct {
+	f                  shamir.Field
+	absoluteThreshold  int
+	packets            []AdditivePacket
+	processed          int
+	usedShares         [][]shamir.PriShare
+	obtainedSubsecrets [][]uint16
+	secretRecovered    bool
+	recoveredKey       []uint16
+	noOfWorkers        int
+}
+
+func NewAdditiveRecoverer(f shamir.Field,
+	absoluteThreshold int) *AdditiveRecoverer {
+	f.InitializeTables()
+	return &AdditiveRecoverer{f: f, absoluteThreshold: absoluteThreshold}
+}
+
+// SetNoOfWorkers sets the number of routines used by the recovery
+// A value smaller than one stands for runtime.NumCPU()
+func (r *AdditiveRecoverer) SetNoOfWorkers(n int) {
+	r.noOfWorkers = n
+}
+
+func (r *AdditiveRecoverer) AddPacket(packet interface{}) error {
+	switch p := packet.(type) {
+	case AdditivePacket:
+		if err := p.Verifier.Check(); err != nil {
+			return err
+		}
+		r.packets = append(r.packets, p)
+	case []byte:
+		decoded, err := UnmarshalAdditivePacket(p)
+		if err != nil {
+			return err
+		}
+		r.packets = append(r.packets, decoded)
+	default:
+		return errors.ErrPacketSchemeMismatch
+	}
+	return nil
+}
+
+func (r *AdditiveRecoverer) TryRecover(ctx context.Context) ([]byte, bool,
+	error) {
+	if !r.secretRecovered {
+		err := processPackets(ctx, &r.processed, len(r.packets),
+			func(obtainedLength int) (bool, error) {
+				var recoveredKey []uint16
+				err := PersonwiseAdditiveOptUsedIndisSecretRecoveryParallelizedUint16(
+					ctx, r.f, r.packets[:obtainedLength], r.absoluteThreshold,
+					&r.usedShares, &r.obtainedSubsecrets, &r.secretRecovered,
+					&recoveredKey, r.noOfWorkers)
+				if err != nil {
+					return false, err
+				}
+				// The key is only kept once it matches the salted hash
+				if r.secretRecovered {
+					r.recoveredKey = recoveredKey
+				}
+				return r.secretRecovered, nil
+			})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if !r.secretRecovered {
+		return nil, false, nil
+	}
+	return shamir.KeyUint16sToKeyBytes(r.recoveredKey), true, nil
+}
+
+func (r *AdditiveRecoverer) Stats() RecoveryStats {
+	stats := RecoveryStats{
+		PacketsObtained:     len(r.packets),
+		PacketsProcessed:    r.processed,
+		SubsecretsRecovered: len(r.obtainedSubsecrets),
+		TotalParts:          1,
+		SecretRecovered:     r.secretRecovered,
+	}
+	if r.secretRecovered {
+		stats.PartsRecovered = 1
+	}
+	return stats
+}
+
+// ****************************************************************************
+// Thresholded
+
+type ThresholdedRecoverer struct {
+	f                  shamir.Field
+	absoluteThreshold  int
+	packets            []ThresholdedPacket
+	processed          int
+	usedShares         [][][]shamir.PriShare
+	obtainedSubsecrets [][]shamir.PriShare
+	secretRecovered    []bool
+	recoveredKey       [][]uint16
+	trusteesApproached []int
+	noOfWorkers        int
+	// The shares of the recovered subsecrets are checked in the robust mode
+	robust             bool
+	inconsistentShares []InconsistentShare
+}
+
+func NewThresholdedRecoverer(f shamir.Field,
+	absoluteThreshold int) *ThresholdedRecoverer {
+	f.InitializeTables()
+	return &ThresholdedRecoverer{f: f, absoluteThreshold: absoluteThreshold}
+}
+
+// SetNoOfWorkers sets the number of routines used by the recovery
+// A value smaller than one stands for runtime.NumCPU()
+func (r *ThresholdedRecoverer) SetNoOfWorkers(n int) {
+	r.noOfWorkers = n
+}
+
+func (r *ThresholdedRecoverer) AddPacket(packet interface{}) error {
+	var thPacket ThresholdedPacket
+	switch p := packet.(type) {
+	case ThresholdedPacket:
+		thPacket = p
+	case []byte:
+		decoded, err := UnmarshalThresholdedPacket(p)
+		if err != nil {
+			return err
+		}
+		thPacket = decoded
+	default:
+		return errors.ErrPacketSchemeMismatch
+	}
+	if err := checkPacketParts(thPacket.RelevantEncryptions,
+		thPacket.ShareData, len(r.secretRecovered)); err != nil {
+		return err
+	}
+	// The number of parts is known from the first packet
+	if len(r.packets) == 0 {
+		noOfParts := len(thPacket.ShareData)
+		r.usedShares = make([][][]shamir.PriShare, noOfParts)
+		r.obtainedSubsecrets = make([][]shamir.PriShare, noOfParts)
+		r.secretRecovered = make([]bool, noOfParts)
+		r.recoveredKey = make([][]uint16, noOfParts)
+	}
+	r.packets = append(r.packets, thPacket)
+	return nil
+}
+
+func (r *ThresholdedRecoverer) TryRecover(ctx context.Context) ([]byte, bool,
+	error) {
+	err := processPackets(ctx, &r.processed, len(r.packets),
+		func(obtainedLength int) (bool, error) {
+			peoplePackets := r.packets[:obtainedLength]
+			if r.robust {
+				peoplePackets = r.consistentPackets(obtainedLength)
+			}
+			for ind1 := range r.secretRecovered {
+				if r.secretRecovered[ind1] {
+					continue
+				}
+				var recoveredSubKey []uint16
+				err := PersonwiseThOptUsedIndisSecretRecoveryParallelizedUint16(
+					ctx, r.f, peoplePackets, r.absoluteThreshold,
+					&(r.usedShares[ind1]), &(r.obtainedSubsecrets[ind1]),
+					&(r.secretRecovered[ind1]), &recoveredSubKey,
+					&r.trusteesApproached, ind1, r.noOfWorkers)
+				if err != nil {
+					return false, err
+				}
+				if r.secretRecovered[ind1] {
+					r.recoveredKey[ind1] = recoveredSubKey
+				}
+			}
+			if r.robust {
+				if err := r.checkConsistency(obtainedLength); err != nil {
+					return false, err
+				}
+			}
+			return utils.AllTrue(r.secretRecovered), nil
+		})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(r.packets) == 0 || !utils.AllTrue(r.secretRecovered) {
+		return nil, false, nil
+	}
+	return shamir.AESKeyUint16sToKeyBytes(r.recoveredKey), true, nil
+}
+
+func (r *ThresholdedRecoverer) Stats() RecoveryStats {
+	stats := RecoveryStats{
+		PacketsObtained:    len(r.packets),
+		PacketsProcessed:   r.processed,
+		TotalParts:         len(r.secretRecovered),
+		InconsistentShares: len(r.inconsistentShares),
+	}
+	for i, recovered := range r.secretRecovered {
+		stats.SubsecretsRecovered += len(r.obtainedSubsecrets[i])
+		if recovered {
+			stats.PartsRecovered++
+		}
+	}
+	stats.SecretRecovered = len(r.packets) > 0 &&
+		stats.PartsRecovered == stats.TotalParts
+	return stats
+}
+
+// ****************************************************************************
+// Hinted
+
+type HintedTRecoverer struct {
+	f                  shamir.Field
+	absoluteThreshold  int
+	packets            []HintedTPacket
+	processed          int
+	usedShares         [][][]shamir.PriShare
+	obtainedSubsecrets [][][]uint16
+	secretRecovered    []bool
+	recoveredKey       [][]uint16
+	hintedTrustees     []int
+	noOfWorkers        int
+}
+
+func NewHintedTRecoverer(f shamir.Field,
+	absoluteThreshold int) *HintedTRecoverer {
+	f.InitializeTables()
+	return &HintedTRecoverer{f: f, absoluteThreshold: absoluteThreshold}
+}
+
+// SetNoOfWorkers sets the number of routines used by the recovery
+// A value smaller than one stands for runtime.NumCPU()
+func (r *HintedTRecoverer) SetNoOfWorkers(n int) {
+	r.noOfWorkers = n
+}
+
+func (r *HintedTRecoverer) AddPacket(packet interface{}) error {
+	var hPacket HintedTPacket
+	switch p := packet.(type) {
+	case HintedTPacket:
+		hPacket = p
+	case []byte:
+		decoded, err := UnmarshalHintedTPacket(p)
+		if err != nil {
+			return err
+		}
+		hPacket = decoded
+	default:
+		return errors.ErrPacketSchemeMismatch
+	}
+	if err := checkPacketParts(hPacket.RelevantEncryptions,
+		hPacket.ShareData, len(r.secretRecovered)); err != nil {
+		return err
+	}
+	if len(r.packets) == 0 {
+		noOfParts := len(hPacket.ShareData)
+		r.usedShares = make([][][]shamir.PriShare, noOfParts)
+		r.obtainedSubsecrets = make([][][]uint16, noOfParts)
+		r.secretRecovered = make([]bool, noOfParts)
+		r.recoveredKey = make([][]uint16, noOfParts)
+	}
+	r.packets = append(r.packets, hPacket)
+	return nil
+}
+
+func (r *HintedTRecoverer) TryRecover(ctx context.Context) ([]byte, bool,
+	error) {
+	err := processPackets(ctx, &r.processed, len(r.packets),
+		func(obtainedLength int) (bool, error) {
+			for ind1 := range r.secretRecovered {
+				if r.secretRecovered[ind1] {
+					continue
+				}
+				var recoveredSubKey []uint16
+				err := PersonwiseHintedTOptUsedIndisSecretRecoveryParallelizedUint16(
+					ctx, r.f, r.packets[:obtainedLength], r.absoluteThreshold,
+					&(r.usedShares[ind1]), &(r.obtainedSubsecrets[ind1]),
+					&(r.secretRecovered[ind1]), &recoveredSubKey, ind1,
+					&r.hintedTrustees, r.noOfWorkers)
+				if err != nil {
+					return false, err
+				}
+				if r.secretRecovered[ind1] {
+					r.recoveredKey[ind1] = recoveredSubKey
+				}
+			}
+			return utils.AllTrue(r.secretRecovered), nil
+		})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(r.packets) == 0 || !utils.AllTrue(r.secretRecovered) {
+		return nil, false, nil
+	}
+	return shamir.AESKeyUint16sToKeyBytes(r.recoveredKey), true, nil
+}
+
+// HintedTrustees returns the hints obtained so far
+// The hints point at the trustees that should be contacted next
+func (r *HintedTRecoverer) HintedTrustees() []int {
+	return append([]int(nil), r.hintedTrustees...)
+}
+
+func (r *HintedTRecoverer) Stats() RecoveryStats {
+	stats := RecoveryStats{
+		PacketsObtained:  len(r.packets),
+		PacketsProcessed: r.processed,
+		TotalParts:       len(r.secretRecovered),
+	}
+	for i, recovered := range r.secretRecovered {
+		stats.SubsecretsRecovered += len(r.obtainedSubsecrets[i])
+		if recovered {
+			stats.PartsRecovered++
+		}
+	}
+	stats.SecretRecovered = len(r.packets) > 0 &&
+		stats.PartsRecovered == stats.TotalParts
+	return stats
+}
+
+// ****************************************************************************
+// Hierarchical
+
+type HierarchicalRecoverer struct {
+	f                   shamir.Field
+	largestShareSetSize int
+	packets             []HierarchicalPacket
+	processed           int
+	candidates          []hierarchicalCandidate
+	loadedPackets       int // packets whose shares are in the candidates
+	recoveredX          []uint16
+	// Number of candidates whose subsets have all been tried
+	triedLength     int
+	secretRecovered bool
+	recoveredKey    []uint16
+}
+
+func NewHierarchicalRecoverer(f shamir.Field,
+	largestShareSetSize int) *HierarchicalRecoverer {
+	f.InitializeTables()
+	return &HierarchicalRecoverer{f: f,
+		largestShareSetSize: largestShareSetSize}
+}
+
+func (r *HierarchicalRecoverer) AddPacket(packet interface{}) error {
+	hPacket, ok := packet.(HierarchicalPacket)
+	if !ok {
+		return errors.ErrPacketSchemeMismatch
+	}
+	r.packets = append(r.packets, hPacket)
+	return nil
+}
+
+func (r *HierarchicalRecoverer) TryRecover(ctx context.Context) ([]byte,
+	bool, error) {
+	if !r.secretRecovered {
+		err := processPackets(ctx, &r.processed, len(r.packets),
+			func(obtainedLength int) (bool, error) {
+				packetIndex := obtainedLength - 1
+				// The shares are already present if the previous run
+				// was interrupted
+				if r.loadedPackets < obtainedLength {
+					for _, shareVal := range r.packets[packetIndex].ShareData {
+						r.candidates = append(r.candidates, hierarchicalCandidate{
+							share: shareVal, packetIndex: packetIndex})
+					}
+					r.loadedPackets = obtainedLength
+				}
+				// Run till the newly recovered nodes do not lead to other
+				// nodes
+				for r.triedLength < len(r.candidates) {
+					currentLength := len(r.candidates)
+					recoveredKey, found, err :=
+						personwiseHierarchicalSecretRecovery(ctx, r.f,
+							r.packets, &r.candidates, &r.recoveredX,
+							r.triedLength, r.largestShareSetSize)
+					if err != nil {
+						return false, err
+					}
+					if found {
+						r.recoveredKey, r.secretRecovered = recoveredKey, true
+						return true, nil
+					}
+					r.triedLength = currentLength
+				}
+				return false, nil
+			})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if !r.secretRecovered {
+		return nil, false, nil
+	}
+	return shamir.KeyUint16sToKeyBytes(r.recoveredKey), true, nil
+}
+
+func (r *HierarchicalRecoverer) Stats() RecoveryStats {
+	stats := RecoveryStats{
+		PacketsObtained:     len(r.packets),
+		PacketsProcessed:    r.processed,
+		SubsecretsRecovered: len(r.recoveredX),
+		TotalParts:          1,
+		SecretRecovered:     r.secretRecovered,
+	}
+	if r.secretRecovered {
+		stats.PartsRecovered = 1
+	}
+	return stats
+}
@@ -0,0 +1,392 @@
+package secret_binary_extension
+
+import (
+	"bytes"
+	"context"
+	goerrors "errors"
+	"fmt"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRecoverer(t *testing.T) {
+	var f shamir.Field
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	for _, schemeTag := range []byte{SchemeTagAdditive, SchemeTagThresholded,
+		SchemeTagHinted} {
+		var recoverer Recoverer
+		switch schemeTag {
+		case SchemeTagAdditive:
+			recoverer = NewAdditiveRecoverer(f, 3)
+		case SchemeTagThresholded:
+			recoverer = NewThresholdedRecoverer(f, 3)
+		default:
+			recoverer = NewHintedTRecoverer(f, 3)
+		}
+		packets := generateEncodedTestPackets(t, schemeTag)
+		var secret []byte
+		done := false
+		// Feed the packets one at a time as the contacts respond
+		for i, packet := range packets {
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			secret, done, err = recoverer.TryRecover(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats := recoverer.Stats()
+			if stats.PacketsObtained != i+1 || stats.PacketsProcessed != i+1 {
+				t.Error("Wrong packet counts", stats)
+			}
+			if stats.SecretRecovered != done {
+				t.Error("Stats do not match the recovery", stats)
+			}
+			if done {
+				break
+			}
+		}
+		if !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered", schemeTag)
+		}
+		// Trying again returns the same secret
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret lost after recovery", schemeTag, err)
+		}
+	}
+}
+
+func TestRecovererInvalidInput(t *testing.T) {
+	var f shamir.Field
+	recoverer := NewThresholdedRecoverer(f, 3)
+	if err := recoverer.AddPacket(AdditivePacket{}); err != errors.ErrPacketSchemeMismatch {
+		t.Error("Packet of another scheme accepted", err)
+	}
+	packets := generateEncodedTestPackets(t, SchemeTagAdditive)
+	if err := recoverer.AddPacket(packets[0]); err != errors.ErrPacketSchemeMismatch {
+		t.Error("Encoded packet of another scheme accepted", err)
+	}
+	// The recovery does not run once the context is cancelled
+	packets = generateEncodedTestPackets(t, SchemeTagThresholded)
+	for _, packet := range packets {
+		if err := recoverer.AddPacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := recoverer.TryRecover(ctx)
+	if !goerrors.Is(err, errors.ErrRecoveryCancelled) ||
+		!goerrors.Is(err, context.Canceled) {
+		t.Error("Cancelled recovery not stopped", err)
+	}
+	if recoverer.Stats().PacketsProcessed != 0 {
+		t.Error("Packets processed after cancellation")
+	}
+	// The recovery continues with a new context
+	secret, done, err := recoverer.TryRecover(context.Background())
+	if err != nil || !done ||
+		!bytes.Equal(secret, []byte("testasdfghjklqwertyu")) {
+		t.Error("Secret not recovered after cancellation", err)
+	}
+}
+
+func TestRecovererPacketShape(t *testing.T) {
+	var f shamir.Field
+	share := []shamir.PriShare{{X: 1, Y: []uint16{1}}}
+	encryptions := [][]byte{{1}}
+	// Every packet has one encryption list and one share list per part
+	testCases := []struct {
+		relevantEncryptions [][][]byte
+		shareData           [][]shamir.PriShare
+	}{
+		{nil, nil},
+		{[][][]byte{encryptions}, nil},
+		{[][][]byte{encryptions}, [][]shamir.PriShare{share, share}},
+	}
+	for _, tc := range testCases {
+		thRecoverer := NewThresholdedRecoverer(f, 2)
+		err := thRecoverer.AddPacket(ThresholdedPacket{
+			RelevantEncryptions: tc.relevantEncryptions,
+			ShareData:           tc.shareData})
+		if err != errors.ErrInvalidPacket {
+			t.Error("Malformed thresholded packet accepted", err)
+		}
+		hRecoverer := NewHintedTRecoverer(f, 2)
+		err = hRecoverer.AddPacket(HintedTPacket{
+			RelevantEncryptions: tc.relevantEncryptions,
+			ShareData:           tc.shareData})
+		if err != errors.ErrInvalidPacket {
+			t.Error("Malformed hinted packet accepted", err)
+		}
+		if _, done, _ := hRecoverer.TryRecover(context.Background()); done {
+			t.Error("Secret recovered without packets")
+		}
+	}
+	// The following packets must have the parts of the first one
+	recoverer := NewThresholdedRecoverer(f, 2)
+	if err := recoverer.AddPacket(ThresholdedPacket{
+		RelevantEncryptions: [][][]byte{encryptions},
+		ShareData:           [][]shamir.PriShare{share}}); err != nil {
+		t.Fatal(err)
+	}
+	err := recoverer.AddPacket(ThresholdedPacket{
+		RelevantEncryptions: [][][]byte{encryptions, encryptions},
+		ShareData:           [][]shamir.PriShare{share, share}})
+	if err != errors.ErrInvalidPacket {
+		t.Error("Packet with another number of parts accepted", err)
+	}
+}
+
+func TestRecoveryCancellation(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	var packets []ThresholdedPacket
+	for _, encoded := range generateEncodedTestPackets(t, SchemeTagThresholded) {
+		packet, err := UnmarshalThresholdedPacket(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err := ThOptUsedIndisSecretRecoveryParallelized(ctx, f, packets,
+		utils.GenerateIndicesSet(len(packets)), 3, 0)
+	if !goerrors.Is(err, errors.ErrRecoveryCancelled) ||
+		!goerrors.Is(err, context.DeadlineExceeded) {
+		t.Error("Recovery not stopped at the deadline", err)
+	}
+	_, err = ThOptUsedIndisSecretRecovery(ctx, f, packets,
+		utils.GenerateIndicesSet(len(packets)), 3)
+	if !goerrors.Is(err, errors.ErrRecoveryCancelled) {
+		t.Error("Sequential recovery not stopped at the deadline", err)
+	}
+	// A failing routine stops the others
+	started := time.Now()
+	err = RunCombinationRoutines(context.Background(), 4,
+		func(ctx context.Context, i int) error {
+			if i == 0 {
+				return errors.ErrInvalidShareSet
+			}
+			<-ctx.Done()
+			return nil
+		})
+	if err != errors.ErrInvalidShareSet {
+		t.Error("Error of the routine not returned", err)
+	}
+	if time.Since(started) > time.Second {
+		t.Error("Routines not stopped after the error")
+	}
+}
+
+func TestNoOfWorkers(t *testing.T) {
+	if NoOfWorkers(0) != runtime.NumCPU() || NoOfWorkers(3) != 3 {
+		t.Error("Wrong number of workers")
+	}
+	// Every subset of the range is taken by exactly one routine
+	n, k := 12, 4
+	firstRank, noOfSubsets, err := utils.GetCombinationRangeWithLast(n, k, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := newCombinationChunks(n, k, firstRank, noOfSubsets, 3)
+	seen := make(chan uint64, noOfSubsets)
+	err = RunCombinationRoutines(context.Background(), 3,
+		func(ctx context.Context, _ int) error {
+			return chunks.run(ctx, func(subsets *utils.CombinationIterator) error {
+				for subsets.Next() {
+					seen <- utils.RankCombination(subsets.Combination())
+				}
+				return nil
+			})
+		})
+	close(seen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranks := make(map[uint64]bool)
+	for rank := range seen {
+		if rank < firstRank || rank >= firstRank+noOfSubsets || ranks[rank] {
+			t.Fatal("Subset outside the range or taken twice", rank)
+		}
+		ranks[rank] = true
+	}
+	if uint64(len(ranks)) != noOfSubsets {
+		t.Error("Subsets missed by the routines", len(ranks), noOfSubsets)
+	}
+	// The recovery gives the same secret with any number of workers
+	for _, workers := range []int{1, 5} {
+		var f shamir.Field
+		recoverer := NewThresholdedRecoverer(f, 3)
+		recoverer.SetNoOfWorkers(workers)
+		for _, packet := range generateEncodedTestPackets(t, SchemeTagThresholded) {
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done ||
+			!bytes.Equal(secret, []byte("testasdfghjklqwertyu")) {
+			t.Error("Secret not recovered with workers", workers, err)
+		}
+	}
+}
+
+func TestRobustThresholdedRecoverer(t *testing.T) {
+	var f shamir.Field
+	var packets []ThresholdedPacket
+	for _, encoded := range generateEncodedTestPackets(t, SchemeTagThresholded) {
+		packet, err := UnmarshalThresholdedPacket(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+	// The first share of a trustee carries the marker of its subsecret
+	corruptedX := packets[1].ShareData[0][0].X
+	corruptedY := append([]uint16(nil), packets[1].ShareData[0][0].Y...)
+	corruptedY[0] ^= 0x00ff
+	packets[1].ShareData[0][0].Y = corruptedY
+
+	recoverer := NewRobustThresholdedRecoverer(f, 3)
+	for _, packet := range packets {
+		if err := recoverer.AddPacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret, done, err := recoverer.TryRecover(context.Background())
+	if err != nil || !done ||
+		!bytes.Equal(secret, []byte("testasdfghjklqwertyu")) {
+		t.Fatal("Secret not recovered with a corrupted share", err)
+	}
+	inconsistent := recoverer.InconsistentShares()
+	if len(inconsistent) != 1 || inconsistent[0] != (InconsistentShare{
+		Packet: 1, SecretIndex: 0, X: corruptedX}) {
+		t.Error("Corrupted share not reported", inconsistent)
+	}
+	if fmt.Sprint(recoverer.InconsistentPackets()) != "[1]" ||
+		recoverer.Stats().InconsistentShares != 1 {
+		t.Error("Wrong inconsistent packets", recoverer.InconsistentPackets())
+	}
+
+	// Nothing is reported for the honest packets
+	recoverer = NewRobustThresholdedRecoverer(f, 3)
+	for _, encoded := range generateEncodedTestPackets(t, SchemeTagThresholded) {
+		if err := recoverer.AddPacket(encoded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, done, err := recoverer.TryRecover(context.Background()); err != nil ||
+		!done || len(recoverer.InconsistentShares()) != 0 {
+		t.Error("Honest shares reported", recoverer.InconsistentShares(), err)
+	}
+}
+
+func TestRobustClaimedShares(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	absoluteThreshold := 3
+	xUsedCoords := []uint16{0}
+	secretPart := make([]uint16, 16)
+	for i := range secretPart {
+		secretPart[i] = uint16(i + 1)
+	}
+	subsecrets, err := GenerateRandomXShares(f, 2, 2, secretPart, &xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var leavesData []shamir.PriShare
+	parentSubsecrets := map[int]map[uint16]shamir.PriShare{
+		0: make(map[uint16]shamir.PriShare)}
+	for _, subsecret := range subsecrets {
+		leaves, err := GenerateRandomXShares(f, absoluteThreshold, 6,
+			subsecret.Y, &xUsedCoords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, leaf := range leaves {
+			parentSubsecrets[0][leaf.X] = subsecret
+		}
+		leavesData = append(leavesData, leaves...)
+	}
+	// Every packet holds two shares of the first subsecret and one of the
+	// second, only the last one holds three shares of the second
+	order := []int{0, 1, 6, 2, 3, 7, 4, 5, 8, 9, 10, 11}
+	currentIndices := []int{0}
+	var packets []ThresholdedPacket
+	for i := 0; i < 4; i++ {
+		var packet ThresholdedPacket
+		packet.Nonce, _ = crypto_protocols.GenerateSalt32()
+		if _, err := GenerateThresholdedPerPersonSharePackets(3,
+			[][]int{order}, [][]shamir.PriShare{leavesData}, &currentIndices,
+			[][]uint16{secretPart}, parentSubsecrets, &packet); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+
+	// Every share carries the marker of its subsecret after the encryption
+	// of the secret part
+	first := subsecrets[0]
+	claimed, marker, err := claimedShares(packets, 0, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 9 {
+		t.Fatal("Shares of the packets without the marker claimed", len(claimed))
+	}
+	for i, claim := range claimed {
+		if claim.packet != i/3 || claim.shareVal.X != leavesData[order[i]].X ||
+			claim.marked != (i%3 != 2) {
+			t.Error("Claim not aligned with its share", i, claim)
+		}
+	}
+	// A repeated subsecret in a packet of the format version 1 carries a
+	// random blob instead
+	encryptions := packets[0].RelevantEncryptions[0]
+	encryptions[2], err = crypto_protocols.GenerateRandomBytes(len(encryptions[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, marker, err = claimedShares(packets, 0, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 9 || !claimed[0].marked || claimed[1].marked ||
+		claimed[1].shareVal.X != leavesData[order[1]].X {
+		t.Fatal("Share with the random blob not claimed without the marker")
+	}
+
+	// A corrupted marked share is found by the decoding, while a corrupted
+	// share without the marker is left out but not reported
+	corrupt := func(shareVal shamir.PriShare) shamir.PriShare {
+		y := append([]uint16(nil), shareVal.Y...)
+		y[0] ^= 0x00ff
+		return shamir.PriShare{X: shareVal.X, Y: y}
+	}
+	claimed[1].shareVal = corrupt(claimed[1].shareVal)
+	claimed[3].shareVal = corrupt(claimed[3].shareVal)
+	verified := []shamir.PriShare{leavesData[3], leavesData[5]}
+	wrong, err := inconsistentClaims(f, absoluteThreshold, first, marker,
+		claimed, verified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wrong) != 1 || wrong[0].packet != 1 ||
+		wrong[0].shareVal.X != leavesData[2].X {
+		t.Error("Wrong shares reported", wrong)
+	}
+	// The decoded subsecret has to open the marker
+	if opened, err := marker.opens(subsecrets[1].Y, first.X); err != nil ||
+		opened {
+		t.Error("Marker opened by another subsecret", err)
+	}
+}
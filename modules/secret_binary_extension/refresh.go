@@ -0,0 +1,180 @@
+package secret_binary_extension
+
+import (
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+)
+
+// **************************************************************************
+// ****************Proactive refresh of the shares***************************
+// **************************************************************************
+
+// The packets are valid forever once they have been distributed
+// Refreshing gives new leaves for the same secret, so that the leaves of the
+// old packets cannot be combined with the new ones
+// The new leaves are at fresh x-coordinates (xUsedCoords still holds the old
+// ones), so an old leaf can never be mistaken for a new one
+// The packets are then generated again from the new leaves with the usual
+// functions (GetAdditiveSharePackets, GetAdditiveAnonymityPackets, ...), which
+// pick new salts and nonces and thus new hashes and encryptions
+// Since the number of trustees and of the leaves of each subsecret stay the
+// same, every packet still holds maxSharesPerPerson shares and the same
+// number of hashes or encryptions, and the random packets are generated
+// again for the same anonymity set size
+
+type RefreshMode int
+
+const (
+	// The dealer splits the same subsecrets into new leaves
+	// Leaked subsecrets are still valid after the refresh
+	RefreshLeaves RefreshMode = iota
+	// The subsecret layer is re-randomized as well
+	// The secret is split into new subsecrets which get new leaves, so
+	// the leaked subsecrets are useless too
+	RefreshSubsecrets
+)
+
+// Gives the number of leaves of every subsecret
+// The leaves are generated one subsecret after another, so the leaves of a
+// subsecret are next to each other in leavesData
+func refreshLeavesNumbers(noOfSubsecrets, noOfLeaves int,
+	parentIndex func(leafIndex int) int) ([]int, error) {
+	leavesNumbers := make([]int, noOfSubsecrets)
+	for i := 0; i < noOfLeaves; i++ {
+		subsecretIndex := parentIndex(i)
+		if subsecretIndex < 0 {
+			return nil, errors.ErrInvalidInput
+		}
+		leavesNumbers[subsecretIndex]++
+	}
+	return leavesNumbers, nil
+}
+
+func uint16sIndex(values [][]uint16, value []uint16) int {
+	for i, v := range values {
+		if crypto_protocols.CompareUint16s(v, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RefreshAdditiveShares gives the new subsecrets, leaves and parents of the
+// additive scheme
+// With RefreshLeaves, the subsecrets are returned as they are
+func RefreshAdditiveShares(f shamir.Field, secretKey []uint16,
+	absoluteThreshold int, subsecrets [][]uint16, leavesData []shamir.PriShare,
+	parentSubsecrets map[uint16][]uint16, xUsedCoords *[]uint16,
+	mode RefreshMode) ([][]uint16, []shamir.PriShare, map[uint16][]uint16,
+	error) {
+	f.InitializeTables()
+	leavesNumbers, err := refreshLeavesNumbers(len(subsecrets),
+		len(leavesData), func(leafIndex int) int {
+			return uint16sIndex(subsecrets,
+				parentSubsecrets[leavesData[leafIndex].X])
+		})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var newSubsecrets [][]uint16
+	switch mode {
+	case RefreshLeaves:
+		newSubsecrets = subsecrets
+	case RefreshSubsecrets:
+		GenerateAdditiveIndisUpperLayers(f, secretKey, len(subsecrets),
+			&newSubsecrets)
+	default:
+		return nil, nil, nil, errors.ErrInvalidInput
+	}
+	newLeavesData := make([]shamir.PriShare, 0, len(leavesData))
+	newParentSubsecrets := make(map[uint16][]uint16)
+	GenerateAdditiveIndisLeavesLayer(f, absoluteThreshold, leavesNumbers,
+		newSubsecrets, &newLeavesData, xUsedCoords, newParentSubsecrets)
+	return newSubsecrets, newLeavesData, newParentSubsecrets, nil
+}
+
+// RefreshThresholdedShares gives the new subsecrets, leaves and parents of
+// the thresholded scheme
+// subsecretsThreshold is the threshold of the subsecrets layer and is only
+// used with RefreshSubsecrets
+func RefreshThresholdedShares(f shamir.Field, secretKey [][]uint16,
+	absoluteThreshold, subsecretsThreshold int,
+	subsecrets [][]shamir.PriShare, leavesData [][]shamir.PriShare,
+	parentSubsecrets map[int]map[uint16]shamir.PriShare,
+	xUsedCoords *[]uint16, mode RefreshMode) ([][]shamir.PriShare,
+	[][]shamir.PriShare, map[int]map[uint16]shamir.PriShare, error) {
+	f.InitializeTables()
+	if len(subsecrets) != len(secretKey) || len(leavesData) != len(secretKey) {
+		return nil, nil, nil, errors.ErrInvalidSliceLength
+	}
+	// All the parts have the same tree
+	leavesNumbers, err := refreshLeavesNumbers(len(subsecrets[0]),
+		len(leavesData[0]), func(leafIndex int) int {
+			parent := parentSubsecrets[0][leavesData[0][leafIndex].X]
+			for i, subsecret := range subsecrets[0] {
+				if subsecret.X == parent.X {
+					return i
+				}
+			}
+			return -1
+		})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var newSubsecrets [][]shamir.PriShare
+	switch mode {
+	case RefreshLeaves:
+		newSubsecrets = subsecrets
+	case RefreshSubsecrets:
+		if subsecretsThreshold < 1 || subsecretsThreshold > len(subsecrets[0]) {
+			return nil, nil, nil, errors.ErrInvalidThreshold
+		}
+		GenerateThresholdedIndisUpperLayers(f, secretKey, len(subsecrets[0]),
+			subsecretsThreshold, &newSubsecrets, xUsedCoords)
+	default:
+		return nil, nil, nil, errors.ErrInvalidInput
+	}
+	var newLeavesData [][]shamir.PriShare
+	newParentSubsecrets := make(map[int]map[uint16]shamir.PriShare)
+	GenerateThresholdedIndisLeavesLayer(f, absoluteThreshold, leavesNumbers,
+		newSubsecrets, &newLeavesData, xUsedCoords, newParentSubsecrets)
+	return newSubsecrets, newLeavesData, newParentSubsecrets, nil
+}
+
+// RefreshHintedTShares gives the new subsecrets, leaves and parents of the
+// hinted scheme
+func RefreshHintedTShares(f shamir.Field, secretKey [][]uint16,
+	absoluteThreshold int, subsecrets [][][]uint16,
+	leavesData [][]shamir.PriShare,
+	parentSubsecrets map[int]map[uint16][]uint16, xUsedCoords *[]uint16,
+	mode RefreshMode) ([][][]uint16, [][]shamir.PriShare,
+	map[int]map[uint16][]uint16, error) {
+	f.InitializeTables()
+	if len(subsecrets) != len(secretKey) || len(leavesData) != len(secretKey) {
+		return nil, nil, nil, errors.ErrInvalidSliceLength
+	}
+	leavesNumbers, err := refreshLeavesNumbers(len(subsecrets[0]),
+		len(leavesData[0]), func(leafIndex int) int {
+			return uint16sIndex(subsecrets[0],
+				parentSubsecrets[0][leavesData[0][leafIndex].X])
+		})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var newSubsecrets [][][]uint16
+	switch mode {
+	case RefreshLeaves:
+		newSubsecrets = subsecrets
+	case RefreshSubsecrets:
+		GenerateHintedTIndisUpperLayers(f, secretKey, len(subsecrets[0]),
+			&newSubsecrets)
+	default:
+		return nil, nil, nil, errors.ErrInvalidInput
+	}
+	var newLeavesData [][]shamir.PriShare
+	newParentSubsecrets := make(map[int]map[uint16][]uint16)
+	GenerateHintedTIndisLeavesLayer(f, absoluteThreshold, leavesNumbers,
+		newSubsecrets, &newLeavesData, xUsedCoords, newParentSubsecrets)
+	return newSubsecrets, newLeavesData, newParentSubsecrets, nil
+}
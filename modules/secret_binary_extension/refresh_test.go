@@ -0,0 +1,152 @@
+package secret_binary_extension
+
+import (
+	"bytes"
+	"context"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/shamir"
+	"testing"
+)
+
+func TestRefreshAdditiveShares(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold := 5, 8, 3
+	for _, mode := range []RefreshMode{RefreshLeaves, RefreshSubsecrets} {
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			GenerateAdditiveTwoLayeredOptIndisShares(f, n, secretKey,
+				absoluteThreshold, 3, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, maxSharesPerPerson, _ := GetAdditiveSharePackets(f, secretKey, n,
+			absoluteThreshold, leavesData, subsecrets, parentSubsecrets,
+			&xUsedCoords)
+
+		newSubsecrets, newLeavesData, newParentSubsecrets, err :=
+			RefreshAdditiveShares(f, secretKey, absoluteThreshold, subsecrets,
+				leavesData, parentSubsecrets, &xUsedCoords, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(newLeavesData) != len(leavesData) {
+			t.Error("Number of leaves changed", mode)
+		}
+		for _, leaf := range newLeavesData {
+			if _, ok := parentSubsecrets[leaf.X]; ok {
+				t.Error("Old x-coordinate reused", mode)
+			}
+		}
+		sameSubsecrets := uint16sIndex(subsecrets, newSubsecrets[0]) != -1
+		if sameSubsecrets != (mode == RefreshLeaves) {
+			t.Error("Subsecrets not refreshed as expected", mode)
+		}
+		// An old leaf does not fit with the new leaves of its subsecret
+		if mode == RefreshLeaves {
+			var mixed []shamir.PriShare
+			for _, leaf := range newLeavesData {
+				if crypto_protocols.CompareUint16s(newParentSubsecrets[leaf.X],
+					subsecrets[0]) && len(mixed) < absoluteThreshold-1 {
+					mixed = append(mixed, leaf)
+				}
+			}
+			mixed = append(mixed, leavesData[0])
+			recovered, err := f.CombineUniqueX(mixed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if crypto_protocols.CompareUint16s(recovered, subsecrets[0]) {
+				t.Error("Old leaf combined with the new leaves")
+			}
+		}
+
+		sharePackets, newMaxSharesPerPerson, _ := GetAdditiveSharePackets(f,
+			secretKey, n, absoluteThreshold, newLeavesData, newSubsecrets,
+			newParentSubsecrets, &xUsedCoords)
+		if newMaxSharesPerPerson != maxSharesPerPerson {
+			t.Error("Padding of the packets changed", mode)
+		}
+		packets, _ := GetAdditiveAnonymityPackets(sharePackets,
+			anonymitySetSize, newMaxSharesPerPerson, len(secretKey),
+			&xUsedCoords)
+		recoverer := NewAdditiveRecoverer(f, absoluteThreshold)
+		for _, packet := range packets {
+			if len(packet.ShareData) != maxSharesPerPerson ||
+				len(packet.RelevantHashes) != maxSharesPerPerson+1 {
+				t.Error("Packets of different sizes", mode)
+			}
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered from the refreshed packets", mode, err)
+		}
+	}
+}
+
+func TestRefreshThresholdedShares(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToAESKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold, noOfSubsecrets := 5, 8, 3, 3
+	for _, mode := range []RefreshMode{RefreshLeaves, RefreshSubsecrets} {
+		subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+			GenerateThresholdedTwoLayeredOptIndisShares(f, n, secretKey,
+				absoluteThreshold, noOfSubsecrets, 50, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		newSubsecrets, newLeavesData, newParentSubsecrets, err :=
+			RefreshThresholdedShares(f, secretKey, absoluteThreshold,
+				noOfSubsecrets, subsecrets, leavesData, parentSubsecrets,
+				&xUsedCoords, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for ind := range newLeavesData {
+			if len(newLeavesData[ind]) != len(leavesData[ind]) {
+				t.Error("Number of leaves changed", mode)
+			}
+			for _, leaf := range newLeavesData[ind] {
+				if _, ok := parentSubsecrets[ind][leaf.X]; ok {
+					t.Error("Old x-coordinate reused", mode)
+				}
+			}
+		}
+		sameSubsecrets := newSubsecrets[0][0].X == subsecrets[0][0].X
+		if sameSubsecrets != (mode == RefreshLeaves) {
+			t.Error("Subsecrets not refreshed as expected", mode)
+		}
+
+		sharePackets, maxSharesPerPerson, encryptionLength, err :=
+			GetThresholdedSharePackets(f, secretKey, n, absoluteThreshold,
+				newLeavesData, newSubsecrets, newParentSubsecrets, &xUsedCoords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets, _ := GetThresholdedAnonymityPackets(sharePackets,
+			anonymitySetSize, maxSharesPerPerson, len(secretKey[0]),
+			len(secretKey), &xUsedCoords, encryptionLength)
+		recoverer := NewThresholdedRecoverer(f, absoluteThreshold)
+		for _, packet := range packets {
+			for ind := range packet.ShareData {
+				if len(packet.ShareData[ind]) != maxSharesPerPerson ||
+					len(packet.RelevantEncryptions[ind]) != maxSharesPerPerson+1 {
+					t.Error("Packets of different sizes", mode)
+				}
+			}
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered from the refreshed packets", mode, err)
+		}
+	}
+}
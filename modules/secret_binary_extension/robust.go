@@ -0,0 +1,339 @@
+package secret_binary_extension
+
+import (
+	"fmt"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/shamir"
+	"sort"
+)
+
+// **************************************************************************
+// ************Robust recovery for the thresholded scheme********************
+// **************************************************************************
+
+// The combination search already skips the corrupted shares, since a subset
+// holding one of them does not give a subsecret matching any marker
+// However, every such subset is tried again and again as more packets come
+// in, and the user never learns which trustee gave the wrong share
+// The robust mode checks the shares of every obtained subsecret once it has
+// been recovered
+// A share belongs to a subsecret if its encryption in the packet decrypts to
+// the marker of that subsecret (the x of the subsecret)
+// The encryption of the j-th share of a packet is the (j+1)-th one, after
+// the encryption of the secret part
+// Together with the shares that recovered the subsecret, more than the
+// threshold number of points of the polynomial are usually available, so
+// Berlekamp-Welch decoding finds the points which are not on it, and the
+// decoded subsecret has to open the marker
+// Those shares are reported and left out of the later combinations
+// The markers are encrypted with a fresh nonce since the format version 2,
+// so every share carries the marker of its subsecret
+// In the packets of the format version 1, only the first share of a
+// subsecret in a packet carries the marker (the others carry random blobs),
+// so the other shares of a packet holding the marker are only taken once
+// they are found on the polynomial, and they are never reported since they
+// may belong to another subsecret
+
+// InconsistentShare is a share which carries the marker of a subsecret but
+// does not lie on its polynomial
+type InconsistentShare struct {
+	Packet      int    // index of the packet in the order of adding
+	SecretIndex int    // part of the secret
+	X           uint16 // x-coordinate of the share
+}
+
+// A share which claims to belong to a subsecret
+// The shares without the marker are in a packet holding the marker of the
+// subsecret and may belong to it
+type claimedShare struct {
+	packet   int
+	shareVal shamir.PriShare
+	marked   bool
+}
+
+// Encryption of the marker of a subsecret in one of the packets
+type subsecretMarker struct {
+	nonce      [32]byte
+	encryption []byte
+	legacy     bool
+}
+
+// Tells whether the value opens the marker with the x of the subsecret
+func (m subsecretMarker) opens(value []uint16, x uint16) (bool, error) {
+	markerX, _, matched, err := crypto_protocols.ThresholdedDecryptionCheckBinExt(
+		shamir.Uint16sToBytes(value), m.nonce, [][]byte{m.encryption},
+		crypto_protocols.LabelLeafCheck, m.legacy)
+	if err != nil {
+		return false, err
+	}
+	return matched && markerX == x, nil
+}
+
+// NewRobustThresholdedRecoverer returns a thresholded recoverer which checks
+// the shares of the recovered subsecrets and reports the inconsistent ones
+func NewRobustThresholdedRecoverer(f shamir.Field,
+	absoluteThreshold int) *ThresholdedRecoverer {
+	r := NewThresholdedRecoverer(f, absoluteThreshold)
+	r.robust = true
+	return r
+}
+
+// InconsistentShares returns the shares found inconsistent so far
+// The list stays empty unless the recoverer is in the robust mode
+func (r *ThresholdedRecoverer) InconsistentShares() []InconsistentShare {
+	return append([]InconsistentShare(nil), r.inconsistentShares...)
+}
+
+// InconsistentPackets returns the indices of the packets holding at least one
+// inconsistent share in the increasing order
+func (r *ThresholdedRecoverer) InconsistentPackets() []int {
+	seen := make(map[int]bool)
+	var packetIndices []int
+	for _, inconsistent := range r.inconsistentShares {
+		if !seen[inconsistent.Packet] {
+			seen[inconsistent.Packet] = true
+			packetIndices = append(packetIndices, inconsistent.Packet)
+		}
+	}
+	sort.Ints(packetIndices)
+	return packetIndices
+}
+
+func (r *ThresholdedRecoverer) isInconsistent(secretIndex int, x uint16) bool {
+	for _, inconsistent := range r.inconsistentShares {
+		if inconsistent.SecretIndex == secretIndex && inconsistent.X == x {
+			return true
+		}
+	}
+	return false
+}
+
+// Copies of the obtained packets without the inconsistent shares
+// The encryptions are kept as they are since the recovery does not rely on
+// their positions
+func (r *ThresholdedRecoverer) consistentPackets(
+	obtainedLength int) []ThresholdedPacket {
+	if len(r.inconsistentShares) == 0 {
+		return r.packets[:obtainedLength]
+	}
+	packets := make([]ThresholdedPacket, obtainedLength)
+	for i, packet := range r.packets[:obtainedLength] {
+		packets[i] = packet
+		packets[i].ShareData = make([][]shamir.PriShare, len(packet.ShareData))
+		for ind, shareData := range packet.ShareData {
+			for _, shareVal := range shareData {
+				if !r.isInconsistent(ind, shareVal.X) {
+					packets[i].ShareData[ind] = append(packets[i].ShareData[ind],
+						shareVal)
+				}
+			}
+		}
+	}
+	return packets
+}
+
+// Checks the shares of all the obtained subsecrets against the packets
+// obtained so far
+func (r *ThresholdedRecoverer) checkConsistency(obtainedLength int) error {
+	for ind, subsecrets := range r.obtainedSubsecrets {
+		for _, subsecret := range subsecrets {
+			claimed, marker, err := claimedShares(r.packets[:obtainedLength],
+				ind, subsecret)
+			if err != nil {
+				return err
+			}
+			verified, err := verifiedShares(r.f, r.absoluteThreshold,
+				r.usedShares[ind], subsecret)
+			if err != nil {
+				return err
+			}
+			wrong, err := inconsistentClaims(r.f, r.absoluteThreshold,
+				subsecret, marker, claimed, verified)
+			if err != nil {
+				return err
+			}
+			for _, claim := range wrong {
+				if !r.isInconsistent(ind, claim.shareVal.X) {
+					r.inconsistentShares = append(r.inconsistentShares,
+						InconsistentShare{Packet: claim.packet, SecretIndex: ind,
+							X: claim.shareVal.X})
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Gives the shares of the packets holding the marker of the subsecret along
+// with one of the markers
+// The shares whose encryption is the marker are marked
+func claimedShares(packets []ThresholdedPacket, secretIndex int,
+	subsecret shamir.PriShare) ([]claimedShare, subsecretMarker, error) {
+	var claimed []claimedShare
+	var marker subsecretMarker
+	bytesVal := shamir.Uint16sToBytes(subsecret.Y)
+	for i, packet := range packets {
+		encryptions := packet.RelevantEncryptions[secretIndex]
+		shareData := packet.ShareData[secretIndex]
+		marked := make([]bool, len(shareData))
+		packetMarked := false
+		for j := range shareData {
+			// The first encryption is the one of the secret part
+			if j+1 >= len(encryptions) {
+				break
+			}
+			x, _, matched, err := crypto_protocols.ThresholdedDecryptionCheckBinExt(
+				bytesVal, packet.Nonce, encryptions[j+1:j+2],
+				crypto_protocols.LabelLeafCheck, packet.Legacy)
+			if err != nil {
+				return nil, marker, err
+			}
+			if matched && x == subsecret.X {
+				if marker.encryption == nil {
+					marker = subsecretMarker{nonce: packet.Nonce,
+						encryption: encryptions[j+1], legacy: packet.Legacy}
+				}
+				marked[j] = true
+				packetMarked = true
+			}
+		}
+		if !packetMarked {
+			continue
+		}
+		for j, shareVal := range shareData {
+			claimed = append(claimed, claimedShare{packet: i,
+				shareVal: shareVal, marked: marked[j]})
+		}
+	}
+	return claimed, marker, nil
+}
+
+// Gives the shares of the sets which recovered the subsecret
+func verifiedShares(f shamir.Field, absoluteThreshold int,
+	usedShares [][]shamir.PriShare,
+	subsecret shamir.PriShare) ([]shamir.PriShare, error) {
+	var verified []shamir.PriShare
+	for _, usedShareSet := range usedShares {
+		if len(usedShareSet) < absoluteThreshold {
+			continue
+		}
+		recovered, err := f.CombineUniqueX(usedShareSet[:absoluteThreshold])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
+		}
+		if !crypto_protocols.CompareUint16s(recovered, subsecret.Y) {
+			continue
+		}
+		for _, shareVal := range usedShareSet {
+			if !crypto_protocols.CheckShareAlreadyUsedBinExt(verified, shareVal) {
+				verified = append(verified, shareVal)
+			}
+		}
+	}
+	return verified, nil
+}
+
+// Gives the marked shares which are not on the polynomial of the subsecret
+// The shares are decoded together with the verified ones as soon as there
+// are more points than the threshold, and the decoded subsecret has to open
+// the marker
+// Otherwise, or if the decoding fails, every marked share is combined with
+// the threshold - 1 verified shares
+func inconsistentClaims(f shamir.Field, absoluteThreshold int,
+	subsecret shamir.PriShare, marker subsecretMarker, claimed []claimedShare,
+	verified []shamir.PriShare) ([]claimedShare, error) {
+	var wrong []claimedShare
+	points := append([]shamir.PriShare(nil), verified...)
+	claimIndices := make(map[int]int)
+	seenX := make(map[uint16]bool)
+	for _, shareVal := range verified {
+		seenX[shareVal.X] = true
+	}
+	for i, claim := range claimed {
+		if crypto_protocols.CheckShareAlreadyUsedBinExt(points, claim.shareVal) {
+			continue
+		}
+		if !claim.marked {
+			// The share may be of another subsecret, so it is only used
+			// once it is found on the polynomial
+			if seenX[claim.shareVal.X] {
+				continue
+			}
+			onPolynomial, err := onSubsecretPolynomial(f, absoluteThreshold,
+				subsecret, verified, claim.shareVal)
+			if err != nil {
+				return nil, err
+			}
+			if onPolynomial {
+				seenX[claim.shareVal.X] = true
+				points = append(points, claim.shareVal)
+			}
+			continue
+		}
+		// Another share with the same x-coordinate is already on the
+		// polynomial
+		if seenX[claim.shareVal.X] {
+			wrong = append(wrong, claim)
+			continue
+		}
+		seenX[claim.shareVal.X] = true
+		claimIndices[len(points)] = i
+		points = append(points, claim.shareVal)
+	}
+	if len(claimIndices) == 0 {
+		return wrong, nil
+	}
+
+	if len(points) > absoluteThreshold {
+		decoded, wrongIndices, err := f.DecodeUniqueX(points, absoluteThreshold)
+		if err == nil {
+			opened, err := marker.opens(decoded, subsecret.X)
+			if err != nil {
+				return nil, err
+			}
+			if opened {
+				for _, index := range wrongIndices {
+					if claimIndex, ok := claimIndices[index]; ok {
+						wrong = append(wrong, claimed[claimIndex])
+					}
+				}
+				return wrong, nil
+			}
+		}
+	}
+	for index := len(verified); index < len(points); index++ {
+		claimIndex, ok := claimIndices[index]
+		if !ok {
+			continue
+		}
+		onPolynomial, err := onSubsecretPolynomial(f, absoluteThreshold,
+			subsecret, verified, points[index])
+		if err != nil {
+			return nil, err
+		}
+		if len(verified) >= absoluteThreshold-1 && !onPolynomial {
+			wrong = append(wrong, claimed[claimIndex])
+		}
+	}
+	return wrong, nil
+}
+
+// Tells whether the share gives the subsecret together with the threshold -
+// 1 verified shares
+// Without enough verified shares nothing is found on the polynomial
+func onSubsecretPolynomial(f shamir.Field, absoluteThreshold int,
+	subsecret shamir.PriShare, verified []shamir.PriShare,
+	shareVal shamir.PriShare) (bool, error) {
+	if len(verified) < absoluteThreshold-1 {
+		return false, nil
+	}
+	relevantShares := make([]shamir.PriShare, absoluteThreshold)
+	copy(relevantShares, verified[:absoluteThreshold-1])
+	relevantShares[absoluteThreshold-1] = shareVal
+	recovered, err := f.CombineUniqueX(relevantShares)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errors.ErrInvalidShareSet, err)
+	}
+	return crypto_protocols.CompareUint16s(recovered, subsecret.Y), nil
+}
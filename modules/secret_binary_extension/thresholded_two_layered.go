@@ -3,10 +3,10 @@ package secret_binary_extension
 import (
 	"encoding/binary"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 
-	"crypto/rand"
 	"key_recovery/modules/errors"
 	"log"
 )
@@ -23,6 +23,9 @@ type ThresholdedPacket struct {
 	Nonce               [32]byte            // includes the list of salts used for each share
 	RelevantEncryptions [][][]byte          // includes the list of h(salt || parent secret)
 	ShareData           [][]shamir.PriShare // share data (for now only one share)
+	// Decoded from a format version before 2, whose marker keys are not
+	// derived with the role labels
+	Legacy bool
 }
 
 // This function generates thresholded shares of the secret
@@ -108,23 +111,37 @@ func GetThresholdedSharePackets(f shamir.Field, secretKey [][]uint16,
 	leavesData [][]shamir.PriShare, subsecrets [][]shamir.PriShare,
 	parentSubsecrets map[int]map[uint16]shamir.PriShare,
 	xUsedCoords *[]uint16) ([]ThresholdedPacket, int, int, error) {
+	return GetThresholdedSharePacketsWeighted(f, secretKey, trustees,
+		absoluteThreshold, leavesData, subsecrets, parentSubsecrets,
+		xUsedCoords, nil)
+}
+
+// The trustees receive the leaves in proportion to their weights
+// Without weights, the leaves are distributed almost uniformly
+func GetThresholdedSharePacketsWeighted(f shamir.Field, secretKey [][]uint16,
+	trustees, absoluteThreshold int,
+	leavesData [][]shamir.PriShare, subsecrets [][]shamir.PriShare,
+	parentSubsecrets map[int]map[uint16]shamir.PriShare,
+	xUsedCoords *[]uint16, weights []int) ([]ThresholdedPacket, int, int, error) {
 	if absoluteThreshold > trustees {
 		return nil, -1, -1, errors.ErrInvalidThreshold
 	}
 	var sharePackets []ThresholdedPacket
 	encryptionLength := 0
 	totalShares := len(leavesData[0])
-	sharesPerPerson := totalShares / trustees
+	rng := randomness.NewRand()
 	// Get how many shares each person should get
-	personWiseShareDistribution, maxSharesPerPerson :=
-		utils.GetPersonWiseShareNumber(trustees,
-			totalShares, sharesPerPerson)
+	personWiseShareDistribution, maxSharesPerPerson, err :=
+		GetPersonWiseShareDistribution(trustees, totalShares, weights, rng)
+	if err != nil {
+		return nil, -1, -1, err
+	}
 	allLeavesIndices := make([][]int, 0)
 	for i := 0; i < len(secretKey); i++ {
 		// Indices of the leaves
 		leavesIndices := utils.GenerateIndicesSet(totalShares)
 		// Randomize the leaves that the trustees should receive
-		utils.Shuffle(leavesIndices)
+		utils.Shuffle(leavesIndices, rng)
 		allLeavesIndices = append(allLeavesIndices, leavesIndices)
 	}
 	currentIndices := make([]int, len(secretKey))
@@ -164,7 +181,8 @@ func GenerateThresholdedPerPersonSharePackets(noOfSharesReceived int,
 		(*thPacket).RelevantEncryptions = append((*thPacket).RelevantEncryptions, [][]byte{})
 		(*thPacket).ShareData = append((*thPacket).ShareData, []shamir.PriShare{})
 		tempSecret := shamir.PriShare{X: uint16(0), Y: keyPart}
-		noncedEncSecretKey, el, err := crypto_protocols.GetRelevantEncryptionBinExt((*thPacket).Nonce, tempSecret)
+		noncedEncSecretKey, el, err := crypto_protocols.GetRelevantEncryptionBinExt((*thPacket).Nonce, tempSecret,
+			crypto_protocols.LabelFinalSecretCheck)
 		if err != nil {
 			return -1, err
 		}
@@ -176,7 +194,8 @@ func GenerateThresholdedPerPersonSharePackets(noOfSharesReceived int,
 		for j := 0; j < noOfSharesReceived; j++ {
 			leafShareVal := leavesData[ind][allLeavesIndices[ind][(*currentIndices)[ind]]]
 			parentSubsecret := parentSubsecrets[ind][leafShareVal.X]
-			noncedEncryption, _, err := crypto_protocols.GetRelevantEncryptionBinExt((*thPacket).Nonce, parentSubsecret)
+			noncedEncryption, _, err := crypto_protocols.GetRelevantEncryptionBinExt((*thPacket).Nonce, parentSubsecret,
+				crypto_protocols.LabelLeafCheck)
 			if err != nil {
 				log.Fatal(err)
 				return -1, err
@@ -216,7 +235,7 @@ func GenerateThresholdedRandomPackets(noOfPackets, relevantSize,
 			(*thPacket).RelevantEncryptions = append((*thPacket).RelevantEncryptions, [][]byte{})
 		}
 		for j := 0; j < (noOfPackets); {
-			if _, err := rand.Read(bufX); err != nil {
+			if _, err := randomness.Read(bufX); err != nil {
 				log.Fatalln(err)
 			}
 			x := binary.BigEndian.Uint16(bufX)
@@ -231,7 +250,7 @@ func GenerateThresholdedRandomPackets(noOfPackets, relevantSize,
 			if exists {
 				continue
 			}
-			if _, err := rand.Read(bufY); err != nil {
+			if _, err := randomness.Read(bufY); err != nil {
 				log.Fatalln(err)
 			}
 			y := shamir.BytesToUint16s(bufY)
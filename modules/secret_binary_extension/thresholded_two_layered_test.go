@@ -1,11 +1,13 @@
 package secret_binary_extension
 
 import (
+	"context"
 
 	// randm "math/rand"
 
 	"fmt"
 	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/shamir"
 	"key_recovery/modules/utils"
 	"log"
@@ -151,7 +153,8 @@ func TestGetThresholdedSharePackets(t *testing.T) {
 								continue
 							}
 							parentSubsecret := parentSubsecrets[ind1][tempShare.X]
-							match1, correctX, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(parentSubsecret.Y, relevantNonce, relevantEncryptions)
+							match1, correctX, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(parentSubsecret.Y, relevantNonce, relevantEncryptions,
+								crypto_protocols.LabelLeafCheck, false)
 							if err != nil {
 								t.Error(err)
 							}
@@ -162,7 +165,8 @@ func TestGetThresholdedSharePackets(t *testing.T) {
 								t.Error("No encryption found")
 							}
 
-							match2, correctX2, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(secretKey[ind1], relevantNonce, relevantEncryptions)
+							match2, correctX2, _, err := crypto_protocols.GetThresholdedIndisShareMatchBinExt(secretKey[ind1], relevantNonce, relevantEncryptions,
+								crypto_protocols.LabelFinalSecretCheck, false)
 							if err != nil {
 								t.Error(err)
 							}
@@ -307,10 +311,13 @@ func TestThresholdedOptUsedIndisSecretRecovery(t *testing.T) {
 						t.Error(err)
 					} else {
 						accessOrder := utils.GenerateIndicesSet(tc.a)
-						utils.Shuffle(accessOrder)
-						recoveredKey := ThOptUsedIndisSecretRecoveryParallelized(f,
-							anonymityPackets, accessOrder,
-							tc.absoluteThreshold)
+						utils.Shuffle(accessOrder, randomness.NewRand())
+						recoveredKey, err := ThOptUsedIndisSecretRecoveryParallelized(context.Background(),
+							f, anonymityPackets, accessOrder,
+							tc.absoluteThreshold, 0)
+						if err != nil {
+							t.Fatal(err)
+						}
 						recoveredSecretKey := shamir.AESKeyUint16sToKeyBytes(recoveredKey)
 						if !crypto_protocols.CheckByteArrayEqual(secretKey8, recoveredSecretKey) {
 							t.Error("wrong recovery")
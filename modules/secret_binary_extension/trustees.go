@@ -0,0 +1,674 @@
+package secret_binary_extension
+
+import (
+	"encoding/binary"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/errors"
+	"key_recovery/modules/randomness"
+	"key_recovery/modules/shamir"
+	"key_recovery/modules/utils"
+	"sort"
+)
+
+// **************************************************************************
+// ******************Changes to the set of trustees**************************
+// **************************************************************************
+
+// The dealers keep what is needed for changing the set of trustees after the
+// packets have been distributed, so the generation does not have to be run
+// again with a new n
+// The packets are kept in their slots of the anonymity set
+// - Adding a trustee issues new leaves on the polynomials of the existing
+// subsecrets and puts the packet of the trustee in place of a random packet
+// (the anonymity set grows if there is none left)
+// - Removing a trustee splits the subsecrets it held leaves of into new
+// leaves for the other holders, so the leaves of the removed trustee are
+// useless, and puts a random packet in its slot
+// - Replacing a trustee does the same, but the new trustee gets as many new
+// leaves as the removed one had
+// Every operation returns the slots whose packets changed, and only those
+// packets have to be sent out again
+// All the packets still hold maxSharesPerPerson shares of every part, so the
+// changed packets cannot be told apart from the random ones
+
+// Leaves held in every slot of the anonymity set along with the subsecret of
+// every leaf
+// Part 0 is the only part for the additive scheme
+type trusteeSet struct {
+	f                  shamir.Field
+	absoluteThreshold  int
+	maxSharesPerPerson int
+	subsecretValues    [][][]uint16 // value of every subsecret of every part
+	// Index of the subsecret of every leaf of every part
+	leafParents []map[uint16]int
+	// Leaves of every part held by the trustee in the slot
+	packetLeaves map[int][][]shamir.PriShare
+}
+
+func newTrusteeSet(f shamir.Field, absoluteThreshold, maxSharesPerPerson int,
+	subsecretValues [][][]uint16, leafParents []map[uint16]int,
+	trusteeShares [][][]shamir.PriShare) *trusteeSet {
+	f.InitializeTables()
+	s := &trusteeSet{f: f, absoluteThreshold: absoluteThreshold,
+		maxSharesPerPerson: maxSharesPerPerson,
+		subsecretValues:    subsecretValues, leafParents: leafParents,
+		packetLeaves: make(map[int][][]shamir.PriShare)}
+	for slot, shareData := range trusteeShares {
+		leaves := make([][]shamir.PriShare, len(shareData))
+		for ind, shares := range shareData {
+			for _, shareVal := range shares {
+				// The other shares are the random padding
+				if _, ok := leafParents[ind][shareVal.X]; ok {
+					leaves[ind] = append(leaves[ind], shareVal)
+				}
+			}
+		}
+		s.packetLeaves[slot] = leaves
+	}
+	return s
+}
+
+// TrusteeIndices gives the slots of the anonymity set holding the packets of
+// the trustees
+func (s *trusteeSet) TrusteeIndices() []int {
+	slots := make([]int, 0, len(s.packetLeaves))
+	for slot := range s.packetLeaves {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	return slots
+}
+
+// Gives the first slot which does not hold the packet of a trustee
+func (s *trusteeSet) freeSlot(anonymitySetSize int) int {
+	for slot := 0; slot < anonymitySetSize; slot++ {
+		if _, ok := s.packetLeaves[slot]; !ok {
+			return slot
+		}
+	}
+	return anonymitySetSize
+}
+
+// Gives the leaves of the subsecret of the part held by the trustees
+func (s *trusteeSet) subsecretLeaves(ind, subsecretIndex int) []shamir.PriShare {
+	var leaves []shamir.PriShare
+	for _, slot := range s.TrusteeIndices() {
+		for _, leaf := range s.packetLeaves[slot][ind] {
+			if s.leafParents[ind][leaf.X] == subsecretIndex {
+				leaves = append(leaves, leaf)
+			}
+		}
+	}
+	return leaves
+}
+
+// Gives the new trustee noOfLeaves leaves of every part
+// The leaves are issued for the subsecrets with the fewest leaves, on the
+// same polynomials as the existing leaves
+func (s *trusteeSet) issueLeaves(slot, noOfLeaves int,
+	xUsedCoords *[]uint16) error {
+	if noOfLeaves < 1 || noOfLeaves > s.maxSharesPerPerson {
+		return errors.ErrInvalidInput
+	}
+	leaves := make([][]shamir.PriShare, len(s.subsecretValues))
+	for ind := range s.subsecretValues {
+		counts := make([]int, len(s.subsecretValues[ind]))
+		for _, subsecretIndex := range s.leafParents[ind] {
+			counts[subsecretIndex]++
+		}
+		for j := 0; j < noOfLeaves; j++ {
+			subsecretIndex := 0
+			for i, count := range counts {
+				if count < counts[subsecretIndex] {
+					subsecretIndex = i
+				}
+			}
+			existing := s.subsecretLeaves(ind, subsecretIndex)
+			if len(existing) < s.absoluteThreshold {
+				return errors.ErrInvalidThreshold
+			}
+			x, err := freshXCoordinate(xUsedCoords)
+			if err != nil {
+				return err
+			}
+			y, err := s.f.EvaluateUniqueX(existing[:s.absoluteThreshold], x)
+			if err != nil {
+				return err
+			}
+			leaves[ind] = append(leaves[ind], shamir.PriShare{X: x, Y: y})
+			s.leafParents[ind][x] = subsecretIndex
+			counts[subsecretIndex]++
+		}
+	}
+	s.packetLeaves[slot] = leaves
+	return nil
+}
+
+// Splits the subsecrets with leaves in the slot into new leaves for the other
+// holders
+// With keep set, the slot gets as many new leaves as it held, otherwise it
+// no longer holds the packet of a trustee
+// Gives the other slots whose leaves changed
+func (s *trusteeSet) reshare(slot int, keep bool,
+	xUsedCoords *[]uint16) ([]int, error) {
+	slotLeaves, ok := s.packetLeaves[slot]
+	if !ok {
+		return nil, errors.ErrInvalidInput
+	}
+	slots := s.TrusteeIndices()
+	// Number of new leaves of every affected subsecret of every part
+	newLeavesNumbers := make([]map[int]int, len(s.subsecretValues))
+	for ind := range s.subsecretValues {
+		newLeavesNumbers[ind] = make(map[int]int)
+		for _, leaf := range slotLeaves[ind] {
+			newLeavesNumbers[ind][s.leafParents[ind][leaf.X]] = 0
+		}
+		for _, holder := range slots {
+			if holder == slot && !keep {
+				continue
+			}
+			for _, leaf := range s.packetLeaves[holder][ind] {
+				subsecretIndex := s.leafParents[ind][leaf.X]
+				if _, affected := newLeavesNumbers[ind][subsecretIndex]; affected {
+					newLeavesNumbers[ind][subsecretIndex]++
+				}
+			}
+		}
+		// The subsecrets must still be recoverable from the leaves
+		for _, number := range newLeavesNumbers[ind] {
+			if number < s.absoluteThreshold {
+				return nil, errors.ErrInvalidThreshold
+			}
+		}
+	}
+
+	changed := make(map[int]bool)
+	for ind := range s.subsecretValues {
+		// Go through the subsecrets in a fixed order so that the same x
+		// coordinates are not picked in a different order
+		affected := make([]int, 0, len(newLeavesNumbers[ind]))
+		for subsecretIndex := range newLeavesNumbers[ind] {
+			affected = append(affected, subsecretIndex)
+		}
+		sort.Ints(affected)
+		for _, subsecretIndex := range affected {
+			newLeaves, err := GenerateRandomXShares(s.f, s.absoluteThreshold,
+				newLeavesNumbers[ind][subsecretIndex],
+				s.subsecretValues[ind][subsecretIndex], xUsedCoords)
+			if err != nil {
+				return nil, err
+			}
+			next := 0
+			for _, holder := range slots {
+				leaves := s.packetLeaves[holder][ind]
+				for j, leaf := range leaves {
+					if s.leafParents[ind][leaf.X] != subsecretIndex {
+						continue
+					}
+					delete(s.leafParents[ind], leaf.X)
+					if holder == slot && !keep {
+						continue
+					}
+					leaves[j] = newLeaves[next]
+					s.leafParents[ind][newLeaves[next].X] = subsecretIndex
+					next++
+					if holder != slot {
+						changed[holder] = true
+					}
+				}
+			}
+		}
+	}
+	if !keep {
+		delete(s.packetLeaves, slot)
+	}
+	changedSlots := make([]int, 0, len(changed))
+	for holder := range changed {
+		changedSlots = append(changedSlots, holder)
+	}
+	sort.Ints(changedSlots)
+	return changedSlots, nil
+}
+
+// Picks a random x-coordinate which has not been used yet
+func freshXCoordinate(xUsedCoords *[]uint16) (uint16, error) {
+	buf := make([]byte, 2)
+	for {
+		if _, err := randomness.Read(buf); err != nil {
+			return 0, err
+		}
+		x := binary.BigEndian.Uint16(buf)
+		if x != 0 && !utils.IsInSliceUint16(*xUsedCoords, x) {
+			*xUsedCoords = append(*xUsedCoords, x)
+			return x, nil
+		}
+	}
+}
+
+// Shuffled order of the leaves of every part of a packet
+func shuffledLeavesIndices(leaves [][]shamir.PriShare) [][]int {
+	rng := randomness.NewRand()
+	allLeavesIndices := make([][]int, len(leaves))
+	for ind := range leaves {
+		allLeavesIndices[ind] = utils.GenerateIndicesSet(len(leaves[ind]))
+		utils.Shuffle(allLeavesIndices[ind], rng)
+	}
+	return allLeavesIndices
+}
+
+// Gives the slots in the order in which they are generated again
+func changedSlots(slot int, others []int) []int {
+	slots := append([]int{slot}, others...)
+	sort.Ints(slots)
+	return slots
+}
+
+// ****************************************************************************
+// Additive
+
+type AdditiveDealer struct {
+	*trusteeSet
+	secretKey        []uint16
+	xUsedCoords      []uint16
+	verifier         crypto_protocols.VerifierParams
+	AnonymityPackets []AdditivePacket
+}
+
+// NewAdditiveDealer takes the output of the generation of the additive scheme
+// The first trustees packets of the anonymity set are the ones of the
+// trustees, as given by GetAdditiveAnonymityPackets
+func NewAdditiveDealer(f shamir.Field, secretKey []uint16,
+	absoluteThreshold int, subsecrets [][]uint16,
+	parentSubsecrets map[uint16][]uint16, xUsedCoords []uint16,
+	anonymityPackets []AdditivePacket, trustees int) (*AdditiveDealer, error) {
+	if trustees > len(anonymityPackets) || len(anonymityPackets) == 0 {
+		return nil, errors.ErrInvalidInput
+	}
+	// The new packets use the same verifier of the secret key
+	verifier := anonymityPackets[0].Verifier
+	if err := verifier.Check(); err != nil {
+		return nil, err
+	}
+	leafParents := []map[uint16]int{make(map[uint16]int)}
+	for x, parent := range parentSubsecrets {
+		subsecretIndex := uint16sIndex(subsecrets, parent)
+		if subsecretIndex < 0 {
+			return nil, errors.ErrInvalidInput
+		}
+		leafParents[0][x] = subsecretIndex
+	}
+	trusteeShares := make([][][]shamir.PriShare, trustees)
+	for i := range trusteeShares {
+		trusteeShares[i] = [][]shamir.PriShare{anonymityPackets[i].ShareData}
+	}
+	return &AdditiveDealer{
+		trusteeSet: newTrusteeSet(f, absoluteThreshold,
+			len(anonymityPackets[0].ShareData), [][][]uint16{subsecrets},
+			leafParents, trusteeShares),
+		secretKey:        secretKey,
+		xUsedCoords:      append([]uint16(nil), xUsedCoords...),
+		verifier:         verifier,
+		AnonymityPackets: append([]AdditivePacket(nil), anonymityPackets...),
+	}, nil
+}
+
+// AddTrustee gives noOfLeaves leaves to a new trustee
+// It returns the slot of the new trustee
+func (d *AdditiveDealer) AddTrustee(noOfLeaves int) ([]int, error) {
+	slot := d.freeSlot(len(d.AnonymityPackets))
+	if err := d.issueLeaves(slot, noOfLeaves, &d.xUsedCoords); err != nil {
+		return nil, err
+	}
+	if err := d.generatePacket(slot); err != nil {
+		return nil, err
+	}
+	return []int{slot}, nil
+}
+
+// RemoveTrustee revokes the leaves of the trustee in the slot
+func (d *AdditiveDealer) RemoveTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, false)
+}
+
+// ReplaceTrustee gives the slot to a new trustee
+func (d *AdditiveDealer) ReplaceTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, true)
+}
+
+func (d *AdditiveDealer) changeTrustee(slot int, keep bool) ([]int, error) {
+	others, err := d.reshare(slot, keep, &d.xUsedCoords)
+	if err != nil {
+		return nil, err
+	}
+	slots := changedSlots(slot, others)
+	for _, changed := range slots {
+		if err := d.generatePacket(changed); err != nil {
+			return nil, err
+		}
+	}
+	return slots, nil
+}
+
+// Generates the packet of the slot again with a new salt
+func (d *AdditiveDealer) generatePacket(slot int) error {
+	var addPacket AdditivePacket
+	leaves, ok := d.packetLeaves[slot]
+	if !ok {
+		randomPackets, err := GetAdditiveAnonymityPackets(nil, 1,
+			d.maxSharesPerPerson, len(d.secretKey), &d.xUsedCoords)
+		if err != nil {
+			return err
+		}
+		addPacket = randomPackets[0]
+	} else {
+		salt, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return err
+		}
+		addPacket.Salt = salt
+		parentSubsecrets := make(map[uint16][]uint16)
+		for x, subsecretIndex := range d.leafParents[0] {
+			parentSubsecrets[x] = d.subsecretValues[0][subsecretIndex]
+		}
+		currentIndex := 0
+		GenerateAdditivePerPersonSharePackets(len(leaves[0]),
+			shuffledLeavesIndices(leaves)[0], leaves[0], &currentIndex,
+			d.secretKey, parentSubsecrets, &addPacket)
+		if len(leaves[0]) < d.maxSharesPerPerson {
+			GenerateAdditiveRandomPackets(d.maxSharesPerPerson-len(leaves[0]),
+				len(d.secretKey), &addPacket, &d.xUsedCoords)
+		}
+	}
+	if d.verifier.Hardened() {
+		packets := []AdditivePacket{addPacket}
+		err := HardenAdditivePackets(packets, d.secretKey, d.verifier)
+		if err != nil {
+			return err
+		}
+		addPacket = packets[0]
+	}
+	if slot == len(d.AnonymityPackets) {
+		d.AnonymityPackets = append(d.AnonymityPackets, addPacket)
+	} else {
+		d.AnonymityPackets[slot] = addPacket
+	}
+	return nil
+}
+
+// ****************************************************************************
+// Thresholded
+
+type ThresholdedDealer struct {
+	*trusteeSet
+	secretKey        [][]uint16
+	subsecrets       [][]shamir.PriShare
+	xUsedCoords      []uint16
+	encryptionLength int
+	AnonymityPackets []ThresholdedPacket
+}
+
+// NewThresholdedDealer takes the output of the generation of the
+// thresholded scheme
+func NewThresholdedDealer(f shamir.Field, secretKey [][]uint16,
+	absoluteThreshold int, subsecrets [][]shamir.PriShare,
+	parentSubsecrets map[int]map[uint16]shamir.PriShare,
+	xUsedCoords []uint16, anonymityPackets []ThresholdedPacket,
+	trustees int) (*ThresholdedDealer, error) {
+	if trustees > len(anonymityPackets) || len(anonymityPackets) == 0 ||
+		len(subsecrets) != len(secretKey) {
+		return nil, errors.ErrInvalidInput
+	}
+	subsecretValues := make([][][]uint16, len(subsecrets))
+	leafParents := make([]map[uint16]int, len(subsecrets))
+	for ind := range subsecrets {
+		subsecretIndices := make(map[uint16]int)
+		for i, subsecret := range subsecrets[ind] {
+			subsecretValues[ind] = append(subsecretValues[ind], subsecret.Y)
+			subsecretIndices[subsecret.X] = i
+		}
+		leafParents[ind] = make(map[uint16]int)
+		for x, parent := range parentSubsecrets[ind] {
+			subsecretIndex, ok := subsecretIndices[parent.X]
+			if !ok {
+				return nil, errors.ErrInvalidInput
+			}
+			leafParents[ind][x] = subsecretIndex
+		}
+	}
+	trusteeShares := make([][][]shamir.PriShare, trustees)
+	for i := range trusteeShares {
+		trusteeShares[i] = anonymityPackets[i].ShareData
+	}
+	return &ThresholdedDealer{
+		trusteeSet: newTrusteeSet(f, absoluteThreshold,
+			len(anonymityPackets[0].ShareData[0]), subsecretValues,
+			leafParents, trusteeShares),
+		secretKey:        secretKey,
+		subsecrets:       subsecrets,
+		xUsedCoords:      append([]uint16(nil), xUsedCoords...),
+		encryptionLength: len(anonymityPackets[0].RelevantEncryptions[0][0]),
+		AnonymityPackets: append([]ThresholdedPacket(nil), anonymityPackets...),
+	}, nil
+}
+
+// AddTrustee gives noOfLeaves leaves of every part to a new trustee
+// It returns the slot of the new trustee
+func (d *ThresholdedDealer) AddTrustee(noOfLeaves int) ([]int, error) {
+	slot := d.freeSlot(len(d.AnonymityPackets))
+	if err := d.issueLeaves(slot, noOfLeaves, &d.xUsedCoords); err != nil {
+		return nil, err
+	}
+	if err := d.generatePacket(slot); err != nil {
+		return nil, err
+	}
+	return []int{slot}, nil
+}
+
+// RemoveTrustee revokes the leaves of the trustee in the slot
+func (d *ThresholdedDealer) RemoveTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, false)
+}
+
+// ReplaceTrustee gives the slot to a new trustee
+func (d *ThresholdedDealer) ReplaceTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, true)
+}
+
+func (d *ThresholdedDealer) changeTrustee(slot int, keep bool) ([]int, error) {
+	others, err := d.reshare(slot, keep, &d.xUsedCoords)
+	if err != nil {
+		return nil, err
+	}
+	slots := changedSlots(slot, others)
+	for _, changed := range slots {
+		if err := d.generatePacket(changed); err != nil {
+			return nil, err
+		}
+	}
+	return slots, nil
+}
+
+// Generates the packet of the slot again with a new nonce
+func (d *ThresholdedDealer) generatePacket(slot int) error {
+	var thPacket ThresholdedPacket
+	leaves, ok := d.packetLeaves[slot]
+	if !ok {
+		randomPackets, err := GetThresholdedAnonymityPackets(nil, 1,
+			d.maxSharesPerPerson, len(d.secretKey[0]), len(d.secretKey),
+			&d.xUsedCoords, d.encryptionLength)
+		if err != nil {
+			return err
+		}
+		thPacket = randomPackets[0]
+	} else {
+		nonce, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return err
+		}
+		thPacket.Nonce = nonce
+		parentSubsecrets := make(map[int]map[uint16]shamir.PriShare)
+		for ind := range d.subsecrets {
+			parentSubsecrets[ind] = make(map[uint16]shamir.PriShare)
+			for x, subsecretIndex := range d.leafParents[ind] {
+				parentSubsecrets[ind][x] = d.subsecrets[ind][subsecretIndex]
+			}
+		}
+		// Every part has the same number of leaves
+		noOfSharesReceived := len(leaves[0])
+		currentIndices := make([]int, len(d.secretKey))
+		_, err = GenerateThresholdedPerPersonSharePackets(noOfSharesReceived,
+			shuffledLeavesIndices(leaves), leaves, &currentIndices, d.secretKey,
+			parentSubsecrets, &thPacket)
+		if err != nil {
+			return err
+		}
+		if noOfSharesReceived < d.maxSharesPerPerson {
+			GenerateThresholdedRandomPackets(
+				d.maxSharesPerPerson-noOfSharesReceived, len(d.secretKey[0]),
+				len(d.secretKey), &thPacket, &d.xUsedCoords, d.encryptionLength)
+		}
+	}
+	if slot == len(d.AnonymityPackets) {
+		d.AnonymityPackets = append(d.AnonymityPackets, thPacket)
+	} else {
+		d.AnonymityPackets[slot] = thPacket
+	}
+	return nil
+}
+
+// ****************************************************************************
+// Hinted
+
+// The hints point at the first noOfHints slots, so the hints of the other
+// trustees stay valid as long as these slots hold the packets of trustees
+type HintedTDealer struct {
+	*trusteeSet
+	secretKey        [][]uint16
+	xUsedCoords      []uint16
+	noOfHints        int
+	encryptionLength int
+	AnonymityPackets []HintedTPacket
+}
+
+// NewHintedTDealer takes the output of the generation of the hinted scheme
+func NewHintedTDealer(f shamir.Field, secretKey [][]uint16,
+	absoluteThreshold int, subsecrets [][][]uint16,
+	parentSubsecrets map[int]map[uint16][]uint16, xUsedCoords []uint16,
+	anonymityPackets []HintedTPacket, trustees, noOfHints int) (*HintedTDealer,
+	error) {
+	if trustees > len(anonymityPackets) || len(anonymityPackets) == 0 ||
+		len(subsecrets) != len(secretKey) || noOfHints < 1 {
+		return nil, errors.ErrInvalidInput
+	}
+	leafParents := make([]map[uint16]int, len(subsecrets))
+	for ind := range subsecrets {
+		leafParents[ind] = make(map[uint16]int)
+		for x, parent := range parentSubsecrets[ind] {
+			subsecretIndex := uint16sIndex(subsecrets[ind], parent)
+			if subsecretIndex < 0 {
+				return nil, errors.ErrInvalidInput
+			}
+			leafParents[ind][x] = subsecretIndex
+		}
+	}
+	trusteeShares := make([][][]shamir.PriShare, trustees)
+	for i := range trusteeShares {
+		trusteeShares[i] = anonymityPackets[i].ShareData
+	}
+	return &HintedTDealer{
+		trusteeSet: newTrusteeSet(f, absoluteThreshold,
+			len(anonymityPackets[0].ShareData[0]), subsecrets, leafParents,
+			trusteeShares),
+		secretKey:        secretKey,
+		xUsedCoords:      append([]uint16(nil), xUsedCoords...),
+		noOfHints:        noOfHints,
+		encryptionLength: len(anonymityPackets[0].RelevantEncryptions[0][0]),
+		AnonymityPackets: append([]HintedTPacket(nil), anonymityPackets...),
+	}, nil
+}
+
+// AddTrustee gives noOfLeaves leaves of every part to a new trustee
+// It returns the slot of the new trustee
+func (d *HintedTDealer) AddTrustee(noOfLeaves int) ([]int, error) {
+	slot := d.freeSlot(len(d.AnonymityPackets))
+	if err := d.issueLeaves(slot, noOfLeaves, &d.xUsedCoords); err != nil {
+		return nil, err
+	}
+	if err := d.generatePacket(slot); err != nil {
+		return nil, err
+	}
+	return []int{slot}, nil
+}
+
+// RemoveTrustee revokes the leaves of the trustee in the slot
+// The hints pointing at the slot lead to a random packet afterwards
+func (d *HintedTDealer) RemoveTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, false)
+}
+
+// ReplaceTrustee gives the slot to a new trustee
+func (d *HintedTDealer) ReplaceTrustee(slot int) ([]int, error) {
+	return d.changeTrustee(slot, true)
+}
+
+func (d *HintedTDealer) changeTrustee(slot int, keep bool) ([]int, error) {
+	others, err := d.reshare(slot, keep, &d.xUsedCoords)
+	if err != nil {
+		return nil, err
+	}
+	slots := changedSlots(slot, others)
+	for _, changed := range slots {
+		if err := d.generatePacket(changed); err != nil {
+			return nil, err
+		}
+	}
+	return slots, nil
+}
+
+// Generates the packet of the slot again with a new nonce and a new hint
+func (d *HintedTDealer) generatePacket(slot int) error {
+	var hPacket HintedTPacket
+	leaves, ok := d.packetLeaves[slot]
+	if !ok {
+		randomPackets, err := GetHintedTAnonymityPackets(nil, 1,
+			d.maxSharesPerPerson, len(d.secretKey[0]), len(d.secretKey),
+			&d.xUsedCoords, d.encryptionLength)
+		if err != nil {
+			return err
+		}
+		hPacket = randomPackets[0]
+	} else {
+		nonce, err := crypto_protocols.GenerateSalt32()
+		if err != nil {
+			return err
+		}
+		hPacket.Nonce = nonce
+		parentSubsecrets := make(map[int]map[uint16][]uint16)
+		for ind := range d.subsecretValues {
+			parentSubsecrets[ind] = make(map[uint16][]uint16)
+			for x, subsecretIndex := range d.leafParents[ind] {
+				parentSubsecrets[ind][x] = d.subsecretValues[ind][subsecretIndex]
+			}
+		}
+		noOfSharesReceived := len(leaves[0])
+		currentIndices := make([]int, len(d.secretKey))
+		_, err = GenerateHintedTPerPersonSharePackets(noOfSharesReceived,
+			shuffledLeavesIndices(leaves), leaves, &currentIndices, d.secretKey,
+			parentSubsecrets, &hPacket, make([]uint16, d.noOfHints),
+			uint16(slot))
+		if err != nil {
+			return err
+		}
+		if noOfSharesReceived < d.maxSharesPerPerson {
+			GenerateHintedTRandomPackets(
+				d.maxSharesPerPerson-noOfSharesReceived, len(d.secretKey[0]),
+				len(d.secretKey), &hPacket, &d.xUsedCoords, d.encryptionLength)
+		}
+	}
+	if slot == len(d.AnonymityPackets) {
+		d.AnonymityPackets = append(d.AnonymityPackets, hPacket)
+	} else {
+		d.AnonymityPackets[slot] = hPacket
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package secret_binary_extension
+
+import (
+	"bytes"
+	"context"
+	crypto_protocols "key_recovery/modules/crypto"
+	"key_recovery/modules/shamir"
+	"testing"
+)
+
+// Checks that an old leaf does not lie on the polynomial of its subsecret
+// any more
+func checkRevokedLeaves(t *testing.T, s *trusteeSet, ind int,
+	oldLeaves []shamir.PriShare, oldParents map[uint16]int) {
+	for _, oldLeaf := range oldLeaves {
+		subsecretIndex := oldParents[oldLeaf.X]
+		leaves := s.subsecretLeaves(ind, subsecretIndex)
+		relevantShares := append([]shamir.PriShare{oldLeaf},
+			leaves[:s.absoluteThreshold-1]...)
+		recovered, err := s.f.CombineUniqueX(relevantShares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if crypto_protocols.CompareUint16s(recovered,
+			s.subsecretValues[ind][subsecretIndex]) {
+			t.Error("Leaf of the removed trustee still valid")
+		}
+	}
+}
+
+func TestAdditiveDealer(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold := 5, 8, 3
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateAdditiveTwoLayeredOptIndisShares(f, n, secretKey,
+			absoluteThreshold, 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, _ := GetAdditiveSharePackets(f,
+		secretKey, n, absoluteThreshold, leavesData, subsecrets,
+		parentSubsecrets, &xUsedCoords)
+	packets, _ := GetAdditiveAnonymityPackets(sharePackets, anonymitySetSize,
+		maxSharesPerPerson, len(secretKey), &xUsedCoords)
+	dealer, err := NewAdditiveDealer(f, secretKey, absoluteThreshold,
+		subsecrets, parentSubsecrets, xUsedCoords, packets, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRecovery := func(operation string, changed []int,
+		before []AdditivePacket) {
+		if len(dealer.AnonymityPackets) != anonymitySetSize {
+			t.Error("Size of the anonymity set changed", operation)
+		}
+		changedSet := make(map[int]bool)
+		for _, slot := range changed {
+			changedSet[slot] = true
+		}
+		recoverer := NewAdditiveRecoverer(f, absoluteThreshold)
+		for i, packet := range dealer.AnonymityPackets {
+			if len(packet.ShareData) != maxSharesPerPerson ||
+				len(packet.RelevantHashes) != maxSharesPerPerson+1 {
+				t.Error("Packets of different sizes", operation)
+			}
+			if (packet.Salt != before[i].Salt) != changedSet[i] {
+				t.Error("Changed packets not reported", operation, i)
+			}
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered", operation, err)
+		}
+	}
+
+	before := append([]AdditivePacket(nil), dealer.AnonymityPackets...)
+	changed, err := dealer.AddTrustee(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != n ||
+		len(dealer.TrusteeIndices()) != n+1 {
+		t.Error("Trustee not added to the first random slot", changed)
+	}
+	checkRecovery("add", changed, before)
+
+	oldLeaves := dealer.packetLeaves[0][0]
+	oldParents := make(map[uint16]int)
+	for _, leaf := range oldLeaves {
+		oldParents[leaf.X] = dealer.leafParents[0][leaf.X]
+	}
+	before = append([]AdditivePacket(nil), dealer.AnonymityPackets...)
+	changed, err = dealer.RemoveTrustee(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed[0] != 0 || len(dealer.TrusteeIndices()) != n {
+		t.Error("Trustee not removed", changed)
+	}
+	checkRevokedLeaves(t, dealer.trusteeSet, 0, oldLeaves, oldParents)
+	checkRecovery("remove", changed, before)
+
+	before = append([]AdditivePacket(nil), dealer.AnonymityPackets...)
+	changed, err = dealer.ReplaceTrustee(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dealer.TrusteeIndices()) != n ||
+		len(dealer.packetLeaves[1][0]) == 0 {
+		t.Error("Trustee not replaced", changed)
+	}
+	checkRecovery("replace", changed, before)
+
+	if _, err := dealer.RemoveTrustee(0); err == nil {
+		t.Error("Random packet removed as a trustee")
+	}
+}
+
+func TestThresholdedDealer(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToAESKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold, noOfSubsecrets := 5, 8, 3, 3
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateThresholdedTwoLayeredOptIndisShares(f, n, secretKey,
+			absoluteThreshold, noOfSubsecrets, 30, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, encryptionLength, err :=
+		GetThresholdedSharePackets(f, secretKey, n, absoluteThreshold,
+			leavesData, subsecrets, parentSubsecrets, &xUsedCoords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, _ := GetThresholdedAnonymityPackets(sharePackets,
+		anonymitySetSize, maxSharesPerPerson, len(secretKey[0]),
+		len(secretKey), &xUsedCoords, encryptionLength)
+	dealer, err := NewThresholdedDealer(f, secretKey, absoluteThreshold,
+		subsecrets, parentSubsecrets, xUsedCoords, packets, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRecovery := func(operation string) {
+		recoverer := NewThresholdedRecoverer(f, absoluteThreshold)
+		for _, packet := range dealer.AnonymityPackets {
+			for ind := range packet.ShareData {
+				if len(packet.ShareData[ind]) != maxSharesPerPerson ||
+					len(packet.RelevantEncryptions[ind]) != maxSharesPerPerson+1 {
+					t.Error("Packets of different sizes", operation)
+				}
+			}
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered", operation, err)
+		}
+	}
+
+	if _, err := dealer.AddTrustee(maxSharesPerPerson); err != nil {
+		t.Fatal(err)
+	}
+	checkRecovery("add")
+	oldLeaves := dealer.packetLeaves[2][1]
+	oldParents := make(map[uint16]int)
+	for _, leaf := range oldLeaves {
+		oldParents[leaf.X] = dealer.leafParents[1][leaf.X]
+	}
+	if _, err := dealer.RemoveTrustee(2); err != nil {
+		t.Fatal(err)
+	}
+	checkRevokedLeaves(t, dealer.trusteeSet, 1, oldLeaves, oldParents)
+	checkRecovery("remove")
+	if _, err := dealer.ReplaceTrustee(0); err != nil {
+		t.Fatal(err)
+	}
+	checkRecovery("replace")
+}
+
+func TestHintedTDealer(t *testing.T) {
+	var f shamir.Field
+	f.InitializeTables()
+	secretKey8 := []byte("testasdfghjklqwertyu")
+	secretKey := shamir.KeyBytesToAESKeyUint16s(secretKey8)
+	n, anonymitySetSize, absoluteThreshold, noOfHints := 5, 8, 3, 2
+	subsecrets, leavesData, parentSubsecrets, xUsedCoords, err :=
+		GenerateHintedTTwoLayeredOptIndisShares(f, n, secretKey,
+			absoluteThreshold, 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePackets, maxSharesPerPerson, encryptionLength, err :=
+		GetHintedTSharePackets(f, secretKey, n, absoluteThreshold, leavesData,
+			subsecrets, parentSubsecrets, &xUsedCoords, noOfHints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, _ := GetHintedTAnonymityPackets(sharePackets, anonymitySetSize,
+		maxSharesPerPerson, len(secretKey[0]), len(secretKey), &xUsedCoords,
+		encryptionLength)
+	dealer, err := NewHintedTDealer(f, secretKey, absoluteThreshold,
+		subsecrets, parentSubsecrets, xUsedCoords, packets, n, noOfHints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operations := []func() ([]int, error){
+		func() ([]int, error) { return dealer.AddTrustee(2) },
+		func() ([]int, error) { return dealer.RemoveTrustee(3) },
+		func() ([]int, error) { return dealer.ReplaceTrustee(4) },
+	}
+	for i, operation := range operations {
+		if _, err := operation(); err != nil {
+			t.Fatal(err)
+		}
+		recoverer := NewHintedTRecoverer(f, absoluteThreshold)
+		for _, packet := range dealer.AnonymityPackets {
+			if err := recoverer.AddPacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		secret, done, err := recoverer.TryRecover(context.Background())
+		if err != nil || !done || !bytes.Equal(secret, secretKey8) {
+			t.Error("Secret not recovered after operation", i, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package secret_binary_extension
+
+import (
+	"context"
+	"key_recovery/modules/utils"
+	"runtime"
+	"sync/atomic"
+)
+
+// **************************************************************************
+// ************Worker pool of the parallelized recovery***************
+// **************************************************************************
+
+// The subsets are split into many more chunks than routines so that the
+// routines which finish early take the remaining chunks instead of
+// staying idle
+const chunksPerWorker = 16
+const minChunkSize = 64
+
+// NoOfWorkers gives the number of routines that the parallelized recovery
+// runs when n routines are requested
+// A value smaller than one stands for runtime.NumCPU()
+func NoOfWorkers(n int) int {
+	if n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// Chunks of the range of the subsets shared by the routines
+type combinationChunks struct {
+	n, k        int
+	firstRank   uint64
+	noOfSubsets uint64
+	chunkSize   uint64
+	next        atomic.Uint64
+}
+
+// Splits the noOfSubsets subsets starting from the rank firstRank into the
+// chunks for noOfRoutines routines
+func newCombinationChunks(n, k int, firstRank, noOfSubsets uint64,
+	noOfRoutines int) *combinationChunks {
+	chunkSize := noOfSubsets / uint64(noOfRoutines*chunksPerWorker)
+	if chunkSize < minChunkSize {
+		chunkSize = minChunkSize
+	}
+	return &combinationChunks{n: n, k: k, firstRank: firstRank,
+		noOfSubsets: noOfSubsets, chunkSize: chunkSize}
+}
+
+// Gives the iterator over the next chunk which has not been taken by any
+// routine and nil once all the chunks have been taken
+func (c *combinationChunks) take() *utils.CombinationIterator {
+	start := c.next.Add(c.chunkSize) - c.chunkSize
+	if start >= c.noOfSubsets {
+		return nil
+	}
+	count := c.chunkSize
+	if c.noOfSubsets-start < count {
+		count = c.noOfSubsets - start
+	}
+	return utils.NewCombinationIterator(c.n, c.k, c.firstRank+start, count)
+}
+
+// Makes the routine go through the chunks until none are left or the
+// context is cancelled
+func (c *combinationChunks) run(ctx context.Context,
+	compute func(subsets *utils.CombinationIterator) error) error {
+	for subsets := c.take(); subsets != nil; subsets = c.take() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := compute(subsets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
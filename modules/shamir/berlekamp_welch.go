@@ -0,0 +1,203 @@
+package shamir
+
+import (
+	"fmt"
+	"key_recovery/modules/errors"
+	"sort"
+)
+
+// DecodeUniqueX recovers the secret from parts of which some may be wrong
+// It runs the Berlekamp-Welch decoder for every element of the secret, so
+// up to (len(parts) - threshold) / 2 wrong parts are corrected
+// threshold is the number of parts needed for combining when none of them
+// is wrong
+// It also gives the indices of the parts which do not lie on the decoded
+// polynomials
+func (f *Field) DecodeUniqueX(parts []PriShare, threshold int) ([]uint16,
+	[]int, error) {
+	if threshold < 1 {
+		return nil, nil, fmt.Errorf("threshold must be at least one")
+	}
+	if len(parts) < threshold {
+		return nil, nil, fmt.Errorf("less than threshold parts cannot be used to decode the secret")
+	}
+	firstPartLen := len(parts[0].Y)
+	if firstPartLen < 1 {
+		return nil, nil, fmt.Errorf("parts must be at least one uint16 long")
+	}
+	seenX := make(map[uint16]bool)
+	for _, part := range parts {
+		if len(part.Y) != firstPartLen {
+			return nil, nil, fmt.Errorf("all parts must be the same length")
+		}
+		if seenX[part.X] {
+			return nil, nil, fmt.Errorf("all parts must have different x-coordinates")
+		}
+		seenX[part.X] = true
+	}
+
+	maxErrors := (len(parts) - threshold) / 2
+	secret := make([]uint16, firstPartLen)
+	points := make([]pair, len(parts))
+	wrongParts := make(map[int]bool)
+	for i := range secret {
+		for j, part := range parts {
+			points[j] = pair{x: part.X, y: part.Y[i]}
+		}
+		p, err := f.berlekampWelch(points, threshold, maxErrors)
+		if err != nil {
+			return nil, nil, err
+		}
+		secret[i] = p.coefficients[0]
+		for j, point := range points {
+			if f.evaluate(point.x, p) != point.y {
+				wrongParts[j] = true
+			}
+		}
+	}
+	// Every element is decoded on its own, so the parts can be wrong in
+	// different elements as long as no element has too many wrong parts
+	wrong := make([]int, 0, len(wrongParts))
+	for j := range wrongParts {
+		wrong = append(wrong, j)
+	}
+	sort.Ints(wrong)
+	return secret, wrong, nil
+}
+
+// Finds the polynomial P of degree below k going through all the points
+// except at most e of them
+// Q = P * E where the error locator E is monic of degree e and vanishes at
+// the wrong points, so Q(x) = y * E(x) for all the points, which is a
+// linear system in the coefficients of Q and E
+func (f *Field) berlekampWelch(points []pair, k, e int) (polynomial, error) {
+	noOfQ := k + e
+	noOfUnknowns := noOfQ + e
+	// Every row holds the coefficients of the unknowns and then the
+	// constant term
+	rows := make([][]uint16, len(points))
+	for i, point := range points {
+		row := make([]uint16, noOfUnknowns+1)
+		xPower := uint16(1)
+		for m := 0; m < noOfQ; m++ {
+			row[m] = xPower
+			if m < e {
+				// Subtraction is the same as addition in GF(2^16)
+				row[noOfQ+m] = f.Mult(point.y, xPower)
+			}
+			xPower = f.Mult(xPower, point.x)
+		}
+		// y * x^e is the term of the leading coefficient of E
+		xPowerE := uint16(1)
+		for m := 0; m < e; m++ {
+			xPowerE = f.Mult(xPowerE, point.x)
+		}
+		row[noOfUnknowns] = f.Mult(point.y, xPowerE)
+		rows[i] = row
+	}
+	solution, ok := f.solveLinearSystem(rows, noOfUnknowns)
+	if !ok {
+		return polynomial{}, errors.ErrTooManyErrors
+	}
+
+	q := polynomial{coefficients: solution[:noOfQ]}
+	locator := polynomial{coefficients: make([]uint16, e+1)}
+	copy(locator.coefficients, solution[noOfQ:])
+	locator.coefficients[e] = 1
+	p, remainder := f.divide(q, locator)
+	for _, coeff := range remainder.coefficients {
+		if coeff != 0 {
+			return polynomial{}, errors.ErrTooManyErrors
+		}
+	}
+	// P has k coefficients as Q has k + e coefficients
+	p.coefficients = p.coefficients[:k]
+
+	agreements := 0
+	for _, point := range points {
+		if f.evaluate(point.x, p) == point.y {
+			agreements++
+		}
+	}
+	if agreements < len(points)-e {
+		return polynomial{}, errors.ErrTooManyErrors
+	}
+	return p, nil
+}
+
+// Gaussian elimination over GF(2^16)
+// The free unknowns are set to zero, since any solution gives the same
+// quotient Q / E
+// ok is false when the system has no solution
+func (f *Field) solveLinearSystem(rows [][]uint16, noOfUnknowns int) ([]uint16,
+	bool) {
+	pivotColumns := make([]int, 0, noOfUnknowns)
+	pivotRow := 0
+	for col := 0; col < noOfUnknowns && pivotRow < len(rows); col++ {
+		selected := -1
+		for r := pivotRow; r < len(rows); r++ {
+			if rows[r][col] != 0 {
+				selected = r
+				break
+			}
+		}
+		if selected == -1 {
+			continue
+		}
+		rows[pivotRow], rows[selected] = rows[selected], rows[pivotRow]
+		// Scale the pivot to one
+		inverse := f.Div(1, rows[pivotRow][col])
+		for c := col; c <= noOfUnknowns; c++ {
+			rows[pivotRow][c] = f.Mult(rows[pivotRow][c], inverse)
+		}
+		// Clear the column in all the other rows
+		for r := range rows {
+			if r == pivotRow || rows[r][col] == 0 {
+				continue
+			}
+			factor := rows[r][col]
+			for c := col; c <= noOfUnknowns; c++ {
+				rows[r][c] = Add(rows[r][c], f.Mult(factor, rows[pivotRow][c]))
+			}
+		}
+		pivotColumns = append(pivotColumns, col)
+		pivotRow++
+	}
+	// The rows without a pivot must have a zero constant term
+	for r := pivotRow; r < len(rows); r++ {
+		if rows[r][noOfUnknowns] != 0 {
+			return nil, false
+		}
+	}
+	solution := make([]uint16, noOfUnknowns)
+	for r, col := range pivotColumns {
+		solution[col] = rows[r][noOfUnknowns]
+	}
+	return solution, true
+}
+
+// Divides a by the monic polynomial b and gives the quotient and the
+// remainder
+func (f *Field) divide(a, b polynomial) (polynomial, polynomial) {
+	degreeB := len(b.coefficients) - 1
+	remainder := make([]uint16, len(a.coefficients))
+	copy(remainder, a.coefficients)
+	if len(remainder) <= degreeB {
+		return polynomial{coefficients: []uint16{0}},
+			polynomial{coefficients: remainder}
+	}
+	quotient := make([]uint16, len(remainder)-degreeB)
+	for i := len(remainder) - 1; i >= degreeB; i-- {
+		coeff := remainder[i]
+		quotient[i-degreeB] = coeff
+		if coeff == 0 {
+			continue
+		}
+		for j := 0; j <= degreeB; j++ {
+			remainder[i-degreeB+j] = Add(remainder[i-degreeB+j],
+				f.Mult(coeff, b.coefficients[j]))
+		}
+	}
+	return polynomial{coefficients: quotient},
+		polynomial{coefficients: remainder[:degreeB]}
+}
@@ -1,12 +1,14 @@
 package shamir
 
 import (
-	"crypto/rand"
+	"bytes"
 	"crypto/subtle"
+	_ "embed"
 	"encoding/binary"
 	"fmt"
 	"key_recovery/modules/errors"
 	"key_recovery/modules/files"
+	"key_recovery/modules/randomness"
 	"key_recovery/modules/utils"
 	"log"
 )
@@ -22,13 +24,22 @@ type PriShare struct {
 	Y []uint16
 }
 
+// The tables are built into the binary, so that the field does not depend
+// on the working directory
+//
+//go:embed expTable_16.gob
+var expTable16 []byte
+
+//go:embed logTable_16.gob
+var logTable16 []byte
+
 func (f *Field) InitializeTables() {
-	table, err := files.LoadSlice16FromFile("expTable_16.gob")
+	table, err := files.LoadSlice16(bytes.NewReader(expTable16))
 	if err != nil {
 		log.Fatalln(err)
 	}
 	copy(f.expTable[:], table)
-	table, err = files.LoadSlice16FromFile("logTable_16.gob")
+	table, err = files.LoadSlice16(bytes.NewReader(logTable16))
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -59,7 +70,7 @@ func makePolynomial(intercept, degree uint16) (polynomial, error) {
 	var b [2]byte
 
 	for i := 1; i < len(p.coefficients); i++ {
-		_, err := rand.Read(b[:])
+		_, err := randomness.Read(b[:])
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -207,7 +218,7 @@ func (f *Field) Split(secret []uint16, parts, threshold int) (map[uint16][]uint1
 	// Generate x-coordinates for each of the parts
 	buf := make([]byte, 2)
 	for len(out) < parts {
-		if _, err := rand.Read(buf); err != nil {
+		if _, err := randomness.Read(buf); err != nil {
 			return nil, nil, err
 		}
 		x := binary.BigEndian.Uint16(buf)
@@ -249,7 +260,7 @@ func (f *Field) SplitUniqueX(secret []uint16, parts, threshold int,
 	// Generate x-coordinates for each of the parts
 	buf := make([]byte, 2)
 	for len(out) < parts {
-		if _, err := rand.Read(buf); err != nil {
+		if _, err := randomness.Read(buf); err != nil {
 			return nil, nil, err
 		}
 		x := binary.BigEndian.Uint16(buf)
@@ -360,3 +371,33 @@ func (f *Field) CombineUniqueX(parts []PriShare) ([]uint16, error) {
 
 	return secret, nil
 }
+
+// EvaluateUniqueX gives the value at x of the polynomial going through the
+// parts
+// It is used for issuing new parts of an already shared secret
+func (f *Field) EvaluateUniqueX(parts []PriShare, x uint16) ([]uint16, error) {
+	if len(parts) < 1 {
+		return nil, fmt.Errorf("at least one part is needed to evaluate the polynomial")
+	}
+	firstPartLen := len(parts[0].Y)
+	if firstPartLen < 1 {
+		return nil, fmt.Errorf("parts must be at least one uint16 long")
+	}
+	for _, part := range parts {
+		if len(part.Y) != firstPartLen {
+			return nil, fmt.Errorf("all parts must be the same length")
+		}
+		if part.X == x {
+			return nil, fmt.Errorf("x-coordinate is already used by a part")
+		}
+	}
+	value := make([]uint16, firstPartLen)
+	points := make([]pair, len(parts))
+	for i := range value {
+		for p, part := range parts {
+			points[p] = pair{x: part.X, y: part.Y[i]}
+		}
+		value[i] = f.interpolate(points, x)
+	}
+	return value, nil
+}
@@ -144,3 +144,52 @@ func TestCombine(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeUniqueX(t *testing.T) {
+	var f Field
+	f.InitializeTables()
+	secret := KeyBytesToKeyUint16s([]byte("decoding test"))
+	testCases := []struct {
+		parts     int
+		threshold int
+		wrong     []int
+		decodable bool
+	}{
+		{5, 3, nil, true},
+		{5, 3, []int{4}, true},
+		{7, 3, []int{0, 5}, true},
+		{9, 4, []int{1, 2}, true},
+		{6, 3, []int{1, 3}, false},
+		{3, 3, nil, true},
+		{3, 3, []int{2}, false},
+	}
+	for _, tc := range testCases {
+		var xUsedCoords []uint16
+		parts, _, err := f.SplitUniqueX(secret, tc.parts, tc.threshold,
+			&xUsedCoords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range tc.wrong {
+			// Corrupt the same element in all the wrong parts
+			parts[index].Y[0] ^= 0x1234
+		}
+		decoded, wrong, err := f.DecodeUniqueX(parts, tc.threshold)
+		if !tc.decodable {
+			if err == nil && bytes.Equal(Uint16sToBytes(decoded), Uint16sToBytes(secret)) {
+				t.Error("Decoded with too many wrong parts", tc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(tc, err)
+			continue
+		}
+		if !bytes.Equal(Uint16sToBytes(decoded), Uint16sToBytes(secret)) {
+			t.Error("Wrong secret decoded", tc)
+		}
+		if fmt.Sprint(wrong) != fmt.Sprint(append([]int{}, tc.wrong...)) {
+			t.Error("Wrong parts", wrong, "instead of", tc.wrong)
+		}
+	}
+}
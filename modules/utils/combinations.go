@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"key_recovery/modules/errors"
+	"math"
+	"math/bits"
+)
+
+// GetCombinationUint64 gives the number of k-subsets of an n-set
+// Unlike GetCombination, it does not overflow for the large sets
+func GetCombinationUint64(n, k int) (uint64, error) {
+	if k < 0 || n < 0 || k > n {
+		return 0, nil
+	}
+	if k > n-k {
+		k = n - k
+	}
+	val := uint64(1)
+	for i := 0; i < k; i++ {
+		// val * (n - i) is always divisible by (i + 1)
+		hi, lo := bits.Mul64(val, uint64(n-i))
+		if hi >= uint64(i+1) {
+			return 0, errors.ErrTooManyCombinations
+		}
+		val, _ = bits.Div64(hi, lo, uint64(i+1))
+	}
+	return val, nil
+}
+
+// Same as GetCombinationUint64 but the overflowing values are given as the
+// largest value, which is larger than any rank
+func getCombinationSaturated(n, k int) uint64 {
+	val, err := GetCombinationUint64(n, k)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return val
+}
+
+// RankCombination gives the position of the combination in the
+// colexicographic order of the k-subsets
+// The combination has to be sorted in ascending order
+func RankCombination(combination []int) uint64 {
+	var rank uint64
+	for i, c := range combination {
+		rank += getCombinationSaturated(c, i+1)
+	}
+	return rank
+}
+
+// UnrankCombination stores the combination at the rank in the
+// colexicographic order of the k-subsets of an n-set in combination
+// k is given by the length of combination
+func UnrankCombination(rank uint64, n int, combination []int) {
+	c := n - 1
+	for i := len(combination); i > 0; i-- {
+		// Largest c such that C(c, i) <= rank
+		for c >= i && getCombinationSaturated(c, i) > rank {
+			c--
+		}
+		combination[i-1] = c
+		rank -= getCombinationSaturated(c, i)
+		c--
+	}
+}
+
+// CombinationIterator goes through a range of the k-subsets of
+// {0, ..., n-1} in colexicographic order without storing them
+// In this order, the subsets that do not contain any of the last r elements
+// come first, i.e., the subsets containing at least one of the last r
+// elements are the ranks from C(n-r, k) to C(n, k)
+type CombinationIterator struct {
+	n           int
+	combination []int
+	remaining   uint64
+	started     bool
+}
+
+// NewCombinationIterator gives the iterator over count subsets starting
+// from the rank first
+func NewCombinationIterator(n, k int, first, count uint64) *CombinationIterator {
+	it := &CombinationIterator{n: n, combination: make([]int, k),
+		remaining: count}
+	if count > 0 {
+		UnrankCombination(first, n, it.combination)
+	}
+	return it
+}
+
+// Next moves to the next subset and returns false once the range is over
+func (it *CombinationIterator) Next() bool {
+	if it.remaining == 0 {
+		return false
+	}
+	it.remaining--
+	if !it.started {
+		it.started = true
+		return true
+	}
+	k := len(it.combination)
+	if k == 0 {
+		it.remaining = 0
+		return false
+	}
+	// Find the smallest element that can be incremented
+	i := 0
+	for i < k-1 && it.combination[i]+1 == it.combination[i+1] {
+		i++
+	}
+	if it.combination[i]+1 >= it.n {
+		it.remaining = 0
+		return false
+	}
+	it.combination[i]++
+	// Reset the smaller elements
+	for j := 0; j < i; j++ {
+		it.combination[j] = j
+	}
+	return true
+}
+
+// Combination gives the current subset as indices in ascending order
+// The slice is reused by Next
+func (it *CombinationIterator) Combination() []int {
+	return it.combination
+}
+
+// GetCombinationRangeWithLast gives the range of the ranks of the k-subsets
+// of an n-set that contain at least one of the last r elements
+func GetCombinationRangeWithLast(n, k, r int) (uint64, uint64, error) {
+	total, err := GetCombinationUint64(n, k)
+	if err != nil {
+		return 0, 0, err
+	}
+	first, err := GetCombinationUint64(n-r, k)
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, total - first, nil
+}
@@ -2,10 +2,10 @@ package utils
 
 import (
 	"bytes"
-	"crypto/rand"
 	"encoding/binary"
 	"log"
 	"math"
+	randm "math/rand"
 	"strconv"
 )
 
@@ -233,8 +233,9 @@ func CeilDivide(a, b int) int {
 	return int(a/b) + 1
 }
 
-func FlipBitsWithProbability(arr []int, probability1, probability2 uint16,
-	index1, index2 int) []int {
+// The bits are flipped with the draws of the given generator
+func FlipBitsWithProbability(arr []int, probability1,
+	probability2 uint16, index1, index2 int, rng *randm.Rand) []int {
 	copyArr := make([]int, len(arr))
 	copy(copyArr, arr)
 	max := uint16(100)
@@ -242,7 +243,7 @@ func FlipBitsWithProbability(arr []int, probability1, probability2 uint16,
 	count1 := 0
 	count2 := 0
 	for i := range copyArr[:index1] {
-		_, err := rand.Read(buf)
+		_, err := rng.Read(buf)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -256,7 +257,7 @@ func FlipBitsWithProbability(arr []int, probability1, probability2 uint16,
 	// fmt.Println(copyArr[:index1], arr[:index1])
 
 	for i := range copyArr[index1:index2] {
-		_, err := rand.Read(buf)
+		_, err := rng.Read(buf)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -280,11 +281,13 @@ func GenerateTrNonTrBitMatrix(trustees, contacts int) []int {
 	return result
 }
 
-func GenerateProbabilityArray(contacts int) ([]byte, error) {
+// The probabilities are drawn from the given generator
+func GenerateProbabilityArray(contacts int,
+	rng *randm.Rand) ([]byte, error) {
 	byteArray := make([]byte, contacts) // Each uint16 requires 2 bytes
 
 	// Fill the byte slice with random data
-	_, err := rand.Read(byteArray)
+	_, err := rng.Read(byteArray)
 	if err != nil {
 		return nil, err
 	}
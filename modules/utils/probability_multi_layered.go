@@ -2,8 +2,9 @@ package utils
 
 import (
 	"fmt"
+	"key_recovery/modules/errors"
 	randm "math/rand"
-	"time"
+	"sort"
 )
 
 // This function returns the number of layers needed for backing up the secret
@@ -136,10 +137,9 @@ func MulSplitShares(trustees int, largestShareSetSize int,
 // In general, the number of shares is more than the number of trustees
 // Therefore, this function assigns the number of shares each person should
 // receive
+// The extra shares are assigned with the draws of the given generator
 func GetPersonWiseShareNumber(trustees int, totalShares int,
-	sharesPerPerson int) ([]int, int) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+	sharesPerPerson int, rng *randm.Rand) ([]int, int) {
 	outputShareNumbers := make([]int, trustees)
 	for i := range outputShareNumbers {
 		outputShareNumbers[i] = sharesPerPerson
@@ -161,6 +161,46 @@ func GetPersonWiseShareNumber(trustees int, totalShares int,
 	return outputShareNumbers, maxSharesPerPerson
 }
 
+// This function assigns the number of shares to each person in proportion
+// to their weights
+// Every person first receives one share and the remaining shares are
+// distributed in proportion to the weights with the largest remainders
+// getting the leftovers
+func GetWeightedPersonWiseShareNumber(weights []int,
+	totalShares int, rng *randm.Rand) ([]int, int, error) {
+	trustees := len(weights)
+	if trustees == 0 || totalShares < trustees {
+		return nil, 0, errors.ErrInvalidInput
+	}
+	totalWeight := 0
+	for _, weight := range weights {
+		if weight <= 0 {
+			return nil, 0, errors.ErrInvalidInput
+		}
+		totalWeight += weight
+	}
+	outputShareNumbers := make([]int, trustees)
+	remainders := make([]int, trustees)
+	sharesLeft := totalShares - trustees
+	assignedShares := 0
+	for i, weight := range weights {
+		outputShareNumbers[i] = 1 + sharesLeft*weight/totalWeight
+		remainders[i] = sharesLeft * weight % totalWeight
+		assignedShares += outputShareNumbers[i]
+	}
+	// Ties between the remainders are broken randomly
+	order := rng.Perm(trustees)
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; assignedShares < totalShares; i++ {
+		outputShareNumbers[order[i]] += 1
+		assignedShares++
+	}
+	maxSharesPerPerson := outputShareNumbers[FindMaxElementIndexInt(outputShareNumbers)]
+	return outputShareNumbers, maxSharesPerPerson, nil
+}
+
 // Here the number of shares for each secret is fixed
 // This is outdated and does not apply to the current design
 func GenerateProbTree(layers, layerPacketsNum int) ([]int,
@@ -285,11 +325,12 @@ func GenerateProbTreeFixedThSingleShare(layers, higherLayerPacketsNum,
 
 // Generates share packets for each trustee
 // This is needed for probability evaluation
-func GetSizedRandomPackets(sharePackets []int, trustees int, size int) [][]int {
+func GetSizedRandomPackets(sharePackets []int, trustees int, size int,
+	rng *randm.Rand) [][]int {
 	var outputPackets [][]int
 	tempPackets := make([]int, len(sharePackets))
 	copy(tempPackets, sharePackets)
-	Shuffle(tempPackets)
+	Shuffle(tempPackets, rng)
 	// noOfPackets := len(sharePackets) / trustees
 	for i := 0; i < trustees; i++ {
 		outputPackets = append(outputPackets, tempPackets[i*size:(i+1)*size])
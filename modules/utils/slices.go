@@ -4,7 +4,6 @@ import (
 	"log"
 	randm "math/rand"
 	"slices"
-	"time"
 )
 
 // This is finding the index of the maximum element in a slice
@@ -83,10 +82,9 @@ func GetIndex(slice []int, target int) int {
 	return -1 // Return -1 if the target element is not found
 }
 
-// Shuffles the elements of a slice
-func Shuffle(slice []int) {
-	source := randm.NewSource(time.Now().UnixNano()) // Seed the random number generator
-	rng := randm.New(source)
+// Shuffles the elements of a slice with the given generator
+// The simulations running in parallel give every routine its own generator
+func Shuffle(slice []int, rng *randm.Rand) {
 	// Fisher-Yates shuffle algorithm
 	for i := len(slice) - 1; i > 0; i-- {
 		j := rng.Intn(i + 1)
@@ -248,6 +246,11 @@ func UpdateOrderBinExt(hintedPeople []int, accessOrder *[]int,
 			continue
 		} else {
 			oldIndex := GetIndex((*accessOrder), hintedPeople[i]-1)
+			// The hinted person may not be a part of the access order
+			// e.g., when only some of the packets have been collected
+			if oldIndex == -1 {
+				continue
+			}
 			newIndex := obtainedLength
 			for {
 				if !IsInSlice(hintedPeople, (*accessOrder)[newIndex]) {
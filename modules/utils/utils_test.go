@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	randm "math/rand"
 	"testing"
 )
 
@@ -319,6 +320,132 @@ func TestFlipBitsWithProbability(t *testing.T) {
 	for _, tc := range testCases {
 		fmt.Println(tc)
 		arr := GenerateTrNonTrBitMatrix(tc.i1, tc.i2)
-		_ = FlipBitsWithProbability(arr, tc.p1, tc.p2, tc.i1, tc.i2)
+		_ = FlipBitsWithProbability(arr, tc.p1, tc.p2, tc.i1, tc.i2,
+			randm.New(randm.NewSource(1)))
+	}
+}
+
+func TestGetWeightedPersonWiseShareNumber(t *testing.T) {
+	rng := randm.New(randm.NewSource(1))
+	testCases := []struct {
+		weights     []int
+		totalShares int
+		expected    []int
+	}{
+		{[]int{1, 1, 1}, 9, []int{3, 3, 3}},
+		{[]int{2, 1}, 30, []int{20, 10}},
+		{[]int{3, 1, 1, 1}, 10, []int{4, 2, 2, 2}},
+		{[]int{100, 1, 1}, 12, []int{10, 1, 1}},
+	}
+	for _, tc := range testCases {
+		shareNumbers, maxSharesPerPerson, err :=
+			GetWeightedPersonWiseShareNumber(tc.weights, tc.totalShares, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range tc.expected {
+			if shareNumbers[i] != tc.expected[i] {
+				t.Error("Wrong distribution", shareNumbers, tc.expected)
+				break
+			}
+		}
+		if maxSharesPerPerson != tc.expected[FindMaxElementIndexInt(tc.expected)] {
+			t.Error("Wrong maximum number of shares", maxSharesPerPerson)
+		}
+	}
+	// The leftovers are still distributed completely
+	shareNumbers, _, _ := GetWeightedPersonWiseShareNumber([]int{1, 1, 1}, 11,
+		rng)
+	if GetSumSlice(shareNumbers) != 11 {
+		t.Error("Not all the shares are distributed", shareNumbers)
+	}
+	if _, _, err := GetWeightedPersonWiseShareNumber([]int{1, 0}, 10, rng); err == nil {
+		t.Error("Zero weight accepted")
+	}
+	if _, _, err := GetWeightedPersonWiseShareNumber([]int{1, 1, 1}, 2, rng); err == nil {
+		t.Error("Fewer shares than trustees accepted")
+	}
+}
+
+func TestCombinationIterator(t *testing.T) {
+	testCases := []struct {
+		n int
+		k int
+		r int
+	}{
+		{12, 3, 4},
+		{10, 4, 1},
+		{9, 2, 9},
+		{6, 6, 2},
+	}
+	for _, tc := range testCases {
+		// The last r elements are the relevant ones
+		relevantIndices := GenerateOffsettedIndicesSetUint16(tc.r,
+			uint16(tc.n-tc.r))
+		expected := GenerateSubsetsOfSizeUint16Filtered(
+			GenerateIndicesSetUint16(tc.n), tc.k, relevantIndices)
+		expectedSubsets := make(map[string]bool)
+		for i := 0; i < len(expected)/tc.k; i++ {
+			expectedSubsets[fmt.Sprint(expected[i*tc.k:(i+1)*tc.k])] = true
+		}
+		first, count, err := GetCombinationRangeWithLast(tc.n, tc.k, tc.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(count) != len(expectedSubsets) {
+			t.Error("Wrong number of subsets", count, len(expectedSubsets))
+		}
+		// Split the range into parts as done for the routines
+		obtained := 0
+		for part := uint64(0); part < 3; part++ {
+			partCount := count / 3
+			if part == 2 {
+				partCount = count - 2*(count/3)
+			}
+			it := NewCombinationIterator(tc.n, tc.k, first+part*(count/3),
+				partCount)
+			for it.Next() {
+				subset := make([]uint16, tc.k)
+				for i, index := range it.Combination() {
+					subset[i] = uint16(index)
+				}
+				if !expectedSubsets[fmt.Sprint(subset)] {
+					t.Error("Unexpected subset", subset)
+				}
+				delete(expectedSubsets, fmt.Sprint(subset))
+				obtained++
+			}
+		}
+		if obtained != int(count) || len(expectedSubsets) != 0 {
+			t.Error("Subsets missing", obtained, len(expectedSubsets))
+		}
+	}
+}
+
+func TestRankCombination(t *testing.T) {
+	combination := make([]int, 4)
+	for rank := uint64(0); rank < 210; rank++ {
+		UnrankCombination(rank, 10, combination)
+		if RankCombination(combination) != rank {
+			t.Error("Wrong rank", rank, combination)
+		}
+	}
+	testCases := []struct {
+		n        int
+		k        int
+		expected uint64
+	}{
+		{20, 3, 1140},
+		{150, 4, 20260275},
+		{60, 30, 118264581564861424},
+	}
+	for _, tc := range testCases {
+		val, err := GetCombinationUint64(tc.n, tc.k)
+		if err != nil || val != tc.expected {
+			t.Error("Wrong number of combinations", tc, val, err)
+		}
+	}
+	if _, err := GetCombinationUint64(1000, 500); err == nil {
+		t.Error("Overflow not detected")
 	}
 }